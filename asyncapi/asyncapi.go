@@ -0,0 +1,223 @@
+// Package asyncapi generates an AsyncAPI 3 document for event payloads described by mason entities.
+// Payload schemas are emitted with the same component names and refs as the OpenAPI output, so both documents
+// can share their component schemas.
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/swaggest/jsonschema-go"
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+)
+
+const version = "3.0.0"
+
+const schemaRefPrefix = "#/components/schemas/"
+
+var schemaRefRe = regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`)
+
+// Action is the AsyncAPI operation action, from the point of view of the application.
+type Action string
+
+const (
+	Send    Action = "send"
+	Receive Action = "receive"
+)
+
+// Channel describes where a set of events is published.
+type Channel struct {
+	Name        string
+	Address     string
+	Description string
+	Action      Action
+	Messages    []model.Entity
+}
+
+type Document struct {
+	AsyncAPI   string               `json:"asyncapi"`
+	Info       Info                 `json:"info"`
+	Channels   map[string]channel   `json:"channels"`
+	Operations map[string]operation `json:"operations"`
+	Components components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type ref struct {
+	Ref string `json:"$ref"`
+}
+
+type channel struct {
+	Address     string         `json:"address,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Messages    map[string]ref `json:"messages"`
+}
+
+type operation struct {
+	Action   Action `json:"action"`
+	Channel  ref    `json:"channel"`
+	Messages []ref  `json:"messages"`
+}
+
+type message struct {
+	Name        string           `json:"name"`
+	ContentType string           `json:"contentType"`
+	Payload     ref              `json:"payload"`
+	Examples    []messageExample `json:"examples,omitempty"`
+}
+
+type messageExample struct {
+	Payload json.RawMessage `json:"payload"`
+}
+
+type components struct {
+	Schemas  map[string]jsonschema.Schema `json:"schemas"`
+	Messages map[string]message           `json:"messages"`
+}
+
+type Generator struct {
+	api      *mason.API
+	info     Info
+	channels []Channel
+}
+
+// NewGenerator creates an AsyncAPI generator. The API is used to resolve refs to other registered entities.
+func NewGenerator(api *mason.API, info Info) *Generator {
+	return &Generator{
+		api:  api,
+		info: info,
+	}
+}
+
+// AddChannel adds a channel, and the messages that are published on it.
+func (g *Generator) AddChannel(ch Channel) *Generator {
+	g.channels = append(g.channels, ch)
+	return g
+}
+
+// Document builds the AsyncAPI document.
+func (g *Generator) Document() (*Document, error) {
+	doc := &Document{
+		AsyncAPI:   version,
+		Info:       g.info,
+		Channels:   make(map[string]channel),
+		Operations: make(map[string]operation),
+		Components: components{
+			Schemas:  make(map[string]jsonschema.Schema),
+			Messages: make(map[string]message),
+		},
+	}
+
+	for _, ch := range g.channels {
+		if ch.Name == "" {
+			return nil, fmt.Errorf("channel name cannot be empty")
+		}
+		action := ch.Action
+		if action == "" {
+			action = Send
+		}
+
+		c := channel{
+			Address:     ch.Address,
+			Description: ch.Description,
+			Messages:    make(map[string]ref),
+		}
+		op := operation{
+			Action:  action,
+			Channel: ref{Ref: "#/channels/" + ch.Name},
+		}
+
+		for _, ent := range ch.Messages {
+			name := mason.RecursivelyUnwrap(ent).Name()
+			if err := g.addSchema(doc, ent); err != nil {
+				return nil, err
+			}
+
+			doc.Components.Messages[name] = message{
+				Name:        name,
+				ContentType: "application/json",
+				Payload:     ref{Ref: schemaRefPrefix + name},
+				Examples:    []messageExample{{Payload: ent.Example()}},
+			}
+			c.Messages[name] = ref{Ref: "#/components/messages/" + name}
+			op.Messages = append(op.Messages, ref{Ref: "#/channels/" + ch.Name + "/messages/" + name})
+		}
+
+		doc.Channels[ch.Name] = c
+		doc.Operations[string(action)+"_"+ch.Name] = op
+	}
+
+	return doc, nil
+}
+
+// Schema builds the document and returns it as JSON.
+func (g *Generator) Schema() ([]byte, error) {
+	doc, err := g.Document()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(doc)
+}
+
+// addSchema adds the schema of the entity, its nested definitions, and any registered entity it references.
+func (g *Generator) addSchema(doc *Document, ent model.WithSchema) error {
+	ent = mason.RecursivelyUnwrap(ent)
+	if _, ok := doc.Components.Schemas[ent.Name()]; ok {
+		return nil
+	}
+
+	sch, err := mason.NewModel(ent).JSONSchema()
+	if err != nil {
+		return fmt.Errorf("failed to get JSON schema for %s: %w", ent.Name(), err)
+	}
+
+	defs := sch.Definitions
+	sch.Definitions = nil
+	doc.Components.Schemas[ent.Name()] = sch
+	// the refs are scanned in the schema and in each of its definitions, which are components too
+	added := []jsonschema.Schema{sch}
+	for name, def := range defs {
+		if def.TypeObject != nil {
+			doc.Components.Schemas[name] = *def.TypeObject
+			added = append(added, *def.TypeObject)
+		}
+	}
+
+	refs := []string{}
+	for _, schema := range added {
+		raw, err := json.Marshal(schema)
+		if err != nil {
+			return err
+		}
+		for _, m := range schemaRefRe.FindAllStringSubmatch(string(raw), -1) {
+			refs = append(refs, m[1])
+		}
+	}
+	sort.Strings(refs)
+	refs = slices.Compact(refs)
+
+	for _, name := range refs {
+		if _, ok := doc.Components.Schemas[name]; ok {
+			continue
+		}
+		refEnt, ok := g.api.GetModel(name)
+		if !ok {
+			return fmt.Errorf("entity %s referenced by %s not found", name, ent.Name())
+		}
+		if err := g.addSchema(doc, refEnt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package asyncapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/asyncapi"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestDocument(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+
+	gen := asyncapi.NewGenerator(api, asyncapi.Info{Title: "Events", Version: "1.0.0"}).
+		AddChannel(asyncapi.Channel{
+			Name:     "orders",
+			Address:  "orders.created",
+			Messages: []model.Entity{&OrderCreated{}},
+		})
+
+	doc, err := gen.Document()
+	assert.NilError(t, err)
+
+	assert.Equal(t, "3.0.0", doc.AsyncAPI)
+	assert.Equal(t, "#/components/messages/OrderCreated", doc.Channels["orders"].Messages["OrderCreated"].Ref)
+	assert.Equal(t, asyncapi.Send, doc.Operations["send_orders"].Action)
+
+	// nested definitions are lifted into the shared components
+	_, ok := doc.Components.Schemas["Money"]
+	assert.Assert(t, ok)
+
+	raw, err := json.Marshal(doc.Components.Schemas["OrderCreated"])
+	assert.NilError(t, err)
+	assert.Equal(t, `{"examples":[{"total":{"amount":10}}],"properties":{"total":{"$ref":"#/components/schemas/Money"}},"type":"object"}`, string(raw))
+}
+
+var _ model.Entity = (*OrderCreated)(nil)
+
+type OrderCreated struct {
+	Total struct {
+		Amount int `json:"amount"`
+	} `json:"total"`
+}
+
+func (o *OrderCreated) Example() []byte {
+	return []byte(`{"total": {"amount": 10}}`)
+}
+
+func (o *OrderCreated) Marshal() (json.RawMessage, error) {
+	return json.Marshal(o)
+}
+
+func (o *OrderCreated) Name() string {
+	return "OrderCreated"
+}
+
+func (o *OrderCreated) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"total": {"$ref": "#/definitions/Money"}
+		},
+		"definitions": {
+			"Money": {"type": "object", "properties": {"amount": {"type": "integer"}}}
+		}
+	}`)
+}
+
+func (o *OrderCreated) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, o)
+}
+
+func TestDocument_NestedRefs(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("countries").Register(mason.HandleGet(func(ctx context.Context, r *http.Request, _ model.Nil) (*Country, error) {
+		return &Country{}, nil
+	}).Path("/countries/{code}").WithOpID("get_country"))
+
+	doc, err := asyncapi.NewGenerator(api, asyncapi.Info{Title: "Events", Version: "1.0.0"}).
+		AddChannel(asyncapi.Channel{
+			Name:     "orders",
+			Address:  "orders.shipped",
+			Messages: []model.Entity{&OrderShipped{}},
+		}).
+		Document()
+	assert.NilError(t, err)
+
+	// the registered entity is only referenced by a definition of the payload
+	raw, err := json.Marshal(doc.Components.Schemas["Address"])
+	assert.NilError(t, err)
+	assert.Equal(t, `{"properties":{"country":{"$ref":"#/components/schemas/Country"}},"type":"object"}`, string(raw))
+	_, ok := doc.Components.Schemas["Country"]
+	assert.Assert(t, ok)
+}
+
+var _ model.Entity = (*OrderShipped)(nil)
+
+type OrderShipped struct {
+	Address struct {
+		Country Country `json:"country"`
+	} `json:"address"`
+}
+
+func (o *OrderShipped) Example() []byte {
+	return []byte(`{"address": {"country": {"code": "CH"}}}`)
+}
+
+func (o *OrderShipped) Marshal() (json.RawMessage, error) {
+	return json.Marshal(o)
+}
+
+func (o *OrderShipped) Name() string {
+	return "OrderShipped"
+}
+
+func (o *OrderShipped) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"address": {"$ref": "#/definitions/Address"}
+		},
+		"definitions": {
+			"Address": {"type": "object", "properties": {"country": {"$ref": "#/definitions/Country"}}}
+		}
+	}`)
+}
+
+func (o *OrderShipped) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, o)
+}
+
+var _ model.Entity = (*Country)(nil)
+
+type Country struct {
+	Code string `json:"code"`
+}
+
+func (c *Country) Example() []byte {
+	return []byte(`{"code": "CH"}`)
+}
+
+func (c *Country) Marshal() (json.RawMessage, error) {
+	return json.Marshal(c)
+}
+
+func (c *Country) Name() string {
+	return "Country"
+}
+
+func (c *Country) Schema() []byte {
+	return []byte(`{"type": "object", "properties": {"code": {"type": "string"}}}`)
+}
+
+func (c *Country) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, c)
+}
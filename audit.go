@@ -0,0 +1,143 @@
+package mason
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// AuditKind is the kind of a policy violation found by API.Audit.
+type AuditKind string
+
+const (
+	// AuditDeprecatedTooLong flags the operations deprecated for longer than MaxDeprecation, which are due for
+	// removal.
+	AuditDeprecatedTooLong AuditKind = "deprecated_too_long"
+	// AuditBetaTooLong flags the beta operations older than MaxBeta, which are due for promotion or removal.
+	AuditBetaTooLong AuditKind = "beta_too_long"
+	// AuditMissingOwner flags the operations without an owner, see Builder.WithOwner.
+	AuditMissingOwner AuditKind = "missing_owner"
+)
+
+func WithOperationOwner(owner string) Option {
+	return func(m *Operation) {
+		m.Owner = owner
+	}
+}
+
+func WithOperationDeprecation(since time.Time) Option {
+	return func(m *Operation) {
+		m.DeprecatedSince = since
+	}
+}
+
+func WithOperationBetaSince(since time.Time) Option {
+	return func(m *Operation) {
+		m.BetaSince = since
+	}
+}
+
+// Deprecated reports whether the operation is deprecated, see Builder.WithDeprecation.
+func (op Operation) Deprecated() bool {
+	return !op.DeprecatedSince.IsZero()
+}
+
+type auditOptions struct {
+	now            time.Time
+	maxDeprecation time.Duration
+	maxBeta        time.Duration
+}
+
+type AuditOption func(*auditOptions)
+
+// AuditAt audits the operations as of the given time, the current time by default.
+func AuditAt(now time.Time) AuditOption {
+	return func(o *auditOptions) {
+		o.now = now
+	}
+}
+
+// MaxDeprecation sets how long an operation can stay deprecated before it is flagged, 180 days by default. Zero
+// disables the check.
+func MaxDeprecation(d time.Duration) AuditOption {
+	return func(o *auditOptions) {
+		o.maxDeprecation = d
+	}
+}
+
+// MaxBeta sets how long an operation can stay in beta before it is flagged, 90 days by default. Zero disables the
+// check. The beta operations registered without a date, see Builder.WithBetaSince, are not checked.
+func MaxBeta(d time.Duration) AuditOption {
+	return func(o *auditOptions) {
+		o.maxBeta = d
+	}
+}
+
+// AuditReport lists the operations violating the deprecation policy of the API, e.g. for a quarterly review. It is
+// JSON serializable.
+type AuditReport struct {
+	AuditedAt time.Time      `json:"auditedAt"`
+	Findings  []AuditFinding `json:"findings"`
+}
+
+// AuditFinding is a policy violation of an operation.
+type AuditFinding struct {
+	Kind        AuditKind `json:"kind"`
+	OperationID string    `json:"operationID"`
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	Owner       string    `json:"owner,omitempty"`
+	// Since is when the operation was deprecated or released in beta, for the findings on their age.
+	Since   time.Time `json:"since,omitzero"`
+	Message string    `json:"message"`
+}
+
+// Audit returns the operations deprecated for too long, the beta operations that are too old and the operations
+// without an owner, sorted by operation ID.
+func (a *API) Audit(opts ...AuditOption) AuditReport {
+	options := auditOptions{
+		now:            time.Now(),
+		maxDeprecation: 180 * 24 * time.Hour,
+		maxBeta:        90 * 24 * time.Hour,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	report := AuditReport{AuditedAt: options.now, Findings: []AuditFinding{}}
+	a.ForEachOperation(func(_ string, op Operation) {
+		add := func(kind AuditKind, since time.Time, format string, args ...any) {
+			report.Findings = append(report.Findings, AuditFinding{
+				Kind:        kind,
+				OperationID: op.OperationID,
+				Method:      op.Method,
+				Path:        op.Path,
+				Owner:       op.Owner,
+				Since:       since,
+				Message:     fmt.Sprintf(format, args...),
+			})
+		}
+
+		if op.Owner == "" {
+			add(AuditMissingOwner, time.Time{}, "The operation has no owner")
+		}
+		if age := options.now.Sub(op.DeprecatedSince); op.Deprecated() && options.maxDeprecation > 0 && age > options.maxDeprecation {
+			add(AuditDeprecatedTooLong, op.DeprecatedSince, "The operation has been deprecated for %d days, longer than the %d days allowed",
+				days(age), days(options.maxDeprecation))
+		}
+		if age := options.now.Sub(op.BetaSince); op.Visibility == VisibilityBeta && !op.BetaSince.IsZero() && options.maxBeta > 0 && age > options.maxBeta {
+			add(AuditBetaTooLong, op.BetaSince, "The operation has been in beta for %d days, longer than the %d days allowed",
+				days(age), days(options.maxBeta))
+		}
+	})
+	slices.SortFunc(report.Findings, func(a, b AuditFinding) int {
+		return cmp.Or(cmp.Compare(a.OperationID, b.OperationID), cmp.Compare(a.Kind, b.Kind))
+	})
+
+	return report
+}
+
+func days(d time.Duration) int {
+	return int(d / (24 * time.Hour))
+}
@@ -0,0 +1,84 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestAudit(t *testing.T) {
+	listItems := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{}, nil
+	}
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	days := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandleGet(listItems).Path("/items").WithOpID("list_items").WithOwner("catalog"))
+	grp.Register(mason.HandleGet(listItems).Path("/v1/items").WithOpID("list_items_v1").WithOwner("catalog").WithDeprecation(days(200)))
+	grp.Register(mason.HandleGet(listItems).Path("/v2/items").WithOpID("list_items_v2").WithOwner("catalog").WithDeprecation(days(30)))
+	grp.Register(mason.HandlePost(createItem).Path("/items/import").WithOpID("import_items").WithBetaSince(days(120)))
+	grp.Register(mason.HandlePost(createItem).Path("/items/sync").WithOpID("sync_items").WithOwner("sync").WithBetaSince(days(10)))
+
+	op, ok := api.GetOperationByID("import_items")
+	assert.Assert(t, ok)
+	assert.Equal(t, mason.VisibilityBeta, op.Visibility)
+
+	report := api.Audit(mason.AuditAt(now))
+	assert.Equal(t, now, report.AuditedAt)
+	assert.DeepEqual(t, []mason.AuditFinding{
+		{
+			Kind:        mason.AuditBetaTooLong,
+			OperationID: "import_items",
+			Method:      http.MethodPost,
+			Path:        "/items/import",
+			Since:       days(120),
+			Message:     "The operation has been in beta for 120 days, longer than the 90 days allowed",
+		},
+		{
+			Kind:        mason.AuditMissingOwner,
+			OperationID: "import_items",
+			Method:      http.MethodPost,
+			Path:        "/items/import",
+			Message:     "The operation has no owner",
+		},
+		{
+			Kind:        mason.AuditDeprecatedTooLong,
+			OperationID: "list_items_v1",
+			Method:      http.MethodGet,
+			Path:        "/v1/items",
+			Owner:       "catalog",
+			Since:       days(200),
+			Message:     "The operation has been deprecated for 200 days, longer than the 180 days allowed",
+		},
+	}, report.Findings)
+
+	// the dates survive the registry export
+	data, err := json.Marshal(api.Registry())
+	assert.NilError(t, err)
+	var reg mason.Registry
+	assert.NilError(t, json.Unmarshal(data, &reg))
+	v1, ok := reg.FindByOpID("list_items_v1")
+	assert.Assert(t, ok)
+	assert.Assert(t, v1.DeprecatedSince.Equal(days(200)))
+	assert.Equal(t, "catalog", v1.Owner)
+
+	// the periods are configurable, and zero disables the checks
+	report = api.Audit(mason.AuditAt(now), mason.MaxDeprecation(0), mason.MaxBeta(7*24*time.Hour))
+	var kinds []string
+	for _, f := range report.Findings {
+		kinds = append(kinds, f.OperationID+":"+string(f.Kind))
+	}
+	assert.DeepEqual(t, []string{"import_items:beta_too_long", "import_items:missing_owner", "sync_items:beta_too_long"}, kinds)
+}
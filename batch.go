@@ -0,0 +1,173 @@
+package mason
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+var (
+	_ Runtime      = (*BatchRuntime)(nil)
+	_ RouteRemover = (*BatchRuntime)(nil)
+	_ RouteGate    = (*BatchRuntime)(nil)
+)
+
+// BatchRuntime wraps a Runtime, and keeps track of the registered handlers so a single batch request can execute
+// many operations. Each operation goes through the handler of its route, so its body is validated against the
+// schema of its own input entity.
+type BatchRuntime struct {
+	Runtime
+	routes routeTable
+}
+
+func NewBatchRuntime(rtm Runtime) *BatchRuntime {
+	return &BatchRuntime{
+		Runtime: rtm,
+	}
+}
+
+func (b *BatchRuntime) Handle(method string, path string, handler WebHandler, mws ...func(WebHandler) WebHandler) {
+	// the wrapped runtime panics on a route registered twice, which must not replace the one served already
+	b.Runtime.Handle(method, path, handler, mws...)
+	b.routes.add(method, path, handler, mws...)
+}
+
+// Unhandle removes the route from the batches and from the wrapped runtime, which must be a RouteRemover.
+func (b *BatchRuntime) Unhandle(method string, path string) bool {
+	return b.routes.unhandle(b.Runtime, method, path)
+}
+
+// Gate gates the route on the wrapped runtime, if it is a RouteGate.
+func (b *BatchRuntime) Gate(method string, path string, enabled func(r *http.Request) bool) {
+	gate(b.Runtime, method, path, enabled)
+}
+
+// Unwrap returns the wrapped runtime, which serves the requests.
+func (b *BatchRuntime) Unwrap() Runtime {
+	return b.Runtime
+}
+
+// Route returns the builder for the batch endpoint, which is registered and documented like any other route:
+//
+//	api.NewRouteGroup("batch").Register(batch.Route(api).Path("/batch").WithOpID("batch"))
+func (b *BatchRuntime) Route(api *API) Builder {
+	return HandlePost(func(ctx context.Context, r *http.Request, in *model.BatchRequest, _ model.Nil) (*model.BatchResponse, error) {
+		rsp := &model.BatchResponse{Results: make([]model.BatchResult, 0, len(in.Operations))}
+		for _, op := range in.Operations {
+			res := b.execute(ctx, api, r, op)
+			if res.Status >= 200 && res.Status < 300 {
+				rsp.Succeeded++
+			} else {
+				rsp.Failed++
+			}
+			rsp.Results = append(rsp.Results, res)
+		}
+
+		return rsp, nil
+	}).WithSuccessCode(http.StatusOK)
+}
+
+// execute runs a single batch operation. Errors are reported in the result, so they never fail the whole batch.
+func (b *BatchRuntime) execute(ctx context.Context, api *API, parent *http.Request, bop model.BatchOperation) model.BatchResult {
+	result := func(status int, body any) model.BatchResult {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return model.BatchResult{ID: bop.ID, Status: http.StatusInternalServerError}
+		}
+		return model.BatchResult{ID: bop.ID, Status: status, Body: raw}
+	}
+
+	target, err := url.Parse(bop.Path)
+	if err != nil {
+		return result(http.StatusBadRequest, err.Error())
+	}
+
+	op, ok := api.matchOperation(bop.Method, target.Path)
+	if !ok {
+		return result(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	}
+	if op.Input != nil && op.Input.Name() == (&model.BatchRequest{}).Name() {
+		return result(http.StatusBadRequest, "nested batches are not supported")
+	}
+
+	handler, ok := b.routes.get(op.Method, op.Path)
+	if !ok {
+		return result(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	}
+
+	body := bop.Body
+	if len(body) == 0 {
+		body = json.RawMessage("{}")
+	}
+	req, err := http.NewRequestWithContext(ctx, bop.Method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return result(http.StatusBadRequest, err.Error())
+	}
+	// sub-requests inherit the headers of the batch, e.g. for authentication
+	req.Header = parent.Header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range pathValues(op.Path, target.Path) {
+		req.SetPathValue(name, value)
+	}
+
+	rec := &recordingWriter{header: make(http.Header)}
+	// like for the top-level requests, an error cannot change a response that is already written
+	if err := handler(ctx, rec, req); err != nil && rec.code == 0 && rec.body.Len() == 0 {
+		if !renderError(ctx, api, rec, req, err) {
+			return result(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		}
+	}
+
+	raw := bytes.TrimSpace(rec.body.Bytes())
+	if len(raw) == 0 {
+		return model.BatchResult{ID: bop.ID, Status: rec.status()}
+	}
+	if !json.Valid(raw) {
+		return result(rec.status(), string(raw))
+	}
+
+	return model.BatchResult{ID: bop.ID, Status: rec.status(), Body: raw}
+}
+
+// renderError renders the error of a sub-request like the one of a top-level request: the errors of the catalog with
+// their status, and the validation errors with a 422. It reports false for the other errors, whose message may be
+// internal.
+func renderError(ctx context.Context, api *API, w http.ResponseWriter, r *http.Request, err error) bool {
+	if def, body, ok := api.matchError(err); ok {
+		return api.Respond(ctx, w, setRetryAfter(w, body, def.retryDelay(err)), def.Status) == nil
+	}
+
+	var fe model.ValidationError
+	if errors.As(api.translateError(r, err), &fe) {
+		return api.Respond(ctx, w, fe, http.StatusUnprocessableEntity) == nil
+	}
+
+	return false
+}
+
+// pathValues extracts the values of the params in a route pattern like /users/{id} from a concrete path.
+func pathValues(pattern string, path string) map[string]string {
+	values := make(map[string]string)
+	pp := strings.Split(strings.Trim(pattern, "/"), "/")
+	sp := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, seg := range pp {
+		if i >= len(sp) || !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+		name := strings.Trim(seg, "{}")
+		if strings.HasSuffix(name, "...") {
+			values[strings.TrimSuffix(name, "...")] = strings.Join(sp[i:], "/")
+			break
+		}
+		values[name] = sp[i]
+	}
+
+	return values
+}
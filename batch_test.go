@@ -0,0 +1,113 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestBatchRuntime(t *testing.T) {
+	rtm := mason.NewHTTPRuntime()
+	batch := mason.NewBatchRuntime(rtm)
+	api := mason.NewAPI(batch)
+	api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).Path("/items/{id}").WithOpID("get_item"))
+	api.NewRouteGroup("items").Register(mason.HandlePost(CreateItem).Path("/items").WithOpID("create_item"))
+	api.NewRouteGroup("batch").Register(batch.Route(api).Path("/batch").WithOpID("batch"))
+
+	req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`{
+		"operations": [
+			{"id": "1", "method": "POST", "path": "/items", "body": {"title": "a"}},
+			{"id": "2", "method": "POST", "path": "/items", "body": {"title": 1}},
+			{"id": "3", "method": "GET", "path": "/items/123"},
+			{"id": "4", "method": "GET", "path": "/nope"}
+		]
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var rsp model.BatchResponse
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+	assert.Equal(t, 2, rsp.Succeeded)
+	assert.Equal(t, 2, rsp.Failed)
+
+	statuses := make(map[string]int)
+	for _, res := range rsp.Results {
+		statuses[res.ID] = res.Status
+	}
+	assert.DeepEqual(t, map[string]int{
+		"1": http.StatusCreated,
+		"2": http.StatusUnprocessableEntity,
+		"3": http.StatusOK,
+		"4": http.StatusNotFound,
+	}, statuses)
+	assert.Equal(t, `{"title":"a"}`, string(rsp.Results[0].Body))
+}
+
+func TestBatchRuntime_Precedence(t *testing.T) {
+	getLatest := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "latest"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	batch := mason.NewBatchRuntime(rtm)
+	api := mason.NewAPI(batch)
+	api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).Path("/items/{id}").WithOpID("get_item"))
+	api.NewRouteGroup("items").Register(mason.HandleGet(getLatest).Path("/items/latest").WithOpID("get_latest_item"))
+	api.NewRouteGroup("batch").Register(batch.Route(api).Path("/batch").WithOpID("batch"))
+
+	req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`{
+		"operations": [{"id": "1", "method": "GET", "path": "/items/latest"}]
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var rsp model.BatchResponse
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+	assert.Equal(t, `{"title":"latest"}`, string(rsp.Results[0].Body))
+}
+
+func TestBatchRuntime_Errors(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		if r.PathValue("id") == "quota" {
+			return nil, &QuotaError{Limit: 10}
+		}
+		return nil, errors.New("connection refused by db-primary:5432")
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	batch := mason.NewBatchRuntime(rtm)
+	api := mason.NewAPI(batch)
+	api.RegisterError("quota_exceeded", http.StatusTooManyRequests, &QuotaError{})
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item").WithErrors("quota_exceeded"))
+	api.NewRouteGroup("batch").Register(batch.Route(api).Path("/batch").WithOpID("batch"))
+
+	req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`{
+		"operations": [
+			{"id": "1", "method": "GET", "path": "/items/quota"},
+			{"id": "2", "method": "GET", "path": "/items/db"}
+		]
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var rsp model.BatchResponse
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+	assert.Equal(t, http.StatusTooManyRequests, rsp.Results[0].Status)
+	assert.Equal(t, `{"limit":10}`, string(rsp.Results[0].Body))
+	assert.Equal(t, http.StatusInternalServerError, rsp.Results[1].Status)
+	assert.Equal(t, `"Internal Server Error"`, string(rsp.Results[1].Body))
+}
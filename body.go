@@ -0,0 +1,58 @@
+package mason
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxBodyPrealloc caps the buffer allocated up front from the Content-Length of a request, so that a client cannot
+// make the server allocate more than it sends.
+const maxBodyPrealloc = 10 << 20
+
+var _ io.ReadCloser = (*bufferedBody)(nil)
+
+// bufferedBody is a request body that was read in full. It keeps the bytes it was read into, so the next reader in
+// the chain takes them as is instead of copying them into a buffer of its own.
+type bufferedBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (b *bufferedBody) Close() error {
+	return nil
+}
+
+// readBody reads the body of the request in full. The bytes of a body that was already buffered, e.g. by the
+// conformance or recorder middlewares, are reused. With restore, the body is replaced by a reader over the bytes, for
+// the next handler in the chain.
+func readBody(r *http.Request, restore bool) ([]byte, error) {
+	if b, ok := r.Body.(*bufferedBody); ok && b.Len() == len(b.data) {
+		setBody(r, b.data, restore)
+		return b.data, nil
+	}
+
+	var buf bytes.Buffer
+	if r.ContentLength > 0 {
+		buf.Grow(int(min(r.ContentLength, maxBodyPrealloc)) + bytes.MinRead)
+	}
+	if _, err := buf.ReadFrom(r.Body); err != nil {
+		return nil, fmt.Errorf("unable to read the body: %w", err)
+	}
+
+	body := buf.Bytes()
+	setBody(r, body, restore)
+
+	return body, nil
+}
+
+// setBody replaces the body of the request by a reader over the bytes, or by an empty body without restore, so the
+// bytes are not held by the request any longer.
+func setBody(r *http.Request, body []byte, restore bool) {
+	if !restore {
+		r.Body = http.NoBody
+		return
+	}
+	r.Body = &bufferedBody{Reader: bytes.NewReader(body), data: body}
+}
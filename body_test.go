@@ -0,0 +1,76 @@
+package mason_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestDecodeRequest_Restore(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"title": "first"}`))
+	item, err := mason.DecodeRequest[*Item](api, req)
+	assert.NilError(t, err)
+	assert.Equal(t, "first", item.Title)
+	body, err := io.ReadAll(req.Body)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"title": "first"}`, string(body))
+
+	req = httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"title": "second"}`))
+	item, err = mason.DecodeRequest[*Item](api, req, mason.WithoutBodyRestore())
+	assert.NilError(t, err)
+	assert.Equal(t, "second", item.Title)
+	body, err = io.ReadAll(req.Body)
+	assert.NilError(t, err)
+	assert.Equal(t, "", string(body))
+}
+
+func TestDecodeBody(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+
+	item, err := mason.DecodeBody[*Item](api, []byte(`{"title": "signed"}`))
+	assert.NilError(t, err)
+	assert.Equal(t, "signed", item.Title)
+
+	_, err = mason.DecodeBody[*Item](api, []byte(`{"name": "untitled"}`))
+	var verr model.ValidationError
+	assert.Assert(t, errors.As(err, &verr))
+
+	item, err = mason.DecodeBody[*Item](api, []byte(`{"name": "untitled"}`), mason.WithoutBodyValidation())
+	assert.NilError(t, err)
+	assert.Equal(t, "", item.Title)
+
+	_, err = mason.DecodeBody[*mason.Stream[*Item]](api, []byte(`{"title": "streamed"}`))
+	assert.ErrorContains(t, err, "DecodeRequest")
+}
+
+func TestDecodeRequest_Middlewares(t *testing.T) {
+	var read string
+	createItem := func(ctx context.Context, r *http.Request, in *Item, params model.Nil) (*Item, error) {
+		// the handler can still read the body decoded before it
+		body, err := io.ReadAll(r.Body)
+		read = string(body)
+		return in, err
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID("create_item").
+		WithMWs(mason.NewRecorder()))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"title": "recorded"}`)))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"title": "recorded"}`, read)
+}
@@ -0,0 +1,57 @@
+package mason
+
+import (
+	"context"
+	"net/http"
+)
+
+type skipBodyValidationKey struct{}
+
+// SkipBodyValidation marks the request of the context as trusted, so its body is decoded without being validated
+// against the schema of the route, e.g. for internal callers sending large machine generated payloads. Middlewares
+// authenticating such callers set it, see TrustedCallers.
+func SkipBodyValidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipBodyValidationKey{}, true)
+}
+
+// BodyValidationSkipped reports whether the body of the request of the context is decoded without being validated,
+// see SkipBodyValidation.
+func BodyValidationSkipped(ctx context.Context) bool {
+	skipped, _ := ctx.Value(skipBodyValidationKey{}).(bool)
+	return skipped
+}
+
+// WithoutBodyValidation decodes the request body without validating it against the schema of the entity.
+func WithoutBodyValidation() DecodeOption {
+	return func(options *decodeOptions) error {
+		options.skipValidation = true
+		return nil
+	}
+}
+
+var _ Middleware = (*TrustedCallersMiddleware)(nil)
+
+// TrustedCallersMiddleware skips the validation of the request bodies of the trusted callers.
+type TrustedCallersMiddleware struct {
+	trusted func(r *http.Request) bool
+}
+
+// TrustedCallers returns a middleware that decodes the request bodies of the callers for which trusted returns true
+// without validating them, see SkipBodyValidation. The caller must be authenticated by trusted, or by a middleware
+// running before, as the bodies of trusted callers reach the handlers unchecked.
+func TrustedCallers(trusted func(r *http.Request) bool) *TrustedCallersMiddleware {
+	return &TrustedCallersMiddleware{trusted: trusted}
+}
+
+func (t *TrustedCallersMiddleware) GetHandler(builder Builder) func(WebHandler) WebHandler {
+	return func(next WebHandler) WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if !t.trusted(r) {
+				return next(ctx, w, r)
+			}
+
+			ctx = SkipBodyValidation(ctx)
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
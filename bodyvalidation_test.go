@@ -0,0 +1,72 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestSkipBodyValidation(t *testing.T) {
+	var skipped []bool
+	createItem := func(ctx context.Context, r *http.Request, in *Item, params model.Nil) (*Item, error) {
+		skipped = append(skipped, mason.BodyValidationSkipped(ctx))
+		return in, nil
+	}
+	trusted := mason.TrustedCallers(func(r *http.Request) bool {
+		return r.Header.Get("X-Internal-Caller") == "ingest"
+	})
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandlePost(createItem).Path("/items").WithOpID("create_item").WithMWs(trusted))
+	grp.Register(mason.HandlePost(createItem).Path("/items/import").WithOpID("import_item").WithoutBodyValidation())
+
+	post := func(path string, caller string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"name": "untitled"}`))
+		if caller != "" {
+			req.Header.Set("X-Internal-Caller", caller)
+		}
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnprocessableEntity, post("/items", "").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, post("/items", "other").Code)
+
+	rec := post("/items", "ingest")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"title":""}`, strings.TrimSpace(rec.Body.String()))
+
+	assert.Equal(t, http.StatusCreated, post("/items/import", "").Code)
+	assert.DeepEqual(t, []bool{true, false}, skipped)
+}
+
+func TestSkipBodyValidation_Stream(t *testing.T) {
+	var titles []string
+	ingest := func(ctx context.Context, r *http.Request, items *mason.Stream[*Item], params model.Nil) (model.Nil, error) {
+		for item, err := range items.All() {
+			if err != nil {
+				return model.Nil{}, err
+			}
+			titles = append(titles, item.Title)
+		}
+		return model.Nil{}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleStream(ingest).Path("/items/stream").WithOpID("ingest_items").WithoutBodyValidation())
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items/stream", strings.NewReader("{\"title\": \"a\"}\n{\"name\": \"b\"}\n")))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.DeepEqual(t, []string{"a", ""}, titles)
+}
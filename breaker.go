@@ -0,0 +1,208 @@
+package mason
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+// ErrCircuitOpen is the code of the error sent to the requests to an operation whose circuit is open, with the
+// StatusServiceUnavailable status and a Retry-After header.
+const ErrCircuitOpen = "circuit_open"
+
+// CircuitState is the state of the circuit of an operation.
+type CircuitState string
+
+const (
+	// CircuitClosed lets the requests through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen refuses the requests, until the cooldown is over.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single request through, which closes the circuit if it succeeds, and opens it again
+	// otherwise.
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+type breakerOptions struct {
+	threshold int
+	cooldown  time.Duration
+	onChange  func(opID string, state CircuitState)
+}
+
+type BreakerOption func(*breakerOptions)
+
+// BreakerThreshold sets how many consecutive failures open the circuit of an operation, 5 by default.
+func BreakerThreshold(n int) BreakerOption {
+	return func(o *breakerOptions) {
+		o.threshold = n
+	}
+}
+
+// BreakerCooldown sets how long a circuit stays open before a request is let through to probe the operation, 30
+// seconds by default.
+func BreakerCooldown(d time.Duration) BreakerOption {
+	return func(o *breakerOptions) {
+		o.cooldown = d
+	}
+}
+
+// OnCircuitChange sets the callback invoked when the circuit of an operation changes state, e.g. to alert on it. It
+// runs while the breaker is locked, so it must not call the breaker.
+func OnCircuitChange(fn func(opID string, state CircuitState)) BreakerOption {
+	return func(o *breakerOptions) {
+		o.onChange = fn
+	}
+}
+
+var _ Middleware = (*CircuitBreaker)(nil)
+
+// CircuitBreaker is a middleware that stops calling the handler of an operation after consecutive failures, e.g.
+// while the database behind it is down, so the requests fail fast with a 503 instead of piling up. The failures are
+// the 5xx responses, the errors of the catalog with a 5xx status, and the other errors that are not validation errors. The circuits are keyed by operation ID, so one
+// breaker can wrap every route.
+type CircuitBreaker struct {
+	api      *API
+	options  breakerOptions
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	// probing is set while the request let through a half open circuit is in flight
+	probing bool
+}
+
+func NewCircuitBreaker(api *API, opts ...BreakerOption) *CircuitBreaker {
+	options := breakerOptions{
+		threshold: 5,
+		cooldown:  30 * time.Second,
+		onChange:  func(string, CircuitState) {},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &CircuitBreaker{
+		api:      api,
+		options:  options,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// State returns the state of the circuit of an operation.
+func (b *CircuitBreaker) State(opID string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.circuits[opID]; ok {
+		return c.state
+	}
+	return CircuitClosed
+}
+
+func (b *CircuitBreaker) GetHandler(builder Builder) func(WebHandler) WebHandler {
+	return func(next WebHandler) WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			opID := builder.OpID()
+			if retryAfter, ok := b.allow(opID); !ok {
+				return respondUnavailable(ctx, b.api, w, ErrCircuitOpen, "The operation is temporarily unavailable", retryAfter)
+			}
+
+			// a panicking handler is recorded as a failure, so a half open circuit is not left probing
+			success := false
+			defer func() { b.record(opID, success) }()
+
+			rw := NewResponseWriter(w)
+			err := next(ctx, rw, r)
+			success = b.succeeded(rw.Status(), err)
+
+			return err
+		}
+	}
+}
+
+// succeeded reports whether a response is a success for the circuit. The errors of the catalog, which the runtime
+// renders with their status, are failures only with a 5xx status, so e.g. a burst of not_found errors does not open
+// the circuit.
+func (b *CircuitBreaker) succeeded(status int, err error) bool {
+	if err == nil {
+		return status < 500
+	}
+	if def, _, ok := b.api.matchError(err); ok {
+		return def.Status < 500
+	}
+
+	return errors.As(err, new(model.ValidationError))
+}
+
+// allow reports whether a request to the operation is let through, and otherwise the time left until the circuit is
+// probed.
+func (b *CircuitBreaker) allow(opID string) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[opID]
+	if !ok {
+		return 0, true
+	}
+
+	switch c.state {
+	case CircuitOpen:
+		if left := b.options.cooldown - time.Since(c.openedAt); left > 0 {
+			return left, false
+		}
+		b.setState(opID, c, CircuitHalfOpen)
+		c.probing = true
+		return 0, true
+	case CircuitHalfOpen:
+		if c.probing {
+			return b.options.cooldown, false
+		}
+		c.probing = true
+		return 0, true
+	default:
+		return 0, true
+	}
+}
+
+// record updates the circuit of the operation with the outcome of a request.
+func (b *CircuitBreaker) record(opID string, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[opID]
+	if !ok {
+		if success {
+			return
+		}
+		c = &circuit{state: CircuitClosed}
+		b.circuits[opID] = c
+	}
+
+	if success {
+		c.failures, c.probing = 0, false
+		b.setState(opID, c, CircuitClosed)
+		return
+	}
+
+	c.failures++
+	if c.state == CircuitHalfOpen || c.failures >= b.options.threshold {
+		c.openedAt, c.probing = time.Now(), false
+		b.setState(opID, c, CircuitOpen)
+	}
+}
+
+func (b *CircuitBreaker) setState(opID string, c *circuit, state CircuitState) {
+	if c.state == state {
+		return
+	}
+	c.state = state
+	b.options.onChange(opID, state)
+}
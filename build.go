@@ -0,0 +1,137 @@
+package mason
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/tailbits/mason/model"
+)
+
+// Build validates the whole API and freezes it, so configuration errors surface at startup instead of on the first
+// request or the first spec generation. It reports, all at once:
+//   - operations registered twice, or sharing an operationID,
+//   - operations without a group,
+//   - unknown errors declared with WithErrors,
+//   - entity schemas with dangling or ambiguous refs, see CheckSchemas, or that cannot be compiled.
+//
+// The schemas are dereferenced and compiled ahead of the first request. Registering routes, models, errors or
+// providers after Build panics, so the returned handler can be shared by concurrent requests. It serves the requests
+// with the runtime of the API, or with the one wrapped by a BatchRuntime or an RPCRuntime, which must be an
+// http.Handler. The runtime is hidden behind the handler, so no route can be registered through it: the routes it
+// serves only change with Extend and Deregister.
+func (a *API) Build() (http.Handler, error) {
+	rtm := baseRuntime(a.Runtime)
+	handler, ok := rtm.(http.Handler)
+	if !ok {
+		return nil, fmt.Errorf("runtime %T is not an http.Handler", rtm)
+	}
+
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	a.frozen = true
+
+	return http.HandlerFunc(handler.ServeHTTP), nil
+}
+
+// validate reports the configuration errors of the API, see Build.
+func (a *API) validate() error {
+	errs := append([]error{}, a.conflicts...)
+
+	type opRef struct {
+		group string
+		op    Operation
+	}
+	var ops []opRef
+	a.ForEachOperation(func(group string, op Operation) {
+		ops = append(ops, opRef{group: group, op: op})
+	})
+	// the registry is a map, so the errors are sorted to be reported in a stable order
+	sort.Slice(ops, func(i, j int) bool {
+		return toKey(ops[i].op.Method, ops[i].op.Path) < toKey(ops[j].op.Method, ops[j].op.Path)
+	})
+
+	opIDs := make(map[string]Operation)
+	routes := make(map[string]Operation)
+	for _, ref := range ops {
+		op := ref.op
+		name := op.Method + " " + op.Path
+
+		if ref.group == "" {
+			errs = append(errs, fmt.Errorf("%s: operation has no group", name))
+		}
+
+		if other, ok := opIDs[op.OperationID]; ok && op.OperationID != "" {
+			errs = append(errs, fmt.Errorf("%s: operationID %s is also used by %s %s", name, op.OperationID, other.Method, other.Path))
+		}
+		opIDs[op.OperationID] = op
+
+		key := toKey(op.Method, normalizePath(op.Path))
+		if other, ok := routes[key]; ok {
+			errs = append(errs, fmt.Errorf("%s: conflicts with %s %s", name, other.Method, other.Path))
+		}
+		routes[key] = op
+
+		for _, code := range op.Errors {
+			if _, ok := a.GetError(code); !ok {
+				errs = append(errs, fmt.Errorf("%s: error %s is not registered", name, code))
+			}
+		}
+	}
+
+	for _, name := range a.modelNames() {
+		// schemas with dangling refs cannot be dereferenced, so they are not compiled
+		if refErrs := a.checkSchema(name, a.models[name]); len(refErrs) > 0 {
+			errs = append(errs, refErrs...)
+			continue
+		}
+		if err := a.compileEntity(a.models[name]); err != nil {
+			errs = append(errs, fmt.Errorf("entity %s: %w", name, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid API: %w", err)
+	}
+
+	return nil
+}
+
+// compileEntity dereferences and compiles the schema of an entity, as the validation of a request body would.
+func (a *API) compileEntity(ent model.Entity) error {
+	if ent == nil || reflect.ValueOf(ent).Kind() == reflect.Ptr && reflect.ValueOf(ent).IsNil() {
+		return nil
+	}
+	if _, ok := ent.(model.Nil); ok || len(ent.Schema()) == 0 {
+		return nil
+	}
+
+	schema, err := a.DereferenceSchema(ent.Schema())
+	if err != nil {
+		return err
+	}
+
+	return model.Compile(schema)
+}
+
+// mustBeMutable panics when the API is frozen by Build, even in Extend, e.g. for the errors and the providers, which
+// the requests being served read without a lock.
+func (a *API) mustBeMutable(what string) {
+	if a.frozen && a.extendable {
+		panic(fmt.Sprintf("cannot register %s in Extend: only routes and models can be added to a built API", what))
+	}
+	if a.frozen {
+		panic(fmt.Sprintf("cannot register %s: the API is frozen by Build", what))
+	}
+}
+
+// mustBeExtendable panics when the API is frozen by Build, outside of Extend.
+func (a *API) mustBeExtendable(what string) {
+	if a.frozen && !a.extendable {
+		panic(fmt.Sprintf("cannot register %s: the API is frozen by Build", what))
+	}
+}
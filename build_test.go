@@ -0,0 +1,81 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestBuild(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "done"}, nil
+	}
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item"))
+		api.NewRouteGroup("items").Register(mason.HandlePost(createItem).Path("/items").WithOpID("create_item"))
+
+		handler, err := api.Build()
+		assert.NilError(t, err)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"title": "new"}`)))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		assert.Assert(t, cmpPanics(func() {
+			api.NewRouteGroup("items").Register(mason.HandlePut(createItem).Path("/items/{id}").WithOpID("update_item"))
+		}))
+	})
+
+	t.Run("wrapped runtimes", func(t *testing.T) {
+		for _, rtm := range []mason.Runtime{
+			mason.NewBatchRuntime(mason.NewHTTPRuntime()),
+			mason.NewRPCRuntime(mason.NewHTTPRuntime()),
+			mason.NewBatchRuntime(mason.NewRPCRuntime(mason.NewHTTPRuntime())),
+		} {
+			api := mason.NewAPI(rtm)
+			api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item"))
+
+			handler, err := api.Build()
+			assert.NilError(t, err)
+			_, ok := handler.(mason.Runtime)
+			assert.Assert(t, !ok)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item").WithErrors("gone"))
+		api.NewRouteGroup("items").Register(mason.HandlePost(createItem).Path("/items").WithOpID("get_item"))
+		api.NewRouteGroup("parents").Register(mason.HandleGet(func(ctx context.Context, r *http.Request, _ model.Nil) (*Parent, error) {
+			return &Parent{}, nil
+		}).Path("/parent").WithOpID("get_parent"))
+
+		_, err := api.Build()
+		assert.ErrorContains(t, err, "operationID get_item is also used by")
+		assert.ErrorContains(t, err, "GET /items/{id}: error gone is not registered")
+		assert.ErrorContains(t, err, "entity Parent: ref #/definitions/Child does not match a definition or a registered entity")
+	})
+}
+
+func cmpPanics(fn func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	fn()
+	return false
+}
@@ -2,9 +2,11 @@ package mason
 
 import (
 	"fmt"
+	"io/fs"
 	"net/http"
 	"path"
 	"strings"
+	"time"
 
 	m "github.com/tailbits/mason/model"
 )
@@ -24,11 +26,38 @@ type Builder interface {
 	WithGroup(group string) Builder
 	WithOpID(segments ...string) Builder
 	WithDesc(d string) Builder
+	WithDescFile(fsys fs.FS, path string) Builder
 	WithTags(tags ...string) Builder
 	WithSuccessCode(code int) Builder
 	WithSummary(s string) Builder
+	WithSummaryLocalized(summaries map[string]string) Builder
+	WithDescLocalized(descs map[string]string) Builder
 	WithMWs(mw ...Middleware) Builder
 	WithExtensions(key string, val interface{}) Builder
+	WithExtensionsMap(ext map[string]any) Builder
+	WithFieldSelection() Builder
+	WithCache(policy CachePolicy) Builder
+	WithPurge(keys ...string) Builder
+	WithContent(policy ContentPolicy) Builder
+	WithPathParam(name string, param PathParam) Builder
+	WithTimeout(d time.Duration) Builder
+	WithResponseLimit(limit ResponseLimit) Builder
+	WithResponseDesc(status int, desc string) Builder
+	WithErrors(codes ...string) Builder
+	WithValidationOptions(opts ...m.ValidationOption) Builder
+	WithoutBodyValidation() Builder
+	WithQueryOptions(opts ...QueryOption) Builder
+	BeforeDecode(hook BeforeDecodeHook) Builder
+	AfterEncode(hook AfterEncodeHook) Builder
+	WithRepresentation(contentType string, rep Representation) Builder
+	WithVisibility(v OperationVisibility) Builder
+	Visibility() OperationVisibility
+	WithFeatureFlag(flags ...string) Builder
+	FeatureFlags() []string
+	WithRollout(flag string) Builder
+	WithOwner(owner string) Builder
+	WithDeprecation(since time.Time) Builder
+	WithBetaSince(since time.Time) Builder
 	SkipIf(skip bool) Builder
 	RegisterBeta(api *API)
 	Register(api *API)
@@ -39,6 +68,7 @@ type RouteBuilderBase struct {
 	method      string
 	path        string
 	mw          []func(WebHandler) WebHandler
+	mwNames     []string
 	desc        string
 	tags        []string
 	summary     string
@@ -46,6 +76,34 @@ type RouteBuilderBase struct {
 	skipped     bool
 	group       string
 	keyVals     map[string]interface{}
+
+	fieldSelection bool
+	noContent      bool
+	cache          *CachePolicy
+	purgeKeys      []string
+	content        *ContentPolicy
+	pathParams     map[string]PathParam
+	compiledParams []compiledPathParam
+	timeout        time.Duration
+	responseLimit  ResponseLimit
+	responseDescs  map[int]string
+	errors         []string
+	validation     []m.ValidationOption
+	skipValidation bool
+	queryOptions   []QueryOption
+	// signedURL is set when a URLSigner verifies the requests of the route
+	signedURL bool
+	// hooks run inside the generated handler, see BeforeDecode and AfterEncode
+	beforeDecodeHooks []BeforeDecodeHook
+	afterEncodeHooks  []AfterEncodeHook
+	representations   []representation
+	visibility        OperationVisibility
+	featureFlags      []string
+	translations      map[string]Translation
+	rollout           string
+	owner             string
+	deprecatedSince   time.Time
+	betaSince         time.Time
 }
 
 func (rb *RouteBuilderBase) validate() error {
@@ -58,7 +116,70 @@ func (rb *RouteBuilderBase) validate() error {
 	if rb.path == "" {
 		return fmt.Errorf("path is required")
 	}
-	return nil
+	return rb.validateSurrogateKeys()
+}
+
+// setPath sets the path of the route, moving inline constraints like {id:[0-9]+} to the path params.
+func (rb *RouteBuilderBase) setPath(p string) {
+	path, params := parsePathConstraints(p)
+	rb.path = path
+	for name, param := range params {
+		rb.setPathParam(name, param)
+	}
+}
+
+// setPathParam merges the constraints of a path param with the ones already declared.
+func (rb *RouteBuilderBase) setPathParam(name string, param PathParam) {
+	if rb.pathParams == nil {
+		rb.pathParams = make(map[string]PathParam)
+	}
+
+	existing := rb.pathParams[name]
+	if param.Type != "" {
+		existing.Type = param.Type
+	}
+	if param.Pattern != "" {
+		existing.Pattern = param.Pattern
+	}
+	rb.pathParams[name] = existing
+}
+
+// compilePathParams compiles the path param constraints of the route, which are checked on every request.
+func (rb *RouteBuilderBase) compilePathParams() {
+	compiled, err := compilePathParams(rb.path, rb.pathParams)
+	if err != nil {
+		panic(fmt.Errorf("%s %s: %w", rb.method, rb.path, err))
+	}
+	rb.compiledParams = compiled
+}
+
+// setResponseDesc sets the description of the response with the given status.
+func (rb *RouteBuilderBase) setResponseDesc(status int, desc string) {
+	if rb.responseDescs == nil {
+		rb.responseDescs = make(map[int]string)
+	}
+	rb.responseDescs[status] = desc
+}
+
+// FeatureFlags returns the flags a tenant needs to use the route, see FeatureGate.
+func (rb *RouteBuilderBase) FeatureFlags() []string {
+	return rb.featureFlags
+}
+
+// Visibility returns the audience of the route, e.g. for a middleware that requires a header on beta routes.
+func (rb *RouteBuilderBase) Visibility() OperationVisibility {
+	if rb.visibility == "" {
+		return VisibilityPublic
+	}
+	return rb.visibility
+}
+
+// CachePolicy returns the cache policy of the route, if it was registered WithCache.
+func (rb *RouteBuilderBase) CachePolicy() (CachePolicy, bool) {
+	if rb.cache == nil {
+		return CachePolicy{}, false
+	}
+	return *rb.cache, true
 }
 
 type RouteBuilderWithBody[T m.Entity, O m.Entity, Q any] struct {
@@ -73,9 +194,10 @@ func (rb *RouteBuilderWithBody[T, O, Q]) ResourceID() string {
 	return RecursivelyUnwrap(t).Name()
 }
 
-// Path sets the path for the route. This can include path parameters like /users/{id}
+// Path sets the path for the route. This can include path parameters like /users/{id}, optionally constrained with a
+// regular expression like /users/{id:[0-9]+}
 func (rb *RouteBuilderWithBody[T, O, Q]) Path(p string) Builder {
-	rb.path = p
+	rb.setPath(p)
 
 	return rb
 }
@@ -103,6 +225,13 @@ func (rb *RouteBuilderWithBody[T, O, Q]) WithDesc(d string) Builder {
 	return rb
 }
 
+// WithDescFile sets the description for the route from a Markdown file, e.g. of an embed.FS. It panics when the file
+// cannot be read.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithDescFile(fsys fs.FS, path string) Builder {
+	rb.desc = readDescFile(fsys, path)
+	return rb
+}
+
 // WithTags sets the tags for the route. This is used primarily for documentation purposes.
 func (rb *RouteBuilderWithBody[T, O, Q]) WithTags(tags ...string) Builder {
 	rb.tags = tags
@@ -119,6 +248,14 @@ func (rb *RouteBuilderWithBody[T, O, Q]) WithExtensions(key string, val interfac
 	return rb
 }
 
+// WithExtensionsMap deep merges custom x- attributes into the extensions of the route, e.g. mason.Internal(). Nested
+// maps are merged key by key, and other values are replaced.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithExtensionsMap(ext map[string]any) Builder {
+	checkExtensionKeys(ext)
+	mergeExtensions(rb.keyVals, ext)
+	return rb
+}
+
 // WithSuccessCode sets the success code for the route. This can be used to override the default success code for the method.
 func (rb *RouteBuilderWithBody[T, O, Q]) WithSuccessCode(code int) Builder {
 	rb.successCode = code
@@ -130,39 +267,214 @@ func (rb *RouteBuilderWithBody[T, O, Q]) WithSummary(s string) Builder {
 	return rb
 }
 
+// WithSummaryLocalized sets the summaries of the route by language, e.g. {"fr": "Créer un article"}. The generators
+// document them in a spec per language, or as x-translations.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithSummaryLocalized(summaries map[string]string) Builder {
+	rb.localize(summaries, func(t *Translation, s string) { t.Summary = s })
+	return rb
+}
+
+// WithDescLocalized sets the descriptions of the route by language, see WithSummaryLocalized.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithDescLocalized(descs map[string]string) Builder {
+	rb.localize(descs, func(t *Translation, d string) { t.Description = d })
+	return rb
+}
+
 // WithMWs defines a set of middlewares to add to the route.
 func (rb *RouteBuilderWithBody[T, O, Q]) WithMWs(mw ...Middleware) Builder {
 	for _, m := range mw {
 		h := m.GetHandler(rb)
 		rb.mw = append(rb.mw, h)
+		rb.mwNames = append(rb.mwNames, middlewareName(m))
+		if _, ok := m.(*URLSigner); ok {
+			rb.signedURL = true
+		}
 	}
 
 	return rb
 }
 
+// WithFieldSelection enables the fields query param, which prunes the response to the requested (dot separated) fields.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithFieldSelection() Builder {
+	rb.fieldSelection = true
+	return rb
+}
+
+// WithCache sets the cache policy of the route, which is sent as the Cache-Control and Vary headers and documented
+// on the operation. Use the Cache middleware to also serve repeated requests from a cache store.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithCache(policy CachePolicy) Builder {
+	rb.cache = &policy
+	return rb
+}
+
+// WithPurge purges the cached responses tagged with the surrogate keys after the successful responses of a route
+// changing resources, see API.OnPurge. The keys can reference the path params of the route, e.g. item-{id}.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithPurge(keys ...string) Builder {
+	rb.purgeKeys = append(rb.purgeKeys, keys...)
+	return rb
+}
+
+// WithContent sets the charsets and the content codings the route consumes and produces, which are enforced on the
+// requests and documented on the operation.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithContent(policy ContentPolicy) Builder {
+	rb.content = &policy
+	return rb
+}
+
+// WithPathParam constrains the type or pattern of a path param. The constraints are enforced by the runtime, and
+// documented on the path param schema.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithPathParam(name string, param PathParam) Builder {
+	rb.setPathParam(name, param)
+	return rb
+}
+
+// WithTimeout sets the time the route has to respond, overriding the default timeout of the API. When it is exceeded,
+// the handler context is cancelled, and a model.TimeoutError is sent with the status set by API.WithTimeoutStatus.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithTimeout(d time.Duration) Builder {
+	rb.timeout = d
+	return rb
+}
+
+// WithResponseLimit sets the maximum size of the responses of the route, overriding the default limit of the API,
+// see ResponseLimit.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithResponseLimit(limit ResponseLimit) Builder {
+	rb.responseLimit = limit
+	return rb
+}
+
+// WithResponseDesc sets the description of the response with the given status in the spec, overriding the default
+// description derived from the status code, see DefaultResponseDescription.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithResponseDesc(status int, desc string) Builder {
+	rb.setResponseDesc(status, desc)
+	return rb
+}
+
+// WithErrors declares the codes of the errors the route returns, from the error catalog of the API, so they are
+// documented in the spec.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithErrors(codes ...string) Builder {
+	rb.errors = append(rb.errors, codes...)
+	return rb
+}
+
+// WithValidationOptions configures the errors reported for an invalid request body, overriding the validation
+// options of the API.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithValidationOptions(opts ...m.ValidationOption) Builder {
+	rb.validation = append(rb.validation, opts...)
+	return rb
+}
+
+// WithoutBodyValidation decodes the request body without validating it against the schema, e.g. for the routes of
+// internal callers sending large machine generated payloads. See SkipBodyValidation to skip it for some callers only.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithoutBodyValidation() Builder {
+	rb.skipValidation = true
+	return rb
+}
+
+// WithQueryOptions configures how the query params of the route are decoded, e.g. with LenientQueryKeys.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithQueryOptions(opts ...QueryOption) Builder {
+	rb.queryOptions = append(rb.queryOptions, opts...)
+	return rb
+}
+
+// BeforeDecode adds a hook that runs before the request is decoded. Hooks run in the order they are added.
+func (rb *RouteBuilderWithBody[T, O, Q]) BeforeDecode(hook BeforeDecodeHook) Builder {
+	rb.beforeDecodeHooks = append(rb.beforeDecodeHooks, hook)
+	return rb
+}
+
+// AfterEncode adds a hook that post-processes the encoded response. Hooks run in the order they are added.
+func (rb *RouteBuilderWithBody[T, O, Q]) AfterEncode(hook AfterEncodeHook) Builder {
+	rb.afterEncodeHooks = append(rb.afterEncodeHooks, hook)
+	return rb
+}
+
+// WithRepresentation adds an alternate representation of the response, e.g. CSV, sent when the Accept header of the
+// request prefers its content type over JSON. Both representations are documented in the spec.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithRepresentation(contentType string, rep Representation) Builder {
+	rb.setRepresentation(contentType, rep)
+	return rb
+}
+
+// WithVisibility sets the audience of the route. Generators only document public routes by default.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithVisibility(v OperationVisibility) Builder {
+	rb.visibility = v
+	return rb
+}
+
+// WithFeatureFlag restricts the route to the tenants with all the flags, e.g. plan:pro. The flags are enforced by
+// the FeatureGate middleware, and generators can document the routes of a plan only.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithFeatureFlag(flags ...string) Builder {
+	rb.featureFlags = append(rb.featureFlags, flags...)
+	return rb
+}
+
+// WithRollout dark launches the route behind a flag of the FlagProvider of the API. The route responds as if it did
+// not exist while the flag is off, see API.WithFlagProvider.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithRollout(flag string) Builder {
+	rb.rollout = flag
+	return rb
+}
+
+// WithOwner sets the team or person responsible for the route, overriding the owner of its group. It is documented as
+// x-owner, for routing the reviews of the spec changes, and API.Audit flags the routes without one.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithOwner(owner string) Builder {
+	rb.owner = owner
+	return rb
+}
+
+// WithDeprecation deprecates the route as of the given date. Generators document it as deprecated, and API.Audit flags
+// it once it has been deprecated for too long.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithDeprecation(since time.Time) Builder {
+	rb.deprecatedSince = since
+	return rb
+}
+
+// WithBetaSince sets the beta visibility on the route, released in beta on the given date, so API.Audit flags it once
+// it has been in beta for too long.
+func (rb *RouteBuilderWithBody[T, O, Q]) WithBetaSince(since time.Time) Builder {
+	rb.betaSince = since
+	return rb.WithVisibility(VisibilityBeta)
+}
+
 // SkipIf ensures that the route is not documented if the condition is true.
 func (rb *RouteBuilderWithBody[T, O, Q]) SkipIf(skip bool) Builder {
 	rb.skipped = skip
 	return rb
 }
 
-// RegisterBeta registers the route and marks it as beta, meaning it will not be included in the OpenAPI documentation.
+// RegisterBeta registers the route with the beta visibility, meaning it is only included in the documentation of
+// generators configured to include beta routes.
 func (rb *RouteBuilderWithBody[T, O, Q]) RegisterBeta(api *API) {
-	rb.SkipIf(true).Register(api)
+	rb.WithVisibility(VisibilityBeta).Register(api)
 }
 
 // Register registers the route with the mux, and finalizes the route configuration.
 func (rb *RouteBuilderWithBody[T, O, Q]) Register(api *API) {
+	rb.applyPlugins(api, rb)
 	if err := rb.validate(); err != nil {
 		panic(err)
 	}
 	if rb.handler == nil {
 		panic("handler is required")
 	}
+	if rb.group == "" {
+		rb.group = inferGroup(api, rb.ResourceID())
+	}
 	if rb.group == "" {
 		msg := fmt.Sprintf("route group name could not be inferred for %s %s; consider using group.WithDefaultName() to set it explicitly", rb.method, rb.path)
 		panic(msg)
 	}
+	if rb.cache != nil {
+		panic(fmt.Sprintf("cache policy is only supported on GET routes, not on %s %s", rb.method, rb.path))
+	}
+
+	rb.compilePathParams()
+	if rb.timeout == 0 {
+		rb.timeout = api.defaultTimeout
+	}
+	if rb.responseLimit.MaxBytes == 0 {
+		rb.responseLimit = api.defaultResponseLimit
+	}
 
 	var output O
 	if rb.successCode == 0 {
@@ -180,13 +492,38 @@ func (rb *RouteBuilderWithBody[T, O, Q]) Register(api *API) {
 			WithDescription(rb.desc),
 			WithSummary(rb.summary),
 			WithTags(rb.tags...),
-			WithExtension(rb.keyVals),
+			WithExtension(api.operationExtensions(rb.group, rb.keyVals)),
+			WithFieldSelectionParam(rb.fieldSelection),
+			WithNoContentResponse(rb.noContent),
+			WithCachePolicy(rb.cache),
+			WithContentPolicy(rb.content),
+			WithPathParams(rb.pathParams),
+			WithTimeoutDuration(rb.timeout),
+			WithOperationTimeoutStatus(api.routeTimeoutStatus()),
+			WithResponseDescriptions(rb.responseDescs),
+			WithErrorCodes(rb.errors...),
+			WithRepresentations(rb.representationEntities()),
+			WithMiddlewareNames(rb.mwNames...),
+			WithOperationVisibility(rb.visibility),
+			WithOperationFeatureFlags(rb.featureFlags...),
+			WithOperationRollout(rb.rollout),
+			WithOperationTranslations(rb.translations),
+			WithOperationOwner(api.operationOwner(rb.group, rb.owner)),
+			WithOperationDeprecation(rb.deprecatedSince),
+			WithOperationBetaSince(rb.betaSince),
+			WithOperationSignedURL(rb.signedURL),
 		)
 	}
 
-	h := newHandlerWithBody(api, rb.handler, rb.successCode)
+	h := api.withErrors(newHandlerWithBody(api, rb.handler, &rb.RouteBuilderBase))
+	if rb.timeout > 0 {
+		h = withTimeout(api, h, rb.timeout, api.routeTimeoutStatus())
+	}
+	if rb.responseLimit.MaxBytes > 0 && !isFile[O]() {
+		h = withResponseLimit(api, h, &rb.RouteBuilderBase)
+	}
 
-	api.Handle(rb.method, rb.path, h, rb.mw...)
+	rb.handle(api, h)
 }
 
 type RouteBuilderNoBody[T m.Entity, Q any] struct {
@@ -200,9 +537,10 @@ func (rb *RouteBuilderNoBody[T, Q]) ResourceID() string {
 	return RecursivelyUnwrap(t).Name()
 }
 
-// Path sets the path for the route. This can include path parameters like /users/{id}
+// Path sets the path for the route. This can include path parameters like /users/{id}, optionally constrained with a
+// regular expression like /users/{id:[0-9]+}
 func (rb *RouteBuilderNoBody[T, Q]) Path(p string) Builder {
-	rb.path = p
+	rb.setPath(p)
 	return rb
 }
 
@@ -228,6 +566,13 @@ func (rb *RouteBuilderNoBody[T, Q]) WithDesc(d string) Builder {
 	return rb
 }
 
+// WithDescFile sets the description for the route from a Markdown file, e.g. of an embed.FS. It panics when the file
+// cannot be read.
+func (rb *RouteBuilderNoBody[T, Q]) WithDescFile(fsys fs.FS, path string) Builder {
+	rb.desc = readDescFile(fsys, path)
+	return rb
+}
+
 // WithTags sets the tags for the route. This is used primarily for documentation purposes.
 func (rb *RouteBuilderNoBody[T, Q]) WithTags(tags ...string) Builder {
 	rb.tags = tags
@@ -244,6 +589,14 @@ func (rb *RouteBuilderNoBody[T, Q]) WithExtensions(key string, val interface{})
 	return rb
 }
 
+// WithExtensionsMap deep merges custom x- attributes into the extensions of the route, e.g. mason.Internal(). Nested
+// maps are merged key by key, and other values are replaced.
+func (rb *RouteBuilderNoBody[T, Q]) WithExtensionsMap(ext map[string]any) Builder {
+	checkExtensionKeys(ext)
+	mergeExtensions(rb.keyVals, ext)
+	return rb
+}
+
 // WithSuccessCode sets the success code for the route. This can be used to override the default success code for the method.
 func (rb *RouteBuilderNoBody[T, Q]) WithSuccessCode(code int) Builder {
 	rb.successCode = code
@@ -255,29 +608,190 @@ func (rb *RouteBuilderNoBody[T, Q]) WithSummary(s string) Builder {
 	return rb
 }
 
+// WithSummaryLocalized sets the summaries of the route by language, e.g. {"fr": "Créer un article"}. The generators
+// document them in a spec per language, or as x-translations.
+func (rb *RouteBuilderNoBody[T, Q]) WithSummaryLocalized(summaries map[string]string) Builder {
+	rb.localize(summaries, func(t *Translation, s string) { t.Summary = s })
+	return rb
+}
+
+// WithDescLocalized sets the descriptions of the route by language, see WithSummaryLocalized.
+func (rb *RouteBuilderNoBody[T, Q]) WithDescLocalized(descs map[string]string) Builder {
+	rb.localize(descs, func(t *Translation, d string) { t.Description = d })
+	return rb
+}
+
 // WithMWs defines a set of middlewares to add to the route.
 func (rb *RouteBuilderNoBody[T, Q]) WithMWs(mw ...Middleware) Builder {
 	for _, m := range mw {
 		h := m.GetHandler(rb)
 		rb.mw = append(rb.mw, h)
+		rb.mwNames = append(rb.mwNames, middlewareName(m))
+		if _, ok := m.(*URLSigner); ok {
+			rb.signedURL = true
+		}
 	}
 
 	return rb
 }
 
+// WithFieldSelection enables the fields query param, which prunes the response to the requested (dot separated) fields.
+func (rb *RouteBuilderNoBody[T, Q]) WithFieldSelection() Builder {
+	rb.fieldSelection = true
+	return rb
+}
+
+// WithCache sets the cache policy of the route, which is sent as the Cache-Control and Vary headers and documented
+// on the operation. Use the Cache middleware to also serve repeated requests from a cache store.
+func (rb *RouteBuilderNoBody[T, Q]) WithCache(policy CachePolicy) Builder {
+	rb.cache = &policy
+	return rb
+}
+
+// WithPurge purges the cached responses tagged with the surrogate keys after the successful responses of a route
+// changing resources, see API.OnPurge. The keys can reference the path params of the route, e.g. item-{id}.
+func (rb *RouteBuilderNoBody[T, Q]) WithPurge(keys ...string) Builder {
+	rb.purgeKeys = append(rb.purgeKeys, keys...)
+	return rb
+}
+
+// WithContent sets the charsets and the content codings the route consumes and produces, which are enforced on the
+// requests and documented on the operation.
+func (rb *RouteBuilderNoBody[T, Q]) WithContent(policy ContentPolicy) Builder {
+	rb.content = &policy
+	return rb
+}
+
+// WithPathParam constrains the type or pattern of a path param. The constraints are enforced by the runtime, and
+// documented on the path param schema.
+func (rb *RouteBuilderNoBody[T, Q]) WithPathParam(name string, param PathParam) Builder {
+	rb.setPathParam(name, param)
+	return rb
+}
+
+// WithTimeout sets the time the route has to respond, overriding the default timeout of the API. When it is exceeded,
+// the handler context is cancelled, and a model.TimeoutError is sent with the status set by API.WithTimeoutStatus.
+func (rb *RouteBuilderNoBody[T, Q]) WithTimeout(d time.Duration) Builder {
+	rb.timeout = d
+	return rb
+}
+
+// WithResponseLimit sets the maximum size of the responses of the route, overriding the default limit of the API,
+// see ResponseLimit.
+func (rb *RouteBuilderNoBody[T, Q]) WithResponseLimit(limit ResponseLimit) Builder {
+	rb.responseLimit = limit
+	return rb
+}
+
+// WithResponseDesc sets the description of the response with the given status in the spec, overriding the default
+// description derived from the status code, see DefaultResponseDescription.
+func (rb *RouteBuilderNoBody[T, Q]) WithResponseDesc(status int, desc string) Builder {
+	rb.setResponseDesc(status, desc)
+	return rb
+}
+
+// WithErrors declares the codes of the errors the route returns, from the error catalog of the API, so they are
+// documented in the spec.
+func (rb *RouteBuilderNoBody[T, Q]) WithErrors(codes ...string) Builder {
+	rb.errors = append(rb.errors, codes...)
+	return rb
+}
+
+// WithValidationOptions configures the errors reported for an invalid request body, overriding the validation
+// options of the API.
+func (rb *RouteBuilderNoBody[T, Q]) WithValidationOptions(opts ...m.ValidationOption) Builder {
+	rb.validation = append(rb.validation, opts...)
+	return rb
+}
+
+// WithoutBodyValidation decodes the request body without validating it against the schema, e.g. for the routes of
+// internal callers sending large machine generated payloads. See SkipBodyValidation to skip it for some callers only.
+func (rb *RouteBuilderNoBody[T, Q]) WithoutBodyValidation() Builder {
+	rb.skipValidation = true
+	return rb
+}
+
+// WithQueryOptions configures how the query params of the route are decoded, e.g. with LenientQueryKeys.
+func (rb *RouteBuilderNoBody[T, Q]) WithQueryOptions(opts ...QueryOption) Builder {
+	rb.queryOptions = append(rb.queryOptions, opts...)
+	return rb
+}
+
+// BeforeDecode adds a hook that runs before the request is decoded. Hooks run in the order they are added.
+func (rb *RouteBuilderNoBody[T, Q]) BeforeDecode(hook BeforeDecodeHook) Builder {
+	rb.beforeDecodeHooks = append(rb.beforeDecodeHooks, hook)
+	return rb
+}
+
+// AfterEncode adds a hook that post-processes the encoded response. Hooks run in the order they are added.
+func (rb *RouteBuilderNoBody[T, Q]) AfterEncode(hook AfterEncodeHook) Builder {
+	rb.afterEncodeHooks = append(rb.afterEncodeHooks, hook)
+	return rb
+}
+
+// WithRepresentation adds an alternate representation of the response, e.g. CSV, sent when the Accept header of the
+// request prefers its content type over JSON. Both representations are documented in the spec.
+func (rb *RouteBuilderNoBody[T, Q]) WithRepresentation(contentType string, rep Representation) Builder {
+	rb.setRepresentation(contentType, rep)
+	return rb
+}
+
+// WithVisibility sets the audience of the route. Generators only document public routes by default.
+func (rb *RouteBuilderNoBody[T, Q]) WithVisibility(v OperationVisibility) Builder {
+	rb.visibility = v
+	return rb
+}
+
+// WithFeatureFlag restricts the route to the tenants with all the flags, e.g. plan:pro. The flags are enforced by
+// the FeatureGate middleware, and generators can document the routes of a plan only.
+func (rb *RouteBuilderNoBody[T, Q]) WithFeatureFlag(flags ...string) Builder {
+	rb.featureFlags = append(rb.featureFlags, flags...)
+	return rb
+}
+
+// WithRollout dark launches the route behind a flag of the FlagProvider of the API. The route responds as if it did
+// not exist while the flag is off, see API.WithFlagProvider.
+func (rb *RouteBuilderNoBody[T, Q]) WithRollout(flag string) Builder {
+	rb.rollout = flag
+	return rb
+}
+
+// WithOwner sets the team or person responsible for the route, overriding the owner of its group. It is documented as
+// x-owner, for routing the reviews of the spec changes, and API.Audit flags the routes without one.
+func (rb *RouteBuilderNoBody[T, Q]) WithOwner(owner string) Builder {
+	rb.owner = owner
+	return rb
+}
+
+// WithDeprecation deprecates the route as of the given date. Generators document it as deprecated, and API.Audit flags
+// it once it has been deprecated for too long.
+func (rb *RouteBuilderNoBody[T, Q]) WithDeprecation(since time.Time) Builder {
+	rb.deprecatedSince = since
+	return rb
+}
+
+// WithBetaSince sets the beta visibility on the route, released in beta on the given date, so API.Audit flags it once
+// it has been in beta for too long.
+func (rb *RouteBuilderNoBody[T, Q]) WithBetaSince(since time.Time) Builder {
+	rb.betaSince = since
+	return rb.WithVisibility(VisibilityBeta)
+}
+
 // SkipIf ensures that the route is not documented if the condition is true.
 func (rb *RouteBuilderNoBody[T, Q]) SkipIf(skip bool) Builder {
 	rb.skipped = skip
 	return rb
 }
 
-// RegisterBeta registers the route and marks it as beta, meaning it will not be included in the OpenAPI documentation.
+// RegisterBeta registers the route with the beta visibility, meaning it is only included in the documentation of
+// generators configured to include beta routes.
 func (rb *RouteBuilderNoBody[T, Q]) RegisterBeta(api *API) {
-	rb.SkipIf(true).Register(api)
+	rb.WithVisibility(VisibilityBeta).Register(api)
 }
 
 // Register registers the route with the mux, and finalizes the route configuration.
 func (rb *RouteBuilderNoBody[T, Q]) Register(api *API) {
+	rb.applyPlugins(api, rb)
 	if err := rb.validate(); err != nil {
 		panic(err)
 	}
@@ -285,7 +799,19 @@ func (rb *RouteBuilderNoBody[T, Q]) Register(api *API) {
 		panic("handler is required")
 	}
 	if rb.group == "" {
-		panic("group is required")
+		rb.group = inferGroup(api, rb.ResourceID())
+	}
+	if rb.group == "" {
+		msg := fmt.Sprintf("route group name could not be inferred for %s %s; consider using group.WithDefaultName() to set it explicitly", rb.method, rb.path)
+		panic(msg)
+	}
+
+	rb.compilePathParams()
+	if rb.timeout == 0 {
+		rb.timeout = api.defaultTimeout
+	}
+	if rb.responseLimit.MaxBytes == 0 {
+		rb.responseLimit = api.defaultResponseLimit
 	}
 
 	var output T
@@ -304,13 +830,55 @@ func (rb *RouteBuilderNoBody[T, Q]) Register(api *API) {
 			WithDescription(rb.desc),
 			WithSummary(rb.summary),
 			WithTags(rb.tags...),
-			WithExtension(rb.keyVals),
+			WithExtension(api.operationExtensions(rb.group, rb.keyVals)),
+			WithFieldSelectionParam(rb.fieldSelection),
+			WithNoContentResponse(rb.noContent),
+			WithCachePolicy(rb.cache),
+			WithContentPolicy(rb.content),
+			WithPathParams(rb.pathParams),
+			WithTimeoutDuration(rb.timeout),
+			WithOperationTimeoutStatus(api.routeTimeoutStatus()),
+			WithResponseDescriptions(rb.responseDescs),
+			WithErrorCodes(rb.errors...),
+			WithRepresentations(rb.representationEntities()),
+			WithMiddlewareNames(rb.mwNames...),
+			WithOperationVisibility(rb.visibility),
+			WithOperationFeatureFlags(rb.featureFlags...),
+			WithOperationRollout(rb.rollout),
+			WithOperationTranslations(rb.translations),
+			WithOperationOwner(api.operationOwner(rb.group, rb.owner)),
+			WithOperationDeprecation(rb.deprecatedSince),
+			WithOperationBetaSince(rb.betaSince),
+			WithOperationSignedURL(rb.signedURL),
 		)
 	}
 
-	h := newHandler(api, rb.handler, rb.successCode)
+	h := api.withErrors(newHandler(api, rb.handler, &rb.RouteBuilderBase))
+	if rb.timeout > 0 {
+		h = withTimeout(api, h, rb.timeout, api.routeTimeoutStatus())
+	}
+	if rb.responseLimit.MaxBytes > 0 && !isFile[T]() {
+		h = withResponseLimit(api, h, &rb.RouteBuilderBase)
+	}
 
-	api.Handle(rb.method, rb.path, h, rb.mw...)
+	rb.handle(api, h)
+}
+
+// inferGroupName names a group after the resource of its routes. Routes without a resource cannot be grouped.
+func inferGroupName(resourceID string) string {
+	if resourceID == (m.Nil{}).Name() {
+		return ""
+	}
+	return resourceID
+}
+
+// inferGroup returns the group of a route registered without one, named after its resource.
+func inferGroup(api *API, resourceID string) string {
+	name := inferGroupName(resourceID)
+	if name == "" {
+		return ""
+	}
+	return api.naming.Groups(name)
 }
 
 func DefaultSuccessCode(method string, output m.WithSchema) int {
@@ -320,6 +888,57 @@ func DefaultSuccessCode(method string, output m.WithSchema) int {
 	return successCodes[method]
 }
 
+var responseDescriptions = map[int]string{
+	http.StatusOK:                           "The request succeeded.",
+	http.StatusCreated:                      "The resource was created.",
+	http.StatusAccepted:                     "The request was accepted for processing.",
+	http.StatusNoContent:                    "The request succeeded, with no content in the response.",
+	http.StatusPartialContent:               "The requested ranges of the resource.",
+	http.StatusNotModified:                  "The resource was not modified since the cached version.",
+	http.StatusBadRequest:                   "The request is malformed.",
+	http.StatusUnauthorized:                 "The request is not authenticated.",
+	http.StatusForbidden:                    "The request is not allowed.",
+	http.StatusNotFound:                     "The resource was not found.",
+	http.StatusMethodNotAllowed:             "The method is not allowed on the resource.",
+	http.StatusNotAcceptable:                "None of the accepted content types can be produced.",
+	http.StatusConflict:                     "The request conflicts with the current state of the resource.",
+	http.StatusGone:                         "The resource is no longer available.",
+	http.StatusPreconditionFailed:           "A precondition of the request failed.",
+	http.StatusRequestEntityTooLarge:        "The request body is too large.",
+	http.StatusUnsupportedMediaType:         "The content type of the request is not supported.",
+	http.StatusRequestedRangeNotSatisfiable: "None of the requested ranges can be satisfied.",
+	http.StatusUnprocessableEntity:          "The request body is invalid.",
+	http.StatusTooManyRequests:              "Too many requests were sent in a given amount of time.",
+	http.StatusInternalServerError:          "The server encountered an unexpected error.",
+	http.StatusNotImplemented:               "The request is not supported by the server.",
+	http.StatusBadGateway:                   "An upstream server returned an invalid response.",
+	http.StatusServiceUnavailable:           "The server is temporarily unavailable.",
+	http.StatusGatewayTimeout:               "The request timed out.",
+}
+
+// DefaultResponseDescription returns the description documented for a response with the given status, unless it is
+// set with WithResponseDesc. Statuses without a tailored description fall back to the status text, e.g. "I'm a teapot".
+func DefaultResponseDescription(status int) string {
+	if desc, ok := responseDescriptions[status]; ok {
+		return desc
+	}
+	if text := http.StatusText(status); text != "" {
+		return text + "."
+	}
+	return fmt.Sprintf("Status %d.", status)
+}
+
+// UnwrapComponent returns the entity wrapped by a DerivedType that keeps a component of its own, see
+// m.DerivedComponent. The other entities are not unwrapped.
+func UnwrapComponent(ent m.WithSchema) (m.WithSchema, bool) {
+	d, ok := ent.(m.DerivedComponent)
+	if !ok || !d.KeepComponent() {
+		return nil, false
+	}
+
+	return d.Unwrap(), true
+}
+
 func RecursivelyUnwrap(current m.WithSchema) m.WithSchema {
 	for {
 		unwrapper, ok := current.(m.DerivedType)
@@ -0,0 +1,245 @@
+package mason
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachePolicy describes how the responses of a GET route may be cached. It is set on the route with WithCache,
+// emitted as the Cache-Control and Vary headers, and documented on the operation response.
+type CachePolicy struct {
+	// MaxAge is how long a response stays fresh.
+	MaxAge time.Duration
+	// Private responses are specific to a user: shared caches, including the Cache middleware, do not store them.
+	Private bool
+	// Vary lists the request headers that select between cached representations, e.g. Accept-Language.
+	Vary []string
+	// SurrogateKeys tag the responses in the Surrogate-Key header, so a CDN can purge them by key, see API.OnPurge.
+	// They can reference the path params of the route, e.g. item-{id}.
+	SurrogateKeys []string
+}
+
+// CacheControl returns the value of the Cache-Control header for the policy.
+func (p CachePolicy) CacheControl() string {
+	if p.MaxAge <= 0 {
+		return "no-cache"
+	}
+
+	scope := "public"
+	if p.Private {
+		scope = "private"
+	}
+
+	return fmt.Sprintf("%s, max-age=%d", scope, int(p.MaxAge.Seconds()))
+}
+
+// VaryHeader returns the value of the Vary header for the policy, or an empty string if responses do not vary.
+func (p CachePolicy) VaryHeader() string {
+	return strings.Join(p.vary(), ", ")
+}
+
+// vary returns the canonical, sorted and deduplicated Vary headers.
+func (p CachePolicy) vary() []string {
+	seen := make(map[string]bool)
+	headers := make([]string, 0, len(p.Vary))
+	for _, h := range p.Vary {
+		h = http.CanonicalHeaderKey(strings.TrimSpace(h))
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		headers = append(headers, h)
+	}
+	sort.Strings(headers)
+
+	return headers
+}
+
+// setHeaders sets the cache headers of the policy on the response.
+func (p CachePolicy) setHeaders(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", p.CacheControl())
+	if vary := p.VaryHeader(); vary != "" {
+		w.Header().Set("Vary", vary)
+	}
+}
+
+// CachedResponse is a response stored in a CacheStore.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// CacheStore is the storage backend of the Cache middleware, e.g. an in-memory map or Redis.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (CachedResponse, bool)
+	Set(ctx context.Context, key string, rsp CachedResponse, ttl time.Duration)
+}
+
+var _ CacheStore = (*MemoryCacheStore)(nil)
+
+// memoryCacheMinSweep is the number of entries from which MemoryCacheStore.Set removes the expired ones.
+const memoryCacheMinSweep = 64
+
+// MemoryCacheStore is a CacheStore that keeps responses in memory, for tests and single instance deployments. The
+// expired entries are removed when they are read, and by Set each time the number of entries doubles, so the entries
+// that are never read again do not pile up.
+type MemoryCacheStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	now     func() time.Time
+	// sweepAt is the number of entries from which Set removes the expired ones
+	sweepAt int
+}
+
+type memoryCacheEntry struct {
+	rsp     CachedResponse
+	expires time.Time
+}
+
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{
+		entries: make(map[string]memoryCacheEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryCacheStore) Get(_ context.Context, key string) (CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return CachedResponse{}, false
+	}
+	if !s.now().Before(entry.expires) {
+		delete(s.entries, key)
+		return CachedResponse{}, false
+	}
+
+	return entry.rsp, true
+}
+
+// Purge removes the responses whose Surrogate-Key header has any of the keys. It is a PurgeHook, for API.OnPurge.
+func (s *MemoryCacheStore) Purge(_ context.Context, keys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, entry := range s.entries {
+		tagged := strings.Fields(entry.rsp.Header.Get(SurrogateKeyHeader))
+		if slices.ContainsFunc(keys, func(key string) bool { return slices.Contains(tagged, key) }) {
+			delete(s.entries, k)
+		}
+	}
+}
+
+func (s *MemoryCacheStore) Set(_ context.Context, key string, rsp CachedResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if len(s.entries) >= max(s.sweepAt, memoryCacheMinSweep) {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.sweepAt = 2 * len(s.entries)
+	}
+
+	s.entries[key] = memoryCacheEntry{rsp: rsp, expires: now.Add(ttl)}
+}
+
+// Len returns the number of responses in the store, including the expired ones not removed yet.
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+// cacheable is implemented by the route builders, so the Cache middleware can read the policy of the route.
+type cacheable interface {
+	CachePolicy() (CachePolicy, bool)
+}
+
+var _ Middleware = (*CacheMiddleware)(nil)
+
+// CacheMiddleware serves repeated GET requests from a CacheStore, for routes registered WithCache.
+type CacheMiddleware struct {
+	store CacheStore
+}
+
+// Cache returns a middleware that caches the successful responses of GET routes with a public cache policy.
+// Responses are keyed by the request URL and the values of the headers the policy varies on. The X-Cache header
+// tells whether a response was served from the cache (HIT) or not (MISS).
+func Cache(store CacheStore) *CacheMiddleware {
+	return &CacheMiddleware{store: store}
+}
+
+func (c *CacheMiddleware) GetHandler(builder Builder) func(WebHandler) WebHandler {
+	return func(next WebHandler) WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			// the policy is read per request, as WithCache may be called after WithMWs
+			cb, ok := builder.(cacheable)
+			if !ok || r.Method != http.MethodGet {
+				return next(ctx, w, r)
+			}
+			policy, ok := cb.CachePolicy()
+			if !ok || policy.Private || policy.MaxAge <= 0 {
+				return next(ctx, w, r)
+			}
+
+			key := cacheKey(r, policy)
+			if rsp, ok := c.store.Get(ctx, key); ok {
+				for k, v := range rsp.Header {
+					w.Header()[k] = v
+				}
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(rsp.Status)
+				_, err := w.Write(rsp.Body)
+				return err
+			}
+
+			rec := &recordingWriter{header: make(http.Header)}
+			if err := next(ctx, rec, r); err != nil {
+				return err
+			}
+
+			if rec.status() == http.StatusOK {
+				c.store.Set(ctx, key, CachedResponse{
+					Status: rec.status(),
+					Header: rec.header.Clone(),
+					Body:   append([]byte(nil), rec.body.Bytes()...),
+				}, policy.MaxAge)
+			}
+
+			rec.header.Set("X-Cache", "MISS")
+			rec.flush(w)
+
+			return nil
+		}
+	}
+}
+
+// cacheKey identifies a cached representation by the request URL and the headers the policy varies on.
+func cacheKey(r *http.Request, policy CachePolicy) string {
+	var sb strings.Builder
+	sb.WriteString(r.Method)
+	sb.WriteString(" ")
+	sb.WriteString(r.URL.RequestURI())
+	for _, h := range policy.vary() {
+		sb.WriteString("\n")
+		sb.WriteString(h)
+		sb.WriteString(": ")
+		sb.WriteString(strings.Join(r.Header.Values(h), ","))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,132 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestCache(t *testing.T) {
+	calls := 0
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		calls++
+		return &Item{Title: r.Header.Get("Accept-Language")}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+		Path("/items/{id}").
+		WithOpID("get_item").
+		WithMWs(mason.Cache(mason.NewMemoryCacheStore())).
+		WithCache(mason.CachePolicy{MaxAge: time.Minute, Vary: []string{"accept-language"}}))
+
+	get := func(lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("en")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "Accept-Language", rec.Header().Get("Vary"))
+
+	rec = get("en")
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, `{"title":"en"}`+"\n", rec.Body.String())
+
+	rec = get("de")
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	assert.Equal(t, 2, calls)
+}
+
+func TestCache_NotStored(t *testing.T) {
+	calls := 0
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		calls++
+		if r.PathValue("id") == "unknown" {
+			return nil, model.NewAPIError("not_found", "item does not exist")
+		}
+		return &Item{Title: "a"}, nil
+	}
+
+	store := mason.NewMemoryCacheStore()
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.RegisterError("not_found", http.StatusNotFound, &model.APIError{})
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandleGet(getItem).
+		Path("/items/{id}").
+		WithOpID("get_item").
+		WithErrors("not_found").
+		WithMWs(mason.Cache(store)).
+		WithCache(mason.CachePolicy{MaxAge: time.Minute}))
+	grp.Register(mason.HandleGet(getItem).
+		Path("/me/items/{id}").
+		WithOpID("get_my_item").
+		WithMWs(mason.Cache(store)).
+		WithCache(mason.CachePolicy{MaxAge: time.Minute, Private: true}))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// the private responses are left to the client
+	for range 2 {
+		rec := get("/me/items/1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "private, max-age=60", rec.Header().Get("Cache-Control"))
+		assert.Equal(t, "", rec.Header().Get("X-Cache"))
+	}
+
+	// the errors are not stored
+	for range 2 {
+		rec := get("/items/unknown")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	}
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	ctx := context.Background()
+	store := mason.NewMemoryCacheStore()
+	tagged := func(keys string) mason.CachedResponse {
+		return mason.CachedResponse{Status: http.StatusOK, Header: http.Header{mason.SurrogateKeyHeader: {keys}}}
+	}
+
+	store.Set(ctx, "a", tagged("items item-1"), time.Minute)
+	store.Set(ctx, "b", tagged("items item-2"), time.Minute)
+	store.Set(ctx, "c", tagged("users"), time.Minute)
+	store.Purge(ctx, []string{"item-1", "users"})
+	_, ok := store.Get(ctx, "a")
+	assert.Assert(t, !ok)
+	_, ok = store.Get(ctx, "b")
+	assert.Assert(t, ok)
+	_, ok = store.Get(ctx, "c")
+	assert.Assert(t, !ok)
+
+	// the expired entries are removed even when they are never read again
+	for i := range 100 {
+		store.Set(ctx, strconv.Itoa(i), tagged("items"), time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	for i := range 50 {
+		store.Set(ctx, "live-"+strconv.Itoa(i), tagged("items"), time.Minute)
+	}
+	assert.Equal(t, 51, store.Len())
+}
@@ -0,0 +1,122 @@
+package mason
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/tailbits/mason/model"
+)
+
+// ClassificationReport lists the classified entities of the API, for the compliance tooling, e.g. to find the
+// operations handling personal data. It is JSON serializable.
+type ClassificationReport struct {
+	Entities []ClassifiedEntity `json:"entities"`
+}
+
+// ClassifiedEntity is an entity classified as a whole, or with classified fields.
+type ClassifiedEntity struct {
+	Name           string                   `json:"name"`
+	Classification model.DataClassification `json:"classification,omitempty"`
+	Fields         []ClassifiedField        `json:"fields,omitempty"`
+	// Operations are the IDs of the operations the entity is the body or an alternate representation of, sorted.
+	Operations []string `json:"operations,omitempty"`
+}
+
+// ClassifiedField is a classified field of an entity, by path, see model.Classifications.
+type ClassifiedField struct {
+	Path           string                   `json:"path"`
+	Classification model.DataClassification `json:"classification"`
+}
+
+// ClassificationReport returns the entities classified with model.WithDataClassification, or with fields marked with
+// the x-data-classification keyword or the classification tag, sorted by name.
+func (a *API) ClassificationReport() (ClassificationReport, error) {
+	operations := make(map[string][]string)
+	a.ForEachOperation(func(_ string, op Operation) {
+		ents := []model.Entity{op.Input, op.Output}
+		for _, rep := range op.Representations {
+			ents = append(ents, rep)
+		}
+		for _, ent := range ents {
+			if !isNilEntity(ent) {
+				operations[ent.Name()] = append(operations[ent.Name()], op.OperationID)
+			}
+		}
+	})
+
+	report := ClassificationReport{Entities: []ClassifiedEntity{}}
+	for _, name := range a.ModelNames() {
+		ent, err := a.classify(a.models[name])
+		if err != nil {
+			return ClassificationReport{}, fmt.Errorf("failed to classify %s: %w", name, err)
+		}
+		if ent.Classification == "" && len(ent.Fields) == 0 {
+			continue
+		}
+		ent.Operations = operations[name]
+		slices.Sort(ent.Operations)
+		ent.Operations = slices.Compact(ent.Operations)
+		report.Entities = append(report.Entities, ent)
+	}
+
+	return report, nil
+}
+
+// Fields returns the fields with the classification, as entity name and path, e.g. User.email, sorted.
+func (r ClassificationReport) Fields(c model.DataClassification) []string {
+	var fields []string
+	for _, ent := range r.Entities {
+		for _, f := range ent.Fields {
+			if f.Classification == c {
+				fields = append(fields, ent.Name+"."+f.Path)
+			}
+		}
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+func (a *API) classify(ent model.Entity) (ClassifiedEntity, error) {
+	res := ClassifiedEntity{Name: ent.Name()}
+	if c, ok := ent.(model.WithDataClassification); ok {
+		res.Classification = c.DataClassification()
+	}
+
+	schema := ent.Schema()
+	if m := NewModel(ent); m.reflectsSchema() {
+		sch, err := reflectSchema(ent)
+		if err != nil {
+			return ClassifiedEntity{}, err
+		}
+		if schema, err = json.Marshal(sch); err != nil {
+			return ClassifiedEntity{}, err
+		}
+	} else if len(schema) > 0 {
+		var err error
+		if schema, err = a.DereferenceSchema(schema); err != nil {
+			return ClassifiedEntity{}, err
+		}
+	} else {
+		return res, nil
+	}
+
+	fields, err := model.Classifications(schema)
+	if err != nil {
+		return ClassifiedEntity{}, err
+	}
+	for path, c := range fields {
+		if path == "" {
+			if res.Classification == "" {
+				res.Classification = c
+			}
+			continue
+		}
+		res.Fields = append(res.Fields, ClassifiedField{Path: path, Classification: c})
+	}
+	sort.Slice(res.Fields, func(i, j int) bool { return res.Fields[i].Path < res.Fields[j].Path })
+
+	return res, nil
+}
@@ -0,0 +1,127 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+var _ model.WithDataClassification = (*Patient)(nil)
+
+type Patient struct {
+	Email     string    `json:"email"`
+	Addresses []Address `json:"addresses"`
+}
+
+type Address struct {
+	Street  string `json:"street"`
+	Country string `json:"country"`
+}
+
+func (c *Patient) DataClassification() model.DataClassification {
+	return model.ClassificationInternal
+}
+
+func (c *Patient) Example() []byte {
+	return []byte(`{"email": "ada@example.com", "addresses": [{"street": "1 Main St"}]}`)
+}
+
+func (c *Patient) Marshal() (json.RawMessage, error) {
+	return json.Marshal(c)
+}
+
+func (c *Patient) Name() string {
+	return "Patient"
+}
+
+func (c *Patient) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"email": {"type": "string", "x-data-classification": "pii"},
+			"addresses": {"type": "array", "items": {"$ref": "#/definitions/Address"}}
+		},
+		"definitions": {
+			"Address": {
+				"type": "object",
+				"properties": {
+					"street": {"type": "string", "x-data-classification": "pii"},
+					"country": {"type": "string", "x-data-classification": "public"}
+				}
+			}
+		}
+	}`)
+}
+
+func (c *Patient) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, c)
+}
+
+type Lead struct {
+	Phone  string `json:"phone" classification:"pii"`
+	Source string `json:"source"`
+}
+
+func (l *Lead) Example() []byte {
+	return []byte(`{"phone": "+33123456789", "source": "ads"}`)
+}
+
+func (l *Lead) Marshal() (json.RawMessage, error) {
+	return json.Marshal(l)
+}
+
+func (l *Lead) Name() string {
+	return "Lead"
+}
+
+func (l *Lead) Schema() []byte {
+	return nil
+}
+
+func (l *Lead) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, l)
+}
+
+func TestClassificationReport(t *testing.T) {
+	getPatient := func(ctx context.Context, r *http.Request, params model.Nil) (*Patient, error) {
+		return &Patient{}, nil
+	}
+	createLead := func(ctx context.Context, r *http.Request, lead *Lead, params model.Nil) (*Lead, error) {
+		return lead, nil
+	}
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("crm")
+	grp.Register(mason.HandleGet(getPatient).Path("/patient").WithOpID("get_patient"))
+	grp.Register(mason.HandlePost(createLead).Path("/leads").WithOpID("create_lead"))
+	grp.Register(mason.HandlePost(createItem).Path("/items").WithOpID("create_item"))
+
+	report, err := api.ClassificationReport()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, mason.ClassificationReport{Entities: []mason.ClassifiedEntity{
+		{
+			Name:       "Lead",
+			Fields:     []mason.ClassifiedField{{Path: "phone", Classification: model.ClassificationPII}},
+			Operations: []string{"create_lead"},
+		},
+		{
+			Name:           "Patient",
+			Classification: model.ClassificationInternal,
+			Fields: []mason.ClassifiedField{
+				{Path: "addresses[].country", Classification: model.ClassificationPublic},
+				{Path: "addresses[].street", Classification: model.ClassificationPII},
+				{Path: "email", Classification: model.ClassificationPII},
+			},
+			Operations: []string{"get_patient"},
+		},
+	}}, report)
+	assert.DeepEqual(t, []string{"Lead.phone", "Patient.addresses[].street", "Patient.email"}, report.Fields(model.ClassificationPII))
+}
@@ -0,0 +1,32 @@
+package mason
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+// notModified sets the Last-Modified header of a GET response implementing model.TimestampedEntity, and answers with a
+// 304 when the If-Modified-Since of the request is not older, so clients syncing a collection only download it when
+// it changed. It reports whether the response was answered.
+func (rb *RouteBuilderBase) notModified(w http.ResponseWriter, r *http.Request, result model.WithSchema) bool {
+	ts, ok := result.(model.TimestampedEntity)
+	if !ok || rb.method != http.MethodGet {
+		return false
+	}
+	// the header has a precision of a second
+	modified := ts.LastModified().UTC().Truncate(time.Second)
+	if modified.IsZero() {
+		return false
+	}
+	w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modified.After(since) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+
+	return true
+}
@@ -0,0 +1,74 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+// timestampedItem is an Item that knows when it last changed.
+type timestampedItem struct {
+	Item
+	updated time.Time
+}
+
+func (i *timestampedItem) LastModified() time.Time {
+	return i.updated
+}
+
+func TestConditionalGet(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 12, 30, 15, 500, time.UTC)
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*timestampedItem, error) {
+		return &timestampedItem{Item: Item{Title: "a"}, updated: updated}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime(mason.WithAutoHead())
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+		Path("/items/{id}").
+		WithOpID("get_item").
+		WithCache(mason.CachePolicy{MaxAge: time.Minute}))
+
+	get := func(method string, since string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/items/1", nil)
+		if since != "" {
+			req.Header.Set("If-Modified-Since", since)
+		}
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("sets last modified", func(t *testing.T) {
+		rec := get(http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "Sun, 01 Mar 2026 12:30:15 GMT", rec.Header().Get("Last-Modified"))
+		assert.Equal(t, `{"title":"a"}`+"\n", rec.Body.String())
+	})
+
+	t.Run("not modified since", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			rec := get(method, "Sun, 01 Mar 2026 12:30:15 GMT")
+			assert.Equal(t, http.StatusNotModified, rec.Code)
+			assert.Equal(t, "", rec.Body.String())
+			assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+			assert.Equal(t, "Sun, 01 Mar 2026 12:30:15 GMT", rec.Header().Get("Last-Modified"))
+		}
+	})
+
+	t.Run("modified since", func(t *testing.T) {
+		rec := get(http.MethodGet, "Sun, 01 Mar 2026 12:30:14 GMT")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		rec := get(http.MethodGet, "yesterday")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
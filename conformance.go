@@ -0,0 +1,330 @@
+package mason
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// Mismatch describes a difference between live traffic and the operations registered on the API.
+type Mismatch struct {
+	OperationID string
+	Method      string
+	Path        string
+	// Phase is either "request" or "response".
+	Phase string
+	Err   error
+}
+
+func (m Mismatch) Error() string {
+	return fmt.Sprintf("%s %s (%s): %s mismatch: %v", m.Method, m.Path, m.OperationID, m.Phase, m.Err)
+}
+
+type conformanceOptions struct {
+	reject     bool
+	onMismatch func(r *http.Request, m Mismatch)
+}
+
+type ConformanceOption func(*conformanceOptions)
+
+// RejectMismatches makes the conformance middleware reject non-conforming traffic, instead of only reporting it.
+// Invalid requests get a 422 and never reach the wrapped handler, and invalid responses are replaced with a 502.
+func RejectMismatches() ConformanceOption {
+	return func(o *conformanceOptions) {
+		o.reject = true
+	}
+}
+
+// OnMismatch sets the callback that is invoked for every mismatch, e.g. to log it.
+func OnMismatch(fn func(r *http.Request, m Mismatch)) ConformanceOption {
+	return func(o *conformanceOptions) {
+		o.onMismatch = fn
+	}
+}
+
+// Conformance returns a middleware that checks live requests and responses against the operations registered on
+// the API: path matching, query params, request bodies, status codes and response bodies. It can wrap any handler,
+// so mason can be used as a conformance gate in front of a service that is not (yet) built with mason.
+func Conformance(api *API, opts ...ConformanceOption) func(http.Handler) http.Handler {
+	options := conformanceOptions{
+		onMismatch: func(*http.Request, Mismatch) {},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op, ok := api.matchOperation(r.Method, r.URL.Path)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			report := func(phase string, err error) {
+				options.onMismatch(r, Mismatch{
+					OperationID: op.OperationID,
+					Method:      r.Method,
+					Path:        r.URL.Path,
+					Phase:       phase,
+					Err:         err,
+				})
+			}
+
+			if err := api.checkRequest(op, r); err != nil {
+				report("request", err)
+				if options.reject {
+					http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+					return
+				}
+			}
+
+			rec := &recordingWriter{header: make(http.Header)}
+			next.ServeHTTP(rec, r)
+
+			if err := api.checkResponse(op, r, rec.status(), rec.header, rec.body.Bytes()); err != nil {
+				report("response", err)
+				if options.reject {
+					http.Error(w, "response does not conform to the API specification", http.StatusBadGateway)
+					return
+				}
+			}
+
+			rec.flush(w)
+		})
+	}
+}
+
+// matchOperation returns the operation of the method whose path matches the request path. When several paths match,
+// the most specific one wins, like the mux would serve it, e.g. /items/latest over /items/{id}.
+func (a *API) matchOperation(method string, path string) (Operation, bool) {
+	var best Operation
+	found := false
+	for _, op := range a.Operations() {
+		if op.Method == method && matchPath(op.Path, path) && (!found || compareRoutes(op.Path, best.Path) < 0) {
+			best, found = op, true
+		}
+	}
+
+	return best, found
+}
+
+func (a *API) checkRequest(op Operation, r *http.Request) error {
+	if err := checkQueryParams(op, r); err != nil {
+		return err
+	}
+
+	if op.Input == nil || op.Input.Name() == (model.Nil{}).Name() {
+		return nil
+	}
+	// the lines of a stream are validated as the handler reads them, without buffering the body, see Stream
+	if _, ok := op.Input.(streamDecoder); ok {
+		return nil
+	}
+
+	body, err := readBody(r, true)
+	if err != nil {
+		return err
+	}
+
+	return a.validateEntity(op.Input, body)
+}
+
+func (a *API) checkResponse(op Operation, r *http.Request, status int, header http.Header, body []byte) error {
+	if status >= 400 {
+		return nil
+	}
+
+	// a conditional GET is answered with a 304 and no body when the client has the latest version, see notModified
+	if status == http.StatusNotModified && isConditional(r) {
+		return nil
+	}
+
+	// files are binary, and answered with the partial content of the Range requests, see serveFile
+	if _, ok := op.Output.(*File); ok {
+		if status != http.StatusOK && status != http.StatusPartialContent {
+			return fmt.Errorf("unexpected status code %d, expected %d or %d", status, http.StatusOK, http.StatusPartialContent)
+		}
+		return nil
+	}
+
+	if op.SuccessCode != 0 && status != op.SuccessCode {
+		return fmt.Errorf("unexpected status code %d, expected %d", status, op.SuccessCode)
+	}
+
+	if op.Output == nil || op.Output.Name() == (model.Nil{}).Name() {
+		return nil
+	}
+
+	// the alternate representations chosen from the Accept header are validated against their own entity, and the raw
+	// ones, e.g. CSV, have no schema to validate against
+	output := op.Output
+	if mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil && mediaType != "application/json" {
+		ent, ok := op.Representations[mediaType]
+		if !ok || ent == nil {
+			return nil
+		}
+		output = ent
+	}
+
+	// a pruned response is not expected to satisfy the required fields of the schema
+	if op.FieldSelection && r.URL.Query().Get(FieldsParam) != "" {
+		return nil
+	}
+
+	return a.validateEntity(output, body)
+}
+
+// isConditional reports whether a request is a conditional GET or HEAD, which may be answered with a 304.
+func isConditional(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	return r.Header.Get("If-Modified-Since") != "" || r.Header.Get("If-None-Match") != ""
+}
+
+func (a *API) validateEntity(ent model.WithSchema, body []byte) error {
+	schema, err := a.DereferenceSchema(ent.Schema())
+	if err != nil {
+		return fmt.Errorf("dereferenceSchema ent[%s]: %w", ent.Name(), err)
+	}
+
+	return model.Validate(schema, body)
+}
+
+func checkQueryParams(op Operation, r *http.Request) error {
+	params := op.QueryParams
+	if params == nil {
+		return nil
+	}
+	t := reflect.TypeOf(params)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	kinds := make(map[string]reflect.Kind)
+	forEachQueryField(t, func(tag string, field reflect.StructField) {
+		kind := field.Type.Kind()
+		if kind == reflect.Ptr {
+			kind = field.Type.Elem().Kind()
+		}
+		kinds[tag] = kind
+		for _, alias := range ParseQueryParamTags(field.Tag).Aliases {
+			kinds[alias] = kind
+		}
+	})
+	if op.FieldSelection {
+		kinds[FieldsParam] = reflect.String
+	}
+	// the signature is checked by the URLSigner
+	if op.SignedURL {
+		kinds[SignatureParam] = reflect.String
+		kinds[ExpiresParam] = reflect.String
+	}
+
+	errs := []model.FieldError{}
+	for key, values := range r.URL.Query() {
+		// deep object params like filter[status]=x are checked by their decoder
+		if name, _, found := strings.Cut(key, "["); found {
+			if _, ok := kinds[name]; ok {
+				continue
+			}
+		}
+
+		kind, ok := kinds[key]
+		if !ok {
+			errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' is not a known query param", key)})
+			continue
+		}
+		for _, v := range values {
+			var err error
+			switch kind {
+			case reflect.Int:
+				_, err = strconv.Atoi(v)
+			case reflect.Bool:
+				_, err = strconv.ParseBool(v)
+			}
+			if err != nil {
+				errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' has an invalid value", key)})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		res := model.ValidationError{Errors: errs}
+		model.SortErrors(&res)
+		return res
+	}
+
+	return nil
+}
+
+// matchPath reports whether a concrete path matches a route pattern like /users/{id} or /files/{path...}.
+func matchPath(pattern string, path string) bool {
+	pp := strings.Split(strings.Trim(pattern, "/"), "/")
+	sp := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, seg := range pp {
+		// the wildcard matches the rest of the path, which is at least one segment
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}") {
+			return i < len(sp) && sp[i] != ""
+		}
+		if i >= len(sp) {
+			return false
+		}
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if sp[i] == "" {
+				return false
+			}
+			continue
+		}
+		if seg != sp[i] {
+			return false
+		}
+	}
+
+	return len(pp) == len(sp)
+}
+
+// recordingWriter buffers a response so it can be inspected before being sent to the client.
+type recordingWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *recordingWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *recordingWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
+
+func (w *recordingWriter) flush(dst http.ResponseWriter) {
+	for k, v := range w.header {
+		dst.Header()[k] = v
+	}
+	dst.WriteHeader(w.status())
+	_, _ = dst.Write(w.body.Bytes())
+}
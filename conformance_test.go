@@ -0,0 +1,272 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestConformance(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).Path("/items/{id}").WithOpID("get_item"))
+	api.NewRouteGroup("items").Register(mason.HandlePost(CreateItem).Path("/items").WithOpID("create_item"))
+
+	legacy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/items/1":
+			_, _ = w.Write([]byte(`{"title": "one"}`))
+		case "/items/2":
+			_, _ = w.Write([]byte(`{"title": 2}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"title": "new"}`))
+		}
+	})
+
+	var mismatches []mason.Mismatch
+	handler := mason.Conformance(api, mason.RejectMismatches(), mason.OnMismatch(func(_ *http.Request, m mason.Mismatch) {
+		mismatches = append(mismatches, m)
+	}))(legacy)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+		phase  string
+	}{
+		{name: "conforming response", method: http.MethodGet, target: "/items/1", status: http.StatusOK},
+		{name: "invalid response body", method: http.MethodGet, target: "/items/2", status: http.StatusBadGateway, phase: "response"},
+		{name: "unknown query param", method: http.MethodGet, target: "/items/1?nope=1", status: http.StatusUnprocessableEntity, phase: "request"},
+		{name: "invalid request body", method: http.MethodPost, target: "/items", body: `{"title": 1}`, status: http.StatusUnprocessableEntity, phase: "request"},
+		{name: "valid request body", method: http.MethodPost, target: "/items", body: `{"title": "new"}`, status: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatches = nil
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.phase == "" {
+				assert.Equal(t, 0, len(mismatches))
+				return
+			}
+			assert.Equal(t, 1, len(mismatches))
+			assert.Equal(t, tt.phase, mismatches[0].Phase)
+		})
+	}
+}
+
+func TestConformanceConditionalGet(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 12, 30, 15, 0, time.UTC)
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*timestampedItem, error) {
+		return &timestampedItem{Item: Item{Title: "a"}, updated: updated}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item"))
+
+	var mismatches []mason.Mismatch
+	handler := mason.Conformance(api, mason.RejectMismatches(), mason.OnMismatch(func(_ *http.Request, m mason.Mismatch) {
+		mismatches = append(mismatches, m)
+	}))(rtm)
+
+	for since, status := range map[string]int{
+		"Sun, 01 Mar 2026 12:30:15 GMT": http.StatusNotModified,
+		"Sun, 01 Mar 2026 12:30:14 GMT": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		req.Header.Set("If-Modified-Since", since)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, status, rec.Code)
+	}
+	assert.Equal(t, 0, len(mismatches))
+}
+
+func TestConformanceFile(t *testing.T) {
+	export := func(ctx context.Context, r *http.Request, params model.Nil) (*mason.File, error) {
+		return &mason.File{Content: strings.NewReader("id,title\n1,a\n2,b\n"), Filename: "export.csv"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("exports").Register(mason.HandleFile(export).Path("/exports/{id}").WithOpID("download_export"))
+
+	var mismatches []mason.Mismatch
+	handler := mason.Conformance(api, mason.RejectMismatches(), mason.OnMismatch(func(_ *http.Request, m mason.Mismatch) {
+		mismatches = append(mismatches, m)
+	}))(rtm)
+
+	for rng, status := range map[string]int{
+		"":           http.StatusOK,
+		"bytes=9-12": http.StatusPartialContent,
+		"bytes=100-": http.StatusRequestedRangeNotSatisfiable,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/exports/1", nil)
+		if rng != "" {
+			req.Header.Set("Range", rng)
+		}
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, status, rec.Code)
+	}
+	assert.Equal(t, 0, len(mismatches))
+}
+
+func TestConformanceRepresentations(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "done"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+		Path("/item").
+		WithOpID("get_item").
+		WithRepresentation("text/csv", mason.RawRepresentation(func(ctx context.Context, w io.Writer, item *Item) error {
+			_, err := fmt.Fprintf(w, "title\n%s\n", item.Title)
+			return err
+		})).
+		WithRepresentation("application/vnd.error+json", mason.EntityRepresentation(func(item *Item) (*model.APIError, error) {
+			return model.NewAPIError("item", item.Title), nil
+		})))
+
+	var mismatches []mason.Mismatch
+	handler := mason.Conformance(api, mason.RejectMismatches(), mason.OnMismatch(func(_ *http.Request, m mason.Mismatch) {
+		mismatches = append(mismatches, m)
+	}))(rtm)
+
+	for _, accept := range []string{"application/json", "text/csv", "application/vnd.error+json"} {
+		req := httptest.NewRequest(http.MethodGet, "/item", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, accept, rec.Header().Get("Content-Type"))
+	}
+	assert.Equal(t, 0, len(mismatches))
+}
+
+func GetItem(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+	return &Item{}, nil
+}
+
+func CreateItem(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+	return item, nil
+}
+
+var _ model.Entity = (*Item)(nil)
+
+type Item struct {
+	Title string `json:"title"`
+}
+
+func (i *Item) Example() []byte {
+	return []byte(`{"title": "example"}`)
+}
+
+func (i *Item) Marshal() (json.RawMessage, error) {
+	return json.Marshal(i)
+}
+
+func (i *Item) Name() string {
+	return "Item"
+}
+
+func (i *Item) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string"}
+		},
+		"required": ["title"]
+	}`)
+}
+
+func (i *Item) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, i)
+}
+
+type exportParams struct {
+	Format string `json:"format"`
+}
+
+func TestConformanceSignedURL(t *testing.T) {
+	export := func(ctx context.Context, r *http.Request, params exportParams) (*mason.File, error) {
+		return &mason.File{Content: strings.NewReader("id,title\n"), Filename: "export." + params.Format}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	signer := mason.NewURLSigner(api, []byte("secret"))
+	api.NewRouteGroup("exports").Register(mason.HandleFile(export).
+		Path("/exports/{id}").
+		WithOpID("download_export").
+		WithMWs(signer))
+
+	var mismatches []mason.Mismatch
+	handler := mason.Conformance(api, mason.RejectMismatches(), mason.OnMismatch(func(_ *http.Request, m mason.Mismatch) {
+		mismatches = append(mismatches, m)
+	}))(rtm)
+
+	signed, err := signer.Sign("download_export", map[string]string{"id": "1"}, url.Values{"format": {"csv"}}, time.Hour)
+	assert.NilError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, signed, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 0, len(mismatches))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, signed+"&nope=1", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, 1, len(mismatches))
+}
+
+func TestConformanceWildcard(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("files").Register(mason.HandleGet(GetItem).Path("/files/{path...}").WithOpID("get_file"))
+
+	legacy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"title": 1}`))
+	})
+
+	var mismatches []mason.Mismatch
+	handler := mason.Conformance(api, mason.OnMismatch(func(_ *http.Request, m mason.Mismatch) {
+		mismatches = append(mismatches, m)
+	}))(legacy)
+
+	// the wildcard needs at least one segment, so the directory itself is not the operation
+	for _, target := range []string{"/files", "/files/"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	assert.Equal(t, 0, len(mismatches))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/files/a/b.txt", nil))
+	assert.Equal(t, 1, len(mismatches))
+	assert.Equal(t, "get_file", mismatches[0].OperationID)
+}
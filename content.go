@@ -0,0 +1,246 @@
+package mason
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// ContentPolicy describes the charsets and the content codings a route consumes and produces, when they are not the
+// UTF-8 uncompressed JSON of the other routes, e.g. for a gzip-only ingestion endpoint. It is set on the route with
+// WithContent, enforced by the generated handler, and documented on the operation.
+type ContentPolicy struct {
+	// Charsets are the charsets accepted in the Content-Type of the request bodies, e.g. iso-8859-1. The bodies are
+	// decoded as is, so a BeforeDecode hook converts the other charsets to UTF-8. Only UTF-8 is accepted when empty.
+	// The bodies without a charset are always accepted.
+	Charsets []string
+	// Encodings are the content codings accepted for the request bodies, e.g. gzip, with identity for the uncompressed
+	// ones. The gzip and deflate bodies are decompressed before they are decoded, the other codings are left to a
+	// BeforeDecode hook. Only uncompressed bodies are accepted when empty.
+	Encodings []string
+	// ResponseCharset is the charset of the responses, e.g. of a Representation encoding them in another charset than
+	// UTF-8. It is announced in their Content-Type, and the requests that do not accept it are refused.
+	ResponseCharset string
+	// MaxDecompressedBytes is the maximum size of a gzip or deflate body once decompressed, 10 MB when zero. The larger
+	// bodies are refused with a 413, so a small compressed body cannot expand into an unbounded one.
+	MaxDecompressedBytes int64
+}
+
+// defaultMaxDecompressedBytes is the maximum size of a decompressed body when the content policy does not set one.
+const defaultMaxDecompressedBytes = 10 << 20
+
+// ErrUnsupportedMediaType, ErrNotAcceptable and ErrRequestTooLarge are the codes of the errors sent to the requests
+// that do not follow the content policy of a route, with the StatusUnsupportedMediaType, StatusNotAcceptable and
+// StatusRequestEntityTooLarge statuses.
+const (
+	ErrUnsupportedMediaType = "unsupported_media_type"
+	ErrNotAcceptable        = "not_acceptable"
+	ErrRequestTooLarge      = "request_too_large"
+)
+
+// acceptsCharset reports whether the charset of a request body is accepted.
+func (p ContentPolicy) acceptsCharset(charset string) bool {
+	if charset == "" {
+		return true
+	}
+	if len(p.Charsets) == 0 {
+		return strings.EqualFold(charset, "utf-8")
+	}
+
+	return slices.ContainsFunc(p.Charsets, func(c string) bool { return strings.EqualFold(c, charset) })
+}
+
+// acceptsEncoding reports whether a content coding of a request body is accepted.
+func (p ContentPolicy) acceptsEncoding(coding string) bool {
+	if len(p.Encodings) == 0 {
+		return coding == "identity"
+	}
+
+	return slices.ContainsFunc(p.Encodings, func(e string) bool { return strings.EqualFold(e, coding) })
+}
+
+// checkContent enforces the content policy of the route. It responds with a 415 itself when the charset or the
+// content coding of the request body is not accepted, and with a 406 when the request does not accept the charset of
+// the responses, and with a 413 when a decompressed body exceeds MaxDecompressedBytes, and returns false. The accepted
+// gzip and deflate bodies are decompressed.
+func (rb *RouteBuilderBase) checkContent(ctx context.Context, api *API, w http.ResponseWriter, r *http.Request) (bool, error) {
+	p := rb.content
+	if p == nil {
+		return true, nil
+	}
+
+	if p.ResponseCharset != "" && !acceptsCharset(r.Header.Get("Accept-Charset"), p.ResponseCharset) {
+		msg := fmt.Sprintf("The responses are encoded in %s", p.ResponseCharset)
+		return false, api.Respond(ctx, w, model.NewAPIError(ErrNotAcceptable, msg), http.StatusNotAcceptable)
+	}
+
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if _, params, err := mime.ParseMediaType(ct); err == nil && !p.acceptsCharset(params["charset"]) {
+			msg := fmt.Sprintf("The charset %s is not supported", params["charset"])
+			return false, api.Respond(ctx, w, model.NewAPIError(ErrUnsupportedMediaType, msg), http.StatusUnsupportedMediaType)
+		}
+	}
+
+	codings := contentCodings(r.Header.Get("Content-Encoding"))
+	for _, coding := range codings {
+		if !p.acceptsEncoding(coding) {
+			msg := fmt.Sprintf("The content coding %s is not supported", coding)
+			return false, api.Respond(ctx, w, model.NewAPIError(ErrUnsupportedMediaType, msg), http.StatusUnsupportedMediaType)
+		}
+	}
+
+	limit := p.MaxDecompressedBytes
+	if limit <= 0 {
+		limit = defaultMaxDecompressedBytes
+	}
+	ok, err := decompressBody(r, codings, limit)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		msg := fmt.Sprintf("The decompressed body exceeds the limit of %d bytes", limit)
+		return false, api.Respond(ctx, w, model.NewAPIError(ErrRequestTooLarge, msg), http.StatusRequestEntityTooLarge)
+	}
+
+	return true, nil
+}
+
+// contentCodings returns the codings of a Content-Encoding header, in the order they were applied.
+func contentCodings(header string) []string {
+	codings := []string{}
+	for _, coding := range strings.Split(header, ",") {
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "" {
+			codings = append(codings, coding)
+		}
+	}
+	if len(codings) == 0 {
+		return []string{"identity"}
+	}
+
+	return codings
+}
+
+// decompressBody decompresses the gzip and deflate bodies, so they are decoded like the uncompressed ones. The bodies
+// are decompressed in full, up to the limit, and it returns false for a body exceeding it. The bodies with other
+// codings are left as is.
+func decompressBody(r *http.Request, codings []string, limit int64) (bool, error) {
+	for _, coding := range codings {
+		if coding != "gzip" && coding != "deflate" && coding != "identity" {
+			return true, nil
+		}
+	}
+
+	var body io.Reader = r.Body
+	for _, coding := range slices.Backward(codings) {
+		var err error
+		switch coding {
+		case "gzip":
+			body, err = gzip.NewReader(body)
+		case "deflate":
+			body, err = zlib.NewReader(body)
+		}
+		if err != nil {
+			return false, invalidCodingError(coding, err)
+		}
+	}
+	if body == r.Body {
+		return true, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return false, invalidCodingError(codings[len(codings)-1], err)
+	}
+	if int64(len(data)) > limit {
+		return false, nil
+	}
+
+	setBody(r, data, true)
+	r.Header.Del("Content-Encoding")
+	r.ContentLength = int64(len(data))
+
+	return true, nil
+}
+
+// invalidCodingError is the error of a body that cannot be decompressed.
+func invalidCodingError(coding string, err error) error {
+	return model.ValidationError{Errors: []model.FieldError{{Message: fmt.Sprintf("The %s body is invalid: %v", coding, err)}}}
+}
+
+// acceptsCharset reports whether an Accept-Charset header accepts the charset.
+func acceptsCharset(header string, charset string) bool {
+	if strings.TrimSpace(header) == "" {
+		return true
+	}
+
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := quality(params)
+
+		name = strings.TrimSpace(name)
+		switch {
+		case strings.EqualFold(name, charset):
+			return q > 0
+		case name == "*":
+			wildcard = q > 0
+		}
+	}
+
+	return wildcard
+}
+
+// quality returns the q parameter of an element of an Accept, Accept-Charset or Accept-Language header, from its
+// parameters separated by semicolons. It is 1 without a q parameter, and 0 when the parameter is invalid.
+func quality(params string) float64 {
+	q := 1.0
+	for _, param := range strings.Split(params, ";") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil || parsed < 0 {
+				parsed = 0
+			}
+			q = parsed
+		}
+	}
+
+	return q
+}
+
+// charsetWriter announces the charset of the responses in their Content-Type.
+type charsetWriter struct {
+	http.ResponseWriter
+	charset     string
+	wroteHeader bool
+}
+
+func (w *charsetWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if ct := w.Header().Get("Content-Type"); ct != "" && !strings.Contains(strings.ToLower(ct), "charset=") {
+			w.Header().Set("Content-Type", ct+"; charset="+w.charset)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *charsetWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *charsetWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
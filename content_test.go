@@ -0,0 +1,128 @@
+package mason_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func gzipped(t *testing.T, body string) io.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(body))
+	assert.NilError(t, err)
+	assert.NilError(t, zw.Close())
+
+	return &buf
+}
+
+func TestContentPolicy_Encodings(t *testing.T) {
+	var titles []string
+	ingest := func(ctx context.Context, r *http.Request, in *Item, params model.Nil) (*Item, error) {
+		titles = append(titles, in.Title)
+		return in, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandlePost(ingest).
+		Path("/items/ingest").
+		WithOpID("ingest_items").
+		WithContent(mason.ContentPolicy{Encodings: []string{"gzip"}}))
+
+	post := func(body io.Reader, encoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items/ingest", body)
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(gzipped(t, `{"title": "compressed"}`), "gzip")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.DeepEqual(t, []string{"compressed"}, titles)
+
+	rec = post(strings.NewReader(`{"title": "plain"}`), "")
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	assert.Assert(t, strings.Contains(rec.Body.String(), `"code":"unsupported_media_type"`))
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, post(strings.NewReader(`{"title": "br"}`), "br").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, post(strings.NewReader(`{"title": "fake"}`), "gzip").Code)
+	assert.DeepEqual(t, []string{"compressed"}, titles)
+}
+
+func TestContentPolicy_Charsets(t *testing.T) {
+	createItem := func(ctx context.Context, r *http.Request, in *Item, params model.Nil) (*Item, error) {
+		return in, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID("create_item").
+		WithContent(mason.ContentPolicy{Charsets: []string{"utf-8", "iso-8859-1"}, ResponseCharset: "utf-8"}))
+
+	post := func(contentType string, acceptCharset string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"title": "first"}`))
+		req.Header.Set("Content-Type", contentType)
+		if acceptCharset != "" {
+			req.Header.Set("Accept-Charset", acceptCharset)
+		}
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("application/json", "")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	assert.Equal(t, http.StatusCreated, post("application/json; charset=ISO-8859-1", "").Code)
+	assert.Equal(t, http.StatusUnsupportedMediaType, post("application/json; charset=utf-16", "").Code)
+
+	assert.Equal(t, http.StatusCreated, post("application/json", "iso-8859-1;q=0.5, *").Code)
+	rec = post("application/json", "iso-8859-1, utf-8;q=0")
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+	assert.Assert(t, strings.Contains(rec.Body.String(), `"code":"not_acceptable"`))
+}
+
+func TestContentPolicy_MaxDecompressedBytes(t *testing.T) {
+	ingest := func(ctx context.Context, r *http.Request, in *Item, params model.Nil) (*Item, error) {
+		return in, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandlePost(ingest).
+		Path("/items/ingest").
+		WithOpID("ingest_items").
+		WithContent(mason.ContentPolicy{Encodings: []string{"gzip"}, MaxDecompressedBytes: 64}))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items/ingest", gzipped(t, body))
+		req.Header.Set("Content-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusCreated, post(`{"title": "small"}`).Code)
+
+	rec := post(`{"title": "` + strings.Repeat("a", 1024) + `"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Assert(t, strings.Contains(rec.Body.String(), `"code":"request_too_large"`))
+}
@@ -1,49 +1,115 @@
 package mason
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"reflect"
+	"slices"
 
 	"github.com/tailbits/mason/model"
 )
 
-type decodeOptions struct{}
+type decodeOptions struct {
+	validation     []model.ValidationOption
+	skipValidation bool
+	skipRestore    bool
+}
 
 type DecodeOption func(options *decodeOptions) error
 
+// WithValidation configures the validation errors of the request body, on top of the validation options of the API.
+func WithValidation(opts ...model.ValidationOption) DecodeOption {
+	return func(options *decodeOptions) error {
+		options.validation = append(options.validation, opts...)
+		return nil
+	}
+}
+
+// WithoutBodyRestore leaves the body of the request consumed once it is decoded, for the terminal consumers of the
+// body. By default, the body is restored for the next handler in the chain.
+func WithoutBodyRestore() DecodeOption {
+	return func(options *decodeOptions) error {
+		options.skipRestore = true
+		return nil
+	}
+}
+
 func DecodeRequest[T model.Entity](api *API, r *http.Request, opts ...DecodeOption) (ent T, err error) {
 	if ent.Name() == "NilEntity" {
 		return ent, nil
 	}
 
+	options, err := newDecodeOptions(opts)
+	if err != nil {
+		return ent, err
+	}
+	validation := append(slices.Clone(api.validation), options.validation...)
+	skipValidation := options.skipValidation || BodyValidationSkipped(r.Context())
+
+	// streams are decoded by the handler as it reads them, see Stream
+	if sd, ok := any(model.New[T]()).(streamDecoder); ok {
+		sd.init(api, r, validation, skipValidation)
+		return sd.(T), nil
+	}
+
+	body, err := readBody(r, !options.skipRestore)
+	if err != nil {
+		return ent, err
+	}
+
+	return decodeBody[T](api, body, validation, skipValidation)
+}
+
+// DecodeBody decodes and validates a request body that was already read, e.g. by a handler checking its signature,
+// without copying it. Unlike DecodeRequest, it does not decode streams, and the validation is only skipped with
+// WithoutBodyValidation.
+func DecodeBody[T model.Entity](api *API, body []byte, opts ...DecodeOption) (ent T, err error) {
+	if ent.Name() == "NilEntity" {
+		return ent, nil
+	}
+
+	options, err := newDecodeOptions(opts)
+	if err != nil {
+		return ent, err
+	}
+	if _, ok := any(model.New[T]()).(streamDecoder); ok {
+		return ent, fmt.Errorf("%s is a stream, decode it with DecodeRequest", ent.Name())
+	}
+
+	return decodeBody[T](api, body, append(slices.Clone(api.validation), options.validation...), options.skipValidation)
+}
+
+func newDecodeOptions(opts []DecodeOption) (decodeOptions, error) {
 	var options decodeOptions
 	for _, opt := range opts {
-		err := opt(&options)
-		if err != nil {
-			return ent, err
+		if err := opt(&options); err != nil {
+			return options, err
 		}
 	}
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		return ent, fmt.Errorf("unable to read the body: %w", err)
+	return options, nil
+}
+
+func decodeBody[T model.Entity](api *API, body []byte, validation []model.ValidationOption, skipValidation bool) (ent T, err error) {
+	if skipValidation {
+		return unmarshalEntity[T](body)
 	}
-	// restore the body for the next handler in the chain
-	r.Body = io.NopCloser(io.Reader(bytes.NewBuffer(body)))
 
 	schema, err := api.DereferenceSchema(ent.Schema())
 	if err != nil {
 		return ent, fmt.Errorf("dereferenceSchema ent[%s]: %w", ent.Name(), err)
 	}
 
-	if err := model.Validate(schema, body); err != nil {
+	if err := model.Validate(schema, body, validation...); err != nil {
 		return ent, fmt.Errorf("model.Validate: %w", err)
 	}
 
+	return unmarshalEntity[T](body)
+}
+
+// unmarshalEntity decodes the data into a new entity of type T.
+func unmarshalEntity[T model.Entity](body []byte) (ent T, err error) {
 	// If the entity is a pointer, we need to create a new instance of the entity,
 	// or else "ent" will be a nil pointer.
 	switch {
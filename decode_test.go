@@ -1,8 +1,11 @@
 package mason_test
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -269,6 +272,94 @@ func TestDecodeQueryParams(t *testing.T) {
 	run(tolerant, t)
 }
 
+func TestDecodeQueryParamTags(t *testing.T) {
+	type params struct {
+		Status string `json:"status" enum:"open,closed" default:"open"`
+		Owner  string `json:"owner" required:"true"`
+	}
+	tagged := decodeTest[params]{
+		Name: "Tagged params",
+		decodeTests: []struct {
+			Name        string
+			QueryString string
+			Expected    params
+			ExpectError bool
+		}{
+			{
+				Name:        "Default value",
+				QueryString: "owner=me",
+				Expected:    params{Status: "open", Owner: "me"},
+			},
+			{
+				Name:        "Enum value",
+				QueryString: "owner=me&status=closed",
+				Expected:    params{Status: "closed", Owner: "me"},
+			},
+			{
+				Name:        "Not in enum",
+				QueryString: "owner=me&status=archived",
+				ExpectError: true,
+			},
+			{
+				Name:        "Missing required",
+				QueryString: "status=open",
+				ExpectError: true,
+			},
+		},
+	}
+	run(tagged, t)
+}
+
+type schemaParams struct {
+	Status string `json:"status"`
+	Limit  int    `json:"limit"`
+}
+
+func (schemaParams) QuerySchema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"status": {"type": "string", "enum": ["open", "closed"]},
+			"limit": {"type": "integer", "maximum": 100}
+		},
+		"required": ["status"]
+	}`)
+}
+
+func TestDecodeQuerySchema(t *testing.T) {
+	schema := decodeTest[schemaParams]{
+		Name: "Query schema",
+		decodeTests: []struct {
+			Name        string
+			QueryString string
+			Expected    schemaParams
+			ExpectError bool
+		}{
+			{
+				Name:        "Valid params",
+				QueryString: "status=open&limit=10",
+				Expected:    schemaParams{Status: "open", Limit: 10},
+			},
+			{
+				Name:        "Not in enum",
+				QueryString: "status=archived",
+				ExpectError: true,
+			},
+			{
+				Name:        "Above maximum",
+				QueryString: "status=open&limit=500",
+				ExpectError: true,
+			},
+			{
+				Name:        "Missing required",
+				QueryString: "limit=10",
+				ExpectError: true,
+			},
+		},
+	}
+	run(schema, t)
+}
+
 func run[Q any](decodeTest decodeTest[Q], t *testing.T) {
 	for _, tt := range decodeTest.decodeTests {
 		t.Run(tt.Name, func(t *testing.T) {
@@ -297,3 +388,135 @@ func run[Q any](decodeTest decodeTest[Q], t *testing.T) {
 func ptr[T any](v T) *T {
 	return &v
 }
+
+func TestDecodeQueryParams_LenientKeys(t *testing.T) {
+	type params struct {
+		PageSize int    `json:"page_size"`
+		SortBy   string `json:"sort_by" required:"true"`
+		Status   string `json:"status"`
+	}
+
+	var matched []string
+	lenient := mason.LenientQueryKeys(func(r *http.Request, key, param string) {
+		matched = append(matched, key+"="+param)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost?PageSize=20&sortBy=name&Status=open&status=closed", nil)
+	got, err := mason.DecodeQueryParams[params](req, lenient)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// the exact keys take precedence
+	expected := params{PageSize: 20, SortBy: "name", Status: "closed"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	sort.Strings(matched)
+	if !reflect.DeepEqual(matched, []string{"PageSize=page_size", "sortBy=sort_by"}) {
+		t.Errorf("Unexpected matched keys: %v", matched)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "http://localhost?sortBy=name", nil)
+	if _, err := mason.DecodeQueryParams[params](req); err == nil {
+		t.Errorf("Expected the required param to be missing without lenient keys")
+	}
+}
+
+type aliasedParams struct {
+	PageSize int `json:"page_size" aliases:"per_page,limit"`
+}
+
+func TestDecodeQueryParams_Aliases(t *testing.T) {
+	var got []int
+	listItems := func(ctx context.Context, r *http.Request, params aliasedParams) (*Item, error) {
+		got = append(got, params.PageSize)
+		return &Item{Title: "first"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(listItems).Path("/items").WithOpID("list_items"))
+
+	get := func(query string) http.Header {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Unexpected status %d for %q", rec.Code, query)
+		}
+		return rec.Header()
+	}
+
+	if h := get("page_size=10"); h.Get("Deprecation") != "" {
+		t.Errorf("Unexpected Deprecation header for the param: %q", h.Get("Deprecation"))
+	}
+	h := get("per_page=20")
+	if h.Get("Deprecation") != "true" {
+		t.Errorf("Expected a Deprecation header for the alias, got %q", h.Get("Deprecation"))
+	}
+	if w := h.Get("Warning"); w != `299 - "Query param 'per_page' is deprecated, use 'page_size' instead"` {
+		t.Errorf("Unexpected Warning header: %q", w)
+	}
+	// the param takes precedence over its aliases
+	get("limit=30&page_size=40")
+
+	if !reflect.DeepEqual(got, []int{10, 20, 40}) {
+		t.Errorf("Unexpected page sizes: %v", got)
+	}
+}
+
+type priceRange struct {
+	Min      int    `json:"min" required:"true"`
+	Max      *int   `json:"max"`
+	Currency string `json:"currency" enum:"eur,usd" default:"eur"`
+}
+
+type objectParams struct {
+	Price  priceRange `json:"price"`
+	Window *struct {
+		Since time.Time `json:"since"`
+	} `json:"window"`
+}
+
+func TestDecodeQueryParams_Objects(t *testing.T) {
+	objects := decodeTest[objectParams]{
+		Name: "Object params",
+		decodeTests: []struct {
+			Name        string
+			QueryString string
+			Expected    objectParams
+			ExpectError bool
+		}{
+			{
+				Name:        "Deep object",
+				QueryString: "price[min]=10&price[max]=50&price[currency]=usd",
+				Expected:    objectParams{Price: priceRange{Min: 10, Max: ptr(50), Currency: "usd"}},
+			},
+			{
+				Name:        "Nested pointer object",
+				QueryString: "price[min]=10&window[since]=2025-10-01",
+				Expected: objectParams{
+					Price: priceRange{Min: 10, Currency: "eur"},
+					Window: &struct {
+						Since time.Time `json:"since"`
+					}{Since: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+			{
+				Name:        "Absent object",
+				QueryString: "",
+				Expected:    objectParams{Price: priceRange{Currency: "eur"}},
+			},
+			{
+				Name:        "Missing required field",
+				QueryString: "price[max]=50",
+				ExpectError: true,
+			},
+			{
+				Name:        "Field not in enum",
+				QueryString: "price[min]=10&price[currency]=gbp",
+				ExpectError: true,
+			},
+		},
+	}
+	run(objects, t)
+}
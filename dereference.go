@@ -9,45 +9,86 @@ import (
 	"github.com/tailbits/mason/model"
 )
 
+// DereferenceSchema makes a schema self-contained, by adding the schemas of the registered entities it references
+// to its definitions. Resolution is recursive: refs inside pulled-in schemas are resolved too, and their own
+// definitions are hoisted to the root, where #/definitions/ refs point. Every entity is added once, so reference
+// cycles terminate. The $defs of draft 2020-12 schemas are treated as definitions. Results are cached per schema
+// until another model is registered.
 func (a *API) DereferenceSchema(schema []byte) ([]byte, error) {
+	if cached, ok := a.derefCache.Load(string(schema)); ok {
+		return cached.([]byte), nil
+	}
+
+	resolved, err := a.ResolveExternalRefs(schema)
+	if err != nil {
+		return nil, err
+	}
+
 	var sch jsonschema.Schema
-	if err := json.Unmarshal(schema, &sch); err != nil {
+	if err := json.Unmarshal(resolved, &sch); err != nil {
 		return nil, fmt.Errorf("json.Unmarshal: schema[%s] %w", string(schema), err)
 	}
+	normalizeDefs(&sch)
 
-	var err error
-	walkRefs(&sch, func(ref *string) {
-		id := strings.TrimPrefix(*ref, "#/definitions/")
-
-		if _, ok := sch.Definitions[id]; !ok {
-			// that means we have an external reference
-			// we need to dereference it
-			e, ok := a.GetModel(id)
-			if !ok {
-				err = fmt.Errorf("entity %s not found", id)
+	// pending holds the refs that still need to be resolved, in order of discovery
+	pending := []string{}
+	collect := func(s *jsonschema.Schema) {
+		walkRefs(s, func(ref *string) {
+			if !strings.HasPrefix(*ref, "#/definitions/") {
 				return
 			}
+			pending = append(pending, strings.TrimPrefix(*ref, "#/definitions/"))
+		})
+	}
+	collect(&sch)
 
-			ent, ok := e.(model.WithSchema)
-			if !ok {
-				err = fmt.Errorf("entity %s does not implement platform.WithSchema", id)
-				return
-			}
+	for len(pending) > 0 {
+		id := pending[0]
+		pending = pending[1:]
 
-			entSchBytes := ent.Schema()
+		if _, ok := sch.Definitions[id]; ok {
+			continue
+		}
 
-			var entSch jsonschema.Schema
-			if err = json.Unmarshal(entSchBytes, &entSch); err != nil {
-				return
-			}
+		// that means we have an external reference, we need to dereference it
+		e, ok := a.GetModel(id)
+		if !ok {
+			return nil, fmt.Errorf("entity %s not found", id)
+		}
+
+		ent, ok := e.(model.WithSchema)
+		if !ok {
+			return nil, fmt.Errorf("entity %s does not implement platform.WithSchema", id)
+		}
 
-			sch.WithDefinitionsItem(id, entSch.ToSchemaOrBool())
+		var entSch jsonschema.Schema
+		if err := json.Unmarshal(ent.Schema(), &entSch); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: entity[%s] %w", id, err)
 		}
-	})
+		normalizeDefs(&entSch)
+		// the $id would change the base of the refs inside the definition
+		entSch.ID = nil
+
+		// nested definitions are hoisted, as refs always point to the root definitions
+		for name, def := range entSch.Definitions {
+			if _, ok := sch.Definitions[name]; ok {
+				continue
+			}
+			sch.WithDefinitionsItem(name, def)
+			collect(def.TypeObject)
+		}
+		entSch.Definitions = nil
+
+		// adding the definition before walking it breaks reference cycles
+		sch.WithDefinitionsItem(id, entSch.ToSchemaOrBool())
+		collect(&entSch)
+	}
 
+	res, err := json.Marshal(sch)
 	if err != nil {
 		return nil, err
 	}
+	a.derefCache.Store(string(schema), res)
 
-	return json.Marshal(sch)
+	return res, nil
 }
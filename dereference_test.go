@@ -0,0 +1,171 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestDereferenceSchema(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("parents").Register(mason.HandlePost(func(ctx context.Context, r *http.Request, in *Parent, _ model.Nil) (*Child, error) {
+		return &Child{}, nil
+	}).Path("/parents").WithOpID("create_parent"))
+
+	schema, err := api.DereferenceSchema((&Parent{}).Schema())
+	assert.NilError(t, err)
+
+	var sch struct {
+		Definitions map[string]json.RawMessage `json:"definitions"`
+	}
+	assert.NilError(t, json.Unmarshal(schema, &sch))
+	for _, name := range []string{"Child", "Parent", "Tag"} {
+		_, ok := sch.Definitions[name]
+		assert.Assert(t, ok, "missing definition %s", name)
+	}
+
+	assert.NilError(t, model.Validate(schema, []byte(`{"child": {"tag": "a", "parent": {"child": {"tag": "b"}}}}`)))
+	assert.ErrorContains(t, model.Validate(schema, []byte(`{"child": {"tag": 1}}`)), "")
+
+	cached, err := api.DereferenceSchema((&Parent{}).Schema())
+	assert.NilError(t, err)
+	assert.Equal(t, string(schema), string(cached))
+}
+
+var _ model.Entity = (*Parent)(nil)
+
+type Parent struct {
+	Child *Child `json:"child,omitempty"`
+}
+
+func (p *Parent) Name() string {
+	return "Parent"
+}
+
+func (p *Parent) Example() []byte {
+	return []byte(`{}`)
+}
+
+func (p *Parent) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"child": {"$ref": "#/definitions/Child"}
+		}
+	}`)
+}
+
+func (p *Parent) Marshal() (json.RawMessage, error) {
+	return json.Marshal(p)
+}
+
+func (p *Parent) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, p)
+}
+
+var _ model.Entity = (*Child)(nil)
+
+type Child struct {
+	Tag    string  `json:"tag,omitempty"`
+	Parent *Parent `json:"parent,omitempty"`
+}
+
+func (c *Child) Name() string {
+	return "Child"
+}
+
+func (c *Child) Example() []byte {
+	return []byte(`{}`)
+}
+
+func (c *Child) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"tag": {"$ref": "#/definitions/Tag"},
+			"parent": {"$ref": "#/definitions/Parent"}
+		},
+		"definitions": {
+			"Tag": {"type": "string"}
+		}
+	}`)
+}
+
+func (c *Child) Marshal() (json.RawMessage, error) {
+	return json.Marshal(c)
+}
+
+func (c *Child) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, c)
+}
+
+func TestDereferenceSchema_Defs(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("shapes").Register(mason.HandlePost(func(ctx context.Context, r *http.Request, in *Shape, _ model.Nil) (*Shape, error) {
+		return in, nil
+	}).Path("/shapes").WithOpID("create_shape"))
+	api.NewRouteGroup("parents").Register(mason.HandlePost(func(ctx context.Context, r *http.Request, in *Parent, _ model.Nil) (*Child, error) {
+		return &Child{}, nil
+	}).Path("/parents").WithOpID("create_parent"))
+
+	schema, err := api.DereferenceSchema((&Shape{}).Schema())
+	assert.NilError(t, err)
+
+	var sch struct {
+		Definitions map[string]json.RawMessage `json:"definitions"`
+		Defs        map[string]json.RawMessage `json:"$defs"`
+	}
+	assert.NilError(t, json.Unmarshal(schema, &sch))
+	for _, name := range []string{"Child", "Parent", "Tag", "Corner"} {
+		_, ok := sch.Definitions[name]
+		assert.Assert(t, ok, "missing definition %s", name)
+	}
+	assert.Equal(t, 0, len(sch.Defs))
+
+	assert.NilError(t, model.Validate(schema, []byte(`{"corners": [{"x": 1}], "child": {"tag": "a"}}`)))
+	assert.ErrorContains(t, model.Validate(schema, []byte(`{"corners": [{"x": "1"}]}`)), "")
+	assert.ErrorContains(t, model.Validate(schema, []byte(`{"extra": true}`)), "")
+}
+
+var _ model.Entity = (*Shape)(nil)
+
+type Shape struct {
+	Corners []map[string]int `json:"corners,omitempty"`
+	Child   *Child           `json:"child,omitempty"`
+}
+
+func (s *Shape) Name() string {
+	return "Shape"
+}
+
+func (s *Shape) Example() []byte {
+	return []byte(`{}`)
+}
+
+func (s *Shape) Schema() []byte {
+	return []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"corners": {"type": "array", "prefixItems": [{"$ref": "#/$defs/Corner"}]},
+			"child": {"$ref": "#/definitions/Child"}
+		},
+		"$defs": {
+			"Corner": {"type": "object", "properties": {"x": {"type": "integer"}}}
+		},
+		"unevaluatedProperties": false
+	}`)
+}
+
+func (s *Shape) Marshal() (json.RawMessage, error) {
+	return json.Marshal(s)
+}
+
+func (s *Shape) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, s)
+}
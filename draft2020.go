@@ -0,0 +1,85 @@
+package mason
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+)
+
+// jsonschema.Schema models draft 7, so the subschemas of the draft 2020-12 keywords end up in its ExtraProperties as
+// plain values. They are decoded when walked, so the refs inside them are resolved and renamed like any other.
+var (
+	draft2020Schemas    = []string{"unevaluatedProperties", "unevaluatedItems"}
+	draft2020SchemaList = []string{"prefixItems"}
+	draft2020SchemaMaps = []string{"$defs", "dependentSchemas"}
+)
+
+func walkDraft2020(schema *jsonschema.Schema, f func(*jsonschema.Schema)) {
+	if len(schema.ExtraProperties) == 0 {
+		return
+	}
+
+	for _, kw := range draft2020Schemas {
+		var sub jsonschema.SchemaOrBool
+		if decodeExtra(schema, kw, &sub) {
+			walkSchema(&sub, f)
+			schema.ExtraProperties[kw] = sub
+		}
+	}
+
+	for _, kw := range draft2020SchemaList {
+		var subs []jsonschema.SchemaOrBool
+		if decodeExtra(schema, kw, &subs) {
+			for i := range subs {
+				walkSchema(&subs[i], f)
+			}
+			schema.ExtraProperties[kw] = subs
+		}
+	}
+
+	for _, kw := range draft2020SchemaMaps {
+		var subs map[string]jsonschema.SchemaOrBool
+		if decodeExtra(schema, kw, &subs) {
+			for _, sub := range subs {
+				walkSchema(&sub, f)
+			}
+			schema.ExtraProperties[kw] = subs
+		}
+	}
+}
+
+// decodeExtra decodes the value of an extra keyword, and reports whether the schema has it.
+func decodeExtra(schema *jsonschema.Schema, kw string, v any) bool {
+	raw, ok := schema.ExtraProperties[kw]
+	if !ok {
+		return false
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(data, v) == nil
+}
+
+// normalizeDefs moves the $defs of a draft 2020-12 schema to its definitions, and points the refs to them, so they are
+// dereferenced and emitted as components like the definitions of older drafts.
+func normalizeDefs(schema *jsonschema.Schema) {
+	var defs map[string]jsonschema.SchemaOrBool
+	if decodeExtra(schema, "$defs", &defs) {
+		delete(schema.ExtraProperties, "$defs")
+		for name, def := range defs {
+			if _, ok := schema.Definitions[name]; !ok {
+				schema.WithDefinitionsItem(name, def)
+			}
+		}
+	}
+
+	walkRefs(schema, func(ref *string) {
+		if rest, ok := strings.CutPrefix(*ref, "#/$defs/"); ok {
+			*ref = "#/definitions/" + rest
+		}
+	})
+}
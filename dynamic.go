@@ -0,0 +1,119 @@
+package mason
+
+import "fmt"
+
+// Extend registers routes on an API after Build, e.g. the ones of a plugin module loaded once the server has started.
+// The routes that register adds are served as soon as they are registered, and the API is validated again like by
+// Build. When register panics, e.g. on a route registered twice, or the API is invalid, the operations it added are
+// removed from the registry and from the runtime, which must be a RouteRemover, and the error is returned. The
+// entities of the removed operations stay registered. Only routes and models can be registered: the errors, the
+// providers and the plugins are read by the requests being served, so registering them panics.
+//
+// The hooks added with OnRoutesChanged are notified once the routes are registered, e.g. to reload a SpecServer. The
+// reports of the API, like Audit or ClassificationReport, must not run concurrently with Extend.
+func (a *API) Extend(register func(api *API)) (err error) {
+	a.extending.Lock()
+	defer a.extending.Unlock()
+
+	before := a.Registry()
+	conflicts := len(a.conflicts)
+	a.extendable = true
+	defer func() {
+		a.extendable = false
+	}()
+
+	func() {
+		defer func() {
+			if v := recover(); v != nil {
+				err = fmt.Errorf("failed to register routes: %v", v)
+			}
+		}()
+		register(a)
+	}()
+	if err == nil {
+		err = a.validate()
+	}
+	if err != nil {
+		a.rollback(before, conflicts)
+		return err
+	}
+
+	a.notifyRoutesChanged()
+
+	return nil
+}
+
+// rollback removes the operations registered since the registry was before, with their routes, and the conflicts
+// they were reported for. The routes served before are left as they are, even when the failed registration was of
+// an operation of another group with the same method and path.
+func (a *API) rollback(before Registry, conflicts int) {
+	remover, _ := a.Runtime.(RouteRemover)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	served := make(map[string]bool)
+	for _, resource := range before {
+		for _, op := range resource {
+			served[op.Method+" "+op.Path] = true
+		}
+	}
+	for group, resource := range a.registry {
+		for key, op := range resource {
+			if _, ok := before[group][key]; ok || served[op.Method+" "+op.Path] {
+				continue
+			}
+			if remover != nil {
+				remover.Unhandle(op.Method, op.Path)
+			}
+		}
+	}
+	a.registry = before
+	a.conflicts = a.conflicts[:conflicts]
+}
+
+// Deregister removes the operation with the operationID from the registry and from the runtime, which must be a
+// RouteRemover, e.g. when the plugin module registering it is unloaded. Its requests are answered like the ones of a
+// route that was never registered. The hooks added with OnRoutesChanged are notified once it is removed.
+func (a *API) Deregister(opID string) error {
+	remover, ok := a.Runtime.(RouteRemover)
+	if _, base := baseRuntime(a.Runtime).(RouteRemover); !ok || !base {
+		return fmt.Errorf("runtime %T cannot remove routes", baseRuntime(a.Runtime))
+	}
+
+	a.extending.Lock()
+	defer a.extending.Unlock()
+
+	a.mu.Lock()
+	group, op, ok := a.registry.findByOpID(opID)
+	if ok {
+		// the route is removed first, so no request is served for an operation missing from the registry
+		remover.Unhandle(op.Method, op.Path)
+		a.registry.RemoveOp(group, op.Method, op.Path)
+	}
+	a.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("operation %s is not registered", opID)
+	}
+	a.notifyRoutesChanged()
+
+	return nil
+}
+
+// OnRoutesChanged adds a hook notified after Extend registers routes and after Deregister removes one, e.g. to reload
+// the served spec with SpecServer.Reload. Hooks run in the order they are added, and must not call Extend or
+// Deregister.
+func (a *API) OnRoutesChanged(hook func()) *API {
+	a.extending.Lock()
+	defer a.extending.Unlock()
+
+	a.routesChanged = append(a.routesChanged, hook)
+	return a
+}
+
+func (a *API) notifyRoutesChanged() {
+	for _, hook := range a.routesChanged {
+		hook()
+	}
+}
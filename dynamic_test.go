@@ -0,0 +1,155 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestExtend(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "done"}, nil
+	}
+	deleteItem := func(ctx context.Context, r *http.Request, _ model.Nil, params model.Nil) error {
+		return nil
+	}
+	serve := func(handler http.Handler, method string, path string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec.Code
+	}
+	newAPI := func(t *testing.T) (*mason.API, http.Handler, *int) {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item"))
+
+		var changes int
+		api.OnRoutesChanged(func() {
+			changes++
+		})
+
+		handler, err := api.Build()
+		assert.NilError(t, err)
+
+		return api, handler, &changes
+	}
+
+	t.Run("register and deregister", func(t *testing.T) {
+		api, handler, changes := newAPI(t)
+
+		err := api.Extend(func(api *mason.API) {
+			api.NewRouteGroup("items").Register(mason.HandleDeleteNoContent(deleteItem).Path("/items/{id}").WithOpID("delete_item"))
+			api.NewRouteGroup("plugins").Register(mason.HandleGet(getItem).Path("/plugins/{id}").WithOpID("get_plugin"))
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, 1, *changes)
+		assert.Equal(t, http.StatusNoContent, serve(handler, http.MethodDelete, "/items/1"))
+		assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/plugins/1"))
+		assert.Assert(t, api.HasOperation(http.MethodGet, "/plugins/{id}"))
+
+		assert.NilError(t, api.Deregister("delete_item"))
+		assert.NilError(t, api.Deregister("get_plugin"))
+		assert.Equal(t, 3, *changes)
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(handler, http.MethodDelete, "/items/1"))
+		assert.Equal(t, http.StatusNotFound, serve(handler, http.MethodGet, "/plugins/1"))
+		assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/items/1"))
+		_, ok := api.GetOperationByID("get_plugin")
+		assert.Assert(t, !ok)
+		_, ok = api.Registry()["plugins"]
+		assert.Assert(t, !ok)
+
+		assert.ErrorContains(t, api.Deregister("get_plugin"), "operation get_plugin is not registered")
+
+		// a deregistered route can be registered again
+		err = api.Extend(func(api *mason.API) {
+			api.NewRouteGroup("plugins").Register(mason.HandleGet(getItem).Path("/plugins/{id}").WithOpID("get_plugin"))
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/plugins/1"))
+	})
+
+	t.Run("rolled back", func(t *testing.T) {
+		api, handler, changes := newAPI(t)
+
+		err := api.Extend(func(api *mason.API) {
+			api.NewRouteGroup("plugins").Register(mason.HandleGet(getItem).Path("/plugins/{id}").WithOpID("get_plugin"))
+			api.NewRouteGroup("plugins").Register(mason.HandleGet(getItem).Path("/plugins/{id}/copy").WithOpID("get_item"))
+		})
+		assert.ErrorContains(t, err, "operationID get_item is also used by")
+		assert.Equal(t, 0, *changes)
+		assert.Equal(t, http.StatusNotFound, serve(handler, http.MethodGet, "/plugins/1"))
+		assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/items/1"))
+		assert.Equal(t, 1, len(api.Operations()))
+
+		err = api.Extend(func(api *mason.API) {
+			api.NewRouteGroup("plugins").Register(mason.HandleGet(getItem).Path("/plugins/{id}").WithOpID("get_plugin"))
+			api.NewRouteGroup("plugins").Register(mason.HandleGet(getItem).WithOpID("list_plugins"))
+		})
+		assert.ErrorContains(t, err, "failed to register routes")
+		assert.Equal(t, http.StatusNotFound, serve(handler, http.MethodGet, "/plugins/1"))
+
+		// the routes served already are left as they are, whichever group registers them again
+		newItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+			return &Item{Title: "NEW"}, nil
+		}
+		for _, group := range []string{"items", "plugins"} {
+			err = api.Extend(func(api *mason.API) {
+				api.NewRouteGroup(group).Register(mason.HandleGet(newItem).Path("/items/{id}").WithOpID("get_new_item"))
+			})
+			assert.ErrorContains(t, err, "route GET /items/{id} is already registered")
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, `{"title":"done"}`+"\n", rec.Body.String())
+		}
+
+		// the errors and the providers, read by the requests being served, stay frozen in Extend
+		err = api.Extend(func(api *mason.API) {
+			api.RegisterError("gone", http.StatusGone, &model.APIError{})
+		})
+		assert.ErrorContains(t, err, "cannot register error gone in Extend")
+		err = api.Extend(func(api *mason.API) {
+			mason.Provide(api, func(r *http.Request) (*Item, error) {
+				return &Item{}, nil
+			})
+		})
+		assert.ErrorContains(t, err, "cannot register provider of *mason_test.Item in Extend")
+
+		// the API stays frozen outside of Extend
+		assert.Assert(t, cmpPanics(func() {
+			api.NewRouteGroup("plugins").Register(mason.HandleGet(getItem).Path("/plugins/{id}").WithOpID("get_plugin"))
+		}))
+	})
+
+	t.Run("wrapped runtimes", func(t *testing.T) {
+		rtm := mason.NewHTTPRuntime()
+		batch := mason.NewBatchRuntime(mason.NewRPCRuntime(rtm))
+		api := mason.NewAPI(batch)
+		api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item"))
+		api.NewRouteGroup("batch").Register(batch.Route(api).Path("/batch").WithOpID("batch"))
+		handler, err := api.Build()
+		assert.NilError(t, err)
+
+		err = api.Extend(func(api *mason.API) {
+			api.NewRouteGroup("plugins").Register(mason.HandleGet(getItem).Path("/plugins/{id}").WithOpID("get_plugin"))
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/plugins/1"))
+
+		assert.NilError(t, api.Deregister("get_plugin"))
+		assert.Equal(t, http.StatusNotFound, serve(handler, http.MethodGet, "/plugins/1"))
+
+		api = mason.NewAPI(mason.NewBatchRuntime(fixedRuntime{mason.NewHTTPRuntime()}))
+		assert.ErrorContains(t, api.Deregister("get_item"), "runtime mason_test.fixedRuntime cannot remove routes")
+	})
+}
+
+// fixedRuntime is a runtime that cannot remove its routes.
+type fixedRuntime struct {
+	mason.Runtime
+}
@@ -0,0 +1,89 @@
+package mason
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/tailbits/mason/model"
+)
+
+// ErrEncodingFailed is the code of the error sent instead of a response that could not be encoded, with the
+// StatusInternalServerError status, see OnEncodeError.
+const ErrEncodingFailed = "encoding_failed"
+
+// EncodeError is the failure to encode the data of a response, e.g. of a type whose MarshalJSON returns an error or
+// panics. Nothing of the response is written when it occurs.
+type EncodeError struct {
+	// Type is the Go type of the data.
+	Type string
+	// Err is the error of the encoder, or the value it panicked with.
+	Err error
+	// Stack is the stack of the goroutine when the encoder panicked, nil when it returned an error.
+	Stack []byte
+}
+
+func (e *EncodeError) Error() string {
+	return fmt.Sprintf("failed to encode response data of type %s: %v", e.Type, e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// encodeSafely runs the encoder of the data, turning its errors and panics into an EncodeError.
+func encodeSafely(data any, encode func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			panicErr, ok := v.(error)
+			if !ok {
+				panicErr = fmt.Errorf("panic: %v", v)
+			}
+			err = &EncodeError{Type: fmt.Sprintf("%T", data), Err: panicErr, Stack: debug.Stack()}
+		}
+	}()
+
+	if err := encode(); err != nil {
+		return &EncodeError{Type: fmt.Sprintf("%T", data), Err: err}
+	}
+
+	return nil
+}
+
+// EncodeFailure is the failure to encode the response of an operation, reported to the hooks added with
+// OnEncodeError.
+type EncodeFailure struct {
+	OperationID string
+	Method      string
+	Path        string
+	Err         *EncodeError
+}
+
+// EncodeErrorHook observes the responses that could not be encoded, e.g. to log the type and the stack.
+type EncodeErrorHook func(ctx context.Context, failure EncodeFailure)
+
+// OnEncodeError adds a hook observing the responses of the routes that could not be encoded. They are answered with
+// a 500 and a model.APIError with the ErrEncodingFailed code, which does not leak the details of the failure. Hooks
+// run in the order they are added, before the error response.
+func (a *API) OnEncodeError(hook EncodeErrorHook) *API {
+	a.encodeHooks = append(a.encodeHooks, hook)
+	return a
+}
+
+// encodeFailed reports an EncodeError of the response to the hooks and responds with a 500 instead. Other errors are
+// returned as is.
+func (rb *RouteBuilderBase) encodeFailed(ctx context.Context, api *API, w http.ResponseWriter, err error) error {
+	var encErr *EncodeError
+	if !errors.As(err, &encErr) {
+		return err
+	}
+
+	failure := EncodeFailure{OperationID: rb.opID, Method: rb.method, Path: rb.path, Err: encErr}
+	for _, hook := range api.encodeHooks {
+		hook(ctx, failure)
+	}
+
+	return api.Respond(ctx, w, model.NewAPIError(ErrEncodingFailed, "The response could not be encoded"), http.StatusInternalServerError)
+}
@@ -0,0 +1,78 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+// brokenItem is an Item whose JSON encoding fails, with an error or a panic.
+type brokenItem struct {
+	Item
+	panics bool
+}
+
+func (i *brokenItem) MarshalJSON() ([]byte, error) {
+	if i.panics {
+		var fields map[string]string
+		fields["title"] = i.Title
+	}
+
+	return nil, errors.New("title is not loaded")
+}
+
+func TestEncodeError(t *testing.T) {
+	var panics bool
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*brokenItem, error) {
+		return &brokenItem{Item: Item{Title: "a"}, panics: panics}, nil
+	}
+
+	var failures []mason.EncodeFailure
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.OnEncodeError(func(ctx context.Context, failure mason.EncodeFailure) {
+		failures = append(failures, failure)
+	})
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item"))
+
+	get := func() (*httptest.ResponseRecorder, model.APIError) {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+
+		var apiErr model.APIError
+		assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+		return rec, apiErr
+	}
+
+	t.Run("error", func(t *testing.T) {
+		failures = nil
+		rec, apiErr := get()
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, mason.ErrEncodingFailed, apiErr.Code)
+
+		assert.Equal(t, 1, len(failures))
+		assert.Equal(t, "get_item", failures[0].OperationID)
+		assert.Equal(t, "*mason_test.brokenItem", failures[0].Err.Type)
+		assert.ErrorContains(t, failures[0].Err, "title is not loaded")
+		assert.Assert(t, failures[0].Err.Stack == nil)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		failures, panics = nil, true
+		rec, apiErr := get()
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, mason.ErrEncodingFailed, apiErr.Code)
+
+		assert.Equal(t, 1, len(failures))
+		assert.ErrorContains(t, failures[0].Err, "assignment to entry in nil map")
+		assert.Assert(t, len(failures[0].Err.Stack) > 0)
+	})
+}
@@ -0,0 +1,116 @@
+package mason
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+// CodedError is an error identified by a code of the error catalog, e.g. a model.APIError.
+type CodedError interface {
+	error
+	ErrorCode() string
+}
+
+// ErrorDef is an entry of the error catalog: the status and the body of the errors with the code.
+type ErrorDef struct {
+	Code   string
+	Status int
+	Entity model.Entity
+	// RetryAfter is the delay the clients should wait before retrying the errors with the code, see ErrorRetryAfter.
+	RetryAfter time.Duration
+}
+
+var (
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+	codedErrorType = reflect.TypeOf((*CodedError)(nil)).Elem()
+)
+
+// RegisterError adds an error to the error catalog of the API. Handler errors are matched against the catalog and
+// rendered with the status and the entity of their definition:
+//   - a CodedError matches the definition with its code, and is rendered as is when it is an entity, or else as a
+//     model.APIError.
+//   - any other error matches the definitions whose entity is an error of the same type, with errors.As, and is
+//     rendered as the matched error.
+//
+// The errors with a delay, see ErrorRetryAfter and RetryAfter, are rendered with a Retry-After header, and with the
+// delay in the retryAfter field of their model.APIError.
+//
+// Routes declare the errors they return WithErrors, so they are documented in the spec. Registering a code twice
+// panics.
+func (a *API) RegisterError(code string, status int, entity model.Entity, opts ...ErrorOption) {
+	a.mustBeMutable("error " + code)
+	if _, ok := a.GetError(code); ok {
+		panic(fmt.Sprintf("error %s is already registered", code))
+	}
+
+	def := ErrorDef{Code: code, Status: status, Entity: entity}
+	for _, opt := range opts {
+		opt(&def)
+	}
+	a.errors = append(a.errors, def)
+	a.registerModel(entity)
+}
+
+// GetError returns the definition of the error with the code.
+func (a *API) GetError(code string) (ErrorDef, bool) {
+	for _, def := range a.errors {
+		if def.Code == code {
+			return def, true
+		}
+	}
+
+	return ErrorDef{}, false
+}
+
+// matchError finds the definition of an error, and the body to render it with.
+func (a *API) matchError(err error) (ErrorDef, any, bool) {
+	var coded CodedError
+	if errors.As(err, &coded) {
+		def, ok := a.GetError(coded.ErrorCode())
+		if !ok {
+			return ErrorDef{}, nil, false
+		}
+		if _, ok := coded.(model.Entity); ok {
+			return def, coded, true
+		}
+		return def, model.NewAPIError(def.Code, coded.Error()), true
+	}
+
+	for _, def := range a.errors {
+		t := reflect.TypeOf(def.Entity)
+		if !t.Implements(errorType) || t.Implements(codedErrorType) {
+			continue
+		}
+
+		target := reflect.New(t)
+		if errors.As(err, target.Interface()) {
+			return def, target.Elem().Interface(), true
+		}
+	}
+
+	return ErrorDef{}, nil, false
+}
+
+// withErrors renders the handler errors that are part of the error catalog. Other errors are returned to the runtime,
+// with the validation errors localized.
+func (a *API) withErrors(next WebHandler) WebHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		err := next(ctx, w, r)
+		if err == nil {
+			return nil
+		}
+
+		def, body, ok := a.matchError(err)
+		if !ok {
+			return a.translateError(r, err)
+		}
+
+		return a.Respond(ctx, w, setRetryAfter(w, body, def.retryDelay(err)), def.Status)
+	}
+}
@@ -0,0 +1,106 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+var _ model.Entity = (*QuotaError)(nil)
+
+type QuotaError struct {
+	Limit int `json:"limit"`
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("quota of %d exceeded", e.Limit)
+}
+
+func (e *QuotaError) Name() string {
+	return "QuotaError"
+}
+
+func (e *QuotaError) Schema() []byte {
+	return []byte(`{"type": "object", "properties": {"limit": {"type": "integer"}}, "required": ["limit"]}`)
+}
+
+func (e *QuotaError) Example() []byte {
+	return []byte(`{"limit": 10}`)
+}
+
+func (e *QuotaError) Marshal() (json.RawMessage, error) {
+	return json.Marshal(e)
+}
+
+func (e *QuotaError) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, e)
+}
+
+var errGone = errors.New("gone")
+
+func TestErrorCatalog(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		switch r.URL.Query().Get("fail") {
+		case "missing":
+			return nil, fmt.Errorf("lookup: %w", model.NewAPIError("not_found", "item does not exist"))
+		case "quota":
+			return nil, fmt.Errorf("lookup: %w", &QuotaError{Limit: 10})
+		case "unregistered":
+			return nil, model.NewAPIError("teapot", "short and stout")
+		case "other":
+			return nil, errGone
+		}
+		return &Item{Title: "ok"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.RegisterError("not_found", http.StatusNotFound, &model.APIError{})
+	api.RegisterError("quota_exceeded", http.StatusTooManyRequests, &QuotaError{})
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+		Path("/items").
+		WithOpID("get_item").
+		WithErrors("not_found", "quota_exceeded"))
+
+	get := func(fail string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?fail="+fail, nil))
+		return rec
+	}
+
+	rec := get("missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, `{"code":"not_found","message":"item does not exist"}`+"\n", rec.Body.String())
+
+	rec = get("quota")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, `{"limit":10}`+"\n", rec.Body.String())
+
+	rec = get("unregistered")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec = get("other")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	op, ok := api.GetOperationByID("get_item")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, []string{"not_found", "quota_exceeded"}, op.Errors)
+}
+
+func TestRegisterError_Duplicate(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.RegisterError("not_found", http.StatusNotFound, &model.APIError{})
+
+	defer func() {
+		assert.Assert(t, recover() != nil)
+	}()
+	api.RegisterError("not_found", http.StatusGone, &model.APIError{})
+}
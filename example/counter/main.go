@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/tailbits/mason"
 	"github.com/tailbits/mason/model"
@@ -89,29 +90,56 @@ func (r *Response) Unmarshal(data json.RawMessage) error {
 
 // =============================================================================
 // Handlers
-var count int
+
+// Counter is provided to the handlers, instead of being a global variable.
+type Counter struct {
+	mu    sync.Mutex
+	count int
+}
 
 func IncrementHandler(ctx context.Context, r *http.Request, inp *Input, params model.Nil) (rsp *Response, err error) {
+	counter, err := mason.Use[*Counter](ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	inc := 1
 	if inp.Increment != nil {
 		inc = *inp.Increment
 	}
-	count += inc
+
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	counter.count += inc
 
 	return &Response{
-		Count: count,
+		Count: counter.count,
 	}, nil
 }
 
 func CountHandler(ctx context.Context, r *http.Request, params model.Nil) (rsp *Response, err error) {
+	counter, err := mason.Use[*Counter](ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+
 	return &Response{
-		Count: count,
+		Count: counter.count,
 	}, nil
 }
 
 func main() {
 	rtm := mason.NewHTTPRuntime()
 	api := mason.NewAPI(rtm)
+
+	counter := &Counter{}
+	mason.Provide(api, func(r *http.Request) (*Counter, error) {
+		return counter, nil
+	})
+
 	grp := api.NewRouteGroup("counter")
 
 	grp.Register(mason.HandlePost(IncrementHandler).
@@ -148,13 +176,9 @@ func main() {
 		return nil
 	})
 
-	server := &http.Server{
-		Addr:    ":9090",
-		Handler: rtm,
-	}
 	fmt.Println("API URL      : http://localhost:9090")
 	fmt.Println("OpenAPI spec : http://localhost:9090/openapi.json")
-	if err := server.ListenAndServe(); err != nil {
+	if err := mason.Serve(context.Background(), rtm, mason.ServeOptions{Addr: ":9090"}); err != nil {
 		panic(err)
 	}
 }
@@ -194,13 +194,9 @@ func main() {
 		return nil
 	})
 
-	server := &http.Server{
-		Addr:    ":9090",
-		Handler: rtm,
-	}
 	fmt.Println("API URL      : http://localhost:9090")
 	fmt.Println("OpenAPI spec : http://localhost:9090/openapi.json")
-	if err := server.ListenAndServe(); err != nil {
+	if err := mason.Serve(context.Background(), rtm, mason.ServeOptions{Addr: ":9090"}); err != nil {
 		panic(err)
 	}
 }
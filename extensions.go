@@ -0,0 +1,98 @@
+package mason
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// Common extensions of the operations, with their typed helpers.
+const (
+	ExtInternal   = "x-internal"
+	ExtBeta       = "x-beta"
+	ExtVisibility = "x-visibility"
+)
+
+// Extensions are custom x- attributes of an operation, see WithExtensionsMap.
+type Extensions map[string]any
+
+// Internal marks an operation as internal, e.g. for docs tooling to hide it.
+func Internal() Extensions {
+	return Extensions{ExtInternal: true}
+}
+
+// Beta marks an operation as beta.
+func Beta() Extensions {
+	return Extensions{ExtBeta: true}
+}
+
+// Visibility sets the audience of an operation, e.g. public or partner.
+func Visibility(audience string) Extensions {
+	return Extensions{ExtVisibility: audience}
+}
+
+// checkExtensionKeys panics when a key does not start with x-.
+func checkExtensionKeys(ext map[string]any) {
+	for key := range ext {
+		if !strings.HasPrefix(key, "x-") {
+			panic(fmt.Errorf("invalid key [%s]: custom keys must start with 'x-'", key))
+		}
+	}
+}
+
+// mergeExtensions deep merges the extensions of src into dst: nested maps are merged key by key, and other values of
+// src replace the ones of dst.
+func mergeExtensions(dst map[string]any, src map[string]any) {
+	for key, val := range src {
+		srcMap, ok := asMap(val)
+		if !ok {
+			dst[key] = val
+			continue
+		}
+
+		dstMap, ok := asMap(dst[key])
+		if !ok {
+			dstMap = make(map[string]any, len(srcMap))
+		} else {
+			dstMap = maps.Clone(dstMap)
+		}
+		mergeExtensions(dstMap, srcMap)
+		dst[key] = dstMap
+	}
+}
+
+func asMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, true
+	case Extensions:
+		return m, true
+	}
+	return nil, false
+}
+
+// groupExtensions returns the extensions of a group path and of its parents, the closest group taking precedence.
+func (a *API) groupExtensions(group string) map[string]any {
+	ext := make(map[string]any)
+
+	segments := strings.Split(group, "/")
+	for i := range segments {
+		meta, ok := a.groupMeta[strings.Join(segments[:i+1], "/")]
+		if ok {
+			mergeExtensions(ext, meta.Extensions)
+		}
+	}
+
+	return ext
+}
+
+// operationExtensions merges the extensions of a route over the ones of its groups.
+func (a *API) operationExtensions(group string, route map[string]any) map[string]any {
+	ext := a.groupExtensions(group)
+	mergeExtensions(ext, route)
+	if len(ext) == 0 {
+		return route
+	}
+
+	return ext
+}
@@ -0,0 +1,50 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestExtensions(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{}, nil
+	}
+
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	items := api.NewRouteGroup("items").WithExtensions(map[string]any{
+		"x-owner": map[string]any{"team": "catalog", "slack": "#catalog"},
+		"x-tier":  1,
+	})
+	items.NewRouteGroup("archive").WithExtensions(mason.Internal()).Register(mason.HandleGet(getItem).
+		Path("/items/archive").
+		WithOpID("get_archived_item").
+		WithExtensions("x-tier", 2).
+		WithExtensionsMap(map[string]any{"x-owner": map[string]any{"team": "archive"}}).
+		WithExtensionsMap(mason.Visibility("partner")))
+	items.Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item"))
+
+	archived, ok := api.GetOperationByID("get_archived_item")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, map[string]any{
+		"x-owner":      map[string]any{"team": "archive", "slack": "#catalog"},
+		"x-tier":       2,
+		"x-internal":   true,
+		"x-visibility": "partner",
+	}, archived.Extensions)
+
+	item, ok := api.GetOperationByID("get_item")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, map[string]any{
+		"x-owner": map[string]any{"team": "catalog", "slack": "#catalog"},
+		"x-tier":  1,
+	}, item.Extensions)
+
+	assert.Assert(t, cmpPanics(func() {
+		mason.HandleGet(getItem).WithExtensionsMap(map[string]any{"owner": "catalog"})
+	}))
+}
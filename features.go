@@ -0,0 +1,77 @@
+package mason
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// ErrFeatureNotEnabled is the code of the error sent to the tenants using an operation their feature flags do not
+// enable, with the StatusForbidden status, see FeatureGate.
+const ErrFeatureNotEnabled = "feature_not_enabled"
+
+// EnabledFor reports whether the operation is available to a tenant with the enabled flags, that is whether they
+// include all the flags of the operation. Operations without flags are available to every tenant.
+func (op Operation) EnabledFor(enabled ...string) bool {
+	return featuresEnabled(op.FeatureFlags, enabled)
+}
+
+func featuresEnabled(required []string, enabled []string) bool {
+	for _, flag := range required {
+		if !slices.Contains(enabled, flag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func WithOperationFeatureFlags(flags ...string) Option {
+	return func(m *Operation) {
+		m.FeatureFlags = flags
+	}
+}
+
+var _ Middleware = (*FeatureGateMiddleware)(nil)
+
+// FeatureGateMiddleware refuses the requests of the tenants without the feature flags of the routes.
+type FeatureGateMiddleware struct {
+	api     *API
+	enabled func(r *http.Request) []string
+}
+
+// FeatureGate returns a middleware that refuses the requests to the routes with feature flags, see WithFeatureFlag,
+// unless enabled returns all of them for the request, e.g. the flags of the plan of the authenticated tenant. The
+// refused requests get a 403 and a model.APIError with the ErrFeatureNotEnabled code.
+func FeatureGate(api *API, enabled func(r *http.Request) []string) *FeatureGateMiddleware {
+	return &FeatureGateMiddleware{api: api, enabled: enabled}
+}
+
+func (g *FeatureGateMiddleware) GetHandler(builder Builder) func(WebHandler) WebHandler {
+	return func(next WebHandler) WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			required := builder.FeatureFlags()
+			if len(required) == 0 {
+				return next(ctx, w, r)
+			}
+
+			enabled := g.enabled(r)
+			if featuresEnabled(required, enabled) {
+				return next(ctx, w, r)
+			}
+
+			var missing []string
+			for _, flag := range required {
+				if !slices.Contains(enabled, flag) {
+					missing = append(missing, flag)
+				}
+			}
+			msg := fmt.Sprintf("The operation requires the features %s", strings.Join(missing, ", "))
+			return g.api.Respond(ctx, w, model.NewAPIError(ErrFeatureNotEnabled, msg), http.StatusForbidden)
+		}
+	}
+}
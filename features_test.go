@@ -0,0 +1,68 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestFeatureGate(t *testing.T) {
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	gate := mason.FeatureGate(api, func(r *http.Request) []string {
+		return strings.Split(r.Header.Get("X-Plan-Features"), ",")
+	})
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandlePost(createItem).Path("/items").WithOpID("create_item").WithMWs(gate))
+	grp.Register(mason.HandlePost(createItem).
+		Path("/items/import").
+		WithOpID("import_items").
+		WithMWs(gate).
+		WithFeatureFlag("plan:pro", "beta:import"))
+
+	post := func(path string, features string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"title": "gated"}`))
+		req.Header.Set("X-Plan-Features", features)
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusCreated, post("/items", "").Code)
+	assert.Equal(t, http.StatusCreated, post("/items/import", "plan:pro,beta:import").Code)
+
+	rec := post("/items/import", "plan:pro")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	var apiErr model.APIError
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+	assert.Equal(t, mason.ErrFeatureNotEnabled, apiErr.Code)
+	assert.Equal(t, "The operation requires the features beta:import", apiErr.Message)
+
+	ops := map[string]mason.Operation{}
+	for _, op := range api.Operations() {
+		ops[op.OperationID] = op
+	}
+	assert.Assert(t, ops["create_item"].EnabledFor())
+	assert.Assert(t, !ops["import_items"].EnabledFor("plan:pro"))
+	assert.Assert(t, ops["import_items"].EnabledFor("plan:pro", "beta:import", "plan:team"))
+
+	// the flags survive the round trip of the registry
+	data, err := json.Marshal(api.Registry())
+	assert.NilError(t, err)
+	var reg mason.Registry
+	assert.NilError(t, json.Unmarshal(data, &reg))
+	for _, op := range reg["items"] {
+		assert.DeepEqual(t, ops[op.OperationID].FeatureFlags, op.FeatureFlags)
+	}
+}
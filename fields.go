@@ -0,0 +1,145 @@
+package mason
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+	"github.com/tailbits/mason/model"
+)
+
+// FieldsParam is the query param used to select the fields of a response, on routes registered WithFieldSelection.
+// Nested fields are separated by dots, e.g. fields=id,author.name. Arrays are transparent, so the fields of a list
+// response apply to each of its items.
+const FieldsParam = "fields"
+
+// fieldTree is the parsed form of a fields param. A field without children is selected as a whole.
+type fieldTree map[string]fieldTree
+
+// selectFields prunes the result to the fields requested by the client. It returns the result untouched when no
+// fields are requested, and a model.ValidationError when a field is not part of the output schema.
+func selectFields(api *API, r *http.Request, result model.WithSchema) (any, error) {
+	fields := splitList(r.URL.Query().Get(FieldsParam), ",")
+	if len(fields) == 0 {
+		return result, nil
+	}
+
+	schBytes, err := api.DereferenceSchema(result.Schema())
+	if err != nil {
+		return nil, fmt.Errorf("dereferenceSchema ent[%s]: %w", result.Name(), err)
+	}
+
+	var sch jsonschema.Schema
+	if err := json.Unmarshal(schBytes, &sch); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	tree := fieldTree{}
+	errs := []model.FieldError{}
+	for _, field := range fields {
+		if !hasField(&sch, sch.Definitions, strings.Split(field, ".")) {
+			errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' references unknown field %s", FieldsParam, field)})
+			continue
+		}
+		tree.add(strings.Split(field, "."))
+	}
+
+	if len(errs) > 0 {
+		return nil, model.ValidationError{Errors: errs}
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return tree.prune(data), nil
+}
+
+func (t fieldTree) add(path []string) {
+	child, ok := t[path[0]]
+	if len(path) == 1 {
+		// selecting a field as a whole wins over selecting some of its children
+		t[path[0]] = nil
+		return
+	}
+	if ok && child == nil {
+		return
+	}
+	if child == nil {
+		child = fieldTree{}
+		t[path[0]] = child
+	}
+	child.add(path[1:])
+}
+
+func (t fieldTree) prune(data any) any {
+	switch v := data.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(t))
+		for key, child := range t {
+			val, ok := v[key]
+			if !ok {
+				continue
+			}
+			if child == nil {
+				res[key] = val
+				continue
+			}
+			res[key] = child.prune(val)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = t.prune(item)
+		}
+		return res
+	default:
+		return data
+	}
+}
+
+// hasField reports whether the dot separated path exists in the schema, following refs and array items.
+func hasField(sch *jsonschema.Schema, defs map[string]jsonschema.SchemaOrBool, path []string) bool {
+	sch = resolveFieldSchema(sch, defs)
+	if sch == nil {
+		return false
+	}
+	if len(path) == 0 {
+		return true
+	}
+
+	prop, ok := sch.Properties[path[0]]
+	if !ok || prop.TypeObject == nil {
+		return false
+	}
+
+	return hasField(prop.TypeObject, defs, path[1:])
+}
+
+func resolveFieldSchema(sch *jsonschema.Schema, defs map[string]jsonschema.SchemaOrBool) *jsonschema.Schema {
+	for depth := 0; sch != nil && depth < 32; depth++ {
+		switch {
+		case sch.Ref != nil:
+			def, ok := defs[strings.TrimPrefix(*sch.Ref, "#/definitions/")]
+			if !ok {
+				return nil
+			}
+			sch = def.TypeObject
+		case sch.Items != nil && sch.Items.SchemaOrBool != nil:
+			sch = sch.Items.SchemaOrBool.TypeObject
+		default:
+			return sch
+		}
+	}
+
+	return nil
+}
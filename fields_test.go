@@ -0,0 +1,40 @@
+package mason_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"gotest.tools/v3/assert"
+)
+
+func TestFieldSelection(t *testing.T) {
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(ListItems).Path("/items").WithOpID("list_items").WithFieldSelection())
+
+	t.Run("returns the full response without fields", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Assert(t, len(rec.Header().Get("X-Next-Cursor")) > 0)
+		assert.Assert(t, len(rec.Body.String()) > 0)
+	})
+
+	t.Run("prunes the response to the selected fields", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?fields=data.title", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"data":[{"title":"a"},{"title":"b"}]}`+"\n", rec.Body.String())
+	})
+
+	t.Run("rejects unknown fields", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?fields=data.titel", nil))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+}
@@ -0,0 +1,89 @@
+package mason
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+// HandlerFile handles a download, e.g. of an export, see HandleFile.
+type HandlerFile[Q any] func(ctx context.Context, r *http.Request, params Q) (*File, error)
+
+// HandleFile registers a GET route responding with a File, whose Range requests are answered with the partial
+// content, so large downloads can be resumed or fetched in chunks.
+func HandleFile[Q any](handler HandlerFile[Q]) *RouteBuilderNoBody[*File, Q] {
+	return HandleGet(HandlerNoBody[*File, Q](handler))
+}
+
+var _ model.Entity = (*File)(nil)
+
+// isFile reports whether the responses of a route are Files.
+func isFile[T any]() bool {
+	var t T
+	_, ok := any(t).(*File)
+	return ok
+}
+
+// File is the response of a download route. It is served with http.ServeContent: the Range requests get a 206 with
+// the requested ranges, or a 416 when none of them is satisfiable, and the responses advertise Accept-Ranges. The
+// AfterEncode hooks, the representations and the field selection of the route do not apply. It is documented as a
+// binary string.
+type File struct {
+	// Content is the content of the file, closed once served if it is an io.Closer.
+	Content io.ReadSeeker
+	// Filename is sent in the Content-Disposition header, if set, and the content type is detected from its extension.
+	Filename string
+	// ContentType is the content type of the file, detected from the Filename or the content when empty.
+	ContentType string
+	// ModTime is when the file last changed, if known, for the Last-Modified header and the conditional requests.
+	ModTime time.Time
+}
+
+func (f *File) Name() string {
+	return "File"
+}
+
+func (f *File) Schema() []byte {
+	return []byte(`{"type": "string", "format": "binary"}`)
+}
+
+// Example is empty, as the files are binary.
+func (f *File) Example() []byte {
+	return nil
+}
+
+// Marshal is not supported, as files are served as is.
+func (f *File) Marshal() (json.RawMessage, error) {
+	return nil, fmt.Errorf("file %s cannot be marshalled", f.Filename)
+}
+
+// Unmarshal is a no-op, as files are only responses.
+func (f *File) Unmarshal(data json.RawMessage) error {
+	return nil
+}
+
+// serveFile serves the content of the file, with the ranges of the request.
+func serveFile(w http.ResponseWriter, r *http.Request, f *File) error {
+	if f == nil || f.Content == nil {
+		return fmt.Errorf("no file to serve")
+	}
+	if closer, ok := f.Content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if f.ContentType != "" {
+		w.Header().Set("Content-Type", f.ContentType)
+	}
+	if f.Filename != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": f.Filename}))
+	}
+	http.ServeContent(w, r, f.Filename, f.ModTime, f.Content)
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestHandleFile(t *testing.T) {
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	export := func(ctx context.Context, r *http.Request, params model.Nil) (*mason.File, error) {
+		return &mason.File{
+			Content:  strings.NewReader("id,title\n1,a\n2,b\n"),
+			Filename: "export.csv",
+			ModTime:  modified,
+		}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("exports").Register(mason.HandleFile(export).Path("/exports/{id}").WithOpID("download_export"))
+
+	get := func(header string, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/exports/1", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("full content", func(t *testing.T) {
+		rec := get("", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "bytes", rec.Header().Get("Accept-Ranges"))
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename=export.csv`, rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "Sun, 01 Mar 2026 12:00:00 GMT", rec.Header().Get("Last-Modified"))
+		assert.Equal(t, "id,title\n1,a\n2,b\n", rec.Body.String())
+	})
+
+	t.Run("partial content", func(t *testing.T) {
+		rec := get("Range", "bytes=9-12")
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, "bytes 9-12/17", rec.Header().Get("Content-Range"))
+		assert.Equal(t, "1,a\n", rec.Body.String())
+	})
+
+	t.Run("unsatisfiable range", func(t *testing.T) {
+		rec := get("Range", "bytes=100-")
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+		assert.Equal(t, "bytes */17", rec.Header().Get("Content-Range"))
+	})
+}
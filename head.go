@@ -0,0 +1,51 @@
+package mason
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// WithAutoHead answers HEAD requests on GET routes, with the headers of the GET response and no body. The
+// Content-Length is computed from the discarded body when the handler does not set it. Routes registered for HEAD
+// take precedence.
+func WithAutoHead() HTTPRuntimeOption {
+	return func(r *HTTPRuntime) {
+		r.autoHead = true
+	}
+}
+
+// headWriter discards the body of a response, and delays the headers until the handler returns, so the
+// Content-Length can be computed.
+type headWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (w *headWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *headWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.size += len(b)
+	return len(b), nil
+}
+
+func (w *headWriter) flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.Header().Get("Content-Length") == "" && bodyAllowed(w.status) {
+		w.Header().Set("Content-Length", strconv.Itoa(w.size))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
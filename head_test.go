@@ -0,0 +1,68 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestAutoHead(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "done"}, nil
+	}
+
+	newRuntime := func(opts ...mason.HTTPRuntimeOption) *mason.HTTPRuntime {
+		rtm := mason.NewHTTPRuntime(opts...)
+		api := mason.NewAPI(rtm)
+		api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+			Path("/item").
+			WithOpID("get_item").
+			WithCache(mason.CachePolicy{MaxAge: 60}))
+		rtm.Handle(http.MethodGet, "/raw", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte("hello"))
+			return err
+		})
+		return rtm
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newRuntime().ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/item", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		rtm := newRuntime(mason.WithAutoHead())
+
+		get := httptest.NewRecorder()
+		rtm.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/item", nil))
+		head := httptest.NewRecorder()
+		rtm.ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/item", nil))
+
+		assert.Equal(t, http.StatusOK, head.Code)
+		assert.DeepEqual(t, get.Header(), head.Header())
+		assert.Equal(t, 0, head.Body.Len())
+	})
+
+	t.Run("computed content length", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newRuntime(mason.WithAutoHead()).ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/raw", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "5", rec.Header().Get("Content-Length"))
+		assert.Equal(t, 0, rec.Body.Len())
+	})
+
+	t.Run("other methods", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newRuntime(mason.WithAutoHead()).ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/item", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
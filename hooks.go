@@ -0,0 +1,152 @@
+package mason
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+// BeforeDecodeHook runs before the request of a route is decoded, e.g. to rename the legacy fields of the body. It can
+// replace the body of the request. An error is handled like the errors of the handler.
+type BeforeDecodeHook func(r *http.Request) error
+
+// AfterEncodeHook runs after the response of a route is encoded, with the status and the JSON body, and returns the
+// body to send, e.g. with the fields renamed for legacy clients.
+type AfterEncodeHook func(ctx context.Context, status int, body []byte) ([]byte, error)
+
+// ValidationHook observes the validation of the request bodies against the schemas of the routes, e.g. to count the
+// failures per operation and per field in the metrics, see API.OnValidation. The responses validated by a
+// ShadowValidator are reported too.
+type ValidationHook func(ctx context.Context, outcome ValidationOutcome)
+
+// ValidationOutcome is the outcome of the validation of a request or a response body.
+type ValidationOutcome struct {
+	OperationID string
+	Method      string
+	Path        string
+	// Phase is either "request" or "response", like the Phase of a Mismatch.
+	Phase string
+	Valid bool
+	// Duration is the time spent validating the body, and decoding it for the requests.
+	Duration time.Duration
+	// Fields are the paths of the failing fields, e.g. items.0.title, with (root) for the body itself. A field failing
+	// several rules is listed once.
+	Fields []string
+	// Err is the model.ValidationError of the body, nil when it is valid. The responses can also fail with another
+	// error, e.g. for an unexpected status.
+	Err error
+}
+
+// OnValidation adds a hook observing the validation of the request bodies of every route. The bodies decoded without
+// validation, e.g. of trusted callers, and the streams, validated as the handlers read them, are not reported. Hooks
+// run in the order they are added, before the handler or the error response.
+func (a *API) OnValidation(hook ValidationHook) *API {
+	a.validationHooks = append(a.validationHooks, hook)
+	return a
+}
+
+// reportValidation hands the outcome of the validation of the request body to the validation hooks. The errors that
+// are not validation errors, e.g. of a body that could not be read, are not outcomes of the validation.
+func (a *API) reportValidation(ctx context.Context, rb *RouteBuilderBase, start time.Time, err error) {
+	if err != nil && !errors.As(err, new(model.ValidationError)) {
+		return
+	}
+
+	a.report(ctx, ValidationOutcome{
+		OperationID: rb.opID,
+		Method:      rb.method,
+		Path:        rb.path,
+		Phase:       "request",
+		Duration:    time.Since(start),
+	}, err)
+}
+
+// report completes the outcome with the error of the validation, and hands it to the validation hooks.
+func (a *API) report(ctx context.Context, outcome ValidationOutcome, err error) {
+	outcome.Valid, outcome.Err = err == nil, err
+	var verr model.ValidationError
+	if errors.As(err, &verr) {
+		outcome.Err = verr
+		for _, fe := range verr.Errors {
+			if field := fieldPath(fe); !slices.Contains(outcome.Fields, field) {
+				outcome.Fields = append(outcome.Fields, field)
+			}
+		}
+	}
+
+	for _, hook := range a.validationHooks {
+		hook(ctx, outcome)
+	}
+}
+
+// validatesBody reports whether the request body is validated as it is decoded, and so reported to the validation
+// hooks.
+func validatesBody[T model.Entity](api *API, rb *RouteBuilderBase, r *http.Request) bool {
+	if len(api.validationHooks) == 0 || rb.skipValidation || BodyValidationSkipped(r.Context()) {
+		return false
+	}
+	var ent T
+	if ent.Name() == "NilEntity" {
+		return false
+	}
+	_, stream := any(model.New[T]()).(streamDecoder)
+
+	return !stream
+}
+
+// fieldPath returns the path of the field of a validation error, including the missing property of the required
+// errors, which are reported on their parent.
+func fieldPath(fe model.FieldError) string {
+	prop, ok := fe.Details()["property"].(string)
+	if fe.Kind() != "required" || !ok {
+		return fe.Field()
+	}
+	if fe.Field() == "(root)" || fe.Field() == "" {
+		return prop
+	}
+
+	return fe.Field() + "." + prop
+}
+
+func (rb *RouteBuilderBase) beforeDecode(r *http.Request) error {
+	for _, hook := range rb.beforeDecodeHooks {
+		if err := hook(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encode hands the response to the runtime, and applies the AfterEncode hooks to the encoded body.
+func (rb *RouteBuilderBase) encode(ctx context.Context, api *API, w http.ResponseWriter, data any) error {
+	if len(rb.afterEncodeHooks) == 0 {
+		return api.Respond(ctx, w, data, rb.successCode)
+	}
+
+	rec := &recordingWriter{header: make(http.Header)}
+	if err := api.Respond(ctx, rec, data, rb.successCode); err != nil {
+		return err
+	}
+
+	body := rec.body.Bytes()
+	for _, hook := range rb.afterEncodeHooks {
+		var err error
+		if body, err = hook(ctx, rec.status(), body); err != nil {
+			return err
+		}
+	}
+	rec.body.Reset()
+	rec.body.Write(body)
+	if rec.header.Get("Content-Length") != "" {
+		rec.header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	rec.flush(w)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package mason_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestHooks(t *testing.T) {
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	var status int
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID("create_item").
+		BeforeDecode(func(r *http.Request) error {
+			// legacy clients send the title as name
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return err
+			}
+			r.Body = io.NopCloser(bytes.NewReader(bytes.Replace(body, []byte(`"name"`), []byte(`"title"`), 1)))
+			return nil
+		}).
+		AfterEncode(func(ctx context.Context, code int, body []byte) ([]byte, error) {
+			status = code
+			return bytes.Replace(body, []byte(`"title"`), []byte(`"name"`), 1), nil
+		}))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name": "legacy"}`)))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `{"name":"legacy"}`+"\n", rec.Body.String())
+}
+
+func TestOnValidation(t *testing.T) {
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	var outcomes []mason.ValidationOutcome
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.OnValidation(func(ctx context.Context, outcome mason.ValidationOutcome) {
+		outcomes = append(outcomes, outcome)
+	})
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID("create_item"))
+	api.NewRouteGroup("items").Register(mason.HandlePut(createItem).
+		Path("/items/{id}").
+		WithOpID("import_item").
+		WithoutBodyValidation())
+
+	for _, body := range []string{`{"title": "valid"}`, `{}`, `{"title": 1}`} {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body)))
+	}
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/items/1", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Equal(t, 3, len(outcomes))
+	assert.Equal(t, "create_item", outcomes[0].OperationID)
+	assert.Equal(t, http.MethodPost, outcomes[0].Method)
+	assert.Equal(t, "/items", outcomes[0].Path)
+	assert.Equal(t, "request", outcomes[0].Phase)
+	assert.Assert(t, outcomes[0].Valid)
+	assert.Assert(t, outcomes[0].Err == nil)
+	assert.Equal(t, 0, len(outcomes[0].Fields))
+
+	assert.Assert(t, !outcomes[1].Valid)
+	assert.DeepEqual(t, []string{"title"}, outcomes[1].Fields)
+	assert.Assert(t, model.IsJSONFieldError(outcomes[1].Err))
+	assert.DeepEqual(t, []string{"title"}, outcomes[2].Fields)
+}
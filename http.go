@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -65,61 +67,254 @@ func HandleGet[T model.Entity, Q any](handler HandlerNoBody[T, Q]) *RouteBuilder
 	}
 }
 
-func newHandlerWithBody[T model.Entity, O model.Entity, Q any](api *API, fn HandlerWithBody[T, O, Q], code int) WebHandler {
+// HandlerNoContent handles a request whose success response has no content, e.g. a delete. It only returns an error.
+type HandlerNoContent[T model.Entity, Q any] func(ctx context.Context, r *http.Request, model T, params Q) error
+
+// HandlePostNoContent registers a POST route responding with an empty body, with a 204 status unless another success
+// code is set. The spec documents the response without content.
+func HandlePostNoContent[T model.Entity, Q any](handler HandlerNoContent[T, Q]) *RouteBuilderWithBody[T, model.Nil, Q] {
+	return noContent(HandlePost(withoutContent(handler)))
+}
+
+// HandlePutNoContent registers a PUT route responding with an empty body, see HandlePostNoContent.
+func HandlePutNoContent[T model.Entity, Q any](handler HandlerNoContent[T, Q]) *RouteBuilderWithBody[T, model.Nil, Q] {
+	return noContent(HandlePut(withoutContent(handler)))
+}
+
+// HandlePatchNoContent registers a PATCH route responding with an empty body, see HandlePostNoContent.
+func HandlePatchNoContent[T model.Entity, Q any](handler HandlerNoContent[T, Q]) *RouteBuilderWithBody[T, model.Nil, Q] {
+	return noContent(HandlePatch(withoutContent(handler)))
+}
+
+// HandleDeleteNoContent registers a DELETE route responding with an empty body, see HandlePostNoContent.
+func HandleDeleteNoContent[T model.Entity, Q any](handler HandlerNoContent[T, Q]) *RouteBuilderWithBody[T, model.Nil, Q] {
+	return noContent(HandleDelete(withoutContent(handler)))
+}
+
+func withoutContent[T model.Entity, Q any](handler HandlerNoContent[T, Q]) HandlerWithBody[T, model.Nil, Q] {
+	return func(ctx context.Context, r *http.Request, in T, params Q) (model.Nil, error) {
+		return model.Nil{}, handler(ctx, r, in, params)
+	}
+}
+
+func noContent[T model.Entity, Q any](rb *RouteBuilderWithBody[T, model.Nil, Q]) *RouteBuilderWithBody[T, model.Nil, Q] {
+	rb.noContent = true
+	return rb
+}
+
+func newHandlerWithBody[T model.Entity, O model.Entity, Q any](api *API, fn HandlerWithBody[T, O, Q], rb *RouteBuilderBase) WebHandler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-		params, err := DecodeQueryParams[Q](r)
+		if ok, err := rb.checkMaintenance(ctx, api, w); !ok {
+			return err
+		}
+
+		if ok, err := rb.checkPathParams(ctx, api, w, r); !ok {
+			return err
+		}
+
+		if ok, err := rb.checkContent(ctx, api, w, r); !ok {
+			return err
+		}
+
+		if err := rb.beforeDecode(r); err != nil {
+			return err
+		}
+
+		params, err := DecodeQueryParams[Q](r, append(slices.Clone(rb.queryOptions), deprecatedQueryParams(w))...)
 		if err != nil {
 			return fmt.Errorf("decodeQueryParams: %w", err)
 		}
 
-		model, err := DecodeRequest[T](api, r)
+		opts := []DecodeOption{WithValidation(rb.validation...)}
+		if rb.skipValidation {
+			opts = append(opts, WithoutBodyValidation())
+		}
+		report, start := validatesBody[T](api, rb, r), time.Now()
+		input, err := DecodeRequest[T](api, r, opts...)
+		if report {
+			api.reportValidation(ctx, rb, start, err)
+		}
 		if err != nil {
 			return fmt.Errorf("validateAndDecode: %w", err)
 		}
 
-		result, err := fn(ctx, r, model, params)
+		ctx, r = api.withScope(ctx, r)
+		result, err := fn(ctx, r, input, params)
 		if err != nil {
 			return err
 		}
 
-		return api.Respond(ctx, w, result, code)
+		if err := rb.respond(ctx, api, w, r, result); err != nil {
+			return err
+		}
+		rb.purge(ctx, api, r)
+
+		return nil
 	}
 }
 
-func newHandler[T model.Entity, Q any](rsp WebResponder, fn HandlerNoBody[T, Q], code int) WebHandler {
+func newHandler[T model.Entity, Q any](api *API, fn HandlerNoBody[T, Q], rb *RouteBuilderBase) WebHandler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-		params, err := DecodeQueryParams[Q](r)
+		if ok, err := rb.checkMaintenance(ctx, api, w); !ok {
+			return err
+		}
+
+		if ok, err := rb.checkPathParams(ctx, api, w, r); !ok {
+			return err
+		}
+
+		if ok, err := rb.checkContent(ctx, api, w, r); !ok {
+			return err
+		}
+
+		if err := rb.beforeDecode(r); err != nil {
+			return err
+		}
+
+		params, err := DecodeQueryParams[Q](r, append(slices.Clone(rb.queryOptions), deprecatedQueryParams(w))...)
 		if err != nil {
 			return fmt.Errorf("decodeQueryParams: %w", err)
 		}
 
+		ctx, r = api.withScope(ctx, r)
 		result, err := fn(ctx, r, params)
 		if err != nil {
 			return err
 		}
 
-		return rsp.Respond(ctx, w, result, code)
+		return rb.respond(ctx, api, w, r, result)
 	}
 }
 
-func DecodeQueryParams[Q any](r *http.Request) (Q, error) {
+// respond applies the route's response options to the handler result, and hands it to the runtime.
+func (rb *RouteBuilderBase) respond(ctx context.Context, api *API, w http.ResponseWriter, r *http.Request, result model.WithSchema) error {
+	if p, ok := result.(model.Paginated); ok {
+		setPageHeaders(w, r, p)
+	}
+	if rb.cache != nil {
+		rb.cache.setHeaders(w)
+	}
+	rb.setSurrogateKeys(w, r, result)
+	if rb.notModified(w, r, result) {
+		return nil
+	}
+	if f, ok := result.(*File); ok {
+		return serveFile(w, r, f)
+	}
+	if rb.content != nil && rb.content.ResponseCharset != "" {
+		w = &charsetWriter{ResponseWriter: w, charset: rb.content.ResponseCharset}
+	}
+	if rb.noContent {
+		w.WriteHeader(rb.successCode)
+		return nil
+	}
+	if len(rb.representations) > 0 {
+		w.Header().Add("Vary", "Accept")
+		if rep, ok := rb.negotiate(r); ok {
+			return rb.encodeFailed(ctx, api, w, rb.respondWith(ctx, w, rep, result))
+		}
+	}
+
+	var data any = result
+	if rb.fieldSelection {
+		selected, err := selectFields(api, r, result)
+		if err != nil {
+			return err
+		}
+		data = selected
+	}
+
+	return rb.encodeFailed(ctx, api, w, rb.encode(ctx, api, w, data))
+}
+
+// QueryValidator can be implemented by query param structs to validate the decoded values.
+// Returning a model.ValidationError results in a 422 response.
+type QueryValidator interface {
+	Validate() error
+}
+
+func DecodeQueryParams[Q any](r *http.Request, opts ...QueryOption) (Q, error) {
 	var params Q
 
 	if err := r.ParseForm(); err != nil {
 		return params, fmt.Errorf("unable to parse query params: %w", err)
 	}
 
+	var options queryOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	resolveQueryAliases(reflect.TypeOf(params), r, options)
+	if options.lenient {
+		matchQueryKeys(reflect.TypeOf(params), r, options)
+	}
+
+	if schema, ok := QuerySchemaOf(params); ok {
+		if err := validateQuerySchema(schema, r.Form); err != nil {
+			return params, err
+		}
+	}
+
+	if err := checkQueryParamTags(reflect.TypeOf(params), r.Form); err != nil {
+		return params, err
+	}
+
+	if err := decodeQueryFields(reflect.ValueOf(&params).Elem(), r.Form); err != nil {
+		return params, err
+	}
+
+	if v, ok := any(&params).(QueryValidator); ok {
+		if err := v.Validate(); err != nil {
+			return params, err
+		}
+	}
+
+	return params, nil
+}
+
+// decodeQueryFields sets the fields of a query param struct from the parsed form.
+// Embedded structs without a json tag are flattened, so reusable param sets can be composed, and the other struct
+// fields are decoded from deep object params, see IsObjectQueryParam.
+func decodeQueryFields(params reflect.Value, form url.Values) error {
 	// loop through fields of params
-	v := reflect.TypeOf(params)
+	v := params.Type()
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Field(i)
 		tag := field.Tag.Get("json")
 		tag = strings.Split(tag, ",")[0]
 		if tag == "" {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := decodeQueryFields(params.Field(i), form); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if qd, ok := params.Field(i).Addr().Interface().(QueryDecoder); ok {
+			if err := qd.DecodeQuery(tag, field.Tag, form); err != nil {
+				return fmt.Errorf("unable to parse query param %q: %w", tag, err)
+			}
+			continue
+		}
+
+		if IsObjectQueryParam(field.Type) {
+			sub := objectQueryForm(form, tag)
+			if len(sub) == 0 && field.Type.Kind() == reflect.Ptr {
+				continue
+			}
+			f := params.Field(i)
+			if field.Type.Kind() == reflect.Ptr {
+				f.Set(reflect.New(field.Type.Elem()))
+				f = f.Elem()
+			}
+			if err := decodeQueryFields(f, sub); err != nil {
+				return fmt.Errorf("unable to parse query param %q: %w", tag, err)
+			}
 			continue
 		}
 
-		value := r.Form.Get(tag)
+		value := form.Get(tag)
 		defaultValue := field.Tag.Get("default")
 
 		if value == "" && defaultValue != "" {
@@ -131,7 +326,7 @@ func DecodeQueryParams[Q any](r *http.Request) (Q, error) {
 		}
 
 		// set the value of the field
-		f := reflect.ValueOf(&params).Elem().Field(i)
+		f := params.Field(i)
 
 		kind := field.Type.Kind()
 
@@ -141,13 +336,13 @@ func DecodeQueryParams[Q any](r *http.Request) (Q, error) {
 		case reflect.Int:
 			n, err := strconv.Atoi(value)
 			if err != nil {
-				return params, fmt.Errorf("unable to parse query params: %w", err)
+				return fmt.Errorf("unable to parse query params: %w", err)
 			}
 			f.SetInt(int64(n))
 		case reflect.Bool:
 			b, err := strconv.ParseBool(value)
 			if err != nil {
-				return params, fmt.Errorf("unable to parse query params: %w", err)
+				return fmt.Errorf("unable to parse query params: %w", err)
 			}
 			f.SetBool(b)
 		case reflect.Struct:
@@ -155,12 +350,12 @@ func DecodeQueryParams[Q any](r *http.Request) (Q, error) {
 			if field.Type == reflect.TypeOf(time.Time{}) {
 				t, err := parseQueryTime(value)
 				if err != nil {
-					return params, fmt.Errorf("unable to parse time for %q: %w", tag, err)
+					return fmt.Errorf("unable to parse time for %q: %w", tag, err)
 				}
 				f.Set(reflect.ValueOf(t))
 				break
 			}
-			return params, fmt.Errorf("unsupported query param struct type: %v", field.Type)
+			return fmt.Errorf("unsupported query param struct type: %v", field.Type)
 		case reflect.Ptr:
 			switch field.Type.Elem().Kind() {
 			case reflect.String:
@@ -168,13 +363,13 @@ func DecodeQueryParams[Q any](r *http.Request) (Q, error) {
 			case reflect.Int:
 				n, err := strconv.Atoi(value)
 				if err != nil {
-					return params, fmt.Errorf("unable to parse query params: %w", err)
+					return fmt.Errorf("unable to parse query params: %w", err)
 				}
 				f.Set(reflect.ValueOf(&n))
 			case reflect.Bool:
 				b, err := strconv.ParseBool(value)
 				if err != nil {
-					return params, fmt.Errorf("unable to parse query params: %w", err)
+					return fmt.Errorf("unable to parse query params: %w", err)
 				}
 				f.Set(reflect.ValueOf(&b))
 			case reflect.Struct:
@@ -182,17 +377,35 @@ func DecodeQueryParams[Q any](r *http.Request) (Q, error) {
 				if field.Type.Elem() == reflect.TypeOf(time.Time{}) {
 					t, err := parseQueryTime(value)
 					if err != nil {
-						return params, fmt.Errorf("unable to parse time for %q: %w", tag, err)
+						return fmt.Errorf("unable to parse time for %q: %w", tag, err)
 					}
 					f.Set(reflect.ValueOf(&t))
 				}
 			}
 		default:
-			return params, fmt.Errorf("unsupported query param type: %v", f.Kind())
+			return fmt.Errorf("unsupported query param type: %v", f.Kind())
 		}
 	}
 
-	return params, nil
+	return nil
+}
+
+// forEachQueryField calls fn for every json-tagged field of a query param struct, flattening embedded structs.
+func forEachQueryField(t reflect.Type, fn func(tag string, field reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "" {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				forEachQueryField(field.Type, fn)
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		fn(tag, field)
+	}
 }
 
 var timeLayouts = []string{
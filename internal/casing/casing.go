@@ -71,3 +71,28 @@ func SnakeToTitleCase(s string) string {
 
 	return result.String()
 }
+
+func ToSnakeCase(s string) string {
+	return strings.ReplaceAll(ToKebabCase(s), "-", "_")
+}
+
+func ToCamelCase(s string) string {
+	pascal := ToPascalCase(s)
+	if pascal == "" {
+		return pascal
+	}
+
+	runes := []rune(pascal)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func ToPascalCase(s string) string {
+	var result strings.Builder
+	for _, word := range strings.FieldsFunc(ToKebabCase(s), func(r rune) bool { return r == '-' || r == ' ' }) {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		result.WriteString(string(runes))
+	}
+	return result.String()
+}
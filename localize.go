@@ -0,0 +1,41 @@
+package mason
+
+// Translation is the summary and the description of an operation in a language, see Builder.WithSummaryLocalized.
+type Translation struct {
+	Summary     string `json:"summary,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func WithOperationTranslations(translations map[string]Translation) Option {
+	return func(m *Operation) {
+		if len(translations) > 0 {
+			m.Translations = translations
+		}
+	}
+}
+
+// Localized returns the operation with its summary and description in the language, e.g. fr, keeping the default ones
+// that are not translated.
+func (op Operation) Localized(lang string) Operation {
+	t := op.Translations[lang]
+	if t.Summary != "" {
+		op.Summary = t.Summary
+	}
+	if t.Description != "" {
+		op.Description = t.Description
+	}
+
+	return op
+}
+
+// localize sets the summaries or the descriptions of the route by language.
+func (rb *RouteBuilderBase) localize(texts map[string]string, set func(t *Translation, text string)) {
+	if rb.translations == nil {
+		rb.translations = make(map[string]Translation, len(texts))
+	}
+	for lang, text := range texts {
+		t := rb.translations[lang]
+		set(&t, text)
+		rb.translations[lang] = t
+	}
+}
@@ -0,0 +1,43 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestLocalized(t *testing.T) {
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID("create_item").
+		WithSummary("Create an item").
+		WithDesc("Creates an item in the catalog.").
+		WithSummaryLocalized(map[string]string{"fr": "Créer un article", "de": "Artikel erstellen"}).
+		WithDescLocalized(map[string]string{"fr": "Crée un article du catalogue."}))
+
+	op, ok := api.GetOperationByID("create_item")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, map[string]mason.Translation{
+		"fr": {Summary: "Créer un article", Description: "Crée un article du catalogue."},
+		"de": {Summary: "Artikel erstellen"},
+	}, op.Translations)
+
+	fr := op.Localized("fr")
+	assert.Equal(t, "Créer un article", fr.Summary)
+	assert.Equal(t, "Crée un article du catalogue.", fr.Description)
+
+	// the texts that are not translated keep their default
+	de := op.Localized("de")
+	assert.Equal(t, "Artikel erstellen", de.Summary)
+	assert.Equal(t, "Creates an item in the catalog.", de.Description)
+	assert.Equal(t, "Create an item", op.Localized("es").Summary)
+}
@@ -0,0 +1,191 @@
+package mason
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+// ErrMaintenance is the code of the error sent to the requests to the operations in maintenance, with the
+// StatusServiceUnavailable status and a Retry-After header.
+const ErrMaintenance = "maintenance"
+
+// Maintenance puts operations, by operation ID, or whole tags into maintenance at runtime, e.g. during a migration of
+// their tables. Their routes respond with a 503, a Retry-After header and a model.APIError with the ErrMaintenance
+// code, until they are taken out of maintenance. It is safe for concurrent use, see API.Maintenance.
+type Maintenance struct {
+	mu         sync.RWMutex
+	operations map[string]time.Duration
+	tags       map[string]time.Duration
+}
+
+// MaintenanceStatus lists the operation IDs and the tags in maintenance, with the seconds of their Retry-After.
+type MaintenanceStatus struct {
+	Operations map[string]int `json:"operations"`
+	Tags       map[string]int `json:"tags"`
+}
+
+// Maintenance returns the maintenance switches of the routes of the API.
+func (a *API) Maintenance() *Maintenance {
+	return a.maintenance
+}
+
+func newMaintenance() *Maintenance {
+	return &Maintenance{
+		operations: make(map[string]time.Duration),
+		tags:       make(map[string]time.Duration),
+	}
+}
+
+// StartOperation puts an operation into maintenance, asking the clients to retry after the given delay.
+func (m *Maintenance) StartOperation(opID string, retryAfter time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.operations[opID] = retryAfter
+}
+
+// StopOperation takes an operation out of maintenance. The operation stays in maintenance while one of its tags is.
+func (m *Maintenance) StopOperation(opID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.operations, opID)
+}
+
+// StartTag puts the operations with a tag into maintenance, asking the clients to retry after the given delay. The
+// route group of an operation counts as one of its tags.
+func (m *Maintenance) StartTag(tag string, retryAfter time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tags[tag] = retryAfter
+}
+
+// StopTag takes the operations with a tag out of maintenance.
+func (m *Maintenance) StopTag(tag string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tags, tag)
+}
+
+// Status returns the operation IDs and the tags in maintenance.
+func (m *Maintenance) Status() MaintenanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := MaintenanceStatus{
+		Operations: make(map[string]int, len(m.operations)),
+		Tags:       make(map[string]int, len(m.tags)),
+	}
+	for opID, retryAfter := range m.operations {
+		status.Operations[opID] = retryAfterSeconds(retryAfter)
+	}
+	for tag, retryAfter := range m.tags {
+		status.Tags[tag] = retryAfterSeconds(retryAfter)
+	}
+
+	return status
+}
+
+// lookup returns the longest delay of the operation, of its group and of its tags in maintenance, and false if none
+// of them is.
+func (m *Maintenance) lookup(opID string, group string, tags []string) (time.Duration, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	retryAfter, found := m.operations[opID]
+	if d, ok := m.tags[group]; ok {
+		retryAfter, found = max(retryAfter, d), true
+	}
+	for _, tag := range tags {
+		if d, ok := m.tags[tag]; ok {
+			retryAfter, found = max(retryAfter, d), true
+		}
+	}
+
+	return retryAfter, found
+}
+
+// MaintenanceRequest is the body of the requests to the admin handler of the maintenance, naming an operation ID, a
+// tag or both.
+type MaintenanceRequest struct {
+	OperationID string `json:"operationID,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+	// RetryAfter is the delay in seconds the clients are asked to wait, when starting a maintenance.
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+// Handler returns the admin hook of the maintenance: GET returns the MaintenanceStatus, PUT starts the maintenance of a
+// MaintenanceRequest, and DELETE stops it. It serves the requests authorize accepts, e.g. the ones with the token of
+// the operators. The other requests are refused with a 403, all of them when authorize is nil.
+func (m *Maintenance) Handler(authorize func(r *http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authorize == nil || !authorize(r) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			w.Header().Set("Allow", "DELETE, GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			var req MaintenanceRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OperationID == "" && req.Tag == "" {
+				http.Error(w, "the body must name an operationID or a tag", http.StatusBadRequest)
+				return
+			}
+			m.apply(r.Method, req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(m.Status())
+	})
+}
+
+func (m *Maintenance) apply(method string, req MaintenanceRequest) {
+	retryAfter := time.Duration(req.RetryAfter) * time.Second
+	if req.OperationID != "" {
+		if method == http.MethodPut {
+			m.StartOperation(req.OperationID, retryAfter)
+		} else {
+			m.StopOperation(req.OperationID)
+		}
+	}
+	if req.Tag != "" {
+		if method == http.MethodPut {
+			m.StartTag(req.Tag, retryAfter)
+		} else {
+			m.StopTag(req.Tag)
+		}
+	}
+}
+
+// checkMaintenance responds itself when the route is in maintenance, and returns false.
+func (rb *RouteBuilderBase) checkMaintenance(ctx context.Context, api *API, w http.ResponseWriter) (bool, error) {
+	retryAfter, ok := api.maintenance.lookup(rb.opID, rb.group, rb.tags)
+	if !ok {
+		return true, nil
+	}
+
+	return false, respondUnavailable(ctx, api, w, ErrMaintenance, "The operation is under maintenance", retryAfter)
+}
+
+// respondUnavailable responds with a 503, the Retry-After header and a model.APIError.
+func respondUnavailable(ctx context.Context, api *API, w http.ResponseWriter, code string, msg string, retryAfter time.Duration) error {
+	if retryAfter > 0 {
+		msg = fmt.Sprintf("%s, retry in %ds", msg, retryAfterSeconds(retryAfter))
+	}
+	body := setRetryAfter(w, model.NewAPIError(code, msg), retryAfter)
+
+	return api.Respond(ctx, w, body, http.StatusServiceUnavailable)
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
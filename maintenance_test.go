@@ -0,0 +1,162 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestMaintenance(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "available"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandleGet(getItem).Path("/items/a").WithOpID("get_item_a").WithTags("catalog"))
+	grp.Register(mason.HandleGet(getItem).Path("/items/b").WithOpID("get_item_b"))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	api.Maintenance().StartOperation("get_item_a", 90*time.Second)
+	rec := get("/items/a")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "90", rec.Header().Get("Retry-After"))
+	var apiErr model.APIError
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+	assert.Equal(t, mason.ErrMaintenance, apiErr.Code)
+	assert.Equal(t, http.StatusOK, get("/items/b").Code)
+
+	api.Maintenance().StopOperation("get_item_a")
+	assert.Equal(t, http.StatusOK, get("/items/a").Code)
+
+	// the admin hook toggles the tags, and the groups count as tags
+	admin := api.Maintenance().Handler(func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer ops"
+	})
+	toggle := func(method string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/maintenance", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer ops")
+		rec := httptest.NewRecorder()
+		admin.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec = toggle(http.MethodPut, `{"tag": "catalog", "retryAfter": 60}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var status mason.MaintenanceStatus
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.DeepEqual(t, map[string]int{"catalog": 60}, status.Tags)
+	assert.Equal(t, http.StatusServiceUnavailable, get("/items/a").Code)
+	assert.Equal(t, http.StatusOK, get("/items/b").Code)
+
+	assert.Equal(t, http.StatusOK, toggle(http.MethodPut, `{"tag": "items"}`).Code)
+	rec = get("/items/b")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "", rec.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, toggle(http.MethodDelete, `{"tag": "items"}`).Code)
+	assert.Equal(t, http.StatusOK, toggle(http.MethodDelete, `{"tag": "catalog"}`).Code)
+	assert.Equal(t, http.StatusOK, get("/items/a").Code)
+	assert.Equal(t, http.StatusBadRequest, toggle(http.MethodPut, `{}`).Code)
+	assert.Equal(t, http.StatusOK, toggle(http.MethodGet, ``).Code)
+
+	// the admin hook is closed to the other requests, and to all of them without an authorization
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/maintenance", strings.NewReader(`{"tag": "items"}`)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, http.StatusOK, get("/items/b").Code)
+
+	rec = httptest.NewRecorder()
+	api.Maintenance().Handler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/maintenance", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	failing := true
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		if failing {
+			return nil, errors.New("database is down")
+		}
+		return &Item{Title: "recovered"}, nil
+	}
+
+	var changes []mason.CircuitState
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	breaker := mason.NewCircuitBreaker(api,
+		mason.BreakerThreshold(2),
+		mason.BreakerCooldown(20*time.Millisecond),
+		mason.OnCircuitChange(func(opID string, state mason.CircuitState) {
+			changes = append(changes, state)
+		}))
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items").WithOpID("get_item").WithMWs(breaker))
+
+	get := func() int {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, get())
+	assert.Equal(t, mason.CircuitClosed, breaker.State("get_item"))
+	assert.Equal(t, http.StatusInternalServerError, get())
+	assert.Equal(t, mason.CircuitOpen, breaker.State("get_item"))
+	assert.Equal(t, http.StatusServiceUnavailable, get())
+
+	// the probe fails, so the circuit opens again
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, http.StatusInternalServerError, get())
+	assert.Equal(t, mason.CircuitOpen, breaker.State("get_item"))
+
+	failing = false
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, get())
+	assert.Equal(t, mason.CircuitClosed, breaker.State("get_item"))
+	assert.DeepEqual(t, []mason.CircuitState{
+		mason.CircuitOpen, mason.CircuitHalfOpen, mason.CircuitOpen, mason.CircuitHalfOpen, mason.CircuitClosed,
+	}, changes)
+}
+
+func TestCircuitBreaker_CatalogErrors(t *testing.T) {
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.RegisterError("not_found", http.StatusNotFound, &model.APIError{})
+	breaker := mason.NewCircuitBreaker(api, mason.BreakerThreshold(2))
+	// the errors of the middlewares after the breaker reach it as they are returned
+	api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).
+		Path("/items/{id}").
+		WithOpID("get_item").
+		WithErrors("not_found").
+		WithMWs(breaker, notFoundMiddleware{}))
+
+	for range 5 {
+		rtm.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/unknown", nil))
+	}
+	assert.Equal(t, mason.CircuitClosed, breaker.State("get_item"))
+}
+
+// notFoundMiddleware fails every request with the not_found error of the catalog, like a lookup of an unknown ID.
+type notFoundMiddleware struct{}
+
+func (notFoundMiddleware) GetHandler(mason.Builder) func(mason.WebHandler) mason.WebHandler {
+	return func(mason.WebHandler) mason.WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return model.NewAPIError("not_found", "item does not exist")
+		}
+	}
+}
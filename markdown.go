@@ -0,0 +1,18 @@
+package mason
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// readDescFile reads a Markdown description from the file system, e.g. an embed.FS holding the longer-form docs of the
+// routes. The description is static, so the routes fail to build when it cannot be read.
+func readDescFile(fsys fs.FS, path string) string {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		panic(fmt.Errorf("failed to read the description: %w", err))
+	}
+
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,42 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithDescFile(t *testing.T) {
+	docs := fstest.MapFS{
+		"docs/items.md":       {Data: []byte("# Items\n\nThe items of the catalog.\n")},
+		"docs/create_item.md": {Data: []byte("Creates an item.\n\n## Limits\n\nAt most 100 items per minute.\n\n")},
+	}
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("items").WithDescriptionFile(docs, "docs/items.md")
+	grp.Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID("create_item").
+		WithDescFile(docs, "docs/create_item.md"))
+
+	op, ok := api.GetOperationByID("create_item")
+	assert.Assert(t, ok)
+	assert.Equal(t, "Creates an item.\n\n## Limits\n\nAt most 100 items per minute.", op.Description)
+	meta, ok := api.GroupMetadata("items")
+	assert.Assert(t, ok)
+	assert.Equal(t, "# Items\n\nThe items of the catalog.", meta.Description)
+
+	assert.Assert(t, func() (panicked bool) {
+		defer func() { panicked = recover() != nil }()
+		mason.HandlePost(createItem).WithDescFile(docs, "docs/missing.md")
+		return false
+	}())
+}
@@ -3,6 +3,9 @@ package mason
 import (
 	"context"
 	"net/http"
+	"reflect"
+	"sync"
+	"time"
 
 	"github.com/tailbits/mason/model"
 )
@@ -18,23 +21,82 @@ type Middleware interface {
 type GroupMetadata struct {
 	Summary     string
 	Description string
+	// Extensions are the default extensions of the operations of the group, see RouteGroup.WithExtensions.
+	Extensions map[string]any
+	// Owner is the team owning the operations of the group, see RouteGroup.WithOwner.
+	Owner string
 }
 
 type API struct {
 	Runtime
+	// mu guards the registry, the models and the schema IDs, which Extend and Deregister change while requests are
+	// served, and the providers
+	mu         sync.RWMutex
 	registry   Registry
 	models     map[string]model.Entity
 	routeIndex groupMap
 	groupMeta  map[string]GroupMetadata
+	// derefCache holds the dereferenced schemas, keyed by the original schema
+	derefCache sync.Map
+	// refResolver resolves external $refs, if they are enabled with WithRefResolver
+	refResolver RefResolver
+	refAllow    []string
+	naming      Naming
+	// defaultTimeout applies to the routes without a timeout of their own
+	defaultTimeout time.Duration
+	// timeoutStatus is the status of the responses of the routes exceeding their timeout, see WithTimeoutStatus
+	timeoutStatus int
+	// defaultResponseLimit applies to the routes without a response size limit of their own
+	defaultResponseLimit ResponseLimit
+	// providers build the request-scoped dependencies registered with Provide
+	providers map[reflect.Type]func(r *http.Request) (any, error)
+	// errors is the error catalog, in order of registration
+	errors []ErrorDef
+	// webhooks are the types of events sent to the webhooks of the clients, in order of registration
+	webhooks []WebhookEvent
+	// translator localizes the messages of validation errors
+	translator model.Translator
+	// validation configures the errors reported for invalid request bodies
+	validation []model.ValidationOption
+	// validationHooks observe the validation of the request bodies, see OnValidation
+	validationHooks []ValidationHook
+	// encodeHooks observe the responses that could not be encoded, see OnEncodeError
+	encodeHooks []EncodeErrorHook
+	// responseLimitHooks observe the responses exceeding the size limit of their route, see OnResponseLimit
+	responseLimitHooks []ResponseLimitHook
+	// purgeHooks purge the cached responses by surrogate key, see OnPurge
+	purgeHooks []PurgeHook
+	// flags decide whether the routes dark launched with WithRollout are on, see WithFlagProvider
+	flags         FlagProvider
+	rolloutStatus int
+	// maintenance holds the operations and the tags in maintenance
+	maintenance *Maintenance
+	// schemaIDs maps the $id of the entity schemas to the entity names
+	schemaIDs map[string]string
+	// conflicts are the operations registered twice, reported by Build
+	conflicts []error
+	// frozen is set by Build, after which nothing can be registered, except routes and models with Extend
+	frozen bool
+	// extendable is set during Extend, when routes and models can be registered on the frozen API
+	extendable bool
+	// extending serializes the calls to Extend and Deregister
+	extending sync.Mutex
+	// plugins are the plugins applied to the routes, see RegisterPlugin
+	plugins []Plugin
+	// routesChanged are the hooks notified by Extend and Deregister, see OnRoutesChanged
+	routesChanged []func()
 }
 
 func NewAPI(runtime Runtime) *API {
 	return &API{
-		Runtime:    runtime,
-		registry:   make(Registry),
-		models:     make(map[string]model.Entity),
-		routeIndex: make(groupMap),
-		groupMeta:  make(map[string]GroupMetadata),
+		Runtime:     runtime,
+		registry:    make(Registry),
+		models:      make(map[string]model.Entity),
+		routeIndex:  make(groupMap),
+		groupMeta:   make(map[string]GroupMetadata),
+		naming:      Naming{}.withDefaults(),
+		schemaIDs:   make(map[string]string),
+		maintenance: newMaintenance(),
 	}
 }
 
@@ -45,18 +107,63 @@ func (a *API) NewRouteGroup(name string) *RouteGroup {
 	}
 }
 
+// WithValidationOptions configures the errors reported for invalid request bodies, e.g. to cap their number. Routes can
+// override them WithValidationOptions.
+func (a *API) WithValidationOptions(opts ...model.ValidationOption) *API {
+	a.validation = append(a.validation, opts...)
+	return a
+}
+
 func (a *API) registerModel(mdl model.Entity) {
+	a.mustBeExtendable("model " + mdl.Name())
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.addModel(mdl)
+}
+
+func (a *API) addModel(mdl model.Entity) {
 	a.models[mdl.Name()] = mdl
+	a.indexSchemaID(mdl)
+	a.derefCache.Clear()
+
+	// the schema of the wrapper refers to the entity it wraps
+	if inner, ok := UnwrapComponent(mdl); ok {
+		if ent, ok := inner.(model.Entity); ok {
+			a.addModel(ent)
+		}
+	}
 }
 
 func (a *API) GetModel(name string) (model.Entity, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	e, ok := a.models[name]
 
 	return e, ok
 }
 
+// ModelNames returns the names of the registered entities, sorted, leaving out the model.Nil of the routes without a
+// body.
+func (a *API) ModelNames() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var names []string
+	for _, name := range a.modelNames() {
+		if !isNilEntity(a.models[name]) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// ForEachOperation calls fn with the registered operations and their group. It iterates over a copy of the registry,
+// so fn can register routes.
 func (a *API) ForEachOperation(fn func(group string, op Operation)) {
-	for group, resource := range a.registry {
+	for group, resource := range a.Registry() {
 		for _, op := range resource {
 			fn(group, op)
 		}
@@ -0,0 +1,157 @@
+package mason
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MirrorHeader is set on the mirrored requests, so the secondary backend can tell them from live traffic, e.g. to
+// skip their side effects.
+const MirrorHeader = "X-Mirrored-Request"
+
+// maxMirrorBody is the size of the largest request body a Mirror replays, the requests with larger bodies are not
+// mirrored.
+const maxMirrorBody = 1 << 20
+
+type mirrorOptions struct {
+	client      *http.Client
+	operations  []string
+	concurrency int
+	onResult    func(opID string, status int, err error)
+}
+
+type MirrorOption func(*mirrorOptions)
+
+// MirrorClient sets the client sending the mirrored requests, a client with a 10 seconds timeout by default.
+func MirrorClient(c *http.Client) MirrorOption {
+	return func(o *mirrorOptions) {
+		o.client = c
+	}
+}
+
+// MirrorOperations only mirrors the requests of the given operations, e.g. when the middleware wraps every route.
+func MirrorOperations(opIDs ...string) MirrorOption {
+	return func(o *mirrorOptions) {
+		o.operations = opIDs
+	}
+}
+
+// MirrorConcurrency sets how many mirrored requests are in flight at once, 8 by default. The requests sampled while
+// all of them are busy are not mirrored, so a slow secondary backend never backs up the traffic.
+func MirrorConcurrency(n int) MirrorOption {
+	return func(o *mirrorOptions) {
+		o.concurrency = n
+	}
+}
+
+// OnMirrorResult sets the callback invoked with the status of each mirrored request, or the error sending it, e.g.
+// to compare the error rates of the backends.
+func OnMirrorResult(fn func(opID string, status int, err error)) MirrorOption {
+	return func(o *mirrorOptions) {
+		o.onResult = fn
+	}
+}
+
+var _ Middleware = (*Mirror)(nil)
+
+// Mirror is a middleware that sends a copy of a share of the requests to a secondary backend, e.g. a rewrite of the
+// handlers, to test it with live traffic before migrating to it. The copies are sent in the background, and their
+// responses discarded, so the live requests are neither slowed down nor affected by the secondary backend.
+type Mirror struct {
+	target  string
+	rate    float64
+	options mirrorOptions
+	sem     chan struct{}
+}
+
+// NewMirror returns a Mirror sending the given share of the requests, e.g. 0.05 for 5%, to the secondary backend at
+// the target base URL, with the path and the query of the original request.
+func NewMirror(target string, rate float64, opts ...MirrorOption) *Mirror {
+	options := mirrorOptions{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		concurrency: 8,
+		onResult:    func(string, int, error) {},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Mirror{
+		target:  strings.TrimSuffix(target, "/"),
+		rate:    rate,
+		options: options,
+		sem:     make(chan struct{}, max(options.concurrency, 1)),
+	}
+}
+
+func (m *Mirror) GetHandler(builder Builder) func(WebHandler) WebHandler {
+	return func(next WebHandler) WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			opID := builder.OpID()
+			if !m.sampled(opID) || r.ContentLength > maxMirrorBody {
+				return next(ctx, w, r)
+			}
+
+			// the body is buffered and restored, so the handler decodes the same bytes as the mirrored request
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				var err error
+				if body, err = readBody(r, true); err != nil {
+					return err
+				}
+			}
+			if len(body) <= maxMirrorBody {
+				m.send(context.WithoutCancel(ctx), opID, r, body)
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func (m *Mirror) sampled(opID string) bool {
+	if len(m.options.operations) > 0 && !slices.Contains(m.options.operations, opID) {
+		return false
+	}
+
+	return m.rate > 0 && rand.Float64() < m.rate
+}
+
+// send mirrors the request in the background, unless too many mirrored requests are in flight.
+func (m *Mirror) send(ctx context.Context, opID string, r *http.Request, body []byte) {
+	req, err := http.NewRequestWithContext(ctx, r.Method, m.target+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		m.options.onResult(opID, 0, fmt.Errorf("failed to mirror the request: %w", err))
+		return
+	}
+	req.Header = r.Header.Clone()
+	for _, name := range []string{"Connection", "Keep-Alive", "Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade"} {
+		req.Header.Del(name)
+	}
+	req.Header.Set(MirrorHeader, "true")
+
+	select {
+	case m.sem <- struct{}{}:
+	default:
+		return
+	}
+	go func() {
+		defer func() { <-m.sem }()
+
+		rsp, err := m.options.client.Do(req)
+		if err != nil {
+			m.options.onResult(opID, 0, fmt.Errorf("failed to mirror the request: %w", err))
+			return
+		}
+		defer rsp.Body.Close()
+		_, _ = io.Copy(io.Discard, rsp.Body)
+		m.options.onResult(opID, rsp.StatusCode, nil)
+	}()
+}
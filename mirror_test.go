@@ -0,0 +1,67 @@
+package mason_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestMirror(t *testing.T) {
+	type mirrored struct {
+		Method, URI, Body, Header string
+	}
+	var mu sync.Mutex
+	var requests []mirrored
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, mirrored{r.Method, r.URL.RequestURI(), string(body), r.Header.Get(mason.MirrorHeader)})
+		mu.Unlock()
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer secondary.Close()
+
+	var wg sync.WaitGroup
+	var statuses []int
+	mirror := mason.NewMirror(secondary.URL, 1,
+		mason.MirrorOperations("create_item"),
+		mason.OnMirrorResult(func(opID string, status int, err error) {
+			defer wg.Done()
+			assert.NilError(t, err)
+			mu.Lock()
+			statuses = append(statuses, status)
+			mu.Unlock()
+		}))
+
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandlePost(createItem).Path("/items").WithOpID("create_item").WithMWs(mirror))
+	grp.Register(mason.HandlePut(createItem).Path("/items/{id}").WithOpID("update_item").WithMWs(mirror))
+
+	wg.Add(1)
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items?dry_run=true", strings.NewReader(`{"title": "mirrored"}`)))
+	// the handler decodes the body the mirror read
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"title":"mirrored"}`+"\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/items/1", strings.NewReader(`{"title": "live only"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	wg.Wait()
+
+	assert.DeepEqual(t, []mirrored{{http.MethodPost, "/items?dry_run=true", `{"title": "mirrored"}`, "true"}}, requests)
+	assert.DeepEqual(t, []int{http.StatusTeapot}, statuses)
+}
@@ -11,9 +11,26 @@ import (
 
 var _ jsonschema.Exposer = (*Model)(nil)
 
+// DefaultRefPrefix is the prefix of the refs between the schemas of the models: the components of an OpenAPI spec.
+const DefaultRefPrefix = "#/components/schemas/"
+
 type Model struct {
 	jsonschema.Struct
 	model.WithSchema
+	// rename maps entity names to component names, see WithComponentNaming.
+	rename NamingStrategy
+	// refPrefix replaces DefaultRefPrefix in the refs of the schema, see WithRefPrefix.
+	refPrefix string
+}
+
+// ModelOption configures a model created with NewModel.
+type ModelOption func(*Model)
+
+// RefPrefix sets the prefix of the refs of the schema of the model, see Model.WithRefPrefix.
+func RefPrefix(prefix string) ModelOption {
+	return func(m *Model) {
+		m.refPrefix = prefix
+	}
 }
 
 func (m Model) IsNil() bool {
@@ -22,38 +39,106 @@ func (m Model) IsNil() bool {
 }
 
 func (m Model) JSONSchema() (jsonschema.Schema, error) {
-	if m.Schema() == nil {
+	var sch jsonschema.Schema
+	switch {
+	case m.reflectsSchema():
+		var err error
+		if sch, err = reflectSchema(m.WithSchema); err != nil {
+			return jsonschema.Schema{}, fmt.Errorf("error reflecting schema for %s: %w", m.Name(), err)
+		}
+	case m.Schema() == nil:
 		return jsonschema.Schema{}, nil
+	default:
+		if err := json.Unmarshal(m.Schema(), &sch); err != nil {
+			return jsonschema.Schema{}, fmt.Errorf("error unmarshalling schema for %s: %w", m.Name(), err)
+		}
 	}
 
-	var sch jsonschema.Schema
-	if err := json.Unmarshal(m.Schema(), &sch); err != nil {
-		return jsonschema.Schema{}, fmt.Errorf("error unmarshalling schema for %s: %w", m.Name(), err)
+	if c, ok := m.WithSchema.(model.WithDataClassification); ok && c.DataClassification() != "" {
+		sch.WithExtraPropertiesItem(model.ClassificationKeyword, c.DataClassification())
 	}
 
-	ex := make(map[string]interface{})
-	if err := json.Unmarshal(m.Example(), &ex); err != nil {
-		return jsonschema.Schema{}, fmt.Errorf("error unmarshalling example for %s : %w", m.Name(), err)
+	// the files are binary, so they are documented without an example
+	if _, isFile := m.WithSchema.(*File); !isFile {
+		ex := make(map[string]interface{})
+		if err := json.Unmarshal(m.Example(), &ex); err != nil {
+			return jsonschema.Schema{}, fmt.Errorf("error unmarshalling example for %s : %w", m.Name(), err)
+		}
+		sch.WithExamples(ex)
 	}
-	sch.WithExamples(ex)
 
+	normalizeDefs(&sch)
+	// the $id would change the base of the refs inside the component
+	sch.ID = nil
 	walkRefs(&sch, func(ref *string) {
-		refID := strings.ReplaceAll(*ref, "#/definitions/", "#/components/schemas/")
-		refID = strings.TrimPrefix(refID, "#/components/schemas/")
+		refID := strings.ReplaceAll(*ref, "#/definitions/", DefaultRefPrefix)
+		refID = strings.TrimPrefix(refID, DefaultRefPrefix)
 
-		*ref = "#/components/schemas/" + refID
+		*ref = m.RefPrefix() + m.componentName(refID)
 	})
 
+	if m.rename != nil && len(sch.Definitions) > 0 {
+		defs := make(map[string]jsonschema.SchemaOrBool, len(sch.Definitions))
+		for name, def := range sch.Definitions {
+			defs[m.componentName(name)] = def
+		}
+		sch.Definitions = defs
+	}
+
 	return sch, nil
 }
 
-func NewModel(ent model.WithSchema) Model {
+// ComponentName returns the name of the schema component of the model.
+func (m Model) ComponentName() string {
+	return m.componentName(m.Name())
+}
+
+// WithComponentNaming returns a copy of the model that names its component, and the components it references,
+// with the given strategy.
+func (m Model) WithComponentNaming(rename NamingStrategy) Model {
+	m.rename = rename
+	m.Struct.DefName = m.componentName(m.Name())
+	return m
+}
+
+// WithRefPrefix returns a copy of the model whose schema refs start with the prefix instead of DefaultRefPrefix, e.g.
+// to embed the schemas into a document with another structure.
+func (m Model) WithRefPrefix(prefix string) Model {
+	m.refPrefix = prefix
+	return m
+}
+
+// RefPrefix returns the prefix of the refs of the schema of the model.
+func (m Model) RefPrefix() string {
+	if m.refPrefix == "" {
+		return DefaultRefPrefix
+	}
+	return m.refPrefix
+}
+
+// ComponentNaming returns the strategy naming the component of the model and the ones it references, nil when they
+// keep the entity names.
+func (m Model) ComponentNaming() NamingStrategy {
+	return m.rename
+}
+
+func (m Model) componentName(name string) string {
+	if m.rename == nil {
+		return name
+	}
+	return m.rename(name)
+}
+
+func NewModel(ent model.WithSchema, opts ...ModelOption) Model {
 	m := Model{
 		Struct: jsonschema.Struct{
 			DefName: ent.Name(),
 		},
 		WithSchema: ent,
 	}
+	for _, opt := range opts {
+		opt(&m)
+	}
 
 	return m
 }
@@ -125,4 +210,6 @@ func walkSchema(schemaOrBool *jsonschema.SchemaOrBool, f func(*jsonschema.Schema
 	}
 
 	walkSchema(schema.Not, f)
+
+	walkDraft2020(schema, f)
 }
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+var _ Entity = (*APIError)(nil)
+
+// APIError is a domain error identified by a code, e.g. not_found. It is rendered with the status the code is
+// registered with.
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// RetryAfter is the number of seconds the clients should wait before retrying, like the Retry-After header.
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+func NewAPIError(code string, message string) *APIError {
+	return &APIError{Code: code, Message: message}
+}
+
+func (e *APIError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// ErrorCode returns the code of the error, used to look it up in the error catalog of the API.
+func (e *APIError) ErrorCode() string {
+	return e.Code
+}
+
+func (e *APIError) Name() string {
+	return "APIError"
+}
+
+func (e *APIError) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"code": {"type": "string"},
+			"message": {"type": "string"},
+			"retryAfter": {"type": "integer", "minimum": 0, "description": "The number of seconds to wait before retrying, like the Retry-After header."}
+		},
+		"required": ["code", "message"],
+		"additionalProperties": false
+	}`)
+}
+
+func (e *APIError) Example() []byte {
+	return []byte(`{
+		"code": "not_found",
+		"message": "The resource does not exist"
+	}`)
+}
+
+func (e *APIError) Marshal() (json.RawMessage, error) {
+	return json.Marshal(e)
+}
+
+func (e *APIError) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, e)
+}
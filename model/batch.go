@@ -0,0 +1,134 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MaxBatchOperations is the maximum number of operations in a single batch request.
+const MaxBatchOperations = 100
+
+// BatchOperation is a single sub-request of a batch. Path may include a query string.
+type BatchOperation struct {
+	ID     string          `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchResult is the outcome of a single batch operation, identified by the ID of the operation.
+type BatchResult struct {
+	ID     string          `json:"id,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+var _ Entity = (*BatchRequest)(nil)
+var _ Entity = (*BatchResponse)(nil)
+
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+func (b *BatchRequest) Name() string {
+	return "BatchRequest"
+}
+
+func (b *BatchRequest) Schema() []byte {
+	return []byte(fmt.Sprintf(`{
+		"type": "object",
+		"properties": {
+			"operations": {
+				"type": "array",
+				"minItems": 1,
+				"maxItems": %d,
+				"items": {
+					"type": "object",
+					"properties": {
+						"id": {"type": "string"},
+						"method": {"type": "string", "enum": ["GET", "POST", "PUT", "PATCH", "DELETE"]},
+						"path": {"type": "string", "pattern": "^/"},
+						"body": {}
+					},
+					"required": ["method", "path"],
+					"additionalProperties": false
+				}
+			}
+		},
+		"required": ["operations"],
+		"additionalProperties": false
+	}`, MaxBatchOperations))
+}
+
+func (b *BatchRequest) Example() []byte {
+	return []byte(`{
+		"operations": [
+			{"id": "1", "method": "POST", "path": "/items", "body": {"title": "a"}},
+			{"id": "2", "method": "GET", "path": "/items/123"}
+		]
+	}`)
+}
+
+func (b *BatchRequest) Marshal() (json.RawMessage, error) {
+	return json.Marshal(b)
+}
+
+func (b *BatchRequest) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, b)
+}
+
+// BatchResponse holds the results of a batch, in the order of the operations. Operations are executed independently,
+// so a batch can partially fail: Failed counts the results with a non-2xx status.
+type BatchResponse struct {
+	Results   []BatchResult `json:"results"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+}
+
+func (b *BatchResponse) Name() string {
+	return "BatchResponse"
+}
+
+func (b *BatchResponse) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"results": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"id": {"type": "string"},
+						"status": {"type": "integer"},
+						"body": {}
+					},
+					"required": ["status"],
+					"additionalProperties": false
+				}
+			},
+			"succeeded": {"type": "integer"},
+			"failed": {"type": "integer"}
+		},
+		"required": ["results", "succeeded", "failed"],
+		"additionalProperties": false
+	}`)
+}
+
+func (b *BatchResponse) Example() []byte {
+	return []byte(`{
+		"results": [
+			{"id": "1", "status": 201, "body": {"title": "a"}},
+			{"id": "2", "status": 422, "body": {"errors": [{"message": "title is required"}]}}
+		],
+		"succeeded": 1,
+		"failed": 1
+	}`)
+}
+
+func (b *BatchResponse) Marshal() (json.RawMessage, error) {
+	return json.Marshal(b)
+}
+
+func (b *BatchResponse) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, b)
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DataClassification is the sensitivity of the data of an entity or a field, for the compliance tooling.
+type DataClassification string
+
+const (
+	ClassificationPublic   DataClassification = "public"
+	ClassificationInternal DataClassification = "internal"
+	ClassificationPII      DataClassification = "pii"
+)
+
+// ClassificationKeyword carries the classification of a field in its schema, e.g. "email": {"type": "string",
+// "x-data-classification": "pii"}. The reflected schemas take it from the classification tag of the fields.
+const ClassificationKeyword = "x-data-classification"
+
+// WithDataClassification is implemented by the entities classified as a whole, whose schema is documented with the
+// x-data-classification extension.
+type WithDataClassification interface {
+	DataClassification() DataClassification
+}
+
+// Classifications returns the classifications of the fields of a schema by path, e.g. email, address.street or
+// keys[].secret, with the empty path for the schema itself. The fields of maps are found under the * key, e.g.
+// labels.*. The schemas referring to themselves are walked once, so their fields are not listed under every nesting.
+func Classifications(schema []byte) (map[string]DataClassification, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse the schema: %w", err)
+	}
+
+	res := make(map[string]DataClassification)
+	s := sensitiveSchema{root: root}
+	visiting := make(map[string]bool)
+
+	var walk func(node map[string]any, path string)
+	walk = func(node map[string]any, path string) {
+		if c, ok := node[ClassificationKeyword].(string); ok && c != "" {
+			res[path] = DataClassification(c)
+		}
+
+		if ref, ok := node["$ref"].(string); ok && !visiting[ref] {
+			if target, ok := s.resolve(ref); ok {
+				visiting[ref] = true
+				walk(target, path)
+				delete(visiting, ref)
+			}
+		}
+		for _, kw := range []string{"allOf", "anyOf", "oneOf"} {
+			subs, _ := node[kw].([]any)
+			for _, sub := range subs {
+				if sub, ok := sub.(map[string]any); ok {
+					walk(sub, path)
+				}
+			}
+		}
+
+		props, _ := node["properties"].(map[string]any)
+		for name, prop := range props {
+			if prop, ok := prop.(map[string]any); ok {
+				walk(prop, joinPath(path, name))
+			}
+		}
+		if additional, ok := node["additionalProperties"].(map[string]any); ok {
+			walk(additional, joinPath(path, "*"))
+		}
+
+		var items []any
+		switch v := node["items"].(type) {
+		case map[string]any:
+			items = append(items, v)
+		case []any:
+			items = append(items, v...)
+		}
+		prefix, _ := node["prefixItems"].([]any)
+		for _, item := range append(items, prefix...) {
+			if item, ok := item.(map[string]any); ok {
+				walk(item, path+"[]")
+			}
+		}
+	}
+	walk(root, "")
+
+	return res, nil
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
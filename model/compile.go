@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// compiledSchema is a schema ready for validation, by the validator of its draft.
+type compiledSchema struct {
+	draft2020 *jsonschema.Schema
+	legacy    *gojsonschema.Schema
+
+	// plan validates the large bodies item by item, see LargeBodyThreshold. It is made from the decoded schema on
+	// first use.
+	decoded  any
+	planOnce sync.Once
+	plan     *streamPlan
+	planErr  error
+}
+
+// compiledSchemas caches the compiled schemas, keyed by the schema.
+var compiledSchemas sync.Map
+
+// Compile compiles a schema for Validate, reporting an invalid schema. Compiled schemas are cached, so validating
+// against them later skips the compilation.
+func Compile(schema []byte) error {
+	_, err := compile(schema)
+	return err
+}
+
+func compile(schema []byte) (*compiledSchema, error) {
+	if cached, ok := compiledSchemas.Load(string(schema)); ok {
+		return cached.(*compiledSchema), nil
+	}
+
+	decoded, err := decodeSchema(schema)
+	if err != nil {
+		return nil, fmt.Errorf("decodeSchema: %w", err)
+	}
+
+	sch := compiledSchema{decoded: decoded}
+	if isDraft2020(decoded) {
+		if sch.draft2020, err = compileDraft2020(decoded); err != nil {
+			return nil, err
+		}
+	} else {
+		doc := gojsonschema.NewBytesLoader(schema)
+		if sch.legacy, err = gojsonschema.NewSchema(doc); err != nil {
+			return nil, fmt.Errorf("gojsonschema.NewSchema: [%s] %w", doc, err)
+		}
+	}
+	compiledSchemas.Store(string(schema), &sch)
+
+	return &sch, nil
+}
+
+// streamPlan returns the plan to validate the large bodies of the schema item by item, nil if there is none.
+func (s *compiledSchema) streamPlan() (*streamPlan, error) {
+	s.planOnce.Do(func() {
+		s.plan, s.planErr = newStreamPlan(s.decoded)
+	})
+
+	return s.plan, s.planErr
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Paginated is implemented by responses that contain one page of a collection.
+// The runtime uses it to set the Link and X-Next-Cursor headers.
+type Paginated interface {
+	NextCursor() string
+	PrevCursor() string
+}
+
+// PageInfo holds the opaque cursors for the pages around the current one.
+type PageInfo struct {
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+}
+
+var _ Entity = (*Connection[Nil])(nil)
+var _ Paginated = (*Connection[Nil])(nil)
+
+// Connection is a response envelope for a page of entities.
+// Its schema is composed from the schema of T, which is included as a definition.
+type Connection[T Entity] struct {
+	Data     []T      `json:"data"`
+	PageInfo PageInfo `json:"page_info"`
+}
+
+func (c *Connection[T]) Name() string {
+	return New[T]().Name() + "Connection"
+}
+
+func (c *Connection[T]) Schema() []byte {
+	item := New[T]()
+
+	return []byte(fmt.Sprintf(`{
+		"type": "object",
+		"properties": {
+			"data": {
+				"type": "array",
+				"items": {"$ref": "#/definitions/%[1]s"}
+			},
+			"page_info": {
+				"type": "object",
+				"properties": {
+					"next_cursor": {"type": ["string", "null"]},
+					"prev_cursor": {"type": ["string", "null"]}
+				},
+				"required": ["next_cursor", "prev_cursor"],
+				"additionalProperties": false
+			}
+		},
+		"required": ["data", "page_info"],
+		"additionalProperties": false,
+		"definitions": {
+			"%[1]s": %[2]s
+		}
+	}`, item.Name(), item.Schema()))
+}
+
+func (c *Connection[T]) Example() []byte {
+	return []byte(fmt.Sprintf(`{
+		"data": [%s],
+		"page_info": {
+			"next_cursor": "eyJpZCI6IjEyMyJ9",
+			"prev_cursor": null
+		}
+	}`, New[T]().Example()))
+}
+
+func (c *Connection[T]) Marshal() (json.RawMessage, error) {
+	return json.Marshal(c)
+}
+
+func (c *Connection[T]) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, c)
+}
+
+func (c *Connection[T]) NextCursor() string {
+	if c.PageInfo.NextCursor == nil {
+		return ""
+	}
+	return *c.PageInfo.NextCursor
+}
+
+func (c *Connection[T]) PrevCursor() string {
+	if c.PageInfo.PrevCursor == nil {
+		return ""
+	}
+	return *c.PageInfo.PrevCursor
+}
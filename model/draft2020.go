@@ -0,0 +1,214 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// draft2020Keywords are the keywords that only draft 2020-12 (and 2019-09) validators enforce.
+var draft2020Keywords = []string{
+	"$defs", "prefixItems", "unevaluatedProperties", "unevaluatedItems", "dependentRequired", "dependentSchemas",
+	"$dynamicRef", "$anchor",
+}
+
+var printer = message.NewPrinter(language.English)
+
+// isDraft2020 reports whether a schema has to be validated as draft 2020-12: it declares the draft, or it uses
+// keywords that older drafts ignore. The keywords are only looked for where they are keywords, not in the names of the
+// properties.
+func isDraft2020(doc any) bool {
+	if obj, ok := doc.(map[string]any); ok {
+		if s, ok := obj["$schema"].(string); ok && strings.Contains(s, "2020-12") {
+			return true
+		}
+	}
+
+	var found bool
+	WalkSchema(doc, func(schema map[string]any) {
+		for _, kw := range draft2020Keywords {
+			if _, ok := schema[kw]; ok {
+				found = true
+			}
+		}
+	})
+
+	return found
+}
+
+// compileDraft2020 compiles a schema for the draft 2020-12 validator, with the registered formats.
+func compileDraft2020(doc any) (*jsonschema.Schema, error) {
+	c := newCompiler(jsonschema.Draft2020)
+	if err := c.AddResource("schema.json", doc); err != nil {
+		return nil, fmt.Errorf("jsonschema.AddResource: %w", err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema.Compile: %w", err)
+	}
+
+	return sch, nil
+}
+
+// newCompiler returns a compiler for the schemas of the draft they declare, or of the default draft, with the
+// registered formats.
+func newCompiler(draft *jsonschema.Draft) *jsonschema.Compiler {
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(draft)
+	c.AssertFormat()
+	for name, check := range registeredFormats() {
+		c.RegisterFormat(&jsonschema.Format{
+			Name: name,
+			Validate: func(v any) error {
+				if !formatChecker(check).IsFormat(v) {
+					return fmt.Errorf("not a valid %s", name)
+				}
+				return nil
+			},
+		})
+	}
+
+	return c
+}
+
+// validateDraft2020 validates a body against a draft 2020-12 schema, and reports the errors like the ones of older
+// drafts, so messages, translations and validation options apply the same way.
+func validateDraft2020(sch *jsonschema.Schema, body []byte) ([]FieldError, error) {
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return []FieldError{invalidJSONError()}, nil
+	}
+
+	return validateInstance(sch, inst, nil)
+}
+
+// validateInstance validates a decoded value, found at the location in the body, against a compiled schema.
+func validateInstance(sch *jsonschema.Schema, inst any, location []string) ([]FieldError, error) {
+	err := sch.Validate(inst)
+	if err == nil {
+		return nil, nil
+	}
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, fmt.Errorf("json schema validate: %w", err)
+	}
+	if len(location) > 0 {
+		relocate(verr, location)
+	}
+
+	var errs []FieldError
+	collectDraft2020Errors(verr, &errs)
+
+	return errs, nil
+}
+
+// relocate prefixes the instance locations of the error and its causes with the location.
+func relocate(verr *jsonschema.ValidationError, location []string) {
+	verr.InstanceLocation = append(slices.Clone(location), verr.InstanceLocation...)
+	for _, cause := range verr.Causes {
+		relocate(cause, location)
+	}
+}
+
+func invalidJSONError() FieldError {
+	return FieldError{field: "(root)", kind: "invalid_json", Message: "body is not valid JSON"}
+}
+
+// newFieldError returns an error of the field, with the custom message of its kind if one is set.
+func newFieldError(field string, kind string, details map[string]interface{}, msg string) FieldError {
+	fe := FieldError{field: field, kind: kind, details: details, Message: msg}
+	if custom, ok := customMessage(fe); ok {
+		fe.Message = custom
+	}
+	return fe
+}
+
+// collectDraft2020Errors flattens the tree of errors to its leaves. The branches of anyOf and oneOf are reported as a
+// single error, like older drafts do.
+func collectDraft2020Errors(verr *jsonschema.ValidationError, errs *[]FieldError) {
+	switch verr.ErrorKind.(type) {
+	case *kind.AnyOf, *kind.OneOf:
+		*errs = append(*errs, draft2020FieldErrors(verr)...)
+		return
+	}
+
+	if len(verr.Causes) == 0 {
+		*errs = append(*errs, draft2020FieldErrors(verr)...)
+		return
+	}
+	for _, cause := range verr.Causes {
+		collectDraft2020Errors(cause, errs)
+	}
+}
+
+// draft2020FieldErrors converts an error to field errors, with the kinds and messages of older drafts.
+func draft2020FieldErrors(verr *jsonschema.ValidationError) []FieldError {
+	field := "(root)"
+	if len(verr.InstanceLocation) > 0 {
+		field = strings.Join(verr.InstanceLocation, ".")
+	}
+	newError := func(kind string, details map[string]interface{}, msg string) FieldError {
+		return newFieldError(field, kind, details, msg)
+	}
+
+	switch k := verr.ErrorKind.(type) {
+	case *kind.Required:
+		errs := make([]FieldError, 0, len(k.Missing))
+		for _, prop := range k.Missing {
+			errs = append(errs, newError("required", map[string]interface{}{"property": prop},
+				fmt.Sprintf("Param '%s' is missing", prop)))
+		}
+		return errs
+	case *kind.AdditionalProperties:
+		errs := make([]FieldError, 0, len(k.Properties))
+		for _, prop := range k.Properties {
+			errs = append(errs, newError("additional_property_not_allowed", map[string]interface{}{"property": prop},
+				fmt.Sprintf("Param '%s' doesn't allow key: %s", field, prop)))
+		}
+		return errs
+	case *kind.FalseSchema:
+		// unevaluatedProperties and unevaluatedItems report the values they reject as false schemas
+		return []FieldError{newError("false", map[string]interface{}{},
+			fmt.Sprintf("Param '%s' is not allowed", field))}
+	case *kind.Type:
+		expected := strings.Join(k.Want, ", ")
+		return []FieldError{newError("invalid_type", map[string]interface{}{"expected": expected, "given": k.Got},
+			fmt.Sprintf("Param '%s' should be of type %s", field, expected))}
+	case *kind.MinLength:
+		return []FieldError{newError("string_gte", map[string]interface{}{"min": k.Want},
+			fmt.Sprintf("Param '%s' is too short", field))}
+	case *kind.MaxLength:
+		return []FieldError{newError("string_lte", map[string]interface{}{"max": k.Want},
+			fmt.Sprintf("Param '%s' is too long", field))}
+	case *kind.MinItems:
+		return []FieldError{newError("array_min_items", map[string]interface{}{"min": k.Want},
+			fmt.Sprintf("Param '%s' must contain atleast %d items", field, k.Want))}
+	case *kind.MaxItems:
+		return []FieldError{newError("array_max_items", map[string]interface{}{"max": k.Want},
+			fmt.Sprintf("Param '%s' must contain at most %d items", field, k.Want))}
+	case *kind.Pattern:
+		return []FieldError{newError("pattern", map[string]interface{}{"pattern": k.Want},
+			fmt.Sprintf("Param '%s' should match pattern %s", field, k.Want))}
+	case *kind.Format:
+		return []FieldError{newError("format", map[string]interface{}{"format": k.Want},
+			fmt.Sprintf("Param '%s' should be a valid %s", field, formatDescription(k.Want)))}
+	default:
+		kw := "schema"
+		if path := verr.ErrorKind.KeywordPath(); len(path) > 0 {
+			kw = path[len(path)-1]
+		}
+		return []FieldError{newError(kw, map[string]interface{}{},
+			fmt.Sprintf("[%s]: %s", kw, verr.ErrorKind.LocalizedString(printer)))}
+	}
+}
+
+// decodeSchema decodes a schema for validation with the draft 2020-12 validator.
+func decodeSchema(schema []byte) (any, error) {
+	return jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+}
@@ -31,3 +31,16 @@ type Entity interface {
 	WithSchema
 	Serializable
 }
+
+// WithReflectedSchema is implemented by the entities that document the schema reflected from their struct instead of
+// the one returned by Schema(), which still validates the payloads. The entities without a Schema() are reflected too.
+// The validation tags of the fields, e.g. minLength, maximum, pattern and enum, end up as constraints of the schema.
+type WithReflectedSchema interface {
+	ReflectSchema() bool
+}
+
+// WithLocalizedDescription is implemented by the entities whose description, the one of their schema, is translated.
+// The descriptions are keyed by language, e.g. {"fr": "Un article du catalogue"}.
+type WithLocalizedDescription interface {
+	LocalizedDescriptions() map[string]string
+}
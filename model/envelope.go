@@ -0,0 +1,95 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+var _ Entity = (*Meta)(nil)
+
+// Meta is the default metadata block of an Envelope, a free-form object.
+type Meta map[string]any
+
+func (m *Meta) Name() string {
+	return "Meta"
+}
+
+func (m *Meta) Schema() []byte {
+	return []byte(`{"type": "object"}`)
+}
+
+func (m *Meta) Example() []byte {
+	return []byte(`{"request_id": "req_2f1c8a"}`)
+}
+
+func (m *Meta) Marshal() (json.RawMessage, error) {
+	return json.Marshal(m)
+}
+
+func (m *Meta) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, m)
+}
+
+// Envelope is a response envelope for an entity, with a free-form metadata block.
+type Envelope[T Entity] = EnvelopeWithMeta[T, *Meta]
+
+var (
+	_ Entity           = (*EnvelopeWithMeta[Nil, *Meta])(nil)
+	_ DerivedComponent = (*EnvelopeWithMeta[Nil, *Meta])(nil)
+)
+
+// EnvelopeWithMeta is a response envelope for an entity, with a metadata block of schema M, e.g. request ids or
+// deprecation notices. Its schema is composed from the schemas of T and M, which are included as definitions. It is
+// named after T, so an API should stick to one metadata schema per entity.
+type EnvelopeWithMeta[T Entity, M Entity] struct {
+	Data T `json:"data"`
+	Meta M `json:"meta,omitempty"`
+}
+
+func (e *EnvelopeWithMeta[T, M]) Name() string {
+	return New[T]().Name() + "Envelope"
+}
+
+func (e *EnvelopeWithMeta[T, M]) Schema() []byte {
+	data := New[T]()
+	meta := New[M]()
+
+	return []byte(fmt.Sprintf(`{
+		"type": "object",
+		"properties": {
+			"data": {"$ref": "#/definitions/%[1]s"},
+			"meta": {"$ref": "#/definitions/%[3]s"}
+		},
+		"required": ["data"],
+		"additionalProperties": false,
+		"definitions": {
+			"%[1]s": %[2]s,
+			"%[3]s": %[4]s
+		}
+	}`, data.Name(), data.Schema(), meta.Name(), meta.Schema()))
+}
+
+func (e *EnvelopeWithMeta[T, M]) Example() []byte {
+	return []byte(fmt.Sprintf(`{
+		"data": %s,
+		"meta": %s
+	}`, New[T]().Example(), New[M]().Example()))
+}
+
+func (e *EnvelopeWithMeta[T, M]) Marshal() (json.RawMessage, error) {
+	return json.Marshal(e)
+}
+
+func (e *EnvelopeWithMeta[T, M]) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, e)
+}
+
+// Unwrap returns the entity in the envelope, which is the resource of the routes responding with the envelope.
+func (e *EnvelopeWithMeta[T, M]) Unwrap() WithSchema {
+	return New[T]()
+}
+
+// KeepComponent documents the envelope with a component of its own, referring to the one of the entity.
+func (e *EnvelopeWithMeta[T, M]) KeepComponent() bool {
+	return true
+}
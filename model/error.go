@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/xeipuuv/gojsonschema"
 )
@@ -13,6 +14,7 @@ import (
 // FieldError is used to indicate an error with a specific request field.
 type FieldError struct {
 	field   string
+	kind    string
 	details map[string]interface{}
 	Message string `json:"message"`
 }
@@ -21,6 +23,11 @@ func (fe FieldError) Field() string {
 	return fe.field
 }
 
+// Kind is the type of the validation error, e.g. required or string_gte, used to customize its message.
+func (fe FieldError) Kind() string {
+	return fe.kind
+}
+
 func (fe FieldError) Details() map[string]interface{} {
 	return fe.details
 }
@@ -40,26 +47,75 @@ func (fe ValidationError) Error() string {
 	return string(d)
 }
 
-func ToValidationError(result *gojsonschema.Result) ValidationError {
+func ToValidationError(result *gojsonschema.Result, opts ...ValidationOption) ValidationError {
 	errs := make([]FieldError, 0, len(result.Errors()))
 	for _, res := range result.Errors() {
 		switch res.(type) {
 		case *gojsonschema.NumberAllOfError, *gojsonschema.NumberAnyOfError, *gojsonschema.NumberOneOfError:
 			continue
 		default:
-			errs = append(errs, FieldError{
+			fe := FieldError{
 				field:   res.Field(),
+				kind:    res.Type(),
 				details: res.Details(),
 				Message: newErrorMessage(res),
-			})
+			}
+			if msg, ok := customMessage(fe); ok {
+				fe.Message = msg
+			}
+			errs = append(errs, fe)
 		}
 	}
 
+	return newValidationError(errs, opts...)
+}
+
+// newValidationError applies the validation options to the errors found by the validator.
+func newValidationError(errs []FieldError, opts ...ValidationOption) ValidationError {
+	var config validationConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.dedupe {
+		errs = dedupeErrors(errs)
+	}
+	if config.failFast && len(errs) > 1 {
+		errs = errs[:1]
+	}
+
 	res := ValidationError{
 		Errors: errs,
 	}
 	SortErrors(&res)
 
+	if config.maxErrors > 0 && len(res.Errors) > config.maxErrors {
+		res.Errors = res.Errors[:config.maxErrors]
+	}
+
+	return res
+}
+
+// dedupeErrors drops the repeated errors, and the errors nested under a field with an invalid type.
+func dedupeErrors(errs []FieldError) []FieldError {
+	invalid := []string{}
+	for _, fe := range errs {
+		if fe.kind == "invalid_type" && fe.field != "(root)" {
+			invalid = append(invalid, fe.field+".")
+		}
+	}
+
+	seen := make(map[string]bool, len(errs))
+	res := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		key := fe.field + "\x00" + fe.Message
+		if seen[key] || slices.ContainsFunc(invalid, func(parent string) bool { return strings.HasPrefix(fe.field, parent) }) {
+			continue
+		}
+		seen[key] = true
+		res = append(res, fe)
+	}
+
 	return res
 }
 
@@ -93,7 +149,7 @@ func newErrorMessage(resErr gojsonschema.ResultError) string {
 	case *gojsonschema.DoesNotMatchPatternError:
 		return fmt.Sprintf("Param '%s' should match pattern %s", resErr.Field(), resErr.Details()["pattern"])
 	case *gojsonschema.DoesNotMatchFormatError:
-		return fmt.Sprintf("Param '%s' should be a valid %s", resErr.Field(), resErr.Details()["format"])
+		return fmt.Sprintf("Param '%s' should be a valid %s", resErr.Field(), formatDescription(fmt.Sprint(resErr.Details()["format"])))
 
 	// case *gojsonschema.FalseError:
 	// case *gojsonschema.InvalidTypeError:
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"maps"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// FormatChecker reports whether a string is valid for a custom format.
+type FormatChecker func(value string) bool
+
+// formatChecker adapts a FormatChecker to gojsonschema. Formats only apply to strings, so other values are valid.
+type formatChecker FormatChecker
+
+func (f formatChecker) IsFormat(input interface{}) bool {
+	s, ok := input.(string)
+	if !ok {
+		return true
+	}
+
+	return f(s)
+}
+
+var (
+	formatsMu          sync.RWMutex
+	formatDescriptions = map[string]string{}
+	formatCheckers     = map[string]FormatChecker{}
+)
+
+// RegisterFormat enforces a custom format, e.g. ulid or phone, in the schemas validated by Validate. The description
+// is used in the messages of the values that do not match, e.g. "Param 'phone' should be a valid E.164 phone number",
+// and defaults to the name of the format. Registering a format again replaces it, including the built-in ones.
+func RegisterFormat(name string, description string, check FormatChecker) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if description == "" {
+		description = name
+	}
+	formatDescriptions[name] = description
+	formatCheckers[name] = check
+	gojsonschema.FormatCheckers.Add(name, formatChecker(check))
+	// draft 2020-12 schemas are compiled with the formats
+	compiledSchemas.Clear()
+}
+
+// formatDescription describes a format in error messages.
+func formatDescription(name string) string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	if desc, ok := formatDescriptions[name]; ok {
+		return desc
+	}
+
+	return name
+}
+
+func registeredFormats() map[string]FormatChecker {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	return maps.Clone(formatCheckers)
+}
@@ -0,0 +1,338 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// LargeBodyThreshold validates the bodies larger than n bytes item by item, when the schema allows it: the items of a
+// top-level array, or of the arrays in the properties of a top-level object, are decoded and validated one at a time,
+// so the decoded body is never held in memory at once. The arrays must be plain lists of items, constrained only by
+// their type, items, minItems and maxItems, and the object must not be constrained beyond its properties, required and
+// additionalProperties. The other bodies are validated as usual. Zero or less validates all the bodies as usual. Like
+// the other options, it can be set for the whole API or per route, with WithValidationOptions.
+func LargeBodyThreshold(n int) ValidationOption {
+	return func(c *validationConfig) {
+		c.largeBody = n
+	}
+}
+
+// streamPlan validates the large bodies of a schema item by item, see LargeBodyThreshold.
+type streamPlan struct {
+	// root validates the object bodies without their streamed arrays, nil for array bodies.
+	root *jsonschema.Schema
+	// arrays are the streamed arrays by property, or by "" for a top-level array.
+	arrays map[string]*streamedArray
+}
+
+type streamedArray struct {
+	// schema validates the values that are not arrays, e.g. null.
+	schema   *jsonschema.Schema
+	items    *jsonschema.Schema
+	minItems int
+	maxItems int
+}
+
+// streamedArrayKeywords are the keywords of the arrays that can be validated item by item.
+var streamedArrayKeywords = map[string]bool{
+	"type": true, "items": true, "minItems": true, "maxItems": true,
+	"title": true, "description": true, "examples": true, "default": true, "$comment": true,
+	"readOnly": true, "writeOnly": true, "deprecated": true,
+}
+
+// streamedObjectKeywords are the keywords of the top-level objects whose arrays can be validated item by item.
+var streamedObjectKeywords = map[string]bool{
+	"type": true, "properties": true, "required": true, "additionalProperties": true,
+	"minProperties": true, "maxProperties": true,
+	"title": true, "description": true, "examples": true, "default": true, "$comment": true,
+	"$schema": true, "$id": true, "definitions": true, "$defs": true,
+}
+
+// newStreamPlan returns the plan to validate the large bodies of the schema, or nil if they can only be validated as a
+// whole.
+func newStreamPlan(doc any) (*streamPlan, error) {
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	draft := jsonschema.Draft7
+	if isDraft2020(doc) {
+		draft = jsonschema.Draft2020
+	}
+
+	// a top-level array
+	if arr, ok := streamableArray(root, true); ok {
+		c := newCompiler(draft)
+		if err := c.AddResource("schema.json", doc); err != nil {
+			return nil, fmt.Errorf("jsonschema.AddResource: %w", err)
+		}
+		sa, err := compileStreamedArray(c, "schema.json#", arr)
+		if err != nil {
+			return nil, err
+		}
+		return &streamPlan{arrays: map[string]*streamedArray{"": sa}}, nil
+	}
+
+	// the arrays in the properties of a top-level object
+	props, _ := root["properties"].(map[string]any)
+	for kw := range root {
+		if !streamedObjectKeywords[kw] {
+			return nil, nil
+		}
+	}
+	c := newCompiler(draft)
+	if err := c.AddResource("schema.json", doc); err != nil {
+		return nil, fmt.Errorf("jsonschema.AddResource: %w", err)
+	}
+
+	plan := &streamPlan{arrays: make(map[string]*streamedArray)}
+	relaxedProps := make(map[string]any, len(props))
+	for name, prop := range props {
+		relaxedProps[name] = prop
+		arr, ok := prop.(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, ok := streamableArray(arr, false); !ok {
+			continue
+		}
+		sa, err := compileStreamedArray(c, "schema.json#/properties/"+escapePointer(name), arr)
+		if err != nil {
+			return nil, err
+		}
+		plan.arrays[name] = sa
+		// the streamed arrays are validated on their own
+		relaxedProps[name] = true
+	}
+	if len(plan.arrays) == 0 {
+		return nil, nil
+	}
+
+	relaxed := make(map[string]any, len(root))
+	for kw, v := range root {
+		relaxed[kw] = v
+	}
+	relaxed["properties"] = relaxedProps
+	// a separate compiler, as the relaxed schema has the $id of the schema
+	rc := newCompiler(draft)
+	if err := rc.AddResource("schema.json", relaxed); err != nil {
+		return nil, fmt.Errorf("jsonschema.AddResource: %w", err)
+	}
+	var err error
+	if plan.root, err = rc.Compile("schema.json"); err != nil {
+		return nil, fmt.Errorf("jsonschema.Compile: %w", err)
+	}
+
+	return plan, nil
+}
+
+// streamableArray reports whether the schema is an array that can be validated item by item. The root schema may
+// also hold the definitions.
+func streamableArray(schema map[string]any, root bool) (map[string]any, bool) {
+	if _, ok := schema["items"].(map[string]any); !ok {
+		return nil, false
+	}
+	for kw := range schema {
+		if streamedArrayKeywords[kw] {
+			continue
+		}
+		if root && (kw == "$schema" || kw == "$id" || kw == "definitions" || kw == "$defs") {
+			continue
+		}
+		return nil, false
+	}
+
+	switch t := schema["type"].(type) {
+	case string:
+		return schema, t == "array"
+	case []any:
+		for _, v := range t {
+			if v == "array" {
+				return schema, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func compileStreamedArray(c *jsonschema.Compiler, loc string, schema map[string]any) (*streamedArray, error) {
+	sa := &streamedArray{minItems: -1, maxItems: -1}
+	var err error
+	if sa.schema, err = c.Compile(loc); err != nil {
+		return nil, fmt.Errorf("jsonschema.Compile: %w", err)
+	}
+	if sa.items, err = c.Compile(loc + "/items"); err != nil {
+		return nil, fmt.Errorf("jsonschema.Compile: %w", err)
+	}
+	if sa.minItems, err = intKeyword(schema, "minItems"); err != nil {
+		return nil, err
+	}
+	if sa.maxItems, err = intKeyword(schema, "maxItems"); err != nil {
+		return nil, err
+	}
+
+	return sa, nil
+}
+
+// intKeyword returns the value of an integer keyword, or -1 if it is missing.
+func intKeyword(schema map[string]any, kw string) (int, error) {
+	v, ok := schema[kw]
+	if !ok {
+		return -1, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%s must be an integer", kw)
+	}
+	i, err := strconv.Atoi(n.String())
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %w", kw, err)
+	}
+
+	return i, nil
+}
+
+func escapePointer(name string) string {
+	var buf bytes.Buffer
+	for _, r := range name {
+		switch r {
+		case '~':
+			buf.WriteString("~0")
+		case '/':
+			buf.WriteString("~1")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+
+	return buf.String()
+}
+
+// validate validates the body item by item. It reports false if the body cannot be validated this way, e.g. it is not
+// of the type of the schema or not valid JSON, so it is validated as a whole instead.
+func (p *streamPlan) validate(body []byte) ([]FieldError, bool, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	if p.root == nil {
+		if nextByte(body, dec) != '[' {
+			return nil, false, nil
+		}
+		errs, ok, err := p.arrays[""].validate(dec, nil)
+		if !ok || err != nil || !atEOF(dec) {
+			return nil, false, err
+		}
+		return errs, true, nil
+	}
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false, nil
+	}
+	var errs []FieldError
+	obj := make(map[string]any)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false, nil
+		}
+		name, _ := tok.(string)
+
+		if arr, ok := p.arrays[name]; ok && nextByte(body, dec) == '[' {
+			arrErrs, ok, err := arr.validate(dec, []string{name})
+			if !ok || err != nil {
+				return nil, false, err
+			}
+			errs = append(errs, arrErrs...)
+			obj[name] = []any{}
+			continue
+		}
+
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, false, nil
+		}
+		obj[name] = v
+		if arr, ok := p.arrays[name]; ok {
+			// the streamed arrays are relaxed in the root schema
+			valErrs, err := validateInstance(arr.schema, v, []string{name})
+			if err != nil {
+				return nil, false, err
+			}
+			errs = append(errs, valErrs...)
+		}
+	}
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('}') || !atEOF(dec) {
+		return nil, false, nil
+	}
+
+	rootErrs, err := validateInstance(p.root, obj, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return append(rootErrs, errs...), true, nil
+}
+
+// validate validates the items of the array the decoder is at one by one, and then their number.
+func (a *streamedArray) validate(dec *json.Decoder, location []string) ([]FieldError, bool, error) {
+	if _, err := dec.Token(); err != nil {
+		return nil, false, nil
+	}
+
+	var errs []FieldError
+	n := 0
+	for ; dec.More(); n++ {
+		var item any
+		if err := dec.Decode(&item); err != nil {
+			return nil, false, nil
+		}
+		itemErrs, err := validateInstance(a.items, item, append(location, strconv.Itoa(n)))
+		if err != nil {
+			return nil, false, err
+		}
+		errs = append(errs, itemErrs...)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, false, nil
+	}
+
+	field := "(root)"
+	if len(location) > 0 {
+		field = location[0]
+	}
+	if a.minItems >= 0 && n < a.minItems {
+		errs = append(errs, newFieldError(field, "array_min_items", map[string]interface{}{"min": a.minItems},
+			fmt.Sprintf("Param '%s' must contain atleast %d items", field, a.minItems)))
+	}
+	if a.maxItems >= 0 && n > a.maxItems {
+		errs = append(errs, newFieldError(field, "array_max_items", map[string]interface{}{"max": a.maxItems},
+			fmt.Sprintf("Param '%s' must contain at most %d items", field, a.maxItems)))
+	}
+
+	return errs, true, nil
+}
+
+// nextByte returns the first byte of the next value of the decoder, skipping the separators.
+func nextByte(body []byte, dec *json.Decoder) byte {
+	for i := int(dec.InputOffset()); i < len(body); i++ {
+		switch body[i] {
+		case ' ', '\t', '\n', '\r', ':', ',':
+			continue
+		default:
+			return body[i]
+		}
+	}
+
+	return 0
+}
+
+func atEOF(dec *json.Decoder) bool {
+	_, err := dec.Token()
+	return err == io.EOF
+}
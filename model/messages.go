@@ -0,0 +1,87 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Translator localizes the messages of validation errors. It reports false when it has no message for the language,
+// so the next preferred language, or the default message, is used.
+type Translator interface {
+	Translate(lang string, fe FieldError) (string, bool)
+}
+
+// TranslatorFunc adapts a function to the Translator interface.
+type TranslatorFunc func(lang string, fe FieldError) (string, bool)
+
+func (f TranslatorFunc) Translate(lang string, fe FieldError) (string, bool) {
+	return f(lang, fe)
+}
+
+// MessageTemplates is a Translator backed by templates, by language and by kind of error, e.g.
+// {"de": {"required": "Parameter '{property}' fehlt"}}. The {field} placeholder is replaced with the field of the
+// error, and any other placeholder with the detail of the same name.
+type MessageTemplates map[string]map[string]string
+
+func (mt MessageTemplates) Translate(lang string, fe FieldError) (string, bool) {
+	tmpl, ok := mt[lang][fe.Kind()]
+	if !ok {
+		return "", false
+	}
+
+	return expandMessage(tmpl, fe), true
+}
+
+func expandMessage(tmpl string, fe FieldError) string {
+	oldnew := []string{"{field}", fe.Field()}
+	for k, v := range fe.Details() {
+		oldnew = append(oldnew, "{"+k+"}", fmt.Sprint(v))
+	}
+
+	return strings.NewReplacer(oldnew...).Replace(tmpl)
+}
+
+var (
+	messagesMu sync.RWMutex
+	messages   = map[string]string{}
+)
+
+// SetErrorMessage overrides the default message of a kind of validation error, e.g. required, with a template that
+// is expanded like the ones of MessageTemplates.
+func SetErrorMessage(kind string, tmpl string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+
+	messages[kind] = tmpl
+}
+
+func customMessage(fe FieldError) (string, bool) {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+
+	tmpl, ok := messages[fe.Kind()]
+	if !ok {
+		return "", false
+	}
+
+	return expandMessage(tmpl, fe), true
+}
+
+// Translate returns a copy of the error with the messages localized in the first of the languages the translator
+// supports. Messages that cannot be translated are kept.
+func (fe ValidationError) Translate(t Translator, langs ...string) ValidationError {
+	res := ValidationError{Errors: make([]FieldError, len(fe.Errors))}
+	for i, e := range fe.Errors {
+		for _, lang := range langs {
+			if msg, ok := t.Translate(lang, e); ok {
+				e.Message = msg
+				break
+			}
+		}
+		res.Errors[i] = e
+	}
+	SortErrors(&res)
+
+	return res
+}
@@ -10,6 +10,14 @@ type DerivedType interface {
 	Unwrap() WithSchema
 }
 
+// DerivedComponent is implemented by the DerivedTypes that keep a component of their own in the docs, e.g. envelopes
+// or versioned aliases, instead of collapsing into the entity they wrap. Their schema refers to the wrapped entity,
+// e.g. with #/definitions/<Name>, which is registered and documented along with them.
+type DerivedComponent interface {
+	DerivedType
+	KeepComponent() bool
+}
+
 func New[T any]() T {
 	var t T
 	if reflect.TypeOf(t).Kind() == reflect.Ptr {
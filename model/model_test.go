@@ -1,6 +1,9 @@
 package model_test
 
 import (
+	"encoding/json"
+	"errors"
+	"regexp"
 	"testing"
 
 	"github.com/tailbits/mason/model"
@@ -20,3 +23,345 @@ func TestErrorsAreSorted(t *testing.T) {
 	want := []string{"aaa", "bbb"}
 	assert.DeepEqual(t, got, want)
 }
+
+func TestValidationErrorMessages(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"title": {"type": "string"}}, "required": ["title"]}`)
+
+	model.SetErrorMessage("required", "{property} is required")
+	defer model.SetErrorMessage("required", "Param '{property}' is missing")
+
+	var fe model.ValidationError
+	assert.Assert(t, errors.As(model.Validate(schema, []byte(`{}`)), &fe))
+	assert.Equal(t, "title is required", fe.Errors[0].Message)
+	assert.Equal(t, "required", fe.Errors[0].Kind())
+
+	translator := model.MessageTemplates{
+		"de": {"required": "{property} fehlt"},
+	}
+	assert.Equal(t, "title fehlt", fe.Translate(translator, "fr", "de").Errors[0].Message)
+	assert.Equal(t, "title is required", fe.Translate(translator, "fr").Errors[0].Message)
+}
+
+func TestValidationOptions(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"code": {"allOf": [{"type": "string"}, {"type": "string"}]}
+		}
+	}`)
+	body := []byte(`{"tags": [1, 2, 3], "code": 1}`)
+
+	count := func(opts ...model.ValidationOption) int {
+		var fe model.ValidationError
+		assert.Assert(t, errors.As(model.Validate(schema, body, opts...), &fe))
+		return len(fe.Errors)
+	}
+
+	assert.Equal(t, 5, count())
+	assert.Equal(t, 4, count(model.DedupeErrors(true)))
+	assert.Equal(t, 2, count(model.MaxErrors(2)))
+	assert.Equal(t, 1, count(model.FailFast(true)))
+}
+
+func TestValidationPartial(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string"},
+			"status": {"type": "string", "enum": ["open", "closed"]},
+			"owner": {
+				"type": "object",
+				"properties": {"id": {"type": "string"}},
+				"required": ["id"]
+			}
+		},
+		"required": ["title", "status"],
+		"additionalProperties": false
+	}`)
+
+	assert.ErrorContains(t, model.Validate(schema, []byte(`{"status": "open"}`)), "title")
+	assert.NilError(t, model.Validate(schema, []byte(`{"status": "open", "owner": {}}`), model.Partial(true)))
+	assert.ErrorContains(t, model.Validate(schema, []byte(`{"status": "done"}`), model.Partial(true)), "status")
+	assert.ErrorContains(t, model.Validate(schema, []byte(`{"title": 1}`), model.Partial(true)), "title")
+	assert.ErrorContains(t, model.Validate(schema, []byte(`{"extra": true}`), model.Partial(true)), "extra")
+}
+
+func TestRegisterFormat(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"slug": {"type": "string", "format": "slug"}}}`)
+	assert.NilError(t, model.Validate(schema, []byte(`{"slug": "Not A Slug"}`)))
+
+	model.RegisterFormat("slug", "lowercase slug", func(value string) bool {
+		return regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`).MatchString(value)
+	})
+
+	assert.NilError(t, model.Validate(schema, []byte(`{"slug": "a-slug"}`)))
+
+	var fe model.ValidationError
+	assert.Assert(t, errors.As(model.Validate(schema, []byte(`{"slug": "Not A Slug"}`)), &fe))
+	assert.Equal(t, "Param 'slug' should be a valid lowercase slug", fe.Errors[0].Message)
+}
+
+func TestValidateDraft2020(t *testing.T) {
+	schema := []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}]},
+			"owner": {"$ref": "#/$defs/Owner"}
+		},
+		"$defs": {
+			"Owner": {
+				"type": "object",
+				"properties": {"name": {"type": "string", "minLength": 2}},
+				"required": ["name"]
+			}
+		},
+		"unevaluatedProperties": false
+	}`)
+
+	assert.NilError(t, model.Validate(schema, []byte(`{"point": [1, 2], "owner": {"name": "ab"}}`)))
+
+	var fe model.ValidationError
+	assert.Assert(t, errors.As(model.Validate(schema, []byte(`{"point": ["a", 2], "owner": {"name": "a"}, "extra": 1}`)), &fe))
+
+	messages := []string{}
+	for _, e := range fe.Errors {
+		messages = append(messages, e.Message)
+	}
+	assert.DeepEqual(t, []string{
+		"Param 'extra' is not allowed",
+		"Param 'owner.name' is too short",
+		"Param 'point.0' should be of type number",
+	}, messages)
+
+	assert.Assert(t, errors.As(model.Validate(schema, []byte(`{"owner": {}}`)), &fe))
+	assert.Equal(t, "Param 'name' is missing", fe.Errors[0].Message)
+	assert.Equal(t, "required", fe.Errors[0].Kind())
+}
+
+func TestValidateDraft2020_PropertyNames(t *testing.T) {
+	// a property named like a draft 2020-12 keyword does not switch the draft-07 tuple items to draft 2020-12
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"prefixItems": {"type": "string"},
+			"point": {"type": "array", "items": [{"type": "number"}, {"type": "number"}]}
+		}
+	}`)
+
+	assert.NilError(t, model.Validate(schema, []byte(`{"prefixItems": "a", "point": [1, 2]}`)))
+
+	var fe model.ValidationError
+	assert.Assert(t, errors.As(model.Validate(schema, []byte(`{"point": ["a", 2]}`)), &fe))
+	assert.Equal(t, 1, len(fe.Errors))
+}
+
+type Invoice struct {
+	Number string `json:"number"`
+}
+
+func (i *Invoice) Name() string {
+	return "Invoice"
+}
+
+func (i *Invoice) Schema() []byte {
+	return []byte(`{"type": "object", "properties": {"number": {"type": "string"}}, "required": ["number"]}`)
+}
+
+func (i *Invoice) Example() []byte {
+	return []byte(`{"number": "INV-1"}`)
+}
+
+func (i *Invoice) Marshal() (json.RawMessage, error) {
+	return json.Marshal(i)
+}
+
+func (i *Invoice) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, i)
+}
+
+type Deprecation struct {
+	Sunset string `json:"sunset"`
+}
+
+func (d *Deprecation) Name() string {
+	return "Deprecation"
+}
+
+func (d *Deprecation) Schema() []byte {
+	return []byte(`{"type": "object", "properties": {"sunset": {"type": "string", "format": "date"}}, "required": ["sunset"], "additionalProperties": false}`)
+}
+
+func (d *Deprecation) Example() []byte {
+	return []byte(`{"sunset": "2027-01-01"}`)
+}
+
+func (d *Deprecation) Marshal() (json.RawMessage, error) {
+	return json.Marshal(d)
+}
+
+func (d *Deprecation) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, d)
+}
+
+func TestEnvelope(t *testing.T) {
+	env := &model.Envelope[*Invoice]{Data: &Invoice{Number: "INV-2"}}
+	assert.Equal(t, "InvoiceEnvelope", env.Name())
+	assert.NilError(t, model.Validate(env.Schema(), env.Example()))
+
+	data, err := env.Marshal()
+	assert.NilError(t, err)
+	assert.Equal(t, `{"data":{"number":"INV-2"}}`, string(data))
+	assert.NilError(t, model.Validate(env.Schema(), data))
+	assert.ErrorContains(t, model.Validate(env.Schema(), []byte(`{"meta": {}}`)), "")
+
+	var decoded model.Envelope[*Invoice]
+	assert.NilError(t, decoded.Unmarshal([]byte(`{"data": {"number": "INV-3"}, "meta": {"request_id": "a"}}`)))
+	assert.Equal(t, "INV-3", decoded.Data.Number)
+	assert.Equal(t, "a", (*decoded.Meta)["request_id"])
+	assert.Equal(t, "Invoice", decoded.Unwrap().Name())
+
+	deprecated := &model.EnvelopeWithMeta[*Invoice, *Deprecation]{}
+	assert.NilError(t, model.Validate(deprecated.Schema(), deprecated.Example()))
+	assert.ErrorContains(t, model.Validate(deprecated.Schema(), []byte(`{"data": {"number": "INV-1"}, "meta": {"request_id": "a"}}`)), "")
+}
+
+func TestValidateLargeBody(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"batch": {"type": "string"},
+			"items": {
+				"type": ["array", "null"],
+				"maxItems": 3,
+				"items": {"$ref": "#/definitions/Item"}
+			}
+		},
+		"required": ["batch", "items"],
+		"additionalProperties": false,
+		"definitions": {
+			"Item": {
+				"type": "object",
+				"properties": {"id": {"type": "integer"}, "name": {"type": "string", "minLength": 1}},
+				"required": ["id", "name"]
+			}
+		}
+	}`)
+
+	errs := func(body string, opts ...model.ValidationOption) []string {
+		err := model.Validate(schema, []byte(body), opts...)
+		if err == nil {
+			return nil
+		}
+		var fe model.ValidationError
+		assert.Assert(t, errors.As(err, &fe))
+		var got []string
+		for _, e := range fe.Errors {
+			got = append(got, e.Field()+":"+e.Kind())
+		}
+		return got
+	}
+	large := model.LargeBodyThreshold(1)
+
+	valid := `{"batch": "b1", "items": [{"id": 1, "name": "a"}, {"id": 2, "name": "b"}]}`
+	assert.Assert(t, errs(valid, large) == nil)
+
+	invalid := `{"items": [{"id": "1", "name": "a"}, {"id": 2}, {"id": 3, "name": ""}, {"id": 4, "name": "d"}], "extra": true}`
+	assert.DeepEqual(t, errs(invalid), errs(invalid, large))
+	assert.DeepEqual(t, []string{
+		"(root):additional_property_not_allowed",
+		"(root):required",
+		"items:array_max_items",
+		"items.0.id:invalid_type",
+		"items.2.name:string_gte",
+		"items.1:required",
+	}, errs(invalid, large))
+
+	assert.Assert(t, errs(`{"batch": "b1", "items": null}`, large) == nil)
+	assert.DeepEqual(t, errs(`{"batch": "b1", "items": 1}`), errs(`{"batch": "b1", "items": 1}`, large))
+	assert.DeepEqual(t, errs(`[1]`), errs(`[1]`, large))
+	// the invalid bodies are validated as a whole
+	broken := []byte(`{"batch": "b1", "items": [}`)
+	assert.Equal(t, model.Validate(schema, broken).Error(), model.Validate(schema, broken, large).Error())
+
+	items := []byte(`{"type": "array", "minItems": 2, "items": {"type": "integer"}}`)
+	fe := model.ValidationError{}
+	assert.Assert(t, errors.As(model.Validate(items, []byte(`["a"]`), large), &fe))
+	assert.Equal(t, 2, len(fe.Errors))
+	assert.NilError(t, model.Validate(items, []byte(`[1, 2, 3]`), large))
+}
+
+func TestRedact(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"password": {"type": "string", "minLength": 8, "x-sensitive": true},
+			"pin": {"type": "integer", "minimum": 1000, "x-sensitive": true},
+			"keys": {"type": "array", "items": {"$ref": "#/definitions/Key"}},
+			"card": {"allOf": [{"$ref": "#/definitions/Card"}]}
+		},
+		"definitions": {
+			"Key": {"type": "object", "properties": {"id": {"type": "string"}, "secret": {"type": "string", "x-sensitive": true}}},
+			"Card": {"type": "object", "x-sensitive": true}
+		}
+	}`)
+
+	redacted, err := model.Redact(schema, []byte(`{
+		"name": "ada",
+		"password": "hunter22",
+		"keys": [{"id": "k1", "secret": "s1"}, {"id": "k2"}],
+		"card": {"number": "4242"}
+	}`))
+	assert.NilError(t, err)
+	assert.Equal(t, `{"card":"[REDACTED]","keys":[{"id":"k1","secret":"[REDACTED]"},{"id":"k2"}],"name":"ada","password":"[REDACTED]"}`, string(redacted))
+
+	// the bodies without sensitive fields are returned as is
+	body := []byte(`{"name": "ada"}`)
+	redacted, err = model.Redact(schema, body)
+	assert.NilError(t, err)
+	assert.Equal(t, string(body), string(redacted))
+
+	// the errors of the sensitive fields do not echo their values
+	err = model.Validate(schema, []byte(`{"password": "short", "pin": 12}`))
+	var verr model.ValidationError
+	assert.Assert(t, errors.As(err, &verr))
+	messages := map[string]string{}
+	for _, fe := range verr.Errors {
+		messages[fe.Field()] = fe.Message
+	}
+	assert.Equal(t, "Param 'password' is too short", messages["password"])
+	assert.Equal(t, "Param 'pin' is invalid", messages["pin"])
+}
+
+func TestClassifications(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"x-data-classification": "internal",
+		"properties": {
+			"email": {"type": "string", "x-data-classification": "pii"},
+			"labels": {"type": "object", "additionalProperties": {"type": "string", "x-data-classification": "public"}},
+			"contacts": {"type": "array", "items": {"$ref": "#/definitions/Contact"}}
+		},
+		"definitions": {
+			"Contact": {
+				"type": "object",
+				"properties": {
+					"phone": {"type": "string", "x-data-classification": "pii"},
+					"referrer": {"$ref": "#/definitions/Contact"}
+				}
+			}
+		}
+	}`)
+
+	// the recursive $refs are only walked once
+	got, err := model.Classifications(schema)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[string]model.DataClassification{
+		"":                 model.ClassificationInternal,
+		"email":            model.ClassificationPII,
+		"labels.*":         model.ClassificationPublic,
+		"contacts[].phone": model.ClassificationPII,
+	}, got)
+}
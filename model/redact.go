@@ -0,0 +1,243 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SensitiveKeyword marks the fields of a schema whose values must not leak, e.g. "password": {"type": "string",
+// "x-sensitive": true}. Their values are replaced by Redact, and never echoed by the validation errors.
+const SensitiveKeyword = "x-sensitive"
+
+// Redacted replaces the values of the sensitive fields.
+const Redacted = "[REDACTED]"
+
+// Redact replaces the values of the sensitive fields of a JSON body with Redacted, e.g. before it is logged or
+// recorded. A sensitive object or array is replaced as a whole.
+func Redact(schema []byte, body []byte) ([]byte, error) {
+	root, err := parseRedactionSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return body, nil
+	}
+
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse the body: %w", err)
+	}
+
+	s := sensitiveSchema{root: root}
+	redacted, changed := s.redact([]map[string]any{root}, doc)
+	if !changed {
+		return body, nil
+	}
+
+	return json.Marshal(redacted)
+}
+
+// parseRedactionSchema parses the schema, and returns nil when none of its fields is sensitive.
+func parseRedactionSchema(schema []byte) (map[string]any, error) {
+	if !strings.Contains(string(schema), SensitiveKeyword) {
+		return nil, nil
+	}
+
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse the schema: %w", err)
+	}
+
+	return root, nil
+}
+
+// sensitiveSchema finds the subschemas of the fields of a body, following the local $refs and the schemas combined
+// with allOf, anyOf and oneOf.
+type sensitiveSchema struct {
+	root map[string]any
+}
+
+// redact returns the value with its sensitive fields redacted, and whether any was.
+func (s sensitiveSchema) redact(nodes []map[string]any, value any) (any, bool) {
+	if s.sensitive(nodes) {
+		return Redacted, true
+	}
+
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			redacted, ok := s.redact(s.properties(nodes, key), field)
+			if ok {
+				v[key], changed = redacted, true
+			}
+		}
+	case []any:
+		for i, item := range v {
+			redacted, ok := s.redact(s.items(nodes, i), item)
+			if ok {
+				v[i], changed = redacted, true
+			}
+		}
+	}
+
+	return value, changed
+}
+
+// sensitiveField reports whether a field of a validation error, e.g. users.0.password, is sensitive, or nested under
+// a sensitive field.
+func (s sensitiveSchema) sensitiveField(field string) bool {
+	nodes := []map[string]any{s.root}
+	if s.sensitive(nodes) {
+		return true
+	}
+	if field == "" || field == "(root)" {
+		return false
+	}
+
+	for _, segment := range strings.Split(field, ".") {
+		next := s.properties(nodes, segment)
+		if i, err := strconv.Atoi(segment); err == nil {
+			next = append(next, s.items(nodes, i)...)
+		}
+		if len(next) == 0 {
+			return false
+		}
+		if s.sensitive(next) {
+			return true
+		}
+		nodes = next
+	}
+
+	return false
+}
+
+func (s sensitiveSchema) sensitive(nodes []map[string]any) bool {
+	return slices.ContainsFunc(s.expand(nodes), func(node map[string]any) bool {
+		flag, _ := node[SensitiveKeyword].(bool)
+		return flag
+	})
+}
+
+// properties returns the subschemas of a property of the objects of the schemas.
+func (s sensitiveSchema) properties(nodes []map[string]any, key string) []map[string]any {
+	var res []map[string]any
+	for _, node := range s.expand(nodes) {
+		props, _ := node["properties"].(map[string]any)
+		if prop, ok := props[key].(map[string]any); ok {
+			res = append(res, prop)
+			continue
+		}
+		if additional, ok := node["additionalProperties"].(map[string]any); ok {
+			res = append(res, additional)
+		}
+	}
+
+	return res
+}
+
+// items returns the subschemas of an item of the arrays of the schemas.
+func (s sensitiveSchema) items(nodes []map[string]any, i int) []map[string]any {
+	var res []map[string]any
+	for _, node := range s.expand(nodes) {
+		prefix, _ := node["prefixItems"].([]any)
+		if tuple, ok := node["items"].([]any); ok {
+			prefix = tuple
+		}
+		if i < len(prefix) {
+			if item, ok := prefix[i].(map[string]any); ok {
+				res = append(res, item)
+			}
+			continue
+		}
+		if item, ok := node["items"].(map[string]any); ok {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
+
+// expand returns the schemas with the schemas they refer to or combine.
+func (s sensitiveSchema) expand(nodes []map[string]any) []map[string]any {
+	var res []map[string]any
+	var visit func(node map[string]any, depth int)
+	visit = func(node map[string]any, depth int) {
+		// guards against the $refs of a schema pointing at itself
+		if depth > 32 {
+			return
+		}
+		res = append(res, node)
+		if ref, ok := node["$ref"].(string); ok {
+			if target, ok := s.resolve(ref); ok {
+				visit(target, depth+1)
+			}
+		}
+		for _, kw := range []string{"allOf", "anyOf", "oneOf"} {
+			subs, _ := node[kw].([]any)
+			for _, sub := range subs {
+				if sub, ok := sub.(map[string]any); ok {
+					visit(sub, depth+1)
+				}
+			}
+		}
+	}
+	for _, node := range nodes {
+		visit(node, 0)
+	}
+
+	return res
+}
+
+// resolve resolves a local $ref, e.g. #/definitions/User.
+func (s sensitiveSchema) resolve(ref string) (map[string]any, bool) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, false
+	}
+
+	var node any = s.root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		node = obj[token]
+	}
+	target, ok := node.(map[string]any)
+
+	return target, ok
+}
+
+// safeKinds are the kinds of validation errors whose messages and details never include the value of the field.
+var safeKinds = []string{
+	"required", "invalid_type", "string_gte", "string_lte", "array_min_items", "array_max_items", "pattern", "format",
+	"additional_property_not_allowed", "false",
+}
+
+// redactErrors replaces the messages of the errors of the sensitive fields that could echo their values, e.g. of a
+// minimum or a const, with a generic one.
+func redactErrors(schema []byte, verr ValidationError) ValidationError {
+	root, err := parseRedactionSchema(schema)
+	if err != nil || root == nil {
+		return verr
+	}
+
+	s := sensitiveSchema{root: root}
+	for i, fe := range verr.Errors {
+		if slices.Contains(safeKinds, fe.kind) || !s.sensitiveField(fe.field) {
+			continue
+		}
+		verr.Errors[i].details = map[string]interface{}{}
+		verr.Errors[i].Message = fmt.Sprintf("Param '%s' is invalid", fe.field)
+	}
+
+	return verr
+}
@@ -0,0 +1,8 @@
+package model
+
+// SurrogateKeyedEntity is implemented by responses that know the surrogate keys they depend on, e.g. the IDs of the
+// resources of a list. The runtime adds them to the Surrogate-Key header of GET responses, so a CDN can purge the
+// cached responses of a resource when it changes.
+type SurrogateKeyedEntity interface {
+	SurrogateKeys() []string
+}
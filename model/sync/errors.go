@@ -63,3 +63,45 @@ type RequiredPropertyError struct {
 func (e *RequiredPropertyError) Error() string {
 	return fmt.Sprintf("schema is missing required property (alternatively mark the field with omitempty) %s", e.Property)
 }
+
+type MissingColumnError struct {
+	Property string
+	Column   string
+}
+
+func (e *MissingColumnError) Error() string {
+	return fmt.Sprintf("table is missing column %s for property %s", e.Column, e.Property)
+}
+
+type AdditionalColumnError struct {
+	Column string
+}
+
+func (e *AdditionalColumnError) Error() string {
+	return fmt.Sprintf("table has column %s, which is not a property of the schema", e.Column)
+}
+
+type ColumnTypeError struct {
+	Property   string
+	Column     string
+	ColumnType string
+	Expected   string
+}
+
+func (e *ColumnTypeError) Error() string {
+	return fmt.Sprintf("column %s is %s when the schema of %s expects %s", e.Column, e.ColumnType, e.Property, e.Expected)
+}
+
+type NullableColumnError struct {
+	Property string
+	Column   string
+	// Nullable is the nullability of the column.
+	Nullable bool
+}
+
+func (e *NullableColumnError) Error() string {
+	if e.Nullable {
+		return fmt.Sprintf("column %s is nullable when property %s is not", e.Column, e.Property)
+	}
+	return fmt.Sprintf("property %s is nullable when column %s is not", e.Property, e.Column)
+}
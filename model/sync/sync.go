@@ -95,10 +95,13 @@ func (v *Validator) getType(sch *jsonschema.Schema) (t string, nullable bool, er
 func (v *Validator) ensureDereference(sch *jsonschema.Schema) (*jsonschema.Schema, bool, error) {
 	if sch.Ref != nil {
 		defPrefix := "#/definitions/"
-		if !strings.HasPrefix(*sch.Ref, defPrefix) {
-			return nil, false, fmt.Errorf("references must be prefixed with %s", defPrefix)
+		key, ok := strings.CutPrefix(*sch.Ref, defPrefix)
+		if !ok {
+			// dereferenced schemas keep draft 2020-12 $defs with the definitions
+			if key, ok = strings.CutPrefix(*sch.Ref, "#/$defs/"); !ok {
+				return nil, false, fmt.Errorf("references must be prefixed with %s", defPrefix)
+			}
 		}
-		key := strings.TrimPrefix(*sch.Ref, defPrefix)
 		ref, ok := v.Sch.Definitions[key]
 		if !ok || ref.TypeObject == nil {
 			return nil, false, fmt.Errorf("could not find reference %s", *sch.Ref)
@@ -381,6 +384,17 @@ func (v *Validator) traverse(sch *jsonschema.Schema, val reflect.Value, omitEmpt
 				return err
 			}
 		}
+
+		// draft 2020-12 tuples: every position must fit the element type of the slice
+		if prefixItems, ok := sch.ExtraProperties["prefixItems"]; ok {
+			items, err := decodeSchemas(prefixItems)
+			if err != nil {
+				return fmt.Errorf("%s: invalid prefixItems: %w", breadcrumbs, err)
+			}
+			if err := v.checkArray(items, elementVal, breadcrumbs); err != nil {
+				return err
+			}
+		}
 	default:
 		t, _, _ := v.getType(sch)
 		return fmt.Errorf("%s: unknown type %s", breadcrumbs, t)
@@ -388,6 +402,21 @@ func (v *Validator) traverse(sch *jsonschema.Schema, val reflect.Value, omitEmpt
 	return nil
 }
 
+// decodeSchemas decodes a list of subschemas kept in the extra properties of a schema.
+func decodeSchemas(raw interface{}) ([]jsonschema.SchemaOrBool, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []jsonschema.SchemaOrBool
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		return nil, err
+	}
+
+	return schemas, nil
+}
+
 func new(name string, model any, sch []byte) (*Validator, error) {
 	parsed := jsonschema.Schema{} // nolint:golint,exhaustruct
 	err := parsed.UnmarshalJSON(sch)
@@ -135,3 +135,149 @@ func TestSchemaSync(t *testing.T) {
 	}
 
 }
+
+var _ model.Entity = (*Draft2020Model)(nil)
+
+type Draft2020Model struct {
+	Owner  *Draft2020Owner `json:"owner"`
+	Scores []int           `json:"scores"`
+}
+
+type Draft2020Owner struct {
+	Name string `json:"name"`
+}
+
+func (t *Draft2020Model) Example() []byte {
+	return []byte(`{"owner": {"name": "a"}, "scores": [1, 2]}`)
+}
+
+func (t *Draft2020Model) Marshal() (json.RawMessage, error) {
+	return json.Marshal(t)
+}
+
+func (t *Draft2020Model) Name() string {
+	return "Draft2020Model"
+}
+
+func (t *Draft2020Model) Schema() []byte {
+	return []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"owner": {"oneOf": [{"type": "null"}, {"$ref": "#/$defs/Owner"}]},
+			"scores": {"type": "array", "prefixItems": [{"type": "integer"}, {"type": "integer"}]}
+		},
+		"$defs": {
+			"Owner": {"type": "object", "properties": {"name": {"type": "string"}}}
+		}
+	}`)
+}
+
+func (t *Draft2020Model) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, t)
+}
+
+func TestSchemaSync_Draft2020(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	validator, err := sync.New(api, &Draft2020Model{})
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	if err := validator.IsSynced(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	validator.Sch.ExtraProperties = nil
+	if err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"owner": {"oneOf": [{"type": "null"}, {"$ref": "#/definitions/Owner"}]},
+			"scores": {"type": "array", "prefixItems": [{"type": "string"}]}
+		}
+	}`), validator.Sch); err != nil {
+		t.Fatalf("failed to unmarshal schema: %v", err)
+	}
+
+	var typeErr *sync.SchemaTypeError
+	if err := validator.IsSynced(); !errors.As(err, &typeErr) {
+		t.Fatalf("expected a schema type error, got %v", err)
+	}
+}
+
+type column struct {
+	name     string
+	typ      string
+	nullable bool
+}
+
+func (c column) ColumnName() string { return c.name }
+func (c column) ColumnType() string { return c.typ }
+func (c column) IsNullable() bool   { return c.nullable }
+
+func TestTableSync(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	schema := []byte(`{"type":"object","properties":{"count":{"type":["integer", "null"]},"discarded_at":{"type":["string", "null"],"format":"date-time"},"omittable":{"type": "string"}},"required":["count","discarded_at"]}`)
+
+	newValidator := func(t *testing.T, columns ...sync.Column) *sync.TableValidator {
+		validator, err := sync.NewTableValidator(api, &TestModel{}, columns, "tenant_id")
+		if err != nil {
+			t.Fatalf("failed to create validator: %v", err)
+		}
+		validator.Sch = &jsonschema.Schema{}
+		if err := json.Unmarshal(schema, validator.Sch); err != nil {
+			t.Fatalf("failed to unmarshal schema: %v", err)
+		}
+		return validator
+	}
+
+	t.Run("synced", func(t *testing.T) {
+		validator := newValidator(t,
+			column{"count", "bigint", true},
+			column{"discarded_at", "timestamp with time zone", true},
+			column{"omittable", "varchar(255)", false},
+			column{"tenant_id", "uuid", false},
+		)
+		if err := validator.IsSynced(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		validator := newValidator(t,
+			column{"count", "text", true},
+			column{"discarded_at", "timestamptz", false},
+			column{"legacy", "jsonb", true},
+		)
+		err := validator.IsSynced()
+
+		var missing *sync.MissingColumnError
+		if !errors.As(err, &missing) || missing.Property != "omittable" {
+			t.Fatalf("expected a missing column error for omittable, got %v", err)
+		}
+		var additional *sync.AdditionalColumnError
+		if !errors.As(err, &additional) || additional.Column != "legacy" {
+			t.Fatalf("expected an additional column error for legacy, got %v", err)
+		}
+		var typeErr *sync.ColumnTypeError
+		if !errors.As(err, &typeErr) || typeErr.Column != "count" || typeErr.Expected != "integer" {
+			t.Fatalf("expected a column type error for count, got %v", err)
+		}
+		var nullableErr *sync.NullableColumnError
+		if !errors.As(err, &nullableErr) || nullableErr.Column != "discarded_at" || nullableErr.Nullable {
+			t.Fatalf("expected a nullable column error for discarded_at, got %v", err)
+		}
+	})
+
+	t.Run("column names", func(t *testing.T) {
+		validator := newValidator(t,
+			column{"c_count", "integer", true},
+			column{"c_discarded_at", "timestamp", true},
+			column{"c_omittable", "text", false},
+		)
+		validator.ColumnName = func(property string) string { return "c_" + property }
+		if err := validator.IsSynced(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package sync
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+)
+
+// Column describes a column of a database table, e.g. read from information_schema.columns or from the migrations.
+type Column interface {
+	ColumnName() string
+	// ColumnType is the SQL type of the column, e.g. varchar(255), bigint, timestamptz or text[].
+	ColumnType() string
+	IsNullable() bool
+}
+
+// TableValidator compares the schema of an entity with the columns of the table it is stored in, to catch the drift
+// between the API models and the storage. Like the Validator of the structs, it only checks the top-level properties.
+type TableValidator struct {
+	*Validator
+	Columns []Column
+	// Ignored are the names of the columns and properties that are only on one side, e.g. tenant_id.
+	Ignored map[string]bool
+	// ColumnName maps a property to the name of its column, the property name by default.
+	ColumnName func(property string) string
+}
+
+// NewTableValidator creates a TableValidator for the entity and the columns of its table. The columns or properties
+// that are only on one side are listed in ignored.
+func NewTableValidator(api *mason.API, ent model.Entity, columns []Column, ignored ...string) (*TableValidator, error) {
+	v, err := New(api, ent)
+	if err != nil {
+		return nil, err
+	}
+
+	tv := &TableValidator{
+		Validator:  v,
+		Columns:    columns,
+		Ignored:    make(map[string]bool, len(ignored)),
+		ColumnName: func(property string) string { return property },
+	}
+	for _, name := range ignored {
+		tv.Ignored[name] = true
+	}
+
+	return tv, nil
+}
+
+// IsSynced reports every difference between the schema and the columns at once:
+//   - properties without a column, as MissingColumnErrors,
+//   - columns without a property, as AdditionalColumnErrors,
+//   - columns whose SQL type does not fit the type of the property, as ColumnTypeErrors,
+//   - nullable columns of properties that are not nullable, and the other way around, as NullableColumnErrors.
+func (v *TableValidator) IsSynced() error {
+	columns := make(map[string]Column, len(v.Columns))
+	for _, col := range v.Columns {
+		columns[col.ColumnName()] = col
+	}
+
+	props := make([]string, 0, len(v.Sch.Properties))
+	for prop := range v.Sch.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	var errs []error
+	mapped := make(map[string]bool, len(props))
+	for _, prop := range props {
+		name := v.ColumnName(prop)
+		mapped[name] = true
+		col, ok := columns[name]
+		if !ok {
+			if !v.Ignored[prop] && !v.Ignored[name] {
+				errs = append(errs, &MissingColumnError{Property: prop, Column: name})
+			}
+			continue
+		}
+
+		sch := v.Sch.Properties[prop].TypeObject
+		if sch == nil {
+			continue
+		}
+		sch, nullableRef, err := v.ensureDereference(sch)
+		if err != nil {
+			errs = append(errs, &ValidationError{Breadcrumbs: v.Name + "." + prop, Err: err})
+			continue
+		}
+		if sch.Type == nil {
+			// the property can hold any value
+			continue
+		}
+		t, nullableType, err := v.getType(sch)
+		if err != nil {
+			errs = append(errs, &ValidationError{Breadcrumbs: v.Name + "." + prop, Err: err})
+			continue
+		}
+
+		if expected := jsonType(col.ColumnType()); expected != "" && expected != t && !(expected == "number" && t == "integer") {
+			errs = append(errs, &ColumnTypeError{Property: prop, Column: name, ColumnType: col.ColumnType(), Expected: t})
+		}
+		if nullable := nullableRef || nullableType; nullable != col.IsNullable() {
+			errs = append(errs, &NullableColumnError{Property: prop, Column: name, Nullable: col.IsNullable()})
+		}
+	}
+
+	for _, col := range v.Columns {
+		if !mapped[col.ColumnName()] && !v.Ignored[col.ColumnName()] {
+			errs = append(errs, &AdditionalColumnError{Column: col.ColumnName()})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// jsonType returns the JSON Schema type of the values of a SQL type, or "" for the types that can hold any value, e.g.
+// json, or that are unknown.
+func jsonType(sqlType string) string {
+	t := strings.ToLower(strings.TrimSpace(sqlType))
+	if strings.HasSuffix(t, "[]") || strings.HasPrefix(t, "_") || strings.HasPrefix(t, "array") {
+		return "array"
+	}
+	if i := strings.IndexAny(t, "( "); i >= 0 && !strings.HasPrefix(t, "double") && !strings.HasPrefix(t, "character") && !strings.HasPrefix(t, "timestamp") && !strings.HasPrefix(t, "time ") {
+		t = t[:i]
+	}
+
+	switch {
+	case t == "json" || t == "jsonb":
+		return ""
+	case t == "boolean" || t == "bool":
+		return "boolean"
+	case slices.Contains([]string{"int", "integer", "smallint", "bigint", "tinyint", "mediumint", "int2", "int4", "int8"}, t) ||
+		strings.HasSuffix(t, "serial"):
+		return "integer"
+	case t == "numeric" || t == "decimal" || t == "real" || strings.HasPrefix(t, "double") || strings.HasPrefix(t, "float"):
+		return "number"
+	case strings.Contains(t, "char") || strings.Contains(t, "text") || strings.HasPrefix(t, "timestamp") ||
+		strings.HasPrefix(t, "time") || t == "date" || t == "uuid" || t == "bytea" || strings.Contains(t, "blob") ||
+		t == "inet" || t == "cidr" || t == "interval":
+		return "string"
+	}
+
+	return ""
+}
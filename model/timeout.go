@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+var _ Entity = (*TimeoutError)(nil)
+
+// TimeoutError is the response of a route that did not complete within its timeout.
+type TimeoutError struct {
+	Message string `json:"message"`
+	// Timeout is the timeout of the route, e.g. 5s.
+	Timeout string `json:"timeout"`
+}
+
+func (e *TimeoutError) Name() string {
+	return "TimeoutError"
+}
+
+func (e *TimeoutError) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"message": {"type": "string"},
+			"timeout": {"type": "string"}
+		},
+		"required": ["message", "timeout"],
+		"additionalProperties": false
+	}`)
+}
+
+func (e *TimeoutError) Example() []byte {
+	return []byte(`{
+		"message": "The request did not complete in time",
+		"timeout": "5s"
+	}`)
+}
+
+func (e *TimeoutError) Marshal() (json.RawMessage, error) {
+	return json.Marshal(e)
+}
+
+func (e *TimeoutError) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, e)
+}
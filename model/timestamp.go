@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// TimestampedEntity is implemented by responses that know when they last changed, e.g. a collection with the time of
+// its latest update. The runtime uses it to set the Last-Modified header of GET responses, and to answer the requests
+// with an If-Modified-Since that is not older with a 304.
+type TimestampedEntity interface {
+	LastModified() time.Time
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -11,7 +12,7 @@ import (
 var ErrBodyEmpty = errors.New("body empty")
 
 // Validate validates the provided model against it's declared tags.
-func Validate(schema []byte, body []byte) error {
+func Validate(schema []byte, body []byte, opts ...ValidationOption) error {
 	if len(body) == 0 {
 		return fmt.Errorf(
 			"validateBodySchema: %w %w",
@@ -20,20 +21,128 @@ func Validate(schema []byte, body []byte) error {
 		)
 	}
 
-	doc := gojsonschema.NewBytesLoader(schema)
-	sch, err := gojsonschema.NewSchema(doc)
+	var config validationConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.partial {
+		var err error
+		if schema, err = withoutRequired(schema); err != nil {
+			return fmt.Errorf("withoutRequired: %w", err)
+		}
+	}
+
+	sch, err := compile(schema)
 	if err != nil {
-		return fmt.Errorf("gojsonschema.NewSchema: [%s] %w", doc, err)
+		return err
+	}
+	if config.largeBody > 0 && len(body) > config.largeBody {
+		plan, err := sch.streamPlan()
+		if err != nil {
+			return err
+		}
+		if plan != nil {
+			errs, ok, err := plan.validate(body)
+			if err != nil {
+				return err
+			}
+			if ok && len(errs) > 0 {
+				return redactErrors(schema, newValidationError(errs, opts...))
+			}
+			if ok {
+				return nil
+			}
+		}
+	}
+	if sch.draft2020 != nil {
+		errs, err := validateDraft2020(sch.draft2020, body)
+		if err != nil {
+			return err
+		}
+		if len(errs) > 0 {
+			return redactErrors(schema, newValidationError(errs, opts...))
+		}
+		return nil
 	}
 
-	res, err := sch.Validate(gojsonschema.NewBytesLoader(body))
+	res, err := sch.legacy.Validate(gojsonschema.NewBytesLoader(body))
 	if err != nil {
 		return fmt.Errorf("json schema validate: %w", err)
 	}
 
 	if !res.Valid() {
-		return ToValidationError(res)
+		return redactErrors(schema, ToValidationError(res, opts...))
 	}
 
 	return nil
 }
+
+type validationConfig struct {
+	maxErrors int
+	failFast  bool
+	dedupe    bool
+	partial   bool
+	largeBody int
+}
+
+// ValidationOption configures the errors reported by Validate.
+type ValidationOption func(*validationConfig)
+
+// MaxErrors caps the number of reported errors. Zero or less reports all of them.
+func MaxErrors(n int) ValidationOption {
+	return func(c *validationConfig) {
+		c.maxErrors = n
+	}
+}
+
+// FailFast reports only the first error found.
+func FailFast(enabled bool) ValidationOption {
+	return func(c *validationConfig) {
+		c.failFast = enabled
+	}
+}
+
+// DedupeErrors drops repeated errors, and the errors nested under a field that is already of the wrong type, which
+// only cascade from it.
+func DedupeErrors(enabled bool) ValidationOption {
+	return func(c *validationConfig) {
+		c.dedupe = enabled
+	}
+}
+
+// Partial treats all the required fields as optional, e.g. for merge-patch updates, while still enforcing the rest of
+// the schema, like types, enums and additionalProperties.
+func Partial(enabled bool) ValidationOption {
+	return func(c *validationConfig) {
+		c.partial = enabled
+	}
+}
+
+// withoutRequired removes the required keywords of a schema, including its subschemas and definitions.
+func withoutRequired(schema []byte) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		return nil, err
+	}
+
+	var strip func(node interface{})
+	strip = func(node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			// a property named required is an object, the keyword is an array
+			if _, ok := n["required"].([]interface{}); ok {
+				delete(n, "required")
+			}
+			for _, v := range n {
+				strip(v)
+			}
+		case []interface{}:
+			for _, v := range n {
+				strip(v)
+			}
+		}
+	}
+	strip(doc)
+
+	return json.Marshal(doc)
+}
@@ -0,0 +1,46 @@
+package model
+
+// The keywords whose values are subschemas, lists of subschemas, and maps of names to subschemas. The other keywords,
+// e.g. properties names, examples, default, enum or const, hold data and are not walked.
+var (
+	subschemaKeywords = []string{
+		"additionalProperties", "additionalItems", "items", "contains", "propertyNames", "not", "if", "then", "else",
+		"unevaluatedProperties", "unevaluatedItems", "contentSchema",
+	}
+	subschemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+	subschemaMapKeywords  = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas", "dependencies"}
+)
+
+// WalkSchema calls fn with the schema and each of its subschemas, including its definitions. Only the keywords holding
+// subschemas are walked, so a property named like a keyword, or an example, is not mistaken for a schema.
+func WalkSchema(schema any, fn func(schema map[string]any)) {
+	obj, ok := schema.(map[string]any)
+	if !ok {
+		return
+	}
+	fn(obj)
+
+	for _, kw := range subschemaKeywords {
+		switch sub := obj[kw].(type) {
+		case map[string]any:
+			WalkSchema(sub, fn)
+		case []any:
+			// items is a list of schemas before draft 2020-12
+			for _, s := range sub {
+				WalkSchema(s, fn)
+			}
+		}
+	}
+	for _, kw := range subschemaListKeywords {
+		subs, _ := obj[kw].([]any)
+		for _, s := range subs {
+			WalkSchema(s, fn)
+		}
+	}
+	for _, kw := range subschemaMapKeywords {
+		subs, _ := obj[kw].(map[string]any)
+		for _, s := range subs {
+			WalkSchema(s, fn)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package mason
+
+import "github.com/tailbits/mason/internal/casing"
+
+// NamingStrategy transforms a name, e.g. to match the naming conventions of an organization.
+type NamingStrategy func(string) string
+
+var (
+	KebabCase  NamingStrategy = casing.ToKebabCase
+	SnakeCase  NamingStrategy = casing.ToSnakeCase
+	CamelCase  NamingStrategy = casing.ToCamelCase
+	PascalCase NamingStrategy = casing.ToPascalCase
+	// KeepCase leaves names as they are.
+	KeepCase NamingStrategy = func(s string) string { return s }
+)
+
+// Naming holds the naming strategies of an API. Nil strategies fall back to the defaults: kebab-case group paths,
+// and tags and component names as they are declared.
+type Naming struct {
+	// Groups applies to each segment of the route group paths.
+	Groups NamingStrategy
+	// Tags applies to the operation tags in the generated spec.
+	Tags NamingStrategy
+	// Components applies to the names of the entity schemas in the generated spec, and the refs pointing to them.
+	Components NamingStrategy
+}
+
+func (n Naming) withDefaults() Naming {
+	if n.Groups == nil {
+		n.Groups = KebabCase
+	}
+	if n.Tags == nil {
+		n.Tags = KeepCase
+	}
+	if n.Components == nil {
+		n.Components = KeepCase
+	}
+	return n
+}
+
+// WithNaming sets the naming strategies of the API. Group paths are computed when routes are registered, so it must
+// be called before registering any route.
+func (a *API) WithNaming(n Naming) *API {
+	a.naming = n.withDefaults()
+	return a
+}
+
+// Naming returns the naming strategies of the API.
+func (a *API) Naming() Naming {
+	return a.naming
+}
@@ -0,0 +1,56 @@
+package mason_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestHandleNoContent(t *testing.T) {
+	var archived []string
+	archiveItem := func(ctx context.Context, r *http.Request, in *Item, params model.Nil) error {
+		if in.Title == "locked" {
+			return errors.New("item is locked")
+		}
+		archived = append(archived, in.Title)
+		return nil
+	}
+	deleteItem := func(ctx context.Context, r *http.Request, in model.Nil, params model.Nil) error {
+		return nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandlePostNoContent(archiveItem).Path("/items/archive").WithOpID("archive_item"))
+	grp.Register(mason.HandleDeleteNoContent(deleteItem).Path("/items/{id}").WithOpID("delete_item").WithSuccessCode(http.StatusAccepted))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items/archive", strings.NewReader(`{"title": "done"}`)))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, 0, rec.Body.Len())
+	assert.Equal(t, "", rec.Header().Get("Content-Type"))
+	assert.DeepEqual(t, []string{"done"}, archived)
+
+	rec = httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items/archive", strings.NewReader(`{"title": "locked"}`)))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec = httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/items/1", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, 0, rec.Body.Len())
+
+	registry := api.Registry()
+	op, ok := registry.FindOp(http.MethodPost, "/items/archive")
+	assert.Assert(t, ok)
+	assert.Assert(t, op.NoContent)
+	assert.Equal(t, http.StatusNoContent, op.SuccessCode)
+}
@@ -0,0 +1,234 @@
+package openapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/tailbits/mason"
+)
+
+// Invalidate collects the operations of the API again, e.g. after registering more routes. The next call to Schema
+// regenerates the spec only if they changed.
+func (g *Generator) Invalidate() error {
+	fresh, err := g.recollect()
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.records = fresh.records
+	g.webhooks = fresh.webhooks
+	g.config = fresh.config
+
+	return nil
+}
+
+// recollect returns a generator with the current records, combining the sources again for combined generators.
+func (g *Generator) recollect() (*Generator, error) {
+	if len(g.sources) == 0 {
+		return NewGenerator(g.api, g.opts...)
+	}
+
+	for _, source := range g.sources {
+		if err := source.Invalidate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return Combine(g.sources...)
+}
+
+// cachedSchema returns the spec generated last if the records and the config it was generated from did not change
+// since, or generates it again.
+func (g *Generator) cachedSchema(generate func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.applyHeader()
+	hash, err := g.hash()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash records: %w", err)
+	}
+	if g.schema != nil && hash == g.schemaHash {
+		return bytes.Clone(g.schema), nil
+	}
+	if g.schema != nil {
+		// the reflector already holds the operations of the previous spec
+		g.Reflector = g.resetReflector()
+	}
+
+	schema, err := generate()
+	if err != nil {
+		return nil, err
+	}
+	g.schema, g.schemaHash = schema, hash
+
+	return bytes.Clone(schema), nil
+}
+
+// resetReflector returns an empty reflector that keeps the header of the spec, e.g. the info customized by the caller.
+func (g *Generator) resetReflector() *Reflector {
+	r := newReflector(g.config)
+	r.Spec.Info = g.Spec.Info
+	r.Spec.Servers = g.Spec.Servers
+	r.Spec.ExternalDocs = g.Spec.ExternalDocs
+	r.Spec.Security = g.Spec.Security
+	r.Spec.JSONSchemaDialect = g.Spec.JSONSchemaDialect
+	r.Spec.MapOfAnything = g.Spec.MapOfAnything
+
+	return r
+}
+
+// hash is the content hash of what the spec is generated from: the header of the spec, the config, the records and
+// the webhooks.
+func (g *Generator) hash() (string, error) {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+
+	spec := g.Spec
+	header := []any{spec.Info, spec.Servers, spec.ExternalDocs, spec.Security, spec.JSONSchemaDialect, spec.MapOfAnything, g.config.validate, g.config.allTags}
+	if err := enc.Encode(header); err != nil {
+		return "", err
+	}
+	for _, record := range g.records {
+		if err := enc.Encode(newRecordKey(record)); err != nil {
+			return "", fmt.Errorf("%s %s: %w", record.Method, record.Path, err)
+		}
+	}
+	for _, webhook := range g.webhooks {
+		key := []any{webhook.Name, webhook.Summary, webhook.Description, newModelKey(webhook.Payload)}
+		if err := enc.Encode(key); err != nil {
+			return "", fmt.Errorf("webhook %s: %w", webhook.Name, err)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recordKey is the hashable content of a record, with the models replaced by their schemas.
+type recordKey struct {
+	Input           *modelKey
+	Output          modelKey
+	ID              string
+	Method          string
+	Path            string
+	Description     string
+	Summary         string
+	SuccessStatus   int
+	Tags            []string
+	QueryParams     string
+	Extensions      map[string]interface{}
+	PathSummary     string
+	PathDescription string
+	FieldSelection  bool
+	NoContent       bool
+	Cache           *mason.CachePolicy
+	Content         *mason.ContentPolicy
+	PathParams      map[string]mason.PathParam
+	Timeout         time.Duration
+	TimeoutStatus   int
+	ResponseDescs   map[int]string
+	Errors          []errorKey
+	Samples         []mason.Sample
+	EntityExamples  bool
+	InputSuffix     string
+	Representations map[string]*modelKey
+	Visibility      mason.OperationVisibility
+	FeatureFlags    []string
+	Rollout         string
+	Translations    map[string]mason.Translation
+	Deprecated      bool
+	Owner           string
+	SignedURL       bool
+}
+
+type modelKey struct {
+	Component    string
+	Schema       []byte
+	Example      []byte
+	Descriptions map[string]string
+}
+
+type errorKey struct {
+	Code      string
+	Status    int
+	Output    modelKey
+	Retryable bool
+}
+
+func newRecordKey(r Record) recordKey {
+	key := recordKey{
+		Output:          newModelKey(r.Output),
+		ID:              r.ID,
+		Method:          r.Method,
+		Path:            r.Path,
+		Description:     r.Description,
+		Summary:         r.Summary,
+		SuccessStatus:   r.SuccessStatus,
+		Tags:            r.Tags,
+		Extensions:      r.Extensions,
+		PathSummary:     r.PathSummary,
+		PathDescription: r.PathDescription,
+		FieldSelection:  r.FieldSelection,
+		NoContent:       r.NoContent,
+		Cache:           r.Cache,
+		Content:         r.Content,
+		PathParams:      r.PathParams,
+		Timeout:         r.Timeout,
+		TimeoutStatus:   r.TimeoutStatus,
+		ResponseDescs:   r.ResponseDescriptions,
+		Samples:         r.Samples,
+		EntityExamples:  r.EntityExamples,
+		InputSuffix:     r.InputVariantSuffix,
+		Visibility:      r.Visibility,
+		FeatureFlags:    r.FeatureFlags,
+		Rollout:         r.Rollout,
+		Translations:    r.Translations,
+		Deprecated:      r.Deprecated,
+		Owner:           r.Owner,
+		SignedURL:       r.SignedURL,
+	}
+	if r.Input != nil {
+		inp := newModelKey(*r.Input)
+		key.Input = &inp
+	}
+	if r.QueryParams != nil {
+		// query params are documented from the struct type and its tags
+		key.QueryParams = reflect.TypeOf(r.QueryParams).String()
+	}
+	for _, e := range r.Errors {
+		key.Errors = append(key.Errors, errorKey{Code: e.Code, Status: e.Status, Output: newModelKey(e.Output), Retryable: e.Retryable})
+	}
+	if r.Representations != nil {
+		key.Representations = make(map[string]*modelKey, len(r.Representations))
+		for ct, m := range r.Representations {
+			if m == nil {
+				key.Representations[ct] = nil
+				continue
+			}
+			rep := newModelKey(*m)
+			key.Representations[ct] = &rep
+		}
+	}
+
+	return key
+}
+
+func newModelKey(m mason.Model) modelKey {
+	if m.WithSchema == nil || reflect.ValueOf(m.WithSchema).Kind() == reflect.Ptr && reflect.ValueOf(m.WithSchema).IsNil() {
+		return modelKey{}
+	}
+
+	return modelKey{
+		Component:    m.ComponentName(),
+		Schema:       m.Schema(),
+		Example:      m.Example(),
+		Descriptions: localizedDescriptions(m.WithSchema),
+	}
+}
@@ -0,0 +1,548 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ChangeKind tells whether a change added something to the API, removed something from it, or modified it.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a change of an operation between two versions of a spec.
+type Change struct {
+	Tag         string     `json:"tag"`
+	Method      string     `json:"method"`
+	Path        string     `json:"path"`
+	OperationID string     `json:"operationId,omitempty"`
+	Kind        ChangeKind `json:"kind"`
+	// Description is a sentence describing the change, e.g. Removed the response property `title`.
+	Description string `json:"description"`
+	// Breaking changes may break the existing clients of the operation.
+	Breaking bool `json:"breaking"`
+	// Owner is the team owning the operation, from its x-owner, see mason.Builder.WithOwner.
+	Owner string `json:"owner,omitempty"`
+}
+
+// Changelog lists the changes of the operations between two versions of a spec, for the release notes of the API
+// consumers. It is marshalled as JSON as is, and rendered as Markdown with Markdown.
+type Changelog struct {
+	Changes []Change `json:"changes"`
+}
+
+// Breaking reports whether any change is breaking.
+func (c Changelog) Breaking() bool {
+	return slices.ContainsFunc(c.Changes, func(ch Change) bool { return ch.Breaking })
+}
+
+// NewChangelog compares two versions of a spec, as generated by Schema. The changes are breaking when they may break
+// the existing clients:
+//   - removed operations, params, response statuses and response properties,
+//   - params that are new and required, or became required,
+//   - request properties that are new and required, became required or were removed,
+//   - response properties that are no longer required,
+//   - types that no longer accept the values of the requests, or produce values the clients do not expect,
+//   - enum values removed from the requests.
+//
+// The other changes, e.g. new operations, optional params and properties or other constraints, are listed as
+// non-breaking. Descriptions and examples are ignored.
+func NewChangelog(oldSpec []byte, newSpec []byte) (Changelog, error) {
+	var oldDoc, newDoc map[string]any
+	if err := json.Unmarshal(oldSpec, &oldDoc); err != nil {
+		return Changelog{}, fmt.Errorf("failed to parse the old spec: %w", err)
+	}
+	if err := json.Unmarshal(newSpec, &newDoc); err != nil {
+		return Changelog{}, fmt.Errorf("failed to parse the new spec: %w", err)
+	}
+
+	d := &changelogDiff{old: oldDoc, new: newDoc}
+	oldOps, newOps := specOperations(oldDoc), specOperations(newDoc)
+	keys := make([]string, 0, len(oldOps)+len(newOps))
+	for key := range oldOps {
+		keys = append(keys, key)
+	}
+	for key := range newOps {
+		if _, ok := oldOps[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		oldOp, inOld := oldOps[key]
+		newOp, inNew := newOps[key]
+		switch {
+		case !inOld:
+			d.op = newOp
+			d.add(ChangeAdded, false, "Added the operation.")
+		case !inNew:
+			d.op = oldOp
+			d.add(ChangeRemoved, true, "Removed the operation.")
+		default:
+			d.op = newOp
+			d.diffOperation(oldOp.doc, newOp.doc)
+		}
+	}
+
+	return Changelog{Changes: d.changes}, nil
+}
+
+// OwnerReview lists the changed operations owned by a team, as method and path, e.g. to request the review of a spec
+// change from the owners of the operations it touches.
+type OwnerReview struct {
+	// Owner is empty for the operations without an owner.
+	Owner      string   `json:"owner"`
+	Operations []string `json:"operations"`
+	// Breaking tells whether any change of the operations is breaking.
+	Breaking bool `json:"breaking"`
+}
+
+// Reviews groups the changed operations by owner, sorted by owner. The owner of a removed operation is the one of the
+// old spec, and of the other operations the one of the new spec.
+func (c Changelog) Reviews() []OwnerReview {
+	byOwner := make(map[string]*OwnerReview)
+	var owners []string
+	for _, ch := range c.Changes {
+		review, ok := byOwner[ch.Owner]
+		if !ok {
+			review = &OwnerReview{Owner: ch.Owner}
+			byOwner[ch.Owner] = review
+			owners = append(owners, ch.Owner)
+		}
+		if op := ch.Method + " " + ch.Path; !slices.Contains(review.Operations, op) {
+			review.Operations = append(review.Operations, op)
+		}
+		review.Breaking = review.Breaking || ch.Breaking
+	}
+	sort.Strings(owners)
+
+	reviews := make([]OwnerReview, 0, len(owners))
+	for _, owner := range owners {
+		review := byOwner[owner]
+		sort.Strings(review.Operations)
+		reviews = append(reviews, *review)
+	}
+
+	return reviews
+}
+
+// Markdown renders the changelog with a section per tag and operation, and a badge on the breaking changes.
+func (c Changelog) Markdown(title string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+	if len(c.Changes) == 0 {
+		b.WriteString("\nNo changes.\n")
+		return b.String()
+	}
+
+	tags := []string{}
+	byTag := make(map[string][]Change)
+	for _, ch := range c.Changes {
+		if _, ok := byTag[ch.Tag]; !ok {
+			tags = append(tags, ch.Tag)
+		}
+		byTag[ch.Tag] = append(byTag[ch.Tag], ch)
+	}
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		fmt.Fprintf(&b, "\n## %s\n", tag)
+		op := ""
+		for _, ch := range byTag[tag] {
+			if heading := ch.Method + " " + ch.Path; heading != op {
+				op = heading
+				fmt.Fprintf(&b, "\n### `%s`", heading)
+				if ch.OperationID != "" {
+					fmt.Fprintf(&b, " (%s)", ch.OperationID)
+				}
+				b.WriteString("\n\n")
+			}
+			b.WriteString("- ")
+			if ch.Breaking {
+				b.WriteString("**Breaking** ")
+			}
+			b.WriteString(ch.Description + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+type specOperation struct {
+	method, path, id, tag, owner string
+	doc                          map[string]any
+}
+
+// specOperations returns the operations of a spec, by method and path.
+func specOperations(doc map[string]any) map[string]specOperation {
+	ops := make(map[string]specOperation)
+	paths, _ := doc["paths"].(map[string]any)
+	for path, item := range paths {
+		item, _ := item.(map[string]any)
+		for method, op := range item {
+			op, ok := op.(map[string]any)
+			if !ok || !slices.Contains([]string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}, method) {
+				continue
+			}
+			sop := specOperation{method: strings.ToUpper(method), path: path, tag: "Other", doc: op}
+			sop.id, _ = op["operationId"].(string)
+			sop.owner, _ = op["x-owner"].(string)
+			if tags, _ := op["tags"].([]any); len(tags) > 0 {
+				sop.tag, _ = tags[0].(string)
+			}
+			ops[path+" "+sop.method] = sop
+		}
+	}
+
+	return ops
+}
+
+type changelogDiff struct {
+	old, new map[string]any
+	op       specOperation
+	changes  []Change
+	// visiting are the pairs of schemas being compared, so the recursive schemas are compared once
+	visiting map[[2]uintptr]bool
+}
+
+func (d *changelogDiff) add(kind ChangeKind, breaking bool, format string, args ...any) {
+	d.changes = append(d.changes, Change{
+		Tag:         d.op.tag,
+		Method:      d.op.method,
+		Path:        d.op.path,
+		OperationID: d.op.id,
+		Kind:        kind,
+		Description: fmt.Sprintf(format, args...),
+		Breaking:    breaking,
+		Owner:       d.op.owner,
+	})
+}
+
+func (d *changelogDiff) diffOperation(oldOp map[string]any, newOp map[string]any) {
+	if deprecated, _ := newOp["deprecated"].(bool); deprecated {
+		if was, _ := oldOp["deprecated"].(bool); !was {
+			d.add(ChangeModified, false, "Deprecated the operation.")
+		}
+	}
+
+	d.diffParams(specParams(oldOp), specParams(newOp))
+
+	oldBody, newBody := d.requestSchema(d.old, oldOp), d.requestSchema(d.new, newOp)
+	switch {
+	case oldBody == nil && newBody != nil:
+		d.add(ChangeAdded, true, "Added a request body.")
+	case oldBody != nil && newBody == nil:
+		d.add(ChangeRemoved, false, "Removed the request body.")
+	case oldBody != nil:
+		d.diffSchema("request", "", oldBody, newBody, true)
+	}
+
+	oldRsps, newRsps := specResponses(oldOp), specResponses(newOp)
+	for _, status := range sortedKeys(oldRsps, newRsps) {
+		oldRsp, inOld := oldRsps[status]
+		newRsp, inNew := newRsps[status]
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, false, "Added the %s response.", status)
+		case !inNew:
+			d.add(ChangeRemoved, true, "Removed the %s response.", status)
+		default:
+			oldSchema, newSchema := d.responseSchema(d.old, oldRsp), d.responseSchema(d.new, newRsp)
+			if oldSchema != nil && newSchema != nil {
+				d.diffSchema(status+" response", "", oldSchema, newSchema, false)
+			}
+		}
+	}
+}
+
+// specParams returns the params of an operation, by location and name, e.g. query page_size.
+func specParams(op map[string]any) map[string]map[string]any {
+	params := make(map[string]map[string]any)
+	list, _ := op["parameters"].([]any)
+	for _, p := range list {
+		p, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		in, _ := p["in"].(string)
+		name, _ := p["name"].(string)
+		params[in+" "+name] = p
+	}
+
+	return params
+}
+
+func (d *changelogDiff) diffParams(oldParams, newParams map[string]map[string]any) {
+	for _, key := range sortedKeys(oldParams, newParams) {
+		oldParam, inOld := oldParams[key]
+		newParam, inNew := newParams[key]
+		in, name, _ := strings.Cut(key, " ")
+		required, _ := newParam["required"].(bool)
+		switch {
+		case !inOld && required:
+			d.add(ChangeAdded, true, "Added the required %s param `%s`.", in, name)
+		case !inOld:
+			d.add(ChangeAdded, false, "Added the optional %s param `%s`.", in, name)
+		case !inNew:
+			d.add(ChangeRemoved, true, "Removed the %s param `%s`.", in, name)
+		default:
+			if was, _ := oldParam["required"].(bool); required && !was {
+				d.add(ChangeModified, true, "Made the %s param `%s` required.", in, name)
+			} else if !required && was {
+				d.add(ChangeModified, false, "Made the %s param `%s` optional.", in, name)
+			}
+			if deprecated, _ := newParam["deprecated"].(bool); deprecated {
+				if was, _ := oldParam["deprecated"].(bool); !was {
+					d.add(ChangeModified, false, "Deprecated the %s param `%s`.", in, name)
+				}
+			}
+			oldSchema, _ := oldParam["schema"].(map[string]any)
+			newSchema, _ := newParam["schema"].(map[string]any)
+			if oldSchema != nil && newSchema != nil {
+				d.diffSchema(in+" param", name, d.resolve(d.old, oldSchema), d.resolve(d.new, newSchema), true)
+			}
+		}
+	}
+}
+
+// specResponses returns the responses of an operation, by status.
+func specResponses(op map[string]any) map[string]map[string]any {
+	rsps := make(map[string]map[string]any)
+	all, _ := op["responses"].(map[string]any)
+	for status, rsp := range all {
+		if rsp, ok := rsp.(map[string]any); ok {
+			rsps[status] = rsp
+		}
+	}
+
+	return rsps
+}
+
+func (d *changelogDiff) requestSchema(doc map[string]any, op map[string]any) map[string]any {
+	body, _ := op["requestBody"].(map[string]any)
+	if body == nil {
+		return nil
+	}
+
+	return d.contentSchema(doc, d.resolve(doc, body))
+}
+
+func (d *changelogDiff) responseSchema(doc map[string]any, rsp map[string]any) map[string]any {
+	return d.contentSchema(doc, d.resolve(doc, rsp))
+}
+
+// contentSchema returns the JSON schema of a request body or a response, or of its first content type.
+func (d *changelogDiff) contentSchema(doc map[string]any, unit map[string]any) map[string]any {
+	content, _ := unit["content"].(map[string]any)
+	mt, ok := content["application/json"].(map[string]any)
+	if !ok {
+		for _, ct := range sortedKeys(content, nil) {
+			if mt, ok = content[ct].(map[string]any); ok {
+				break
+			}
+		}
+	}
+	schema, _ := mt["schema"].(map[string]any)
+	if schema == nil {
+		return nil
+	}
+
+	return d.resolve(doc, schema)
+}
+
+// resolve follows the local $ref of a schema, a request body or a response.
+func (d *changelogDiff) resolve(doc map[string]any, v map[string]any) map[string]any {
+	for seen := 0; seen < 32; seen++ {
+		ref, ok := v["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return v
+		}
+		var target any = doc
+		for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, _ := target.(map[string]any)
+			target = m[strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")]
+		}
+		next, ok := target.(map[string]any)
+		if !ok {
+			return v
+		}
+		v = next
+	}
+
+	return v
+}
+
+// schemaKeywordsIgnored are the keywords that are not changes of the contract, or that are compared on their own.
+var schemaKeywordsIgnored = map[string]bool{
+	"description": true, "title": true, "examples": true, "example": true, "$comment": true,
+	"type": true, "enum": true, "properties": true, "required": true, "items": true, "$ref": true,
+}
+
+// diffSchema compares the schemas of a request (towards the server) or of a response (towards the clients), at a
+// dot separated property path.
+func (d *changelogDiff) diffSchema(where string, path string, oldSchema, newSchema map[string]any, request bool) {
+	pair := [2]uintptr{reflect.ValueOf(oldSchema).Pointer(), reflect.ValueOf(newSchema).Pointer()}
+	if d.visiting[pair] {
+		return
+	}
+	if d.visiting == nil {
+		d.visiting = make(map[[2]uintptr]bool)
+	}
+	d.visiting[pair] = true
+	defer delete(d.visiting, pair)
+
+	subject := where
+	if path != "" {
+		if strings.HasSuffix(where, "param") {
+			subject = fmt.Sprintf("%s `%s`", where, path)
+		} else {
+			subject = fmt.Sprintf("%s property `%s`", where, path)
+		}
+	}
+
+	oldTypes, newTypes := schemaTypes(oldSchema), schemaTypes(newSchema)
+	if !slices.Equal(oldTypes, newTypes) && len(oldTypes) > 0 && len(newTypes) > 0 {
+		// the requests may only accept more types, and the responses only produce fewer
+		breaking := !isSubset(oldTypes, newTypes)
+		if !request {
+			breaking = !isSubset(newTypes, oldTypes)
+		}
+		d.add(ChangeModified, breaking, "Changed the type of the %s from %s to %s.", subject, strings.Join(oldTypes, "|"), strings.Join(newTypes, "|"))
+	}
+
+	oldEnum, newEnum := enumValues(oldSchema), enumValues(newSchema)
+	if oldEnum != nil && newEnum != nil {
+		if removed := difference(oldEnum, newEnum); len(removed) > 0 {
+			d.add(ChangeRemoved, request, "Removed the values %s from the %s.", strings.Join(removed, ", "), subject)
+		}
+		if added := difference(newEnum, oldEnum); len(added) > 0 {
+			d.add(ChangeAdded, false, "Added the values %s to the %s.", strings.Join(added, ", "), subject)
+		}
+	}
+
+	oldProps, _ := oldSchema["properties"].(map[string]any)
+	newProps, _ := newSchema["properties"].(map[string]any)
+	oldRequired, newRequired := requiredSet(oldSchema), requiredSet(newSchema)
+	for _, name := range sortedKeys(oldProps, newProps) {
+		prop := name
+		if path != "" {
+			prop = path + "." + name
+		}
+		oldProp, inOld := oldProps[name].(map[string]any)
+		newProp, inNew := newProps[name].(map[string]any)
+		switch {
+		case !inOld && request && newRequired[name]:
+			d.add(ChangeAdded, true, "Added the required %s property `%s`.", where, prop)
+		case !inOld:
+			d.add(ChangeAdded, false, "Added the optional %s property `%s`.", where, prop)
+		case !inNew:
+			d.add(ChangeRemoved, true, "Removed the %s property `%s`.", where, prop)
+		default:
+			if request && newRequired[name] && !oldRequired[name] {
+				d.add(ChangeModified, true, "Made the %s property `%s` required.", where, prop)
+			}
+			if !request && oldRequired[name] && !newRequired[name] {
+				d.add(ChangeModified, true, "Made the %s property `%s` optional.", where, prop)
+			}
+			d.diffSchema(where, prop, d.resolve(d.old, oldProp), d.resolve(d.new, newProp), request)
+		}
+	}
+
+	oldItems, _ := oldSchema["items"].(map[string]any)
+	newItems, _ := newSchema["items"].(map[string]any)
+	if oldItems != nil && newItems != nil {
+		d.diffSchema(where, path+"[]", d.resolve(d.old, oldItems), d.resolve(d.new, newItems), request)
+	}
+
+	for _, kw := range sortedKeys(oldSchema, newSchema) {
+		if schemaKeywordsIgnored[kw] || reflect.DeepEqual(oldSchema[kw], newSchema[kw]) {
+			continue
+		}
+		d.add(ChangeModified, false, "Changed `%s` of the %s from %s to %s.", kw, subject, compactJSON(oldSchema[kw]), compactJSON(newSchema[kw]))
+	}
+}
+
+// schemaTypes returns the sorted types of a schema.
+func schemaTypes(schema map[string]any) []string {
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+func enumValues(schema map[string]any) []string {
+	enum, ok := schema["enum"].([]any)
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(enum))
+	for _, v := range enum {
+		values = append(values, compactJSON(v))
+	}
+
+	return values
+}
+
+func requiredSet(schema map[string]any) map[string]bool {
+	required := make(map[string]bool)
+	list, _ := schema["required"].([]any)
+	for _, v := range list {
+		if name, ok := v.(string); ok {
+			required[name] = true
+		}
+	}
+
+	return required
+}
+
+// isSubset reports whether all the values of a are in b.
+func isSubset(a, b []string) bool {
+	return len(difference(a, b)) == 0
+}
+
+// difference returns the values of a that are not in b.
+func difference(a, b []string) []string {
+	var diff []string
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			diff = append(diff, v)
+		}
+	}
+
+	return diff
+}
+
+// sortedKeys returns the sorted keys of both maps.
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
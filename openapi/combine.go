@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"fmt"
+)
+
+// Combine merges the operations of several generators into a single generator, e.g. to publish one gateway-level
+// document for multiple services. The records of each generator are taken as they are, after its own filters and
+// transforms. Components are collected into the same spec, so definitions that share a name across services must be
+// identical, like within a single API. The validation setting is taken from the first generator.
+func Combine(gens ...*Generator) (*Generator, error) {
+	if len(gens) == 0 {
+		return nil, fmt.Errorf("at least one generator is required")
+	}
+
+	config := gens[0].config
+	config.allTags = nil
+
+	seenOps := make(map[string]int)
+	seenIDs := make(map[string]int)
+	seenTags := make(map[string]bool)
+
+	var records []Record
+	var webhooks []WebhookRecord
+	seenWebhooks := make(map[string]int)
+	for i, gen := range gens {
+		for _, webhook := range gen.webhooks {
+			if j, ok := seenWebhooks[webhook.Name]; ok {
+				return nil, fmt.Errorf("webhook %s is defined by generator %d and generator %d", webhook.Name, j, i)
+			}
+			seenWebhooks[webhook.Name] = i
+			webhooks = append(webhooks, webhook)
+		}
+
+		for _, record := range gen.records {
+			key := record.Method + " " + record.Path
+			if j, ok := seenOps[key]; ok {
+				return nil, fmt.Errorf("operation %s is defined by generator %d and generator %d", key, j, i)
+			}
+			seenOps[key] = i
+
+			if record.ID != "" {
+				if j, ok := seenIDs[record.ID]; ok {
+					return nil, fmt.Errorf("operationID [%s] is used by generator %d and generator %d", record.ID, j, i)
+				}
+				seenIDs[record.ID] = i
+			}
+
+			records = append(records, record.clone())
+		}
+
+		for _, tag := range gen.config.allTags {
+			if !seenTags[tag] {
+				seenTags[tag] = true
+				config.allTags = append(config.allTags, tag)
+			}
+		}
+	}
+
+	if err := resolveConflicts(records, config.conflicts); err != nil {
+		return nil, err
+	}
+
+	return &Generator{
+		api:       gens[0].api,
+		config:    config,
+		records:   records,
+		webhooks:  webhooks,
+		sources:   gens,
+		Reflector: newReflector(config),
+	}, nil
+}
@@ -0,0 +1,62 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/swaggest/openapi-go/openapi31"
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/openapi"
+	"gotest.tools/v3/assert"
+)
+
+func TestCombine(t *testing.T) {
+	newGenerator := func(t *testing.T, register func(api *mason.API)) *openapi.Generator {
+		t.Helper()
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		register(api)
+		gen, err := openapi.NewGenerator(api)
+		assert.NilError(t, err)
+		return gen
+	}
+
+	a := newGenerator(t, func(api *mason.API) {
+		api.NewRouteGroup("TestA").Register(mason.HandleGet(GetResourceA).Path("/test-a").WithOpID("fetch_a").WithDesc("Get A"))
+	})
+	b := newGenerator(t, func(api *mason.API) {
+		api.NewRouteGroup("TestB").Register(mason.HandleGet(GetResourceB).Path("/test-b").WithOpID("fetch_b").WithDesc("Get B"))
+	})
+
+	t.Run("merges operations and components", func(t *testing.T) {
+		gen, err := openapi.Combine(a, b)
+		assert.NilError(t, err)
+
+		schema, err := gen.Schema()
+		assert.NilError(t, err)
+
+		var spec openapi31.Spec
+		assert.NilError(t, json.Unmarshal(schema, &spec))
+		assert.Equal(t, 2, len(spec.Paths.MapOfPathItemValues))
+		_, ok := spec.Components.Schemas["TestResourceA"]
+		assert.Assert(t, ok)
+		_, ok = spec.Components.Schemas["TestResourceB"]
+		assert.Assert(t, ok)
+	})
+
+	t.Run("rejects duplicate operations", func(t *testing.T) {
+		_, err := openapi.Combine(a, a)
+		assert.ErrorContains(t, err, "GET /test-a")
+	})
+
+	t.Run("rejects conflicting definitions across sources", func(t *testing.T) {
+		conflicting := newGenerator(t, func(api *mason.API) {
+			api.NewRouteGroup("TestC").Register(mason.HandleGet(GetConflictingResourceA).Path("/test-c").WithOpID("fetch_c").WithDesc("Get C"))
+		})
+
+		gen, err := openapi.Combine(a, conflicting)
+		assert.NilError(t, err)
+
+		_, err = gen.Schema()
+		assert.ErrorContains(t, err, "different definition")
+	})
+}
@@ -0,0 +1,279 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+	"github.com/tailbits/mason"
+)
+
+// ConflictStrategy decides how the components that share a name with different schemas are resolved.
+type ConflictStrategy int
+
+const (
+	// ConflictFail fails the generation on the first conflict. It is the default.
+	ConflictFail ConflictStrategy = iota
+	// ConflictSuffix numbers the conflicting components, e.g. User and User2.
+	ConflictSuffix
+	// ConflictNamespace prefixes the conflicting components with the route group of their operation, e.g. User and
+	// LegacyUser.
+	ConflictNamespace
+	// ConflictReport fails the generation with a ConflictsError listing all the conflicts at once.
+	ConflictReport
+)
+
+// Conflicts sets the strategy resolving the components that share a name with different schemas. With ConflictSuffix
+// and ConflictNamespace, the schema used by the first operation, in the order of their paths and methods, keeps the
+// name.
+func Conflicts(strategy ConflictStrategy) openAPIOption {
+	return func(c *config) {
+		c.conflicts = strategy
+	}
+}
+
+// Conflict is a component name shared by different schemas.
+type Conflict struct {
+	Name string
+	// Operations lists the operations using each of the schemas, e.g. [["GET /users"], ["GET /legacy/users"]].
+	Operations [][]string
+}
+
+// ConflictsError is returned by ConflictReport with all the conflicts between the components.
+type ConflictsError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictsError) Error() string {
+	msgs := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		variants := make([]string, len(c.Operations))
+		for j, ops := range c.Operations {
+			variants[j] = "(" + strings.Join(ops, ", ") + ")"
+		}
+		msgs[i] = fmt.Sprintf("%s is defined differently by %s", c.Name, strings.Join(variants, " and "))
+	}
+
+	return fmt.Sprintf("%d conflicting definitions: %s", len(e.Conflicts), strings.Join(msgs, "; "))
+}
+
+// maxConflictRounds bounds the renaming, since renaming a component changes the schemas referring to it.
+const maxConflictRounds = 10
+
+// componentVariant is one of the schemas sharing a component name, with the models defining it.
+type componentVariant struct {
+	name        string
+	fingerprint string
+	models      []*mason.Model
+	groups      []string
+	operations  []string
+}
+
+// resolveConflicts applies the conflict strategy to the components of the records, renaming the models with
+// ConflictSuffix and ConflictNamespace. The conflicts left unresolved fail in addDefinition, like with ConflictFail.
+func resolveConflicts(records []Record, strategy ConflictStrategy) error {
+	if strategy == ConflictFail {
+		return nil
+	}
+
+	for range maxConflictRounds {
+		conflicts, err := findConflicts(records)
+		if err != nil || len(conflicts) == 0 {
+			return err
+		}
+
+		if strategy == ConflictReport {
+			cerr := &ConflictsError{}
+			for _, variants := range conflicts {
+				c := Conflict{Name: variants[0].name}
+				for _, v := range variants {
+					c.Operations = append(c.Operations, v.operations)
+				}
+				cerr.Conflicts = append(cerr.Conflicts, c)
+			}
+			return cerr
+		}
+
+		taken := make(map[string]bool)
+		for _, variants := range conflicts {
+			for _, v := range variants {
+				taken[strings.ToLower(v.name)] = true
+			}
+		}
+		for _, variants := range conflicts {
+			for _, v := range variants[1:] {
+				name := v.name
+				if strategy == ConflictNamespace && len(v.groups) > 0 {
+					name = mason.PascalCase(strings.ReplaceAll(v.groups[0], "/", "-")) + v.name
+				}
+				name = freeName(name, taken)
+				taken[strings.ToLower(name)] = true
+				for _, m := range v.models {
+					*m = m.WithComponentNaming(renaming(*m, v.name, name))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// findConflicts groups the components of the records by case-insensitive name, and returns the names with more than
+// one schema. The records are visited in the order of their paths and methods, so the outcome is deterministic.
+func findConflicts(records []Record) ([][]*componentVariant, error) {
+	order := make([]int, len(records))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := records[order[a]], records[order[b]]
+		if ra.Path != rb.Path {
+			return ra.Path < rb.Path
+		}
+		return ra.Method < rb.Method
+	})
+
+	byName := make(map[string][]*componentVariant)
+	var names []string
+	for _, i := range order {
+		record := &records[i]
+		operation := record.Method + " " + record.Path
+		for _, m := range recordModels(record) {
+			components, err := modelComponents(*m)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range components {
+				key := strings.ToLower(c.name)
+				if _, ok := byName[key]; !ok {
+					names = append(names, key)
+				}
+				byName[key] = addVariant(byName[key], c.name, c.fingerprint, m, record.Group, operation)
+			}
+		}
+	}
+
+	var conflicts [][]*componentVariant
+	for _, key := range names {
+		if len(byName[key]) > 1 {
+			conflicts = append(conflicts, byName[key])
+		}
+	}
+
+	return conflicts, nil
+}
+
+func addVariant(variants []*componentVariant, name string, fingerprint string, m *mason.Model, group string, operation string) []*componentVariant {
+	for _, v := range variants {
+		if v.name == name && v.fingerprint == fingerprint {
+			if !slices.Contains(v.models, m) {
+				v.models = append(v.models, m)
+			}
+			if !slices.Contains(v.operations, operation) {
+				v.operations = append(v.operations, operation)
+				v.groups = append(v.groups, group)
+			}
+			return variants
+		}
+	}
+
+	return append(variants, &componentVariant{
+		name:        name,
+		fingerprint: fingerprint,
+		models:      []*mason.Model{m},
+		groups:      []string{group},
+		operations:  []string{operation},
+	})
+}
+
+// recordModels returns the models of the record that end up as components.
+func recordModels(record *Record) []*mason.Model {
+	var models []*mason.Model
+	if record.Input != nil && !record.Input.IsNil() {
+		models = append(models, record.Input)
+	}
+	if !record.Output.IsNil() {
+		models = append(models, &record.Output)
+	}
+	for i := range record.Errors {
+		models = append(models, &record.Errors[i].Output)
+	}
+	cts := make([]string, 0, len(record.Representations))
+	for ct, m := range record.Representations {
+		if m != nil {
+			cts = append(cts, ct)
+		}
+	}
+	sort.Strings(cts)
+	for _, ct := range cts {
+		models = append(models, record.Representations[ct])
+	}
+
+	return models
+}
+
+type component struct {
+	name        string
+	fingerprint string
+}
+
+// modelComponents returns the components defined by the model: its own schema, and the nested definitions.
+func modelComponents(m mason.Model) ([]component, error) {
+	schema, err := m.JSONSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get JSON schema of %s: %w", m.Name(), err)
+	}
+
+	defs := schema.Definitions
+	schema.Definitions = nil
+	schema.Examples = nil
+	components := []component{{name: m.ComponentName(), fingerprint: fingerprint(schema)}}
+
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if def := defs[name]; def.TypeObject != nil {
+			components = append(components, component{name: name, fingerprint: fingerprint(*def.TypeObject)})
+		}
+	}
+
+	return components, nil
+}
+
+func fingerprint(schema jsonschema.Schema) string {
+	b, _ := schema.MarshalJSON()
+	return string(b)
+}
+
+// renaming returns the component naming of the model, with the component named from renamed to.
+func renaming(m mason.Model, from string, to string) mason.NamingStrategy {
+	naming := m.ComponentNaming()
+	return func(entity string) string {
+		name := entity
+		if naming != nil {
+			name = naming(entity)
+		}
+		if name == from {
+			return to
+		}
+		return name
+	}
+}
+
+// freeName returns the name, or the name with the first free numeric suffix, e.g. User2.
+func freeName(name string, taken map[string]bool) string {
+	if !taken[strings.ToLower(name)] {
+		return name
+	}
+	for i := 2; ; i++ {
+		if candidate := name + strconv.Itoa(i); !taken[strings.ToLower(candidate)] {
+			return candidate
+		}
+	}
+}
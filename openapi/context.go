@@ -1,8 +1,13 @@
 package openapi
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,6 +15,7 @@ import (
 	"github.com/swaggest/openapi-go"
 	"github.com/swaggest/openapi-go/openapi31"
 	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
 )
 
 type ContextWrapper struct {
@@ -23,28 +29,160 @@ func (c ContextWrapper) addToReflector() error {
 }
 
 // from takes a Record and uses it to populate the ContextWrapper with the necessary information to generate an OpenAPI operation.
-func (c *ContextWrapper) from(record Record) error {
+func (c *ContextWrapper) from(record *Record) error {
 	if !record.Output.IsNil() {
-		if err := c.addRespStructure(record.Output, openapi.WithHTTPStatus(record.SuccessStatus)); err != nil {
+		options := []openapi.ContentOption{
+			openapi.WithHTTPStatus(record.SuccessStatus),
+			withResponseDescription(record.responseDescription(record.SuccessStatus)),
+		}
+		if record.EntityExamples {
+			options = append(options, withEntityExample(record.Output))
+		}
+		if _, ok := record.Output.WithSchema.(model.Paginated); ok {
+			options = append(options, withResponseHeaders(paginationHeaders))
+		}
+		if record.Cache != nil {
+			options = append(options, withResponseHeaders(cacheHeaders(*record.Cache)))
+		}
+		if record.conditional() {
+			options = append(options, withResponseHeaders(lastModifiedHeaders))
+		}
+		if record.surrogateKeyed() {
+			options = append(options, withResponseHeaders(surrogateKeyHeaders))
+		}
+		if record.file() {
+			options = append(options, withFileContent(), withResponseHeaders(fileHeaders))
+		}
+		if record.Content != nil && record.Content.ResponseCharset != "" {
+			options = append(options, withResponseCharset(record.Content.ResponseCharset))
+		}
+		if len(record.Samples) > 0 {
+			options = append(options, withSampleExamples(record.Samples, func(s mason.Sample) json.RawMessage { return s.Response }))
+		}
+		if len(record.Representations) > 0 {
+			for _, m := range record.Representations {
+				if m == nil {
+					continue
+				}
+				if err := c.reflector.addModel(m); err != nil {
+					return fmt.Errorf("failed to add definition for %s: %w", m.Name(), err)
+				}
+			}
+			options = append(options, withRepresentations(record.Representations))
+		}
+		if err := c.addRespStructure(&record.Output, options...); err != nil {
+			return err
+		}
+	} else if record.NoContent {
+		c.OperationContext.AddRespStructure(nil,
+			openapi.WithHTTPStatus(record.SuccessStatus),
+			withResponseDescription(record.responseDescription(record.SuccessStatus)),
+		)
+	}
+
+	if record.file() {
+		err := c.addRespStructure(&record.Output,
+			openapi.WithHTTPStatus(http.StatusPartialContent),
+			withResponseDescription(record.responseDescription(http.StatusPartialContent)),
+			withFileContent(),
+			withResponseHeaders(partialContentHeaders),
+		)
+		if err != nil {
+			return err
+		}
+		c.OperationContext.AddRespStructure(nil,
+			openapi.WithHTTPStatus(http.StatusRequestedRangeNotSatisfiable),
+			withResponseDescription(record.responseDescription(http.StatusRequestedRangeNotSatisfiable)),
+			withResponseHeaders(unsatisfiableRangeHeaders),
+		)
+	}
+
+	if record.conditional() {
+		c.OperationContext.AddRespStructure(nil,
+			openapi.WithHTTPStatus(http.StatusNotModified),
+			withResponseDescription(record.responseDescription(http.StatusNotModified)),
+		)
+	}
+
+	if record.Timeout > 0 {
+		status := record.TimeoutStatus
+		if status == 0 {
+			status = http.StatusGatewayTimeout
+		}
+		timeoutErr := mason.NewModel(&model.TimeoutError{}, mason.RefPrefix(c.reflector.refPrefix)).WithComponentNaming(c.reflector.rename)
+		err := c.addRespStructure(&timeoutErr,
+			openapi.WithHTTPStatus(status),
+			withResponseDescription(record.responseDescription(status)),
+		)
+		if err != nil {
 			return err
 		}
 	}
 
+	if err := c.addErrorResponses(record); err != nil {
+		return err
+	}
+
+	if err := c.addContentResponses(record); err != nil {
+		return err
+	}
+
 	if record.Input != nil && !record.Input.IsNil() {
-		if err := c.addReqStructure(*record.Input); err != nil {
+		var options []openapi.ContentOption
+		if record.EntityExamples {
+			options = append(options, withEntityExample(*record.Input))
+		}
+		// streams, e.g. mason.Stream, are documented with the schema of a single line
+		if ct, ok := record.Input.WithSchema.(interface{ ContentType() string }); ok {
+			options = append(options, withRequestContentType(ct.ContentType()))
+		}
+		if len(record.Samples) > 0 {
+			options = append(options, withSampleExamples(record.Samples, func(s mason.Sample) json.RawMessage { return s.Request }))
+		}
+		if record.Content != nil && len(record.Content.Charsets) > 0 {
+			options = append(options, withRequestCharsets(record.Content.Charsets))
+		}
+		if record.InputVariantSuffix != "" {
+			variant, err := c.reflector.addInputVariant(record.Input, record.InputVariantSuffix)
+			if err != nil {
+				return fmt.Errorf("failed to add input variant for %s: %w", record.Input.Name(), err)
+			}
+			if variant != "" {
+				options = append(options, withSchemaRef(*record.Input, variant))
+			}
+		}
+		if err := c.addReqStructure(record.Input, options...); err != nil {
 			return err
 		}
 	}
 
 	pathParams := []openapi31.ParameterOrReference{}
 	forEachPathParam(record.Method, record.Path, func(param string) {
-		pathParams = append(pathParams, makeRequiredPathParam(param))
+		pathParams = append(pathParams, makeRequiredPathParam(param, record.PathParams[param]))
 	})
 
-	forEachQueryParam(record.QueryParams, func(name string, t string, format string, desc string) {
-		pathParams = append(pathParams, makeOptionalQueryParam(name, t, format, desc))
+	forEachQueryParam(record.QueryParams, func(p queryParam) {
+		pathParams = append(pathParams, makeQueryParam(p))
 	})
 
+	if record.FieldSelection {
+		pathParams = append(pathParams, makeQueryParam(fieldsParam()))
+	}
+
+	if record.conditional() {
+		pathParams = append(pathParams, ifModifiedSinceParam())
+	}
+
+	if record.file() {
+		pathParams = append(pathParams, rangeParams()...)
+	}
+
+	if record.Content != nil && record.Input != nil && !record.Input.IsNil() {
+		if param, ok := contentEncodingParam(*record.Content); ok {
+			pathParams = append(pathParams, param)
+		}
+	}
+
 	c.WithParameters(pathParams...)
 
 	c.WithID(record.ID)
@@ -73,32 +211,130 @@ func (c *ContextWrapper) from(record Record) error {
 	if record.Extensions != nil {
 		c.Operation.WithMapOfAnything(record.Extensions)
 	}
+	if record.Deprecated {
+		c.Operation.WithDeprecated(true)
+	}
+	if len(record.FeatureFlags) > 0 {
+		c.Operation.WithMapOfAnythingItem("x-feature-flags", record.FeatureFlags)
+	}
+	if record.Owner != "" {
+		c.Operation.WithMapOfAnythingItem("x-owner", record.Owner)
+	}
+	if record.SignedURL {
+		c.Operation.WithSecurity(map[string][]string{mason.SignedURLScheme: {}})
+		c.reflector.Spec.ComponentsEns().WithSecuritySchemesItem(mason.SignedURLScheme, signedURLScheme())
+	}
+	if c.reflector.translations && len(record.Translations) > 0 {
+		c.Operation.WithMapOfAnythingItem("x-translations", record.Translations)
+	}
 
 	return nil
 }
 
 // addReqStructure provides duplicate-detection to the openapi-go AddReqStructure method.
-func (c ContextWrapper) addReqStructure(o mason.Model, options ...openapi.ContentOption) error {
+func (c ContextWrapper) addReqStructure(o *mason.Model, options ...openapi.ContentOption) error {
 	if err := c.reflector.addModel(o); err != nil {
 		return fmt.Errorf("failed to add definition for %s: %w", o.Name(), err)
 	}
 
-	c.OperationContext.AddReqStructure(o, options...)
+	c.OperationContext.AddReqStructure(*o, options...)
 
 	return nil
 }
 
 // addRespStructure provides duplicate-detection to the openapi-go AddRespStructure method.
-func (c ContextWrapper) addRespStructure(o mason.Model, options ...openapi.ContentOption) error {
+func (c ContextWrapper) addRespStructure(o *mason.Model, options ...openapi.ContentOption) error {
 	if err := c.reflector.addModel(o); err != nil {
 		return fmt.Errorf("failed to add definition for %s: %w", o.Name(), err)
 	}
 
-	c.OperationContext.AddRespStructure(o, options...)
+	c.OperationContext.AddRespStructure(*o, options...)
+
+	return nil
+}
+
+// addErrorResponses documents the errors of the operation, one response per status. The errors sharing a status must
+// share the entity too, and their codes are listed in the description of the response.
+func (c ContextWrapper) addErrorResponses(record *Record) error {
+	errs := record.Errors
+	statuses := []int{}
+	byStatus := make(map[int][]*ErrorRecord)
+	for i := range errs {
+		e := &errs[i]
+		if _, ok := byStatus[e.Status]; !ok {
+			statuses = append(statuses, e.Status)
+		}
+		byStatus[e.Status] = append(byStatus[e.Status], e)
+	}
+
+	for _, status := range statuses {
+		errs := byStatus[status]
+		codes := make([]string, 0, len(errs))
+		retryable := false
+		for _, e := range errs {
+			retryable = retryable || e.Retryable
+			if e.Output.Name() != errs[0].Output.Name() {
+				return fmt.Errorf("errors %s and %s share status %d with different entities", errs[0].Code, e.Code, status)
+			}
+			codes = append(codes, "`"+e.Code+"`")
+		}
+
+		desc := record.responseDescription(status) + " Error codes: " + strings.Join(codes, ", ") + "."
+		options := []openapi.ContentOption{
+			openapi.WithHTTPStatus(status),
+			withResponseDescription(desc),
+		}
+		if retryable {
+			options = append(options, withResponseHeaders(retryHeaders))
+		}
+		err := c.addRespStructure(&errs[0].Output, options...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addContentResponses documents the responses to the requests that do not follow the content policy of the operation,
+// unless the operation documents errors with the same status.
+func (c ContextWrapper) addContentResponses(record *Record) error {
+	if record.Content == nil {
+		return nil
+	}
+
+	statuses := []int{}
+	if record.Input != nil && !record.Input.IsNil() {
+		statuses = append(statuses, http.StatusUnsupportedMediaType)
+	}
+	if record.Content.ResponseCharset != "" {
+		statuses = append(statuses, http.StatusNotAcceptable)
+	}
+
+	for _, status := range statuses {
+		if slices.ContainsFunc(record.Errors, func(e ErrorRecord) bool { return e.Status == status }) {
+			continue
+		}
+		apiErr := mason.NewModel(&model.APIError{}, mason.RefPrefix(c.reflector.refPrefix)).WithComponentNaming(c.reflector.rename)
+		err := c.addRespStructure(&apiErr,
+			openapi.WithHTTPStatus(status),
+			withResponseDescription(record.responseDescription(status)),
+		)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// withResponseDescription sets the description of the response.
+func withResponseDescription(desc string) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		cu.Description = desc
+	}
+}
+
 func NewContextWrapper(ctx openapi.OperationContext, r *Reflector) *ContextWrapper {
 	ctxWrapper := ContextWrapper{
 		OperationContext: ctx,
@@ -120,9 +356,29 @@ func forEachPathParam(method string, path string, f func(string)) {
 	}
 }
 
-func makeRequiredPathParam(param string) openapi31.ParameterOrReference {
+// makeRequiredPathParam documents a path param, with the type and pattern it is constrained to.
+func makeRequiredPathParam(param string, constraint mason.PathParam) openapi31.ParameterOrReference {
 	req := true
-	s, err := jsonschema.String.ToSchemaOrBool().ToSimpleMap()
+
+	var schema jsonschema.Schema
+	switch constraint.Type {
+	case mason.PathParamInteger:
+		schema.WithType(jsonschema.Integer.Type())
+	case mason.PathParamNumber:
+		schema.WithType(jsonschema.Number.Type())
+	case mason.PathParamBoolean:
+		schema.WithType(jsonschema.Boolean.Type())
+	case mason.PathParamUUID:
+		schema.WithType(jsonschema.String.Type())
+		schema.WithFormat("uuid")
+	default:
+		schema.WithType(jsonschema.String.Type())
+	}
+	if constraint.Pattern != "" {
+		schema.WithPattern("^(?:" + constraint.Pattern + ")$")
+	}
+
+	s, err := schema.ToSchemaOrBool().ToSimpleMap()
 	if err != nil {
 		return openapi31.ParameterOrReference{}
 	}
@@ -137,23 +393,55 @@ func makeRequiredPathParam(param string) openapi31.ParameterOrReference {
 	}
 }
 
-func forEachQueryParam(queryParams any, f func(string, string, string, string)) {
+// queryParam describes a query param derived from a field of the query param struct.
+type queryParam struct {
+	name   string
+	typ    string
+	format string
+	desc   string
+	// schema, when set, replaces the schema derived from typ and format.
+	schema *jsonschema.Schema
+	style  openapi31.ParameterStyle
+	// explode is only emitted together with style.
+	explode bool
+	// tags are the default, enum, example, required and deprecated tags of the field.
+	tags mason.QueryParamTags
+}
+
+func forEachQueryParam(queryParams any, f func(queryParam)) {
 	if queryParams == nil {
 		return
 	}
+	if schema, ok := mason.QuerySchemaOf(queryParams); ok {
+		forEachQuerySchemaParam(schema, f)
+		return
+	}
 
 	t := reflect.TypeOf(queryParams)
 	if t.Kind() != reflect.Struct {
 		return
 	}
 
+	forEachQueryField(t, f)
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	sortType   = reflect.TypeOf(mason.Sort{})
+	filterType = reflect.TypeOf(mason.Filter{})
+)
+
+func forEachQueryField(t reflect.Type, f func(queryParam)) {
 	descriptions := QueryParamDescriptions(t)
-	timeType := reflect.TypeOf(time.Time{})
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		tag := field.Tag.Get("json")
 		tag = strings.Split(tag, ",")[0]
 		if tag == "" {
+			// embedded param structs (e.g. mason.PageParams) are flattened
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				forEachQueryField(field.Type, f)
+			}
 			continue
 		}
 
@@ -161,40 +449,234 @@ func forEachQueryParam(queryParams any, f func(string, string, string, string))
 		if desc == "" {
 			desc = descriptions[field.Name]
 		}
+
+		tags := mason.ParseQueryParamTags(field.Tag)
+		emit := func(p queryParam) {
+			p.tags = tags
+			f(p)
+			// the aliases are documented as deprecated copies of the param
+			for _, alias := range tags.Aliases {
+				p.name = alias
+				p.desc = fmt.Sprintf("Deprecated alias of %s.", tag)
+				p.tags.Required = false
+				p.tags.Deprecated = true
+				f(p)
+			}
+		}
+
+		switch field.Type {
+		case sortType:
+			emit(sortParam(tag, desc, field.Tag))
+			continue
+		case filterType:
+			emit(filterParam(tag, desc, field.Tag))
+			continue
+		}
+
+		if mason.IsObjectQueryParam(field.Type) {
+			emit(objectParam(tag, desc, field.Type))
+			continue
+		}
+
 		switch field.Type.Kind() {
 		case reflect.String:
-			f(tag, "string", "", desc)
+			emit(queryParam{name: tag, typ: "string", desc: desc})
 		case reflect.Int:
-			f(tag, "integer", "", desc)
+			emit(queryParam{name: tag, typ: "integer", desc: desc})
 		case reflect.Bool:
-			f(tag, "boolean", "", desc)
+			emit(queryParam{name: tag, typ: "boolean", desc: desc})
 		case reflect.Struct:
 			if field.Type == timeType {
-				f(tag, "string", "date-time", desc)
+				emit(queryParam{name: tag, typ: "string", format: "date-time", desc: desc})
 			}
 		case reflect.Ptr:
 			switch field.Type.Elem().Kind() {
 			case reflect.String:
-				f(tag, "string", "", desc)
+				emit(queryParam{name: tag, typ: "string", desc: desc})
 			case reflect.Int:
-				f(tag, "integer", "", desc)
+				emit(queryParam{name: tag, typ: "integer", desc: desc})
 			case reflect.Bool:
-				f(tag, "boolean", "", desc)
+				emit(queryParam{name: tag, typ: "boolean", desc: desc})
 			case reflect.Struct:
 				if field.Type.Elem() == timeType {
-					f(tag, "string", "date-time", desc)
+					emit(queryParam{name: tag, typ: "string", format: "date-time", desc: desc})
 				}
 			}
 		}
 	}
 }
 
-func makeOptionalQueryParam(name string, t string, format string, desc string) openapi31.ParameterOrReference {
-	req := false
+// forEachQuerySchemaParam documents the properties of a query schema, see mason.WithQuerySchema, with their schemas
+// used verbatim.
+func forEachQuerySchemaParam(schema []byte, f func(queryParam)) {
+	var obj struct {
+		Properties map[string]jsonschema.Schema `json:"properties"`
+		Required   []string                     `json:"required"`
+	}
+	if err := json.Unmarshal(schema, &obj); err != nil {
+		return
+	}
+
+	names := make([]string, 0, len(obj.Properties))
+	for name := range obj.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := obj.Properties[name]
+		p := queryParam{name: name, schema: &prop}
+		if prop.Description != nil {
+			p.desc = *prop.Description
+		}
+		p.tags.Required = slices.Contains(obj.Required, name)
+		p.tags.Deprecated = prop.Deprecated != nil && *prop.Deprecated
+		f(p)
+	}
+}
+
+// sortParam documents a mason.Sort field as a comma separated list of the sortable fields, optionally prefixed with -.
+func sortParam(name string, desc string, tag reflect.StructTag) queryParam {
+	enum := []interface{}{}
+	for _, field := range mason.SortableFields(tag) {
+		enum = append(enum, field, "-"+field)
+	}
+
+	items := jsonschema.String.ToSchemaOrBool()
+	items.TypeObject.WithEnum(enum...)
+
+	var schema jsonschema.Schema
+	schema.WithType(jsonschema.Array.Type())
+	schema.WithItems(*(&jsonschema.Items{}).WithSchemaOrBool(items))
+
+	return queryParam{name: name, desc: desc, schema: &schema, style: openapi31.ParameterStyleForm, explode: false}
+}
+
+// fieldsParam documents the fields param of operations with field selection, e.g. fields=id,author.name.
+func fieldsParam() queryParam {
+	var schema jsonschema.Schema
+	schema.WithType(jsonschema.Array.Type())
+	schema.WithItems(*(&jsonschema.Items{}).WithSchemaOrBool(jsonschema.String.ToSchemaOrBool()))
+
+	return queryParam{
+		name:    mason.FieldsParam,
+		desc:    "Comma separated list of fields to include in the response. Nested fields are separated by dots.",
+		schema:  &schema,
+		style:   openapi31.ParameterStyleForm,
+		explode: false,
+	}
+}
+
+// filterParam documents a mason.Filter field as a deepObject param, e.g. filter[status][in]=a,b.
+func filterParam(name string, desc string, tag reflect.StructTag) queryParam {
 	var schema jsonschema.Schema
-	if t != "" {
+	schema.WithType(jsonschema.Object.Type())
+	schema.WithAdditionalProperties(jsonschema.SchemaOrBool{TypeBoolean: ptr(false)})
+
+	for field, ops := range mason.FilterableFields(tag) {
+		var fieldSchema jsonschema.Schema
+		fieldSchema.WithType(jsonschema.Object.Type())
+		fieldSchema.WithAdditionalProperties(jsonschema.SchemaOrBool{TypeBoolean: ptr(false)})
+		for _, op := range ops {
+			fieldSchema.WithPropertiesItem(string(op), jsonschema.String.ToSchemaOrBool())
+		}
+		schema.WithPropertiesItem(field, fieldSchema.ToSchemaOrBool())
+	}
+
+	return queryParam{name: name, desc: desc, schema: &schema, style: openapi31.ParameterStyleDeepObject, explode: true}
+}
+
+// objectParam documents a struct field as a deepObject param, e.g. price[min]=1&price[max]=5, see
+// mason.IsObjectQueryParam.
+func objectParam(name string, desc string, t reflect.Type) queryParam {
+	schema := objectParamSchema(t)
+	return queryParam{name: name, desc: desc, schema: &schema, style: openapi31.ParameterStyleDeepObject, explode: true}
+}
+
+// objectParamSchema returns the schema of an object param, with the fields documented like the query params.
+func objectParamSchema(t reflect.Type) jsonschema.Schema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var schema jsonschema.Schema
+	schema.WithType(jsonschema.Object.Type())
+	schema.WithAdditionalProperties(jsonschema.SchemaOrBool{TypeBoolean: ptr(false)})
+	forEachObjectField(t, func(tag string, field reflect.StructField) {
+		var prop jsonschema.Schema
+		if mason.IsObjectQueryParam(field.Type) {
+			prop = objectParamSchema(field.Type)
+		} else {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			var typ string
+			switch {
+			case ft == timeType:
+				typ = "string"
+				prop.WithFormat("date-time")
+			case ft.Kind() == reflect.String:
+				typ = "string"
+			case ft.Kind() == reflect.Int:
+				typ = "integer"
+			case ft.Kind() == reflect.Bool:
+				typ = "boolean"
+			default:
+				// e.g. the sort and filter params, which are not documented within objects
+				return
+			}
+			prop.WithType(jsonschema.SimpleType(typ).Type())
+
+			tags := mason.ParseQueryParamTags(field.Tag)
+			if tags.Default != "" {
+				prop.WithDefault(typedQueryValue(typ, tags.Default))
+			}
+			for _, v := range tags.Enum {
+				prop.Enum = append(prop.Enum, typedQueryValue(typ, v))
+			}
+			if tags.Example != "" {
+				prop.WithExamples(typedQueryValue(typ, tags.Example))
+			}
+		}
+		if doc := field.Tag.Get("doc"); doc != "" {
+			prop.WithDescription(doc)
+		}
+		if mason.ParseQueryParamTags(field.Tag).Required {
+			schema.Required = append(schema.Required, tag)
+		}
+		schema.WithPropertiesItem(tag, prop.ToSchemaOrBool())
+	})
+
+	return schema
+}
+
+// forEachObjectField calls f for every json-tagged field of an object param, flattening embedded structs.
+func forEachObjectField(t reflect.Type, f func(tag string, field reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "" {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				forEachObjectField(field.Type, f)
+			}
+			continue
+		}
+		if tag != "-" {
+			f(tag, field)
+		}
+	}
+}
+
+func makeQueryParam(p queryParam) openapi31.ParameterOrReference {
+	req := p.tags.Required
+	var schema jsonschema.Schema
+	if p.schema != nil {
+		schema = *p.schema
+	}
+	if p.typ != "" {
 		var jt jsonschema.Type
-		switch t {
+		switch p.typ {
 		case "string":
 			jt.WithSimpleTypes(jsonschema.String)
 		case "integer":
@@ -206,22 +688,415 @@ func makeOptionalQueryParam(name string, t string, format string, desc string) o
 		}
 		schema.WithType(jt)
 	}
-	if format != "" {
+	if p.format != "" {
+		format := p.format
 		schema.Format = &format
 	}
+	// the values of the typed params are documented with their type, the ones of the schema params are left as is
+	if p.typ != "" {
+		if p.tags.Default != "" {
+			schema.WithDefault(typedQueryValue(p.typ, p.tags.Default))
+		}
+		for _, v := range p.tags.Enum {
+			schema.Enum = append(schema.Enum, typedQueryValue(p.typ, v))
+		}
+		if p.tags.Example != "" {
+			schema.WithExamples(typedQueryValue(p.typ, p.tags.Example))
+		}
+	}
 	s, err := schema.ToSchemaOrBool().ToSimpleMap()
 	if err != nil {
 		return openapi31.ParameterOrReference{}
 	}
 
 	param := &openapi31.Parameter{
-		Name:     name,
+		Name:     p.name,
 		In:       openapi31.ParameterInQuery,
 		Required: &req,
 		Schema:   s,
 	}
-	if desc != "" {
-		param.WithDescription(desc)
+	if p.desc != "" {
+		param.WithDescription(p.desc)
+	}
+	if p.style != "" {
+		param.WithStyle(p.style)
+		param.WithExplode(p.explode)
+	}
+	if p.tags.Deprecated {
+		param.WithDeprecated(true)
+	}
+	return openapi31.ParameterOrReference{Parameter: param}
+}
+
+// typedQueryValue converts a value from the struct tags of a query param to the type of the param.
+func typedQueryValue(typ string, v string) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+
+	return v
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// paginationHeaders are set by the runtime on responses that implement model.Paginated.
+var paginationHeaders = map[string]string{
+	"Link":          "Links to the next and previous pages (RFC 8288).",
+	"X-Next-Cursor": "Cursor for the next page, to be passed as the after query param.",
+}
+
+// lastModifiedHeaders are set by the runtime on GET responses that implement model.TimestampedEntity.
+var lastModifiedHeaders = map[string]string{
+	"Last-Modified": "When the resource last changed, to be passed as the If-Modified-Since header.",
+}
+
+// ifModifiedSinceParam documents the header of the conditional requests, see model.TimestampedEntity.
+func ifModifiedSinceParam() openapi31.ParameterOrReference {
+	param := &openapi31.Parameter{
+		Name:   "If-Modified-Since",
+		In:     openapi31.ParameterInHeader,
+		Schema: map[string]interface{}{"type": "string"},
 	}
+	param.WithDescription("The Last-Modified of the cached version. The response is a 304 when the resource did not change since.")
+
 	return openapi31.ParameterOrReference{Parameter: param}
 }
+
+// fileHeaders are set by the runtime on the responses of a mason.File.
+var fileHeaders = map[string]string{
+	"Accept-Ranges":       "The unit of the ranges that can be requested with the Range header: `bytes`.",
+	"Content-Disposition": "The name of the file, when it has one.",
+}
+
+// partialContentHeaders are set by the runtime on the partial responses of a mason.File.
+var partialContentHeaders = map[string]string{
+	"Content-Range": "The range of the file in the response, and its size, e.g. `bytes 0-1023/4096`. Several ranges are sent as multipart/byteranges.",
+}
+
+// unsatisfiableRangeHeaders are set by the runtime when none of the ranges of a request is in the file.
+var unsatisfiableRangeHeaders = map[string]string{
+	"Content-Range": "The size of the file, e.g. `bytes */4096`.",
+}
+
+// rangeParams documents the headers of the Range requests, see mason.File.
+func rangeParams() []openapi31.ParameterOrReference {
+	rng := &openapi31.Parameter{
+		Name:   "Range",
+		In:     openapi31.ParameterInHeader,
+		Schema: map[string]interface{}{"type": "string"},
+	}
+	rng.WithDescription("The byte ranges to download, e.g. `bytes=0-1023`. The response is a 206 with the partial content.")
+
+	ifRange := &openapi31.Parameter{
+		Name:   "If-Range",
+		In:     openapi31.ParameterInHeader,
+		Schema: map[string]interface{}{"type": "string"},
+	}
+	ifRange.WithDescription("The Last-Modified of the file the ranges are from. The full file is sent when it changed since.")
+
+	return []openapi31.ParameterOrReference{{Parameter: rng}, {Parameter: ifRange}}
+}
+
+// withFileContent documents the content of a mason.File as binary, instead of JSON.
+func withFileContent() openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		customize := cu.Customize
+		cu.Customize = func(cor openapi.ContentOrReference) {
+			if customize != nil {
+				customize(cor)
+			}
+
+			rsp, ok := cor.(*openapi31.ResponseOrReference)
+			if !ok || rsp.Response == nil {
+				return
+			}
+			if mt, ok := rsp.Response.Content["application/json"]; ok {
+				delete(rsp.Response.Content, "application/json")
+				rsp.Response.Content["application/octet-stream"] = mt
+			}
+		}
+	}
+}
+
+// signedURLScheme documents the signature of the URLs signed by a mason.URLSigner. Their expiry is part of the
+// signature, and sent as the mason.ExpiresParam query param.
+func signedURLScheme() openapi31.SecuritySchemeOrReference {
+	scheme := &openapi31.SecurityScheme{
+		APIKey: &openapi31.SecuritySchemeAPIKey{Name: mason.SignatureParam, In: openapi31.SecuritySchemeAPIKeyInQuery},
+	}
+	scheme.WithDescription(fmt.Sprintf("A time-limited signed URL, with its expiry in the `%s` query param, in seconds "+
+		"since the epoch. Requests with an invalid or expired signature get a 403.", mason.ExpiresParam))
+
+	return openapi31.SecuritySchemeOrReference{SecurityScheme: scheme}
+}
+
+// retryHeaders are set by the runtime on the error responses with a delay, see mason.ErrorRetryAfter.
+var retryHeaders = map[string]string{
+	"Retry-After": "The number of seconds to wait before retrying, also sent in the retryAfter field of the error.",
+}
+
+// surrogateKeyHeaders are set by the runtime on GET responses with surrogate keys, see mason.SurrogateKeyHeader.
+var surrogateKeyHeaders = map[string]string{
+	mason.SurrogateKeyHeader: "The surrogate keys of the response, separated by spaces, which purge it from the caches of the CDN.",
+}
+
+// cacheHeaders are set by the runtime on responses of operations with a cache policy.
+func cacheHeaders(policy mason.CachePolicy) map[string]string {
+	headers := map[string]string{
+		"Cache-Control": fmt.Sprintf("Caching directives for the response: `%s`.", policy.CacheControl()),
+	}
+	if vary := policy.VaryHeader(); vary != "" {
+		headers["Vary"] = fmt.Sprintf("Request headers that select the cached representation: `%s`.", vary)
+	}
+
+	return headers
+}
+
+// withResponseHeaders documents string response headers, keyed by name with their description.
+func withResponseHeaders(headers map[string]string) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		customize := cu.Customize
+		cu.Customize = func(cor openapi.ContentOrReference) {
+			if customize != nil {
+				customize(cor)
+			}
+
+			rsp, ok := cor.(*openapi31.ResponseOrReference)
+			if !ok || rsp.Response == nil {
+				return
+			}
+
+			s, err := jsonschema.String.ToSchemaOrBool().ToSimpleMap()
+			if err != nil {
+				return
+			}
+			for name, desc := range headers {
+				header := openapi31.Header{Schema: s}
+				header.WithDescription(desc)
+				rsp.Response.WithHeadersItem(name, openapi31.HeaderOrReference{Header: &header})
+			}
+		}
+	}
+}
+
+// withRepresentations documents the alternate representations of a response next to its JSON content. Raw
+// representations are documented as strings.
+func withRepresentations(reps map[string]*mason.Model) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		customize := cu.Customize
+		cu.Customize = func(cor openapi.ContentOrReference) {
+			if customize != nil {
+				customize(cor)
+			}
+
+			rsp, ok := cor.(*openapi31.ResponseOrReference)
+			if !ok || rsp.Response == nil {
+				return
+			}
+
+			for ct, m := range reps {
+				schema := map[string]interface{}{"type": "string"}
+				if m != nil {
+					schema = map[string]interface{}{"$ref": m.RefPrefix() + m.ComponentName()}
+				}
+				rsp.Response.WithContentItem(ct, openapi31.MediaType{Schema: schema})
+			}
+		}
+	}
+}
+
+// withResponseCharset documents the charset of the response in its content types.
+func withResponseCharset(charset string) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		customize := cu.Customize
+		cu.Customize = func(cor openapi.ContentOrReference) {
+			if customize != nil {
+				customize(cor)
+			}
+
+			rsp, ok := cor.(*openapi31.ResponseOrReference)
+			if !ok || rsp.Response == nil {
+				return
+			}
+
+			content := make(map[string]openapi31.MediaType, len(rsp.Response.Content))
+			for ct, mt := range rsp.Response.Content {
+				content[ct+"; charset="+charset] = mt
+			}
+			rsp.Response.Content = content
+		}
+	}
+}
+
+// withRequestCharsets documents the other charsets accepted for the request body, as content types with a charset
+// next to the ones without.
+func withRequestCharsets(charsets []string) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		customize := cu.Customize
+		cu.Customize = func(cor openapi.ContentOrReference) {
+			if customize != nil {
+				customize(cor)
+			}
+
+			req, ok := cor.(*openapi31.RequestBodyOrReference)
+			if !ok || req.RequestBody == nil {
+				return
+			}
+
+			content := make(map[string]openapi31.MediaType, len(req.RequestBody.Content)*(len(charsets)+1))
+			for ct, mt := range req.RequestBody.Content {
+				content[ct] = mt
+				for _, charset := range charsets {
+					content[ct+"; charset="+charset] = mt
+				}
+			}
+			req.RequestBody.Content = content
+		}
+	}
+}
+
+// contentEncodingParam documents the content codings accepted for the request body as a Content-Encoding header,
+// required when the uncompressed bodies are refused.
+func contentEncodingParam(policy mason.ContentPolicy) (openapi31.ParameterOrReference, bool) {
+	identity := len(policy.Encodings) == 0
+	enum := []interface{}{}
+	for _, coding := range policy.Encodings {
+		if strings.EqualFold(coding, "identity") {
+			identity = true
+			continue
+		}
+		enum = append(enum, strings.ToLower(coding))
+	}
+	if len(enum) == 0 {
+		return openapi31.ParameterOrReference{}, false
+	}
+
+	var schema jsonschema.Schema
+	schema.WithType(jsonschema.String.Type())
+	schema.WithEnum(enum...)
+	s, err := schema.ToSchemaOrBool().ToSimpleMap()
+	if err != nil {
+		return openapi31.ParameterOrReference{}, false
+	}
+
+	req := !identity
+	param := &openapi31.Parameter{
+		Name:     "Content-Encoding",
+		In:       openapi31.ParameterInHeader,
+		Required: &req,
+		Schema:   s,
+	}
+	param.WithDescription("Content coding of the request body.")
+
+	return openapi31.ParameterOrReference{Parameter: param}, true
+}
+
+// withRequestContentType documents the request body with another content type than JSON, keeping its JSON schema,
+// which the reflector would otherwise replace with a string.
+func withRequestContentType(contentType string) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		customize := cu.Customize
+		cu.Customize = func(cor openapi.ContentOrReference) {
+			if customize != nil {
+				customize(cor)
+			}
+
+			req, ok := cor.(*openapi31.RequestBodyOrReference)
+			if !ok || req.RequestBody == nil {
+				return
+			}
+			if mt, ok := req.RequestBody.Content["application/json"]; ok {
+				delete(req.RequestBody.Content, "application/json")
+				req.RequestBody.Content[contentType] = mt
+			}
+		}
+	}
+}
+
+// withEntityExample documents the example of the entity as a named example of the content with its schema, so the
+// documentation UIs show it with the operation.
+func withEntityExample(m mason.Model) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		customize := cu.Customize
+		cu.Customize = func(cor openapi.ContentOrReference) {
+			if customize != nil {
+				customize(cor)
+			}
+
+			var value interface{}
+			if err := json.Unmarshal(m.Example(), &value); err != nil {
+				return
+			}
+
+			var content map[string]openapi31.MediaType
+			switch c := cor.(type) {
+			case *openapi31.ResponseOrReference:
+				if c.Response != nil {
+					content = c.Response.Content
+				}
+			case *openapi31.RequestBodyOrReference:
+				if c.RequestBody != nil {
+					content = c.RequestBody.Content
+				}
+			}
+
+			ref := m.RefPrefix() + m.ComponentName()
+			for ct, mt := range content {
+				if mt.Schema["$ref"] != ref {
+					continue
+				}
+				example := openapi31.Example{Value: &value}
+				example.WithSummary("Example " + m.ComponentName())
+				mt.WithExamplesItem(m.ComponentName(), openapi31.ExampleOrReference{Example: &example})
+				content[ct] = mt
+			}
+		}
+	}
+}
+
+// withSampleExamples documents recorded samples as the named examples of a request or response body.
+func withSampleExamples(samples []mason.Sample, body func(mason.Sample) json.RawMessage) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		customize := cu.Customize
+		cu.Customize = func(cor openapi.ContentOrReference) {
+			if customize != nil {
+				customize(cor)
+			}
+
+			var content map[string]openapi31.MediaType
+			switch c := cor.(type) {
+			case *openapi31.ResponseOrReference:
+				if c.Response != nil {
+					content = c.Response.Content
+				}
+			case *openapi31.RequestBodyOrReference:
+				if c.RequestBody != nil {
+					content = c.RequestBody.Content
+				}
+			}
+
+			for ct, mt := range content {
+				for i, sample := range samples {
+					var value interface{}
+					if err := json.Unmarshal(body(sample), &value); err != nil {
+						continue
+					}
+
+					example := openapi31.Example{Value: &value}
+					example.WithSummary(sample.Method + " " + sample.URL)
+					mt.WithExamplesItem(fmt.Sprintf("sample_%d", i+1), openapi31.ExampleOrReference{Example: &example})
+				}
+				content[ct] = mt
+			}
+		}
+	}
+}
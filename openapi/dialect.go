@@ -0,0 +1,56 @@
+package openapi
+
+// DefaultDialect is the JSON Schema dialect of the schemas of an OpenAPI 3.1 spec, unless the spec declares another.
+const DefaultDialect = "https://spec.openapis.org/oas/3.1/dialect/base"
+
+// SchemaDeclaration is how the generator handles the $schema keywords of the entity schemas, see SchemaDeclarations.
+type SchemaDeclaration int
+
+const (
+	// SchemaKeep keeps the $schema keywords of the entity schemas as they are.
+	SchemaKeep SchemaDeclaration = iota
+	// SchemaStrip removes the $schema keywords, so the schemas use the dialect of the spec.
+	SchemaStrip
+	// SchemaNormalize replaces the $schema keywords with the dialect of the spec.
+	SchemaNormalize
+)
+
+// JSONSchemaDialect sets the jsonSchemaDialect field of the spec, the dialect of the schemas that do not declare one
+// with $schema.
+func JSONSchemaDialect(uri string) openAPIOption {
+	return func(c *config) {
+		c.dialect = uri
+	}
+}
+
+// SchemaDeclarations sets how the $schema keywords of the entity schemas are documented, kept by default. Some
+// validators reject components declaring a dialect other than the one of the spec.
+func SchemaDeclarations(decl SchemaDeclaration) openAPIOption {
+	return func(c *config) {
+		c.schemaDecl = decl
+	}
+}
+
+// dialectOf returns the dialect of the schemas of the spec.
+func (c config) dialectOf() string {
+	if c.dialect != "" {
+		return c.dialect
+	}
+	return DefaultDialect
+}
+
+// declareSchema applies the handling of the $schema keywords to a component and its subschemas.
+func (r *Reflector) declareSchema(schema map[string]any) {
+	if r.schemaDecl == SchemaKeep {
+		return
+	}
+
+	if _, ok := schema["$schema"]; ok {
+		if r.schemaDecl == SchemaStrip {
+			delete(schema, "$schema")
+		} else {
+			schema["$schema"] = r.dialect
+		}
+	}
+	forEachSubschema(schema, r.declareSchema)
+}
@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+)
+
+// SchemaDifference is a difference between two schemas at a JSON pointer, e.g. /properties/name/type. Old or New is
+// nil when the value is only in one of them.
+type SchemaDifference struct {
+	Path string
+	Old  any
+	New  any
+}
+
+func (d SchemaDifference) String() string {
+	switch {
+	case d.Old == nil:
+		return fmt.Sprintf("%s: added %s", d.Path, compactJSON(d.New))
+	case d.New == nil:
+		return fmt.Sprintf("%s: removed %s", d.Path, compactJSON(d.Old))
+	default:
+		return fmt.Sprintf("%s: %s != %s", d.Path, compactJSON(d.Old), compactJSON(d.New))
+	}
+}
+
+// DiffSchemas returns the differences between two schemas, ignoring their examples, sorted by path.
+func DiffSchemas(a jsonschema.Schema, b jsonschema.Schema) []SchemaDifference {
+	a.Examples = nil
+	b.Examples = nil
+
+	var diffs []SchemaDifference
+	diffValues("", toJSONValue(a), toJSONValue(b), &diffs)
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+
+	return diffs
+}
+
+// DefinitionConflictError is returned when two entities define a component with the same name but different schemas.
+type DefinitionConflictError struct {
+	Name        string
+	Differences []SchemaDifference
+}
+
+func (e *DefinitionConflictError) Error() string {
+	diffs := make([]string, len(e.Differences))
+	for i, d := range e.Differences {
+		diffs[i] = d.String()
+	}
+
+	return fmt.Sprintf("definition with name [%s] already exists but with a different definition: %s", e.Name, strings.Join(diffs, "; "))
+}
+
+func diffValues(path string, a any, b any, diffs *[]SchemaDifference) {
+	am, aIsMap := a.(map[string]any)
+	bm, bIsMap := b.(map[string]any)
+	if aIsMap && bIsMap {
+		for key, av := range am {
+			diffValues(path+"/"+escapePointer(key), av, bm[key], diffs)
+		}
+		for key, bv := range bm {
+			if _, ok := am[key]; !ok {
+				diffValues(path+"/"+escapePointer(key), nil, bv, diffs)
+			}
+		}
+		return
+	}
+
+	as, aIsSlice := a.([]any)
+	bs, bIsSlice := b.([]any)
+	if aIsSlice && bIsSlice && len(as) == len(bs) {
+		for i := range as {
+			diffValues(path+"/"+strconv.Itoa(i), as[i], bs[i], diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		if path == "" {
+			path = "/"
+		}
+		*diffs = append(*diffs, SchemaDifference{Path: path, Old: a, New: b})
+	}
+}
+
+func toJSONValue(schema jsonschema.Schema) any {
+	b, err := schema.MarshalJSON()
+	if err != nil {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// escapePointer escapes a key as a JSON pointer token.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func compactJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SpecExtensions sets x- extensions at the root of the spec, e.g. x-api-id.
+func SpecExtensions(ext map[string]any) openAPIOption {
+	return func(c *config) {
+		c.specExt = ext
+	}
+}
+
+// InfoExtensions sets x- extensions on the info object of the spec, e.g. x-audience.
+func InfoExtensions(ext map[string]any) openAPIOption {
+	return func(c *config) {
+		c.infoExt = ext
+	}
+}
+
+// ServerExtensions sets x- extensions on each server of the spec, including the servers set after the generator is
+// created.
+func ServerExtensions(ext map[string]any) openAPIOption {
+	return func(c *config) {
+		c.serverExt = ext
+	}
+}
+
+// checkExtensions returns an error when a key of the spec extensions does not start with x-.
+func (c config) checkExtensions() error {
+	for _, ext := range []map[string]any{c.specExt, c.infoExt, c.serverExt} {
+		for key := range ext {
+			if !strings.HasPrefix(key, "x-") {
+				return fmt.Errorf("invalid extension key [%s]: custom keys must start with 'x-'", key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyHeader sets the extensions and the dialect of the config on the header of the spec. It is idempotent, so it runs
+// before every generation, after the header may have been customized.
+func (g *Generator) applyHeader() {
+	if g.config.dialect != "" {
+		g.Spec.WithJSONSchemaDialect(g.config.dialect)
+	}
+	for key, val := range g.config.specExt {
+		g.Spec.WithMapOfAnythingItem(key, val)
+	}
+	for key, val := range g.config.infoExt {
+		g.Spec.Info.WithMapOfAnythingItem(key, val)
+	}
+	for i := range g.Spec.Servers {
+		for key, val := range g.config.serverExt {
+			g.Spec.Servers[i].WithMapOfAnythingItem(key, val)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/swaggest/openapi-go/openapi31"
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/internal/casing"
+	"github.com/tailbits/mason/model"
+)
+
+var _ model.Entity = (*ImportedEntity)(nil)
+
+// ImportedEntity is a schema-backed entity created from a third-party OpenAPI document.
+// Its data is kept as raw JSON, since there is no Go type behind it.
+type ImportedEntity struct {
+	name    string
+	schema  []byte
+	example []byte
+	data    json.RawMessage
+}
+
+func (e *ImportedEntity) Name() string {
+	return e.name
+}
+
+func (e *ImportedEntity) Schema() []byte {
+	return e.schema
+}
+
+func (e *ImportedEntity) Example() []byte {
+	return e.example
+}
+
+func (e *ImportedEntity) Marshal() (json.RawMessage, error) {
+	return e.data, nil
+}
+
+func (e *ImportedEntity) Unmarshal(data json.RawMessage) error {
+	e.data = append(e.data[:0], data...)
+	return nil
+}
+
+// Import parses an OpenAPI 3.1 document into a mason registry, and the entities backing its component schemas.
+// Operations are grouped by their first tag (or first path segment), and refs are rewritten to the #/definitions/
+// form used by mason entities, so the result can be diffed against a generated spec or used to scaffold handlers.
+func Import(spec []byte) (*mason.Registry, []model.Entity, error) {
+	var doc openapi31.Spec
+	if err := doc.UnmarshalJSON(spec); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	imp := importer{entities: make(map[string]*ImportedEntity)}
+
+	if doc.Components != nil {
+		for name, sch := range doc.Components.Schemas {
+			if _, err := imp.addEntity(name, sch); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	registry := make(mason.Registry)
+	if doc.Paths != nil {
+		for path, item := range doc.Paths.MapOfPathItemValues {
+			for method, op := range pathOperations(item) {
+				mop, err := imp.toOperation(method, path, op)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%s %s: %w", method, path, err)
+				}
+				registry.AddOp(importGroup(path, op), mop)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(imp.entities))
+	for name := range imp.entities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entities := make([]model.Entity, 0, len(names))
+	for _, name := range names {
+		entities = append(entities, imp.entities[name])
+	}
+
+	return &registry, entities, nil
+}
+
+type importer struct {
+	entities map[string]*ImportedEntity
+}
+
+func (imp *importer) addEntity(name string, sch map[string]interface{}) (*ImportedEntity, error) {
+	example := []byte("{}")
+	if ex, ok := sch["examples"].([]interface{}); ok && len(ex) > 0 {
+		example, _ = json.Marshal(ex[0])
+	} else if ex, ok := sch["example"]; ok {
+		example, _ = json.Marshal(ex)
+	}
+
+	raw, err := json.Marshal(sch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema %s: %w", name, err)
+	}
+	raw = []byte(strings.ReplaceAll(string(raw), `"#/components/schemas/`, `"#/definitions/`))
+
+	ent := &ImportedEntity{name: name, schema: raw, example: example}
+	imp.entities[name] = ent
+
+	return ent, nil
+}
+
+// entityFor resolves a media type schema to an entity, creating one named fallback for inline schemas.
+func (imp *importer) entityFor(content map[string]openapi31.MediaType, fallback string) (model.Entity, error) {
+	media, ok := content["application/json"]
+	if !ok || media.Schema == nil {
+		return nil, nil
+	}
+
+	if ref, ok := media.Schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		ent, ok := imp.entities[name]
+		if !ok {
+			return nil, fmt.Errorf("schema %s not found", ref)
+		}
+		return ent, nil
+	}
+
+	ent, err := imp.addEntity(fallback, media.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if media.Example != nil {
+		ent.example, _ = json.Marshal(*media.Example)
+	}
+
+	return ent, nil
+}
+
+func (imp *importer) toOperation(method string, path string, op *openapi31.Operation) (mason.Operation, error) {
+	opID := strings.ToLower(method) + "_" + strings.NewReplacer("/", "_", "{", "", "}", "").Replace(strings.Trim(path, "/"))
+	if op.ID != nil {
+		opID = *op.ID
+	}
+
+	mop := mason.Operation{
+		OperationID: opID,
+		Method:      method,
+		Path:        path,
+		Tags:        op.Tags,
+		Extensions:  op.MapOfAnything,
+		Output:      model.Nil{},
+		QueryParams: queryParamsStruct(op.Parameters),
+	}
+	if op.Summary != nil {
+		mop.Summary = *op.Summary
+	}
+	if op.Description != nil {
+		mop.Description = *op.Description
+	}
+
+	if op.RequestBody != nil && op.RequestBody.RequestBody != nil {
+		ent, err := imp.entityFor(op.RequestBody.RequestBody.Content, opID+"_request")
+		if err != nil {
+			return mop, err
+		}
+		mop.Input = ent
+	}
+
+	if op.Responses != nil {
+		code, rsp := successResponse(op.Responses)
+		mop.SuccessCode = code
+		if rsp != nil {
+			ent, err := imp.entityFor(rsp.Content, opID+"_response")
+			if err != nil {
+				return mop, err
+			}
+			if ent != nil {
+				mop.Output = ent
+			}
+		}
+	}
+
+	return mop, nil
+}
+
+// successResponse returns the lowest 2xx response of an operation.
+func successResponse(rsps *openapi31.Responses) (int, *openapi31.Response) {
+	best := 0
+	var rsp *openapi31.Response
+	for status, r := range rsps.MapOfResponseOrReferenceValues {
+		code, err := strconv.Atoi(status)
+		if err != nil || code < 200 || code > 299 {
+			continue
+		}
+		if best == 0 || code < best {
+			best = code
+			rsp = r.Response
+		}
+	}
+
+	return best, rsp
+}
+
+// queryParamsStruct builds a struct value with one json-tagged field per query parameter,
+// which is the shape mason expects for Operation.QueryParams.
+func queryParamsStruct(params []openapi31.ParameterOrReference) any {
+	fields := []reflect.StructField{}
+	for _, p := range params {
+		if p.Parameter == nil || p.Parameter.In != openapi31.ParameterInQuery {
+			continue
+		}
+
+		var t reflect.Type
+		switch p.Parameter.Schema["type"] {
+		case "integer":
+			t = reflect.TypeOf(0)
+		case "boolean":
+			t = reflect.TypeOf(false)
+		default:
+			t = reflect.TypeOf("")
+		}
+
+		tag := fmt.Sprintf(`json:%q`, p.Parameter.Name)
+		if p.Parameter.Description != nil {
+			tag += fmt.Sprintf(` doc:%q`, *p.Parameter.Description)
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("P%d", len(fields)),
+			Type: t,
+			Tag:  reflect.StructTag(tag),
+		})
+	}
+
+	return reflect.New(reflect.StructOf(fields)).Elem().Interface()
+}
+
+func pathOperations(item openapi31.PathItem) map[string]*openapi31.Operation {
+	ops := map[string]*openapi31.Operation{
+		http.MethodGet:    item.Get,
+		http.MethodPut:    item.Put,
+		http.MethodPost:   item.Post,
+		http.MethodDelete: item.Delete,
+		http.MethodPatch:  item.Patch,
+	}
+	for method, op := range ops {
+		if op == nil {
+			delete(ops, method)
+		}
+	}
+
+	return ops
+}
+
+func importGroup(path string, op *openapi31.Operation) string {
+	if len(op.Tags) > 0 {
+		return casing.ToKebabCase(op.Tags[0])
+	}
+
+	return strings.SplitN(strings.Trim(path, "/"), "/", 2)[0]
+}
@@ -0,0 +1,35 @@
+package openapi_test
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason/openapi"
+	"gotest.tools/v3/assert"
+)
+
+func TestImport(t *testing.T) {
+	spec, err := os.ReadFile("testdata/basic_schema.json")
+	assert.NilError(t, err)
+
+	registry, entities, err := openapi.Import(spec)
+	assert.NilError(t, err)
+
+	names := []string{}
+	for _, ent := range entities {
+		names = append(names, ent.Name())
+	}
+	assert.DeepEqual(t, []string{"TestResourceA", "TestResourceB"}, names)
+
+	op, ok := registry.FindOp(http.MethodPut, "/test-a")
+	assert.Assert(t, ok)
+	assert.Equal(t, "create_test_resource", op.OperationID)
+	assert.Equal(t, "TestResourceA", op.Input.Name())
+	assert.Equal(t, "TestResourceA", op.Output.Name())
+	assert.Equal(t, http.StatusOK, op.SuccessCode)
+
+	// refs are rewritten to the form used by mason entities
+	assert.Assert(t, strings.Contains(string(op.Output.Schema()), "#/definitions/TestResourceB"))
+}
@@ -0,0 +1,45 @@
+package openapi
+
+import (
+	"maps"
+
+	"github.com/swaggest/jsonschema-go"
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+)
+
+// localize returns the schema of the component of the model with its description in the locale, or with the
+// x-translations of its descriptions, see Locale and Translations.
+func (r *Reflector) localize(m *mason.Model, schema jsonschema.Schema) jsonschema.Schema {
+	descs := localizedDescriptions(m.WithSchema)
+	if len(descs) == 0 {
+		return schema
+	}
+
+	if desc, ok := descs[r.locale]; ok && r.locale != "" {
+		schema.WithDescription(desc)
+	}
+	if r.translations {
+		translations := make(map[string]mason.Translation, len(descs))
+		for lang, desc := range descs {
+			translations[lang] = mason.Translation{Description: desc}
+		}
+		// the extra properties are shared with the cached schema of the model
+		schema.ExtraProperties = maps.Clone(schema.ExtraProperties)
+		schema.WithExtraPropertiesItem("x-translations", translations)
+	}
+
+	return schema
+}
+
+// localizedDescriptions returns the descriptions of the entity by language, see model.WithLocalizedDescription.
+func localizedDescriptions(ent model.WithSchema) map[string]string {
+	if e, ok := ent.(externalRefsEntity); ok {
+		ent = e.Entity
+	}
+	if l, ok := ent.(model.WithLocalizedDescription); ok {
+		return l.LocalizedDescriptions()
+	}
+
+	return nil
+}
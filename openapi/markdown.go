@@ -0,0 +1,173 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The rules of the findings on the Markdown of the descriptions, see ValidateMarkdown.
+const (
+	// MarkdownLinkRule reports the empty links, the relative links that break once the spec is published, and the
+	// anchors that match no heading of the description.
+	MarkdownLinkRule = "markdown-link"
+	// MarkdownHeadingRule reports the empty headings, the headings skipping a level and the duplicate headings, whose
+	// anchors are ambiguous.
+	MarkdownHeadingRule = "markdown-heading"
+)
+
+// ValidateMarkdown lints the CommonMark of the descriptions of the spec, e.g. the ones loaded with
+// mason.Builder.WithDescFile. The findings of the links are errors and the ones of the headings warnings, which go
+// through RuleSeverity and NonFatalWarnings like the findings of the Validator. Their line is the one in the
+// description.
+func ValidateMarkdown() openAPIOption {
+	return func(c *config) {
+		c.markdown = true
+	}
+}
+
+var (
+	headingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?[ \t]*#*[ \t]*$`)
+	linkRe    = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]*)>?(?:\s+"[^"]*")?\s*\)`)
+	fenceRe   = regexp.MustCompile("^ {0,3}(```|~~~)")
+	slugRe    = regexp.MustCompile(`[^\p{L}\p{N}\- ]`)
+	schemeRe  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.\-]*:`)
+)
+
+// lintMarkdown returns the findings on the Markdown of the descriptions of the spec.
+func lintMarkdown(spec []byte) ([]Finding, error) {
+	var doc any
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse the spec: %w", err)
+	}
+
+	var findings []Finding
+	walkDescriptions(doc, "$", func(path string, desc string) {
+		findings = append(findings, lintDescription(path, desc)...)
+	})
+
+	return findings, nil
+}
+
+// walkDescriptions calls fn with the descriptions of the spec and their JSON path, in the order of the keys. The
+// examples are skipped, since their description properties are data.
+func walkDescriptions(node any, path string, fn func(path string, desc string)) {
+	switch v := node.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if key == "example" || key == "examples" {
+				continue
+			}
+			child := jsonPath(path, key)
+			if desc, ok := v[key].(string); ok && key == "description" {
+				fn(child, desc)
+				continue
+			}
+			walkDescriptions(v[key], child, fn)
+		}
+	case []any:
+		for i, item := range v {
+			walkDescriptions(item, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	}
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func jsonPath(path string, key string) string {
+	if identRe.MatchString(key) {
+		return path + "." + key
+	}
+
+	return fmt.Sprintf("%s['%s']", path, strings.ReplaceAll(key, "'", `\'`))
+}
+
+// lintDescription returns the findings on the links and the headings of a description, outside of its code blocks.
+func lintDescription(path string, desc string) []Finding {
+	var findings []Finding
+	report := func(rule string, severity string, line int, format string, args ...any) {
+		findings = append(findings, Finding{
+			Rule:     rule,
+			Severity: severity,
+			Path:     path,
+			Line:     line,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	type link struct {
+		dest string
+		line int
+	}
+	var links []link
+	anchors := make(map[string]bool)
+	level := 0
+	fence := ""
+	for i, line := range strings.Split(desc, "\n") {
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			switch fence {
+			case "":
+				fence = m[1]
+			case m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			text := strings.TrimSpace(m[2])
+			switch {
+			case text == "":
+				report(MarkdownHeadingRule, SeverityWarn, i+1, "empty heading")
+			case level > 0 && len(m[1]) > level+1:
+				report(MarkdownHeadingRule, SeverityWarn, i+1, "heading %q skips from level %d to %d", text, level, len(m[1]))
+			}
+			if text != "" {
+				anchor := slugify(text)
+				if anchors[anchor] {
+					report(MarkdownHeadingRule, SeverityWarn, i+1, "duplicate heading %q", text)
+				}
+				anchors[anchor] = true
+			}
+			level = len(m[1])
+			continue
+		}
+
+		for _, m := range linkRe.FindAllStringSubmatch(line, -1) {
+			links = append(links, link{dest: m[1], line: i + 1})
+		}
+	}
+
+	// the anchors can point at the headings below the link
+	for _, l := range links {
+		switch {
+		case l.dest == "":
+			report(MarkdownLinkRule, SeverityError, l.line, "empty link")
+		case strings.HasPrefix(l.dest, "#"):
+			if !anchors[strings.ToLower(strings.TrimPrefix(l.dest, "#"))] {
+				report(MarkdownLinkRule, SeverityError, l.line, "anchor %q matches no heading", l.dest)
+			}
+		case !schemeRe.MatchString(l.dest) && !strings.HasPrefix(l.dest, "/"):
+			report(MarkdownLinkRule, SeverityError, l.line, "relative link %q, use an absolute URL", l.dest)
+		}
+	}
+
+	return findings
+}
+
+// slugify returns the anchor of a heading, the way the documentation UIs derive it, e.g. getting-started for Getting
+// Started.
+func slugify(text string) string {
+	slug := slugRe.ReplaceAllString(strings.ToLower(text), "")
+	return strings.ReplaceAll(slug, " ", "-")
+}
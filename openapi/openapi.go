@@ -1,17 +1,50 @@
 package openapi
 
-import "github.com/tailbits/mason"
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+)
 
 type config struct {
-	validate    bool
-	filterFn    func(Record) bool
-	tagsFn      func(mason.Operation) []string
-	allTags     []string
-	transformFn func(*Record)
+	validate     bool
+	filterFn     func(Record) bool
+	tagsFn       func(mason.Operation) []string
+	allTags      []string
+	transformFn  func(*Record)
+	examples     ExampleSource
+	entityEx     bool
+	inputSuffix  string
+	visibility   []mason.OperationVisibility
+	features     []string
+	byFeatures   bool
+	rollouts     func(flag string) bool
+	locale       string
+	translations bool
+	markdown     bool
+	validator    Validator
+	severities   map[string]string
+	nonFatal     bool
+	refPrefix    string
+	rename       mason.NamingStrategy
+	conflicts    ConflictStrategy
+	specExt      map[string]any
+	infoExt      map[string]any
+	serverExt    map[string]any
+	dialect      string
+	schemaDecl   SchemaDeclaration
+	sortFn       func(a, b Record) int
+	strictModels bool
 }
 
 type openAPIOption func(*config)
 
+// Validate skips the validation of the generated specs when skip is true. The specs are only validated with a
+// validator, see WithValidator, so Validate(false) alone no longer validates them.
 func Validate(skip bool) openAPIOption {
 	return func(c *config) {
 		c.validate = skip
@@ -37,13 +70,138 @@ func Transform(fn func(*Record)) openAPIOption {
 	}
 }
 
+// ExampleSource provides recorded samples of the operations, e.g. a mason.Recorder.
+type ExampleSource interface {
+	Samples(opID string) []mason.Sample
+}
+
+// Examples documents the samples of the operations as named examples of their request and response bodies.
+func Examples(source ExampleSource) openAPIOption {
+	return func(c *config) {
+		c.examples = source
+	}
+}
+
+// EntityExamples documents the examples of the entities as named examples of the request and success response bodies
+// of each operation, so the documentation UIs show them with the operation. The parser of openapi-go rejects named
+// examples, so they are left out by default.
+func EntityExamples() openAPIOption {
+	return func(c *config) {
+		c.entityEx = true
+	}
+}
+
+// Visibility sets the visibility tiers of the documented operations, only public ones by default, e.g. to generate an
+// internal spec including beta and internal operations.
+func Visibility(tiers ...mason.OperationVisibility) openAPIOption {
+	return func(c *config) {
+		c.visibility = tiers
+	}
+}
+
+// Features only documents the operations available to a tenant with the enabled feature flags, e.g. to generate the
+// spec of each plan. All the operations are documented by default, whatever their flags.
+func Features(enabled ...string) openAPIOption {
+	return func(c *config) {
+		c.features = enabled
+		c.byFeatures = true
+	}
+}
+
+// Rollouts only documents the operations dark launched behind a flag, see mason.Builder.WithRollout, when enabled
+// returns true for their flag, e.g. to keep them out of the public spec until they are rolled out. They are all
+// documented by default.
+func Rollouts(enabled func(flag string) bool) openAPIOption {
+	return func(c *config) {
+		c.rollouts = enabled
+	}
+}
+
+// Locale documents the summaries and the descriptions of the operations, see mason.Builder.WithSummaryLocalized, and
+// the descriptions of the entities, see model.WithLocalizedDescription, in the language, e.g. to generate a spec per
+// language. The texts that are not translated keep their default.
+func Locale(lang string) openAPIOption {
+	return func(c *config) {
+		c.locale = lang
+	}
+}
+
+// Translations documents the translated summaries and descriptions as x-translations extensions of the operations and
+// of the schema components, keyed by language, e.g. for a docs site switching languages client side.
+func Translations() openAPIOption {
+	return func(c *config) {
+		c.translations = true
+	}
+}
+
+// RenameComponents renames the schema components, and the refs pointing to them, after the component naming of the
+// API, e.g. to prefix them with the name of the service before combining specs.
+func RenameComponents(fn func(string) string) openAPIOption {
+	return func(c *config) {
+		c.rename = fn
+	}
+}
+
+// RefPrefix sets the prefix of the refs to the schema components, mason.DefaultRefPrefix by default, e.g. to embed
+// the generated schemas into a document with another structure. Transform can still override it per operation with
+// mason.Model.WithRefPrefix.
+func RefPrefix(prefix string) openAPIOption {
+	return func(c *config) {
+		c.refPrefix = prefix
+	}
+}
+
+// WithValidator validates the generated specs with the validator, e.g. a vacuum.Validator. Without one, the specs are
+// not validated.
+func WithValidator(v Validator) openAPIOption {
+	return func(c *config) {
+		c.validator = v
+	}
+}
+
+// RuleSeverity overrides the severity of the findings of a linting rule, e.g. SeverityWarn to make them non-fatal with
+// NonFatalWarnings, or SeverityOff to ignore them.
+func RuleSeverity(rule string, severity string) openAPIOption {
+	return func(c *config) {
+		if c.severities == nil {
+			c.severities = make(map[string]string)
+		}
+		c.severities[rule] = severity
+	}
+}
+
+// NonFatalWarnings returns the spec despite the findings below the error severity, which are kept as the warnings of
+// the generator instead. By default, any finding fails the validation.
+func NonFatalWarnings() openAPIOption {
+	return func(c *config) {
+		c.nonFatal = true
+	}
+}
+
 type Generator struct {
 	api     *mason.API
 	records []Record
-	config  config
+	// webhooks are the webhook events of the API, documented in the webhooks section.
+	webhooks []WebhookRecord
+	config   config
+	opts     []openAPIOption
+	// sources are the generators combined into this one, see Combine.
+	sources []*Generator
 	*Reflector
+
+	mu sync.Mutex
+	// schema is the spec generated last, from the records and config with the content hash schemaHash.
+	schema     []byte
+	schemaHash string
+	// warnings are the non-fatal findings of the last validation.
+	warnings []Finding
+	// unused are the registered entities the last generated spec does not document.
+	unused []string
 }
 
+// NewGenerator returns the generator of the OpenAPI spec of the API. The specs are not validated by default: to validate
+// them with the vacuum linter, like the generators did before it moved to its own package, pass
+// WithValidator(vacuum.Validator{}).
 func NewGenerator(a *mason.API, opts ...openAPIOption) (*Generator, error) {
 	// initialise config
 	config := config{
@@ -52,32 +210,174 @@ func NewGenerator(a *mason.API, opts ...openAPIOption) (*Generator, error) {
 		tagsFn:      func(mason.Operation) []string { return []string{} },
 		allTags:     []string{},
 		transformFn: func(r *Record) {},
+		visibility:  []mason.OperationVisibility{mason.VisibilityPublic},
+		refPrefix:   mason.DefaultRefPrefix,
 	}
 
 	// apply options
 	for _, opt := range opts {
 		opt(&config)
 	}
+	if err := config.checkExtensions(); err != nil {
+		return nil, err
+	}
+
+	naming := a.Naming()
+	if rename := config.rename; rename != nil {
+		components := naming.Components
+		naming.Components = func(name string) string { return rename(components(name)) }
+	}
+	config.rename = naming.Components
 
 	var records []Record
+	var err error
 	forEachCollectedRoute(a, func(group string, op mason.Operation) {
+		if err != nil || !op.VisibleIn(config.visibility...) || config.byFeatures && !op.EnabledFor(config.features...) {
+			return
+		}
+		if op.Rollout != "" && config.rollouts != nil && !config.rollouts(op.Rollout) {
+			return
+		}
+		if op.Input, err = withExternalRefs(a, op.Input); err != nil {
+			err = fmt.Errorf("%s %s: %w", op.Method, op.Path, err)
+			return
+		}
+		if op.Output, err = withExternalRefs(a, op.Output); err != nil {
+			err = fmt.Errorf("%s %s: %w", op.Method, op.Path, err)
+			return
+		}
+
+		if config.locale != "" {
+			op = op.Localized(config.locale)
+		}
+
 		meta, _ := a.GroupMetadata(group)
 		record := toRecord(op, config.tagsFn, meta)
+		record.Group = group
+		if record.Errors, err = errorDefs(a, op.Errors); err != nil {
+			err = fmt.Errorf("%s %s: %w", op.Method, op.Path, err)
+			return
+		}
+		applyNaming(&record, naming)
+		applyRefPrefix(&record, config.refPrefix)
+		if config.examples != nil {
+			record.Samples = config.examples.Samples(op.OperationID)
+		}
+		record.EntityExamples = config.entityEx
+		record.InputVariantSuffix = config.inputSuffix
 		config.transformFn(&record)
 
 		if config.filterFn(record) {
 			records = append(records, record)
 		}
 	})
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveConflicts(records, config.conflicts); err != nil {
+		return nil, err
+	}
+
+	allTags := make([]string, len(config.allTags))
+	for i, tag := range config.allTags {
+		allTags[i] = naming.Tags(tag)
+	}
+	config.allTags = allTags
 
 	return &Generator{
 		api:       a,
 		config:    config,
+		opts:      opts,
 		records:   records,
-		Reflector: newReflector(),
+		webhooks:  webhookRecords(a, naming, config.refPrefix),
+		Reflector: newReflector(config),
 	}, nil
 }
 
+// externalRefsEntity replaces the schema of an entity with one where the external refs are inlined.
+type externalRefsEntity struct {
+	model.Entity
+	schema []byte
+}
+
+func (e externalRefsEntity) Schema() []byte {
+	return e.schema
+}
+
+// withExternalRefs inlines the external refs of the entity schema, so they end up as components of the spec.
+func withExternalRefs(a *mason.API, ent model.Entity) (model.Entity, error) {
+	if ent == nil || reflect.ValueOf(ent).Kind() == reflect.Ptr && reflect.ValueOf(ent).IsNil() {
+		return ent, nil
+	}
+
+	schema := ent.Schema()
+	resolved, err := a.ResolveExternalRefs(schema)
+	if err != nil {
+		return nil, fmt.Errorf("entity %s: %w", ent.Name(), err)
+	}
+	if bytes.Equal(schema, resolved) {
+		return ent, nil
+	}
+
+	return externalRefsEntity{Entity: ent, schema: resolved}, nil
+}
+
+// errorDefs looks up the errors of an operation in the error catalog of the API.
+func errorDefs(a *mason.API, codes []string) ([]ErrorRecord, error) {
+	records := make([]ErrorRecord, 0, len(codes))
+	for _, code := range codes {
+		def, ok := a.GetError(code)
+		if !ok {
+			return nil, fmt.Errorf("error %s is not registered", code)
+		}
+		records = append(records, ErrorRecord{Code: def.Code, Status: def.Status, Output: mason.NewModel(def.Entity), Retryable: def.Retryable()})
+	}
+
+	return records, nil
+}
+
+// applyNaming renames the tags and the components of the record with the naming strategies of the API.
+func applyNaming(record *Record, naming mason.Naming) {
+	tags := make([]string, len(record.Tags))
+	for i, tag := range record.Tags {
+		tags[i] = naming.Tags(tag)
+	}
+	record.Tags = tags
+
+	if record.Input != nil {
+		inp := record.Input.WithComponentNaming(naming.Components)
+		record.Input = &inp
+	}
+	record.Output = record.Output.WithComponentNaming(naming.Components)
+	for i := range record.Errors {
+		record.Errors[i].Output = record.Errors[i].Output.WithComponentNaming(naming.Components)
+	}
+	for ct, m := range record.Representations {
+		if m != nil {
+			named := m.WithComponentNaming(naming.Components)
+			record.Representations[ct] = &named
+		}
+	}
+}
+
+// applyRefPrefix sets the prefix of the refs of the models of the record.
+func applyRefPrefix(record *Record, prefix string) {
+	if record.Input != nil {
+		inp := record.Input.WithRefPrefix(prefix)
+		record.Input = &inp
+	}
+	record.Output = record.Output.WithRefPrefix(prefix)
+	for i := range record.Errors {
+		record.Errors[i].Output = record.Errors[i].Output.WithRefPrefix(prefix)
+	}
+	for ct, m := range record.Representations {
+		if m != nil {
+			rep := m.WithRefPrefix(prefix)
+			record.Representations[ct] = &rep
+		}
+	}
+}
+
 func forEachCollectedRoute(api *mason.API, fn func(group string, op mason.Operation)) {
 	api.ForEachOperation(func(group string, op mason.Operation) {
 		fn(group, op)
@@ -86,21 +386,37 @@ func forEachCollectedRoute(api *mason.API, fn func(group string, op mason.Operat
 
 func toRecord(op mason.Operation, tagsFn func(mason.Operation) []string, meta mason.GroupMetadata) Record {
 	record := Record{
-		ID:              op.OperationID,
-		Method:          op.Method,
-		Path:            op.Path,
-		Description:     op.Description,
-		Summary:         op.Summary,
-		Tags:            append(tagsFn(op), op.Tags...),
-		SuccessStatus:   op.SuccessCode,
-		Extensions:      op.Extensions,
-		PathSummary:     meta.Summary,
-		PathDescription: meta.Description,
+		ID:                   op.OperationID,
+		Method:               op.Method,
+		Path:                 op.Path,
+		Description:          op.Description,
+		Summary:              op.Summary,
+		Tags:                 append(tagsFn(op), op.Tags...),
+		SuccessStatus:        op.SuccessCode,
+		Extensions:           op.Extensions,
+		PathSummary:          meta.Summary,
+		PathDescription:      meta.Description,
+		FieldSelection:       op.FieldSelection,
+		NoContent:            op.NoContent,
+		Cache:                op.Cache,
+		Content:              op.Content,
+		PathParams:           op.PathParams,
+		Timeout:              op.Timeout,
+		TimeoutStatus:        op.TimeoutStatus,
+		ResponseDescriptions: op.ResponseDescriptions,
+		Visibility:           op.Visibility,
+		FeatureFlags:         op.FeatureFlags,
+		Rollout:              op.Rollout,
+		Translations:         op.Translations,
+		Deprecated:           op.Deprecated(),
+		Owner:                op.Owner,
+		SignedURL:            op.SignedURL,
 	}
 
 	record.AddInputModel(op.Input)
 	record.AddOutputModel(op.Output)
 	record.AddQueryParams(op.QueryParams)
+	record.AddRepresentations(op.Representations)
 
 	return record
 }
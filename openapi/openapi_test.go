@@ -4,15 +4,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/swaggest/jsonschema-go"
 	"github.com/swaggest/openapi-go/openapi31"
 	"github.com/tailbits/mason"
 	"github.com/tailbits/mason/model"
 	"github.com/tailbits/mason/openapi"
+	"github.com/tailbits/mason/openapi/vacuum"
 	"gotest.tools/v3/assert"
 )
 
@@ -269,7 +278,7 @@ func TestOpenAPIGen(t *testing.T) {
 				t.Fatalf("error setting up test: %v", err)
 			}
 
-			gen, err := openapi.NewGenerator(api, openapi.Validate(false))
+			gen, err := openapi.NewGenerator(api, openapi.Validate(false), openapi.WithValidator(vacuum.Validator{}))
 			assert.NilError(t, err, "failed to create OpenAPI generator")
 
 			schema, err := gen.Schema()
@@ -319,6 +328,469 @@ func TestOpenAPIPathMetadata(t *testing.T) {
 	}
 }
 
+func TestOpenAPIPagination(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(ListResourceB).
+			Path("/foos").
+			WithOpID("list_foos").
+			WithDesc("List foos"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	op := spec.Paths.MapOfPathItemValues["/foos"].Get
+	params := []string{}
+	for _, p := range op.Parameters {
+		params = append(params, p.Parameter.Name)
+	}
+	assert.DeepEqual(t, []string{"limit", "after", "before"}, params)
+
+	rsp := op.Responses.MapOfResponseOrReferenceValues["200"].Response
+	_, ok := rsp.Headers["X-Next-Cursor"]
+	assert.Assert(t, ok)
+	_, ok = spec.Components.Schemas["TestResourceBConnection"]
+	assert.Assert(t, ok)
+}
+
+func TestOpenAPISortAndFilter(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchResourceB).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithDesc("Search foos"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	params := spec.Paths.MapOfPathItemValues["/foos"].Get.Parameters
+	assert.Equal(t, 2, len(params))
+
+	sort := params[0].Parameter
+	assert.Equal(t, openapi31.ParameterStyleForm, *sort.Style)
+	items := sort.Schema["items"].(map[string]interface{})
+	assert.DeepEqual(t, []interface{}{"name", "-name"}, items["enum"])
+
+	filter := params[1].Parameter
+	assert.Equal(t, openapi31.ParameterStyleDeepObject, *filter.Style)
+	props := filter.Schema["properties"].(map[string]interface{})
+	_, ok := props["status"]
+	assert.Assert(t, ok)
+}
+
+func TestOpenAPIFieldSelection(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchResourceB).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithDesc("Search foos").
+			WithFieldSelection(),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	params := spec.Paths.MapOfPathItemValues["/foos"].Get.Parameters
+	assert.Equal(t, 3, len(params))
+
+	fields := params[2].Parameter
+	assert.Equal(t, "fields", fields.Name)
+	assert.Equal(t, openapi31.ParameterStyleForm, *fields.Style)
+	assert.Equal(t, "array", fields.Schema["type"])
+}
+
+func TestOpenAPICache(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchResourceB).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithDesc("Search foos").
+			WithCache(mason.CachePolicy{MaxAge: time.Minute, Vary: []string{"accept-language"}}),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	rsp := spec.Paths.MapOfPathItemValues["/foos"].Get.Responses.MapOfResponseOrReferenceValues["200"].Response
+	cc, ok := rsp.Headers["Cache-Control"]
+	assert.Assert(t, ok)
+	assert.Assert(t, strings.Contains(*cc.Header.Description, "public, max-age=60"))
+	vary, ok := rsp.Headers["Vary"]
+	assert.Assert(t, ok)
+	assert.Assert(t, strings.Contains(*vary.Header.Description, "Accept-Language"))
+}
+
+func TestOpenAPITimeout(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchResourceB).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithDesc("Search foos").
+			WithTimeout(5 * time.Second),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	rsp, ok := spec.Paths.MapOfPathItemValues["/foos"].Get.Responses.MapOfResponseOrReferenceValues["504"]
+	assert.Assert(t, ok)
+	assert.Equal(t, "#/components/schemas/TimeoutError", rsp.Response.Content["application/json"].Schema["$ref"])
+}
+
+func TestOpenAPIErrors(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.RegisterError("not_found", http.StatusNotFound, &model.APIError{})
+	api.RegisterError("gone", http.StatusNotFound, &model.APIError{})
+	api.RegisterError("conflict", http.StatusConflict, &model.APIError{})
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchResourceB).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithDesc("Search foos").
+			WithErrors("not_found", "gone", "conflict"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	responses := spec.Paths.MapOfPathItemValues["/foos"].Get.Responses.MapOfResponseOrReferenceValues
+	notFound, ok := responses["404"]
+	assert.Assert(t, ok)
+	assert.Equal(t, "The resource was not found. Error codes: `not_found`, `gone`.", notFound.Response.Description)
+	assert.Equal(t, "#/components/schemas/APIError", notFound.Response.Content["application/json"].Schema["$ref"])
+	_, ok = responses["409"]
+	assert.Assert(t, ok)
+}
+
+func TestOpenAPIErrors_Unregistered(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchResourceB).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithErrors("not_found"),
+	)
+
+	_, err := openapi.NewGenerator(api)
+	assert.ErrorContains(t, err, "error not_found is not registered")
+}
+
+func TestOpenAPIResponseDescriptions(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.RegisterError("not_found", http.StatusNotFound, &model.APIError{})
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchResourceB).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithDesc("Search foos").
+			WithTimeout(5*time.Second).
+			WithErrors("not_found").
+			WithResponseDesc(http.StatusOK, "The matching foos.").
+			WithResponseDesc(http.StatusNotFound, "No foo matches."),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	responses := spec.Paths.MapOfPathItemValues["/foos"].Get.Responses.MapOfResponseOrReferenceValues
+	assert.Equal(t, "The matching foos.", responses["200"].Response.Description)
+	assert.Equal(t, "No foo matches. Error codes: `not_found`.", responses["404"].Response.Description)
+	assert.Equal(t, "The request timed out.", responses["504"].Response.Description)
+}
+
+func TestOpenAPIDraft2020(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Tracks").Register(
+		mason.HandleGet(GetTrack).
+			Path("/tracks").
+			WithOpID("fetch_track").
+			WithDesc("Get a track"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	_, ok := spec.Components.Schemas["Point"]
+	assert.Assert(t, ok)
+	points := spec.Components.Schemas["Track"]["properties"].(map[string]interface{})["points"].(map[string]interface{})
+	prefixItems := points["prefixItems"].([]interface{})
+	assert.Equal(t, "#/components/schemas/Point", prefixItems[0].(map[string]interface{})["$ref"])
+	assert.Equal(t, false, spec.Components.Schemas["Track"]["unevaluatedProperties"])
+	assert.Assert(t, !strings.Contains(string(schema), "$defs"))
+}
+
+func TestOpenAPISchemaID(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Places")
+	grp.Register(
+		mason.HandleGet(GetTrack).
+			Path("/tracks").
+			WithOpID("fetch_track").
+			WithDesc("Get a track"),
+	)
+	grp.Register(
+		mason.HandleGet(GetRoute).
+			Path("/routes").
+			WithOpID("fetch_route").
+			WithDesc("Get a route"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	track := spec.Components.Schemas["Route"]["properties"].(map[string]interface{})["track"].(map[string]interface{})
+	assert.Equal(t, "#/components/schemas/Track", track["$ref"])
+	_, hasID := spec.Components.Schemas["Track"]["$id"]
+	assert.Assert(t, !hasID)
+}
+
+type staticSamples map[string][]mason.Sample
+
+func (s staticSamples) Samples(opID string) []mason.Sample {
+	return s[opID]
+}
+
+func TestOpenAPIExamples(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchResourceB).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithDesc("Search foos"),
+	)
+
+	gen, err := openapi.NewGenerator(api, openapi.Examples(staticSamples{
+		"search_foos": {{
+			OperationID: "search_foos",
+			Method:      http.MethodGet,
+			URL:         "/foos?q=bar",
+			Status:      http.StatusOK,
+			Response:    json.RawMessage(`{"id": "b1"}`),
+		}},
+	}))
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	// openapi31.Example cannot be unmarshalled, so the spec is inspected as plain JSON
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Responses map[string]struct {
+				Content map[string]struct {
+					Examples map[string]struct {
+						Summary string      `json:"summary"`
+						Value   interface{} `json:"value"`
+					} `json:"examples"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	example, ok := spec.Paths["/foos"]["get"].Responses["200"].Content["application/json"].Examples["sample_1"]
+	assert.Assert(t, ok)
+	assert.Equal(t, "GET /foos?q=bar", example.Summary)
+	assert.DeepEqual(t, map[string]interface{}{"id": "b1"}, example.Value)
+}
+
+func TestOpenAPIStream(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleStream(func(ctx context.Context, _ *http.Request, items *mason.Stream[*TestResourceA], _ model.Nil) (*TestResourceB, error) {
+			return &TestResourceB{}, nil
+		}).
+			Path("/foos/import").
+			WithOpID("import_foos").
+			WithDesc("Import foos"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	content := spec.Paths.MapOfPathItemValues["/foos/import"].Post.RequestBody.RequestBody.Content
+	assert.Equal(t, 1, len(content))
+	assert.Equal(t, "#/components/schemas/TestResourceA", content[mason.NDJSONContentType].Schema["$ref"])
+}
+
+func TestOpenAPIRepresentations(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(GetResourceB).
+			Path("/foos/{id}").
+			WithOpID("get_foo").
+			WithDesc("Get a foo").
+			WithRepresentation("text/csv", mason.RawRepresentation(func(ctx context.Context, w io.Writer, foo *TestResourceB) error {
+				return nil
+			})).
+			WithRepresentation("application/vnd.foo+json", mason.EntityRepresentation(func(foo *TestResourceB) (*TestResourceA, error) {
+				return &TestResourceA{}, nil
+			})),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	content := spec.Paths.MapOfPathItemValues["/foos/{id}"].Get.Responses.MapOfResponseOrReferenceValues["200"].Response.Content
+	assert.Equal(t, 3, len(content))
+	assert.Equal(t, "#/components/schemas/TestResourceB", content["application/json"].Schema["$ref"])
+	assert.Equal(t, "#/components/schemas/TestResourceA", content["application/vnd.foo+json"].Schema["$ref"])
+	assert.Equal(t, "string", content["text/csv"].Schema["type"])
+}
+
+func TestOpenAPIExternalRefs(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Places").Register(
+		mason.HandleGet(GetPlace).
+			Path("/places").
+			WithOpID("fetch_place").
+			WithDesc("Get a place"),
+	)
+	api.WithRefResolver(mason.RefResolverFunc(func(ref string) ([]byte, error) {
+		return []byte(`{"definitions": {"Address": {"type": "object", "properties": {"street": {"type": "string"}}}}}`), nil
+	}), "https://schemas.example.com/")
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	_, ok := spec.Components.Schemas["Address"]
+	assert.Assert(t, ok)
+	place := spec.Components.Schemas["Place"]["properties"].(map[string]interface{})
+	assert.Equal(t, "#/components/schemas/Address", place["address"].(map[string]interface{})["$ref"])
+}
+
+func TestOpenAPINaming(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime()).WithNaming(mason.Naming{
+		Tags:       mason.KebabCase,
+		Components: mason.SnakeCase,
+	})
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(ListResourceB).
+			Path("/foos").
+			WithOpID("list_foos").
+			WithDesc("List foos").
+			WithTags("FooBars"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	assert.DeepEqual(t, []string{"foo-bars"}, spec.Paths.MapOfPathItemValues["/foos"].Get.Tags)
+	_, ok := spec.Components.Schemas["test_resource_b_connection"]
+	assert.Assert(t, ok)
+	_, ok = spec.Components.Schemas["test_resource_b"]
+	assert.Assert(t, ok)
+	assert.Assert(t, !strings.Contains(string(schema), "#/components/schemas/TestResourceB"))
+}
+
+func TestOpenAPIPathParamConstraints(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(GetResourceB).
+			Path("/foos/{id:[a-z]+}/{n}").
+			WithOpID("fetch_foo").
+			WithDesc("Get a foo").
+			WithPathParam("n", mason.PathParam{Type: mason.PathParamInteger}),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	params := spec.Paths.MapOfPathItemValues["/foos/{id}/{n}"].Get.Parameters
+	assert.Equal(t, "^(?:[a-z]+)$", params[0].Parameter.Schema["pattern"])
+	assert.Equal(t, "integer", params[1].Parameter.Schema["type"])
+}
+
 // Helper function to format JSON
 func formatJSON(b []byte) ([]byte, error) {
 	var prettyJSON bytes.Buffer
@@ -345,6 +817,19 @@ func GetResourceB(ctx context.Context, _ *http.Request, params TestParams) (*Tes
 	return &TestResourceB{}, nil
 }
 
+func ListResourceB(ctx context.Context, _ *http.Request, params mason.PageParams) (*model.Connection[*TestResourceB], error) {
+	return &model.Connection[*TestResourceB]{}, nil
+}
+
+type SearchParams struct {
+	Sort   mason.Sort   `json:"sort" sortable:"name"`
+	Filter mason.Filter `json:"filter" filterable:"status:eq,in"`
+}
+
+func SearchResourceB(ctx context.Context, _ *http.Request, params SearchParams) (*TestResourceB, error) {
+	return &TestResourceB{}, nil
+}
+
 func GetResourceWithMissingRef(ctx context.Context, _ *http.Request, params TestParams) (*ResourceWithMissingRef, error) {
 	return &ResourceWithMissingRef{}, nil
 }
@@ -452,17 +937,127 @@ func (t *TestResourceB) Unmarshal(data json.RawMessage) error {
 var _ model.Entity = (*TestResourceB)(nil)
 
 // =============================================================================
-// ResourceWithMissingRef has a schema that references a non-existent resource
-var _ model.Entity = (*ResourceWithMissingRef)(nil)
+// Place references a schema from a shared schema repository
+var _ model.Entity = (*Place)(nil)
 
-type ResourceWithMissingRef struct{}
+type Place struct{}
 
-func (t *ResourceWithMissingRef) Example() []byte {
-	return []byte(`
-	{
-		"z": "example"
-	}
-	`)
+func GetPlace(ctx context.Context, _ *http.Request, params TestParams) (*Place, error) {
+	return &Place{}, nil
+}
+
+func (p *Place) Example() []byte {
+	return []byte(`{"address": {"street": "Main St"}}`)
+}
+
+func (p *Place) Marshal() (json.RawMessage, error) {
+	return json.Marshal(p)
+}
+
+func (p *Place) Name() string {
+	return "Place"
+}
+
+func (p *Place) Schema() []byte {
+	return []byte(`
+	{
+		"type":"object",
+		"properties": {
+			"address": {"$ref": "https://schemas.example.com/common.json#/definitions/Address"}
+		}
+	}
+	`)
+}
+
+func (p *Place) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, p)
+}
+
+type Track struct{}
+
+func GetTrack(ctx context.Context, _ *http.Request, params TestParams) (*Track, error) {
+	return &Track{}, nil
+}
+
+func (p *Track) Example() []byte {
+	return []byte(`{"points": [{"lat": 1, "lng": 2}]}`)
+}
+
+func (p *Track) Marshal() (json.RawMessage, error) {
+	return json.Marshal(p)
+}
+
+func (p *Track) Name() string {
+	return "Track"
+}
+
+func (p *Track) Schema() []byte {
+	return []byte(`
+	{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "https://schemas.example.com/track",
+		"type":"object",
+		"properties": {
+			"points": {"type": "array", "prefixItems": [{"$ref": "#/$defs/Point"}]}
+		},
+		"$defs": {
+			"Point": {"type": "object", "properties": {"lat": {"type": "number"}, "lng": {"type": "number"}}}
+		},
+		"unevaluatedProperties": false
+	}
+	`)
+}
+
+func (p *Track) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, p)
+}
+
+// Route references Track by its $id.
+type Route struct{}
+
+func GetRoute(ctx context.Context, _ *http.Request, params TestParams) (*Route, error) {
+	return &Route{}, nil
+}
+
+func (p *Route) Example() []byte {
+	return []byte(`{}`)
+}
+
+func (p *Route) Marshal() (json.RawMessage, error) {
+	return json.Marshal(p)
+}
+
+func (p *Route) Name() string {
+	return "Route"
+}
+
+func (p *Route) Schema() []byte {
+	return []byte(`
+	{
+		"type":"object",
+		"properties": {
+			"track": {"$ref": "https://schemas.example.com/track"}
+		}
+	}
+	`)
+}
+
+func (p *Route) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, p)
+}
+
+// =============================================================================
+// ResourceWithMissingRef has a schema that references a non-existent resource
+var _ model.Entity = (*ResourceWithMissingRef)(nil)
+
+type ResourceWithMissingRef struct{}
+
+func (t *ResourceWithMissingRef) Example() []byte {
+	return []byte(`
+	{
+		"z": "example"
+	}
+	`)
 }
 
 func (t *ResourceWithMissingRef) Marshal() (json.RawMessage, error) {
@@ -612,3 +1207,1856 @@ func (t *TestResourceAConflicting) Schema() []byte {
 func (t *TestResourceAConflicting) Unmarshal(data json.RawMessage) error {
 	return json.Unmarshal(data, t)
 }
+
+func TestOpenAPIVisibility(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(
+		mason.HandleGet(GetResourceB).
+			Path("/foos/{id}").
+			WithOpID("get_foo").
+			WithDesc("Get a foo"),
+	)
+	grp.Register(
+		mason.HandleGet(GetResourceB).
+			Path("/foos/{id}/preview").
+			WithOpID("preview_foo").
+			WithDesc("Preview a foo").
+			WithVisibility(mason.VisibilityBeta),
+	)
+	grp.Register(
+		mason.HandleGet(GetResourceB).
+			Path("/foos/{id}/debug").
+			WithOpID("debug_foo").
+			WithDesc("Debug a foo").
+			WithVisibility(mason.VisibilityInternal),
+	)
+
+	paths := func(gen *openapi.Generator, err error) []string {
+		assert.NilError(t, err)
+
+		schema, err := gen.Schema()
+		assert.NilError(t, err)
+
+		var spec openapi31.Spec
+		assert.NilError(t, json.Unmarshal(schema, &spec))
+
+		var out []string
+		for path := range spec.Paths.MapOfPathItemValues {
+			out = append(out, path)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	assert.DeepEqual(t, []string{"/foos/{id}"}, paths(openapi.NewGenerator(api)))
+	assert.DeepEqual(t, []string{"/foos/{id}", "/foos/{id}/debug"}, paths(openapi.NewGenerator(api, openapi.Visibility(mason.VisibilityPublic, mason.VisibilityInternal))))
+}
+
+func TestOpenAPISchemaCache(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(
+		mason.HandleGet(GetResourceB).
+			Path("/foos/{id}").
+			WithOpID("get_foo").
+			WithDesc("Get a foo"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	gen.Spec.Info.WithTitle("Foo API")
+
+	first, err := gen.Schema()
+	assert.NilError(t, err)
+	second, err := gen.Schema()
+	assert.NilError(t, err)
+	assert.Equal(t, string(first), string(second))
+
+	// the operations registered since are documented only once invalidated
+	grp.Register(
+		mason.HandleGet(GetResourceA).
+			Path("/foos/{id}/a").
+			WithOpID("get_foo_a").
+			WithDesc("Get the A of a foo"),
+	)
+	cached, err := gen.Schema()
+	assert.NilError(t, err)
+	assert.Equal(t, string(first), string(cached))
+
+	assert.NilError(t, gen.Invalidate())
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+	assert.Equal(t, "Foo API", spec.Info.Title)
+	assert.Equal(t, 2, len(spec.Paths.MapOfPathItemValues))
+
+	// customizing the spec invalidates the cache too
+	gen.Spec.Info.WithTitle("Bar API")
+	schema, err = gen.Schema()
+	assert.NilError(t, err)
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+	assert.Equal(t, "Bar API", spec.Info.Title)
+	assert.Equal(t, 2, len(spec.Paths.MapOfPathItemValues))
+}
+
+func BenchmarkGeneratorSchema(b *testing.B) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Foos")
+	// every route documents its own error model, so there are as many schemas to resolve as routes
+	for i := range 300 {
+		code := fmt.Sprintf("foo_%d_failed", i)
+		api.RegisterError(code, http.StatusConflict, &BenchError{name: fmt.Sprintf("Foo%dError", i)})
+		grp.Register(
+			mason.HandleGet(GetResourceB).
+				Path(fmt.Sprintf("/foos/%d/{id}", i)).
+				WithOpID(fmt.Sprintf("get_foo_%d", i)).
+				WithDesc("Get a foo").
+				WithErrors(code),
+		)
+	}
+
+	for b.Loop() {
+		gen, err := openapi.NewGenerator(api, openapi.Validate(true))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := gen.Schema(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchError is an error model whose name is set per instance, so a benchmark can register many distinct models.
+type BenchError struct {
+	name string
+}
+
+func (e *BenchError) Example() []byte {
+	return []byte(`{"code": "failed", "reason": "the foo failed"}`)
+}
+
+func (e *BenchError) Marshal() (json.RawMessage, error) {
+	return json.Marshal(e)
+}
+
+func (e *BenchError) Name() string {
+	return e.name
+}
+
+func (e *BenchError) Schema() []byte {
+	return []byte(`
+	{
+		"type":"object",
+		"properties": {
+			"code": {"type": "string"},
+			"reason": {"type": "string"}
+		},
+		"required": ["code"]
+	}
+	`)
+}
+
+func (e *BenchError) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, e)
+}
+
+// Invalid has a schema with a duplicated enum entry.
+type Invalid struct{}
+
+func GetInvalid(ctx context.Context, _ *http.Request, params TestParams) (*Invalid, error) {
+	return &Invalid{}, nil
+}
+
+func (i *Invalid) Example() []byte {
+	return []byte(`{"status": "open"}`)
+}
+
+func (i *Invalid) Marshal() (json.RawMessage, error) {
+	return json.Marshal(i)
+}
+
+func (i *Invalid) Name() string {
+	return "Invalid"
+}
+
+func (i *Invalid) Schema() []byte {
+	return []byte(`{"type":"object","properties":{"status":{"type":"string","enum":["open","closed","open"]}}}`)
+}
+
+func (i *Invalid) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, i)
+}
+
+func TestOpenAPIValidationError(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Invalids").Register(
+		mason.HandleGet(GetInvalid).
+			Path("/invalids").
+			WithOpID("get_invalid").
+			WithDesc("Get an invalid"),
+	)
+
+	gen, err := openapi.NewGenerator(api, openapi.WithValidator(vacuum.Validator{}))
+	assert.NilError(t, err)
+
+	_, err = gen.Schema()
+	var verr *openapi.SpecValidationError
+	assert.Assert(t, errors.As(err, &verr))
+	assert.Assert(t, len(verr.Findings) > 0)
+
+	var finding openapi.Finding
+	assert.Assert(t, errors.As(err, &finding))
+	assert.Equal(t, verr.Findings[0], finding)
+	assert.Assert(t, finding.Rule != "")
+	assert.Assert(t, finding.Line > 0)
+
+	// without a validator, the spec is not validated
+	gen, err = openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	_, err = gen.Schema()
+	assert.NilError(t, err)
+}
+
+func TestOpenAPIValidationWarnings(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Invalids").Register(
+		mason.HandleGet(GetInvalid).
+			Path("/invalids").
+			WithOpID("get_invalid").
+			WithDesc("Get an invalid"),
+	)
+
+	gen, err := openapi.NewGenerator(api, openapi.WithValidator(vacuum.Validator{}), openapi.RuleSeverity("duplicated-entry-in-enum", openapi.SeverityWarn), openapi.NonFatalWarnings())
+	assert.NilError(t, err)
+	_, err = gen.Schema()
+	assert.NilError(t, err)
+	warnings := gen.Warnings()
+	assert.Equal(t, 1, len(warnings))
+	assert.Equal(t, "duplicated-entry-in-enum", warnings[0].Rule)
+	assert.Equal(t, openapi.SeverityWarn, warnings[0].Severity)
+
+	gen, err = openapi.NewGenerator(api, openapi.WithValidator(vacuum.Validator{}), openapi.RuleSeverity("duplicated-entry-in-enum", openapi.SeverityOff))
+	assert.NilError(t, err)
+	_, err = gen.Schema()
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(gen.Warnings()))
+
+	// errors stay fatal
+	gen, err = openapi.NewGenerator(api, openapi.WithValidator(vacuum.Validator{}), openapi.RuleSeverity("duplicated-entry-in-enum", openapi.SeverityError), openapi.NonFatalWarnings())
+	assert.NilError(t, err)
+	_, err = gen.Schema()
+	var verr *openapi.SpecValidationError
+	assert.Assert(t, errors.As(err, &verr))
+}
+
+type TaggedParams struct {
+	Status string `json:"status" enum:"open,closed" default:"open"`
+	Limit  int    `json:"limit" default:"20" example:"50"`
+	Owner  string `json:"owner" required:"true"`
+	Legacy bool   `json:"legacy" deprecated:"true"`
+	Size   int    `json:"page_size" aliases:"per_page"`
+}
+
+func SearchTagged(ctx context.Context, _ *http.Request, params TaggedParams) (*TestResourceB, error) {
+	return &TestResourceB{}, nil
+}
+
+func TestOpenAPIQueryParamTags(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchTagged).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithDesc("Search foos"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	params := map[string]*openapi31.Parameter{}
+	for _, p := range spec.Paths.MapOfPathItemValues["/foos"].Get.Parameters {
+		params[p.Parameter.Name] = p.Parameter
+	}
+
+	status := params["status"]
+	assert.Equal(t, "open", status.Schema["default"])
+	assert.DeepEqual(t, []interface{}{"open", "closed"}, status.Schema["enum"])
+	assert.Assert(t, !*status.Required)
+
+	limit := params["limit"]
+	assert.Equal(t, float64(20), limit.Schema["default"])
+	assert.DeepEqual(t, []interface{}{float64(50)}, limit.Schema["examples"])
+
+	assert.Assert(t, *params["owner"].Required)
+	assert.Assert(t, *params["legacy"].Deprecated)
+
+	assert.Assert(t, params["page_size"].Deprecated == nil)
+	perPage := params["per_page"]
+	assert.Assert(t, *perPage.Deprecated)
+	assert.Equal(t, "Deprecated alias of page_size.", *perPage.Description)
+	assert.Equal(t, "integer", perPage.Schema["type"])
+}
+
+type SchemaParams struct {
+	Status string `json:"status"`
+}
+
+func (SchemaParams) QuerySchema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"status": {"type": "string", "enum": ["open", "closed"], "description": "Status of the foos."}
+		},
+		"required": ["status"]
+	}`)
+}
+
+func SearchWithSchema(ctx context.Context, _ *http.Request, params SchemaParams) (*TestResourceB, error) {
+	return &TestResourceB{}, nil
+}
+
+func TestOpenAPIQuerySchema(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchWithSchema).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithDesc("Search foos"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	params := spec.Paths.MapOfPathItemValues["/foos"].Get.Parameters
+	assert.Equal(t, 1, len(params))
+	status := params[0].Parameter
+	assert.Equal(t, "status", status.Name)
+	assert.Assert(t, *status.Required)
+	assert.Equal(t, "Status of the foos.", *status.Description)
+	assert.DeepEqual(t, []interface{}{"open", "closed"}, status.Schema["enum"])
+}
+
+func TestOpenAPIRefPrefix(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Tracks").Register(
+		mason.HandleGet(GetTrack).
+			Path("/tracks").
+			WithOpID("fetch_track").
+			WithDesc("Get a track"),
+	)
+
+	gen, err := openapi.NewGenerator(api, openapi.RefPrefix("#/schemas/"))
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	points := spec.Components.Schemas["Track"]["properties"].(map[string]interface{})["points"].(map[string]interface{})
+	prefixItems := points["prefixItems"].([]interface{})
+	assert.Equal(t, "#/schemas/Point", prefixItems[0].(map[string]interface{})["$ref"])
+	rsp := spec.Paths.MapOfPathItemValues["/tracks"].Get.Responses.MapOfResponseOrReferenceValues["200"].Response
+	assert.Equal(t, "#/schemas/Track", rsp.Content["application/json"].Schema["$ref"])
+
+	// models can override it
+	sch, err := mason.NewModel(&Track{}, mason.RefPrefix("#/$defs/")).JSONSchema()
+	assert.NilError(t, err)
+	raw, err := sch.MarshalJSON()
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(raw), `"$ref":"#/$defs/Point"`))
+}
+
+func TestOpenAPIRenameComponents(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Tracks").Register(
+		mason.HandleGet(GetTrack).
+			Path("/tracks").
+			WithOpID("fetch_track").
+			WithDesc("Get a track").
+			WithTimeout(time.Second),
+	)
+
+	gen, err := openapi.NewGenerator(api, openapi.RenameComponents(func(name string) string { return "Geo" + name }))
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	names := []string{}
+	for name := range spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.DeepEqual(t, []string{"GeoPoint", "GeoTimeoutError", "GeoTrack"}, names)
+
+	points := spec.Components.Schemas["GeoTrack"]["properties"].(map[string]interface{})["points"].(map[string]interface{})
+	prefixItems := points["prefixItems"].([]interface{})
+	assert.Equal(t, "#/components/schemas/GeoPoint", prefixItems[0].(map[string]interface{})["$ref"])
+	rsp := spec.Paths.MapOfPathItemValues["/tracks"].Get.Responses.MapOfResponseOrReferenceValues["200"].Response
+	assert.Equal(t, "#/components/schemas/GeoTrack", rsp.Content["application/json"].Schema["$ref"])
+}
+
+func TestOpenAPIConflicts(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Original").Register(
+		mason.HandleGet(GetResourceA).
+			Path("/resource-a").
+			WithOpID("fetch_resource_a").
+			WithDesc("Get resource A"),
+	)
+	api.NewRouteGroup("Legacy").Register(
+		mason.HandleGet(GetConflictingResourceA).
+			Path("/v1/resource-a").
+			WithOpID("fetch_legacy_resource_a").
+			WithDesc("Get legacy resource A"),
+	)
+	api.NewRouteGroup("Lower").Register(
+		mason.HandleGet(GetLowerResourceA).
+			Path("/v2/resource-a").
+			WithOpID("fetch_lower_resource_a").
+			WithDesc("Get lower resource A"),
+	)
+
+	refs := func(strategy openapi.ConflictStrategy) (map[string]string, []string) {
+		gen, err := openapi.NewGenerator(api, openapi.Conflicts(strategy))
+		assert.NilError(t, err)
+
+		schema, err := gen.Schema()
+		assert.NilError(t, err)
+
+		var spec openapi31.Spec
+		assert.NilError(t, json.Unmarshal(schema, &spec))
+
+		refs := map[string]string{}
+		for path, item := range spec.Paths.MapOfPathItemValues {
+			rsp := item.Get.Responses.MapOfResponseOrReferenceValues["200"].Response
+			refs[path] = strings.TrimPrefix(rsp.Content["application/json"].Schema["$ref"].(string), mason.DefaultRefPrefix)
+		}
+		names := []string{}
+		for name := range spec.Components.Schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return refs, names
+	}
+
+	suffixed, names := refs(openapi.ConflictSuffix)
+	assert.DeepEqual(t, map[string]string{
+		"/resource-a":    "TestResourceA",
+		"/v1/resource-a": "TestResourceA2",
+		"/v2/resource-a": "testresourcea3",
+	}, suffixed)
+	assert.DeepEqual(t, []string{"TestResourceA", "TestResourceA2", "testresourcea3"}, names)
+
+	namespaced, _ := refs(openapi.ConflictNamespace)
+	assert.DeepEqual(t, map[string]string{
+		"/resource-a":    "TestResourceA",
+		"/v1/resource-a": "LegacyTestResourceA",
+		"/v2/resource-a": "Lowertestresourcea",
+	}, namespaced)
+
+	_, err := openapi.NewGenerator(api, openapi.Conflicts(openapi.ConflictReport))
+	var cerr *openapi.ConflictsError
+	assert.Assert(t, errors.As(err, &cerr))
+	assert.Equal(t, 1, len(cerr.Conflicts))
+	assert.DeepEqual(t, [][]string{{"GET /resource-a"}, {"GET /v1/resource-a"}, {"GET /v2/resource-a"}}, cerr.Conflicts[0].Operations)
+}
+
+func TestOpenAPIDefinitionConflictError(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Original").Register(
+		mason.HandleGet(GetResourceA).
+			Path("/resource-a").
+			WithOpID("fetch_resource_a").
+			WithDesc("Get resource A"),
+	)
+	api.NewRouteGroup("Legacy").Register(
+		mason.HandleGet(GetConflictingResourceA).
+			Path("/v1/resource-a").
+			WithOpID("fetch_legacy_resource_a").
+			WithDesc("Get legacy resource A"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	_, err = gen.Schema()
+	var cerr *openapi.DefinitionConflictError
+	assert.Assert(t, errors.As(err, &cerr))
+	assert.Equal(t, "TestResourceA", cerr.Name)
+
+	paths := []string{}
+	for _, d := range cerr.Differences {
+		paths = append(paths, d.Path)
+	}
+	assert.DeepEqual(t, []string{"/properties/y", "/properties/z"}, paths)
+}
+
+func TestDiffSchemas(t *testing.T) {
+	var a, b jsonschema.Schema
+	assert.NilError(t, json.Unmarshal([]byte(`{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`), &a))
+	assert.NilError(t, json.Unmarshal([]byte(`{"type":"object","properties":{"name":{"type":"integer"}},"examples":[{"name":1}]}`), &b))
+
+	diffs := openapi.DiffSchemas(a, b)
+	assert.DeepEqual(t, []openapi.SchemaDifference{
+		{Path: "/properties/name/type", Old: "string", New: "integer"},
+		{Path: "/required", Old: []any{"name"}, New: nil},
+	}, diffs)
+	assert.Equal(t, `/properties/name/type: "string" != "integer"`, diffs[0].String())
+	assert.Equal(t, `/required: removed ["name"]`, diffs[1].String())
+	assert.Equal(t, 0, len(openapi.DiffSchemas(a, a)))
+}
+
+func TestOpenAPIEntityExamples(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandlePut(CreateResourceA).
+			Path("/foos").
+			WithOpID("create_foo").
+			WithDesc("Create a foo"),
+	)
+
+	gen, err := openapi.NewGenerator(api, openapi.EntityExamples())
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	// the parser of openapi-go rejects named examples
+	var spec struct {
+		Paths map[string]map[string]struct {
+			RequestBody struct {
+				Content map[string]struct {
+					Examples map[string]struct {
+						Value any `json:"value"`
+					} `json:"examples"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Content map[string]struct {
+					Examples map[string]struct {
+						Value any `json:"value"`
+					} `json:"examples"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	expected := map[string]any{"x": "example", "y": map[string]any{"y": "example"}}
+	op := spec.Paths["/foos"]["put"]
+	assert.DeepEqual(t, expected, op.RequestBody.Content["application/json"].Examples["TestResourceA"].Value)
+	assert.DeepEqual(t, expected, op.Responses["200"].Content["application/json"].Examples["TestResourceA"].Value)
+}
+
+// Ticket has server-defined fields, marked readOnly.
+type Ticket struct{}
+
+func CreateTicket(ctx context.Context, _ *http.Request, ticket *Ticket, query TestQuery) (*Ticket, error) {
+	return ticket, nil
+}
+
+func (t *Ticket) Example() []byte {
+	return []byte(`{"id": "t1", "title": "Broken", "comments": [{"id": "c1", "body": "Indeed"}]}`)
+}
+
+func (t *Ticket) Marshal() (json.RawMessage, error) {
+	return json.Marshal(t)
+}
+
+func (t *Ticket) Name() string {
+	return "Ticket"
+}
+
+func (t *Ticket) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string", "readOnly": true},
+			"title": {"type": "string"},
+			"comments": {"type": "array", "items": {"$ref": "#/definitions/Comment"}}
+		},
+		"required": ["id", "title"],
+		"definitions": {
+			"Comment": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "string", "readOnly": true},
+					"body": {"type": "string"}
+				}
+			}
+		}
+	}`)
+}
+
+func (t *Ticket) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, t)
+}
+
+func TestOpenAPIInputVariants(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Tickets").Register(
+		mason.HandlePost(CreateTicket).
+			Path("/tickets").
+			WithOpID("create_ticket").
+			WithDesc("Create a ticket"),
+	)
+
+	gen, err := openapi.NewGenerator(api, openapi.InputVariants("Input"))
+	assert.NilError(t, err)
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	op := spec.Paths.MapOfPathItemValues["/tickets"].Post
+	assert.Equal(t, "#/components/schemas/TicketInput", op.RequestBody.RequestBody.Content["application/json"].Schema["$ref"])
+	assert.Equal(t, "#/components/schemas/Ticket", op.Responses.MapOfResponseOrReferenceValues["201"].Response.Content["application/json"].Schema["$ref"])
+
+	schemas := spec.Components.Schemas
+	input := schemas["TicketInput"]
+	props := input["properties"].(map[string]any)
+	assert.Assert(t, props["id"] == nil)
+	assert.Assert(t, props["title"] != nil)
+	assert.DeepEqual(t, []any{"title"}, input["required"])
+	assert.Equal(t, "#/components/schemas/CommentInput", props["comments"].(map[string]any)["items"].(map[string]any)["$ref"])
+	assert.DeepEqual(t, []any{map[string]any{"title": "Broken", "comments": []any{map[string]any{"id": "c1", "body": "Indeed"}}}}, input["examples"])
+
+	comment := schemas["CommentInput"]["properties"].(map[string]any)
+	assert.Assert(t, comment["id"] == nil)
+	assert.Assert(t, schemas["Comment"]["properties"].(map[string]any)["id"] != nil)
+	assert.Assert(t, schemas["Ticket"]["properties"].(map[string]any)["id"] != nil)
+}
+
+func TestOpenAPISpecExtensions(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(GetResourceB).
+			Path("/foos/{id}").
+			WithOpID("get_foo").
+			WithDesc("Get a foo"),
+	)
+
+	gen, err := openapi.NewGenerator(api,
+		openapi.SpecExtensions(map[string]any{"x-api-id": "foos"}),
+		openapi.InfoExtensions(map[string]any{"x-audience": "public"}),
+		openapi.ServerExtensions(map[string]any{"x-region": "eu"}),
+	)
+	assert.NilError(t, err)
+	gen.Spec.WithServers(openapi31.Server{URL: "https://eu.example.com"})
+
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+	assert.Equal(t, "foos", spec.MapOfAnything["x-api-id"])
+	assert.Equal(t, "public", spec.Info.MapOfAnything["x-audience"])
+	assert.Equal(t, "eu", spec.Servers[0].MapOfAnything["x-region"])
+
+	// the extensions survive the regeneration
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(GetResourceB).
+			Path("/foos/{id}/preview").
+			WithOpID("preview_foo").
+			WithDesc("Preview a foo"),
+	)
+	assert.NilError(t, gen.Invalidate())
+	schema, err = gen.Schema()
+	assert.NilError(t, err)
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+	assert.Assert(t, spec.Paths.MapOfPathItemValues["/foos/{id}/preview"].Get != nil)
+	assert.Equal(t, "foos", spec.MapOfAnything["x-api-id"])
+	assert.Equal(t, "eu", spec.Servers[0].MapOfAnything["x-region"])
+
+	_, err = openapi.NewGenerator(api, openapi.SpecExtensions(map[string]any{"api-id": "foos"}))
+	assert.ErrorContains(t, err, "custom keys must start with 'x-'")
+}
+
+// Draft7 declares the dialect of its schema.
+type Draft7 struct{}
+
+func GetDraft7(ctx context.Context, _ *http.Request, params TestParams) (*Draft7, error) {
+	return &Draft7{}, nil
+}
+
+func (d *Draft7) Example() []byte {
+	return []byte(`{"name": "draft"}`)
+}
+
+func (d *Draft7) Marshal() (json.RawMessage, error) {
+	return json.Marshal(d)
+}
+
+func (d *Draft7) Name() string {
+	return "Draft7"
+}
+
+func (d *Draft7) Schema() []byte {
+	return []byte(`{"$schema":"http://json-schema.org/draft-07/schema#","type":"object","properties":{"name":{"type":"string"}}}`)
+}
+
+func (d *Draft7) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, d)
+}
+
+func TestOpenAPISchemaDialect(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Drafts").Register(
+		mason.HandleGet(GetDraft7).
+			Path("/drafts").
+			WithOpID("get_draft").
+			WithDesc("Get a draft"),
+	)
+
+	component := func(gen *openapi.Generator, err error) (string, openapi31.Spec) {
+		assert.NilError(t, err)
+		schema, err := gen.Schema()
+		assert.NilError(t, err)
+
+		var spec openapi31.Spec
+		assert.NilError(t, json.Unmarshal(schema, &spec))
+		decl, _ := spec.Components.Schemas["Draft7"]["$schema"].(string)
+		return decl, spec
+	}
+
+	decl, spec := component(openapi.NewGenerator(api))
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", decl)
+	assert.Assert(t, spec.JSONSchemaDialect == nil)
+
+	decl, _ = component(openapi.NewGenerator(api, openapi.SchemaDeclarations(openapi.SchemaStrip)))
+	assert.Equal(t, "", decl)
+
+	decl, _ = component(openapi.NewGenerator(api, openapi.SchemaDeclarations(openapi.SchemaNormalize)))
+	assert.Equal(t, openapi.DefaultDialect, decl)
+
+	dialect := "https://json-schema.org/draft/2020-12/schema"
+	decl, spec = component(openapi.NewGenerator(api, openapi.JSONSchemaDialect(dialect), openapi.SchemaDeclarations(openapi.SchemaNormalize)))
+	assert.Equal(t, dialect, decl)
+	assert.Equal(t, dialect, *spec.JSONSchemaDialect)
+}
+
+func TestOpenAPISortOperations(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(mason.HandleGet(GetResourceB).Path("/zoos/{id}").WithOpID("get_zoo").WithDesc("Get a zoo").WithTags("animals"))
+	grp.Register(mason.HandleGet(GetResourceB).Path("/bars/{id}").WithOpID("get_bar").WithDesc("Get a bar").WithTags("places"))
+	grp.Register(mason.HandleGet(GetResourceB).Path("/cats/{id}").WithOpID("get_cat").WithDesc("Get a cat").WithTags("animals"))
+	grp.Register(mason.HandleGet(GetResourceB).Path("/misc/{id}").WithOpID("get_misc").WithDesc("Get a misc"))
+
+	pathOrder := func(gen *openapi.Generator, err error) []string {
+		assert.NilError(t, err)
+		schema, err := gen.Schema()
+		assert.NilError(t, err)
+
+		var spec struct {
+			Paths json.RawMessage `json:"paths"`
+		}
+		assert.NilError(t, json.Unmarshal(schema, &spec))
+		dec := json.NewDecoder(bytes.NewReader(spec.Paths))
+		_, err = dec.Token()
+		assert.NilError(t, err)
+		var paths []string
+		for dec.More() {
+			key, err := dec.Token()
+			assert.NilError(t, err)
+			paths = append(paths, key.(string))
+			var item json.RawMessage
+			assert.NilError(t, dec.Decode(&item))
+		}
+		return paths
+	}
+
+	assert.DeepEqual(t, []string{"/bars/{id}", "/cats/{id}", "/misc/{id}", "/zoos/{id}"},
+		pathOrder(openapi.NewGenerator(api, openapi.SortOperations(openapi.ByPath))))
+	assert.DeepEqual(t, []string{"/cats/{id}", "/zoos/{id}", "/bars/{id}", "/misc/{id}"},
+		pathOrder(openapi.NewGenerator(api, openapi.SortOperations(openapi.ByTag))))
+
+	byOpID := func(a, b openapi.Record) int { return strings.Compare(b.ID, a.ID) }
+	assert.DeepEqual(t, []string{"/zoos/{id}", "/misc/{id}", "/cats/{id}", "/bars/{id}"},
+		pathOrder(openapi.NewGenerator(api, openapi.SortOperations(byOpID))))
+}
+
+func TestOpenAPIUnusedModels(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.RegisterError("not_found", http.StatusNotFound, &model.APIError{})
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(
+		mason.HandleGet(GetResourceB).
+			Path("/foos/{id}").
+			WithOpID("get_foo").
+			WithDesc("Get a foo"),
+	)
+	grp.Register(
+		mason.HandleGet(GetDraft7).
+			Path("/drafts").
+			WithOpID("get_draft").
+			WithDesc("Get a draft").
+			WithVisibility(mason.VisibilityBeta),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	_, err = gen.Schema()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"APIError", "Draft7"}, gen.UnusedModels())
+
+	gen, err = openapi.NewGenerator(api, openapi.Visibility(mason.VisibilityPublic, mason.VisibilityBeta), openapi.FailOnUnusedModels())
+	assert.NilError(t, err)
+	_, err = gen.Schema()
+	var unusedErr *openapi.UnusedModelsError
+	assert.Assert(t, errors.As(err, &unusedErr))
+	assert.DeepEqual(t, []string{"APIError"}, unusedErr.Models)
+	assert.ErrorContains(t, err, "unused models: APIError")
+}
+
+func TestOpenAPIDerivedComponents(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(func(ctx context.Context, _ *http.Request, params TestParams) (*ResourceBV1, error) {
+			return &ResourceBV1{}, nil
+		}).
+			Path("/v1/foos/{id}").
+			WithOpID("get_foo_v1").
+			WithDesc("Get a foo"),
+	)
+
+	_, ok := api.GetModel("TestResourceB")
+	assert.Assert(t, ok)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	spec, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var doc struct {
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	assert.NilError(t, json.Unmarshal(spec, &doc))
+
+	assert.Equal(t, "#/components/schemas/TestResourceB", doc.Components.Schemas["ResourceBV1"]["properties"].(map[string]any)["data"].(map[string]any)["$ref"])
+	_, ok = doc.Components.Schemas["TestResourceB"]
+	assert.Assert(t, ok)
+	assert.Assert(t, strings.Contains(string(spec), `"$ref":"#/components/schemas/ResourceBV1"`))
+}
+
+var (
+	_ model.Entity           = (*ResourceBV1)(nil)
+	_ model.DerivedComponent = (*ResourceBV1)(nil)
+)
+
+// ResourceBV1 is an envelope of TestResourceB, for the first version of the API.
+type ResourceBV1 struct {
+	Data TestResourceB `json:"data"`
+}
+
+func (r *ResourceBV1) Example() []byte {
+	return []byte(`{"data": {"y": "example"}}`)
+}
+
+func (r *ResourceBV1) Marshal() (json.RawMessage, error) {
+	return json.Marshal(r)
+}
+
+func (r *ResourceBV1) Name() string {
+	return "ResourceBV1"
+}
+
+func (r *ResourceBV1) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"data": {"$ref": "#/definitions/TestResourceB"}
+		}
+	}`)
+}
+
+func (r *ResourceBV1) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, r)
+}
+
+func (r *ResourceBV1) Unwrap() model.WithSchema {
+	return &r.Data
+}
+
+func (r *ResourceBV1) KeepComponent() bool {
+	return true
+}
+
+func TestOpenAPIEnvelope(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(func(ctx context.Context, _ *http.Request, params TestParams) (*model.Envelope[*TestResourceB], error) {
+			return &model.Envelope[*TestResourceB]{}, nil
+		}).
+			Path("/foos/{id}").
+			WithOpID("get_foo").
+			WithDesc("Get a foo"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	spec, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var doc struct {
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	assert.NilError(t, json.Unmarshal(spec, &doc))
+
+	props := doc.Components.Schemas["TestResourceBEnvelope"]["properties"].(map[string]any)
+	assert.Equal(t, "#/components/schemas/TestResourceB", props["data"].(map[string]any)["$ref"])
+	assert.Equal(t, "#/components/schemas/Meta", props["meta"].(map[string]any)["$ref"])
+	assert.DeepEqual(t, []any{map[string]any{"y": "example"}}, doc.Components.Schemas["TestResourceB"]["examples"])
+}
+
+func TestOpenAPINoContent(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleDeleteNoContent(func(ctx context.Context, _ *http.Request, _ model.Nil, params TestParams) error {
+			return nil
+		}).
+			Path("/foos/{id}").
+			WithOpID("delete_foo").
+			WithDesc("Delete a foo"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	spec, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Responses map[string]map[string]any `json:"responses"`
+		} `json:"paths"`
+	}
+	assert.NilError(t, json.Unmarshal(spec, &doc))
+
+	responses := doc.Paths["/foos/{id}"]["delete"].Responses
+	assert.DeepEqual(t, map[string]any{"description": "The request succeeded, with no content in the response."}, responses["204"])
+}
+
+func TestOpenAPIContentPolicy(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandlePut(CreateResourceA).
+			Path("/foos").
+			WithOpID("ingest_foos").
+			WithDesc("Ingest foos").
+			WithContent(mason.ContentPolicy{
+				Charsets:        []string{"iso-8859-1"},
+				Encodings:       []string{"gzip"},
+				ResponseCharset: "iso-8859-1",
+			}),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+	op := spec.Paths.MapOfPathItemValues["/foos"].Put
+
+	content := op.RequestBody.RequestBody.Content
+	assert.Assert(t, content["application/json"].Schema != nil)
+	assert.DeepEqual(t, content["application/json"].Schema, content["application/json; charset=iso-8859-1"].Schema)
+
+	var encoding *openapi31.Parameter
+	for _, p := range op.Parameters {
+		if p.Parameter.Name == "Content-Encoding" {
+			encoding = p.Parameter
+		}
+	}
+	assert.Assert(t, encoding != nil)
+	assert.Equal(t, openapi31.ParameterInHeader, encoding.In)
+	assert.Assert(t, *encoding.Required)
+	assert.DeepEqual(t, []interface{}{"gzip"}, encoding.Schema["enum"])
+
+	rsp := op.Responses.MapOfResponseOrReferenceValues
+	_, ok := rsp["200"].Response.Content["application/json; charset=iso-8859-1"]
+	assert.Assert(t, ok)
+	assert.Equal(t, "#/components/schemas/APIError", rsp["415"].Response.Content["application/json"].Schema["$ref"])
+	assert.Equal(t, "#/components/schemas/APIError", rsp["406"].Response.Content["application/json"].Schema["$ref"])
+}
+
+type PriceRange struct {
+	Min      int    `json:"min" required:"true"`
+	Max      *int   `json:"max" doc:"Inclusive upper bound."`
+	Currency string `json:"currency" enum:"eur,usd" default:"eur"`
+}
+
+type ObjectParams struct {
+	Price PriceRange `json:"price" doc:"Price range of the foos." required:"true"`
+}
+
+func SearchByPrice(ctx context.Context, _ *http.Request, params ObjectParams) (*TestResourceB, error) {
+	return &TestResourceB{}, nil
+}
+
+func TestOpenAPIObjectQueryParams(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchByPrice).
+			Path("/foos").
+			WithOpID("search_foos_by_price").
+			WithDesc("Search foos by price"),
+	)
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	params := spec.Paths.MapOfPathItemValues["/foos"].Get.Parameters
+	assert.Equal(t, 1, len(params))
+	price := params[0].Parameter
+	assert.Equal(t, "price", price.Name)
+	assert.Equal(t, "Price range of the foos.", *price.Description)
+	assert.Equal(t, openapi31.ParameterStyleDeepObject, *price.Style)
+	assert.Assert(t, *price.Explode)
+	assert.Assert(t, *price.Required)
+	assert.DeepEqual(t, map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []interface{}{"min"},
+		"properties": map[string]interface{}{
+			"min":      map[string]interface{}{"type": "integer"},
+			"max":      map[string]interface{}{"type": "integer", "description": "Inclusive upper bound."},
+			"currency": map[string]interface{}{"type": "string", "default": "eur", "enum": []interface{}{"eur", "usd"}},
+		},
+	}, price.Schema)
+}
+
+type memoryStore struct {
+	objects   map[string][]byte
+	checksums map[string]string
+	puts      int
+}
+
+func (s *memoryStore) Checksum(ctx context.Context, key string) (string, bool, error) {
+	checksum, ok := s.checksums[key]
+	return checksum, ok, nil
+}
+
+func (s *memoryStore) Put(ctx context.Context, key string, data []byte, contentType string, checksum string) error {
+	s.objects[key], s.checksums[key] = data, checksum
+	s.puts++
+	return nil
+}
+
+func TestOpenAPIPublish(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(
+		mason.HandleGet(SearchTagged).
+			Path("/foos").
+			WithOpID("search_foos").
+			WithDesc("Search foos"),
+	)
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	spec, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var posts []string
+	portal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts = append(posts, r.Header.Get("Authorization")+" "+r.Header.Get("Content-Digest"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer portal.Close()
+
+	path := filepath.Join(t.TempDir(), "docs", "openapi.json")
+	store := &memoryStore{objects: map[string][]byte{}, checksums: map[string]string{}}
+	targets := []openapi.Publisher{
+		openapi.FilePublisher{Path: path},
+		openapi.ObjectStorePublisher{Store: store, Key: "specs/openapi.json"},
+		&openapi.PortalPublisher{URL: portal.URL, Header: http.Header{"Authorization": {"Bearer token"}}},
+	}
+
+	assert.NilError(t, gen.Publish(context.Background(), targets...))
+	written, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Equal(t, string(spec), string(written))
+	assert.Equal(t, string(spec), string(store.objects["specs/openapi.json"]))
+	assert.Equal(t, openapi.SpecHash(spec), store.checksums["specs/openapi.json"])
+	assert.Equal(t, 1, len(posts))
+	assert.Assert(t, strings.HasPrefix(posts[0], "Bearer token sha-256=:"))
+
+	// the unchanged spec is not published again
+	old := time.Now().Add(-time.Hour)
+	assert.NilError(t, os.Chtimes(path, old, old))
+	assert.NilError(t, gen.Publish(context.Background(), targets...))
+	info, err := os.Stat(path)
+	assert.NilError(t, err)
+	assert.Assert(t, info.ModTime().Equal(old))
+	assert.Equal(t, 1, store.puts)
+	assert.Equal(t, 1, len(posts))
+
+	// a failing target does not prevent the others
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	other := filepath.Join(t.TempDir(), "openapi.json")
+	err = gen.Publish(context.Background(), &openapi.PortalPublisher{URL: failing.URL}, openapi.FilePublisher{Path: other})
+	assert.ErrorContains(t, err, "status 502")
+	_, err = os.Stat(other)
+	assert.NilError(t, err)
+}
+
+type ChangelogV1 struct {
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+func (c *ChangelogV1) Name() string { return "Ticket" }
+func (c *ChangelogV1) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string"},
+			"status": {"type": "string", "enum": ["open", "closed"]},
+			"legacy_id": {"type": "integer"}
+		},
+		"required": ["title", "status"]
+	}`)
+}
+func (c *ChangelogV1) Example() []byte { return []byte(`{"title": "a", "status": "open"}`) }
+func (c *ChangelogV1) Marshal() (json.RawMessage, error) {
+	return json.Marshal(c)
+}
+func (c *ChangelogV1) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, c)
+}
+
+type ChangelogV2 struct{ ChangelogV1 }
+
+func (c *ChangelogV2) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string"},
+			"status": {"type": "string", "enum": ["open", "closed", "archived"]},
+			"priority": {"type": "integer"}
+		},
+		"required": ["title", "status", "priority"]
+	}`)
+}
+
+type ChangelogParams struct {
+	Status string `json:"status"`
+}
+
+type ChangelogParamsV2 struct {
+	Status string `json:"status" required:"true"`
+	Limit  int    `json:"limit"`
+}
+
+func TestOpenAPIChangelog(t *testing.T) {
+	v1 := mason.NewAPI(mason.NewHTTPRuntime())
+	g1 := v1.NewRouteGroup("tickets")
+	g1.Register(mason.HandlePost(func(ctx context.Context, r *http.Request, in *ChangelogV1, params model.Nil) (*ChangelogV1, error) {
+		return in, nil
+	}).Path("/tickets").WithOpID("create_ticket").WithTags("Tickets"))
+	g1.Register(mason.HandleGet(func(ctx context.Context, r *http.Request, params ChangelogParams) (*ChangelogV1, error) {
+		return &ChangelogV1{}, nil
+	}).Path("/tickets/search").WithOpID("search_tickets").WithTags("Tickets"))
+	g1.Register(mason.HandleDelete(func(ctx context.Context, r *http.Request, in model.Nil, params model.Nil) (*ChangelogV1, error) {
+		return &ChangelogV1{}, nil
+	}).Path("/tickets/{id}").WithOpID("delete_ticket").WithTags("Tickets"))
+
+	v2 := mason.NewAPI(mason.NewHTTPRuntime())
+	g2 := v2.NewRouteGroup("tickets")
+	g2.Register(mason.HandlePost(func(ctx context.Context, r *http.Request, in *ChangelogV2, params model.Nil) (*ChangelogV2, error) {
+		return in, nil
+	}).Path("/tickets").WithOpID("create_ticket").WithTags("Tickets"))
+	g2.Register(mason.HandleGet(func(ctx context.Context, r *http.Request, params ChangelogParamsV2) (*ChangelogV2, error) {
+		return &ChangelogV2{}, nil
+	}).Path("/tickets/search").WithOpID("search_tickets").WithTags("Tickets"))
+	g2.Register(mason.HandleGet(func(ctx context.Context, r *http.Request, params model.Nil) (*ChangelogV2, error) {
+		return &ChangelogV2{}, nil
+	}).Path("/tickets/{id}").WithOpID("get_ticket").WithTags("Tickets"))
+
+	spec := func(api *mason.API) []byte {
+		gen, err := openapi.NewGenerator(api)
+		assert.NilError(t, err)
+		schema, err := gen.Schema()
+		assert.NilError(t, err)
+		return schema
+	}
+	oldSpec, newSpec := spec(v1), spec(v2)
+
+	log, err := openapi.NewChangelog(oldSpec, newSpec)
+	assert.NilError(t, err)
+	assert.Assert(t, log.Breaking())
+
+	descriptions := map[string]bool{}
+	for _, ch := range log.Changes {
+		descriptions[fmt.Sprintf("%s %s: %s", ch.Method, ch.Path, ch.Description)] = ch.Breaking
+	}
+	assert.DeepEqual(t, map[string]bool{
+		"POST /tickets: Added the required request property `priority`.":                            true,
+		"POST /tickets: Removed the request property `legacy_id`.":                                  true,
+		"POST /tickets: Added the values \"archived\" to the request property `status`.":            false,
+		"POST /tickets: Added the optional 201 response property `priority`.":                       false,
+		"POST /tickets: Removed the 201 response property `legacy_id`.":                             true,
+		"POST /tickets: Added the values \"archived\" to the 201 response property `status`.":       false,
+		"GET /tickets/search: Made the query param `status` required.":                              true,
+		"GET /tickets/search: Added the optional query param `limit`.":                              false,
+		"GET /tickets/search: Added the optional 200 response property `priority`.":                 false,
+		"GET /tickets/search: Removed the 200 response property `legacy_id`.":                       true,
+		"GET /tickets/search: Added the values \"archived\" to the 200 response property `status`.": false,
+		"DELETE /tickets/{id}: Removed the operation.":                                              true,
+		"GET /tickets/{id}: Added the operation.":                                                   false,
+	}, descriptions)
+
+	md := log.Markdown("Changes in v2")
+	assert.Assert(t, strings.HasPrefix(md, "# Changes in v2\n\n## Tickets\n"))
+	assert.Assert(t, strings.Contains(md, "\n### `DELETE /tickets/{id}` (delete_ticket)\n\n- **Breaking** Removed the operation.\n"))
+	assert.Assert(t, strings.Contains(md, "- Added the optional query param `limit`.\n"))
+
+	data, err := json.Marshal(log)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(data), `"breaking":true`))
+
+	same, err := openapi.NewChangelog(newSpec, newSpec)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(same.Changes))
+	assert.Equal(t, "# Changes\n\nNo changes.\n", same.Markdown("Changes"))
+}
+
+func TestOpenAPIAdviseVersion(t *testing.T) {
+	search := mason.HandleGet(SearchTagged).Path("/foos").WithOpID("search_foos").WithTags("Foos")
+
+	previous := mason.NewAPI(mason.NewHTTPRuntime())
+	previous.NewRouteGroup("Foos").Register(search)
+	prevGen, err := openapi.NewGenerator(previous)
+	assert.NilError(t, err)
+	prevGen.Spec.Info.WithVersion("v1.4.2")
+	prevSpec, err := prevGen.Schema()
+	assert.NilError(t, err)
+
+	current := mason.NewAPI(mason.NewHTTPRuntime())
+	current.NewRouteGroup("Foos").Register(search)
+	current.NewRouteGroup("Foos").Register(mason.HandleGet(SearchByPrice).Path("/foos/by-price").WithOpID("search_foos_by_price").WithTags("Foos"))
+	gen, err := openapi.NewGenerator(current)
+	assert.NilError(t, err)
+
+	advice, err := gen.AdviseVersion(prevSpec)
+	assert.NilError(t, err)
+	assert.Equal(t, openapi.BumpMinor, advice.Bump)
+	assert.Equal(t, "v1.4.2", advice.Previous)
+	assert.Equal(t, "v1.5.0", advice.Next)
+	assert.DeepEqual(t, []string{"GET /foos/by-price: Added the operation."}, advice.Reasons())
+
+	breaking := openapi.Changelog{Changes: []openapi.Change{
+		{Method: "GET", Path: "/foos", Kind: openapi.ChangeAdded, Description: "Added the optional query param `limit`."},
+		{Method: "GET", Path: "/foos", Kind: openapi.ChangeRemoved, Description: "Removed the operation.", Breaking: true},
+	}}
+	advice = openapi.AdviseVersion(breaking, "2.3.1")
+	assert.Equal(t, openapi.BumpMajor, advice.Bump)
+	assert.Equal(t, "3.0.0", advice.Next)
+	assert.Equal(t, 1, len(advice.Justification))
+	// breaking changes only bump the minor version before 1.0.0
+	assert.Equal(t, "0.4.0", openapi.AdviseVersion(breaking, "0.3.1").Next)
+	assert.Equal(t, "", openapi.AdviseVersion(breaking, "2024-10").Next)
+
+	advice = openapi.AdviseVersion(openapi.Changelog{}, "1.0.1")
+	assert.Equal(t, openapi.BumpPatch, advice.Bump)
+	assert.Equal(t, "1.0.2", advice.Next)
+	assert.Equal(t, 0, len(advice.Justification))
+	// a pre-release is bumped to its release
+	assert.Equal(t, "2.0.0", openapi.AdviseVersion(breaking, "2.0.0-rc.1").Next)
+	assert.Equal(t, "3.0.0", openapi.AdviseVersion(breaking, "2.1.0-rc.1").Next)
+	assert.Equal(t, "1.0.0", openapi.AdviseVersion(openapi.Changelog{}, "1.0.0-rc.1+build.5").Next)
+}
+
+func TestOpenAPIFeatures(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(mason.HandlePost(CreateResourceA).Path("/foos").WithOpID("create_foo").WithDesc("Create a foo"))
+	grp.Register(mason.HandlePut(CreateResourceA).
+		Path("/foos/import").
+		WithOpID("import_foos").
+		WithDesc("Import foos").
+		WithFeatureFlag("plan:pro"))
+	grp.Register(mason.HandlePut(CreateResourceA).
+		Path("/foos/sync").
+		WithOpID("sync_foos").
+		WithDesc("Sync foos").
+		WithFeatureFlag("plan:pro", "beta:sync"))
+
+	paths := func(gen *openapi.Generator, err error) []string {
+		assert.NilError(t, err)
+		schema, err := gen.Schema()
+		assert.NilError(t, err)
+
+		var spec openapi31.Spec
+		assert.NilError(t, json.Unmarshal(schema, &spec))
+		var paths []string
+		for path := range spec.Paths.MapOfPathItemValues {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	all := []string{"/foos", "/foos/import", "/foos/sync"}
+	assert.DeepEqual(t, all, paths(openapi.NewGenerator(api)))
+	assert.DeepEqual(t, []string{"/foos"}, paths(openapi.NewGenerator(api, openapi.Features())))
+	assert.DeepEqual(t, []string{"/foos", "/foos/import"}, paths(openapi.NewGenerator(api, openapi.Features("plan:pro"))))
+	assert.DeepEqual(t, all, paths(openapi.NewGenerator(api, openapi.Features("plan:pro", "beta:sync"))))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+	assert.DeepEqual(t, []any{"plan:pro", "beta:sync"}, spec.Paths.MapOfPathItemValues["/foos/sync"].Put.MapOfAnything["x-feature-flags"])
+	_, ok := spec.Paths.MapOfPathItemValues["/foos"].Post.MapOfAnything["x-feature-flags"]
+	assert.Assert(t, !ok)
+}
+
+func TestOpenAPIRollouts(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(mason.HandlePost(CreateResourceA).Path("/foos").WithOpID("create_foo").WithDesc("Create a foo"))
+	grp.Register(mason.HandlePut(CreateResourceA).
+		Path("/foos/import").
+		WithOpID("import_foos").
+		WithDesc("Import foos").
+		WithRollout("foo-import"))
+
+	hasImport := func(gen *openapi.Generator, err error) bool {
+		assert.NilError(t, err)
+		schema, err := gen.Schema()
+		assert.NilError(t, err)
+
+		var spec openapi31.Spec
+		assert.NilError(t, json.Unmarshal(schema, &spec))
+		_, ok := spec.Paths.MapOfPathItemValues["/foos/import"]
+		return ok
+	}
+
+	assert.Assert(t, hasImport(openapi.NewGenerator(api)))
+	assert.Assert(t, !hasImport(openapi.NewGenerator(api, openapi.Rollouts(func(flag string) bool { return false }))))
+	assert.Assert(t, hasImport(openapi.NewGenerator(api, openapi.Rollouts(func(flag string) bool { return flag == "foo-import" }))))
+}
+
+// Contact is classified as a whole, and has a PII field.
+type Contact struct{}
+
+func GetContact(ctx context.Context, _ *http.Request, params TestParams) (*Contact, error) {
+	return &Contact{}, nil
+}
+
+func (p *Contact) DataClassification() model.DataClassification {
+	return model.ClassificationInternal
+}
+
+func (p *Contact) Example() []byte {
+	return []byte(`{"email": "ada@example.com"}`)
+}
+
+func (p *Contact) Marshal() (json.RawMessage, error) {
+	return json.Marshal(p)
+}
+
+func (p *Contact) Name() string {
+	return "Contact"
+}
+
+func (p *Contact) Schema() []byte {
+	return []byte(`
+	{
+		"type":"object",
+		"properties": {
+			"email": {"type": "string", "x-data-classification": "pii"}
+		}
+	}
+	`)
+}
+
+func (p *Contact) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, p)
+}
+
+func TestOpenAPIDataClassification(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Contacts").Register(mason.HandleGet(GetContact).Path("/contact").WithOpID("get_contact").WithDesc("Get the contact"))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	contact := spec.Components.Schemas["Contact"]
+	assert.Equal(t, "internal", contact["x-data-classification"])
+	email := contact["properties"].(map[string]interface{})["email"].(map[string]interface{})
+	assert.Equal(t, "pii", email["x-data-classification"])
+}
+
+// Article has a description in French and German.
+type Article struct{}
+
+func GetArticle(ctx context.Context, _ *http.Request, params TestParams) (*Article, error) {
+	return &Article{}, nil
+}
+
+func (p *Article) LocalizedDescriptions() map[string]string {
+	return map[string]string{"fr": "Un article du catalogue", "de": "Ein Artikel des Katalogs"}
+}
+
+func (p *Article) Example() []byte {
+	return []byte(`{"title": "Pen"}`)
+}
+
+func (p *Article) Marshal() (json.RawMessage, error) {
+	return json.Marshal(p)
+}
+
+func (p *Article) Name() string {
+	return "Article"
+}
+
+func (p *Article) Schema() []byte {
+	return []byte(`
+	{
+		"type":"object",
+		"description": "An article of the catalog",
+		"properties": {
+			"title": {"type": "string"}
+		}
+	}
+	`)
+}
+
+func (p *Article) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, p)
+}
+
+func TestOpenAPILocale(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Articles").Register(mason.HandleGet(GetArticle).
+		Path("/articles").
+		WithOpID("get_article").
+		WithSummary("Get an article").
+		WithDesc("Returns an article of the catalog").
+		WithSummaryLocalized(map[string]string{"fr": "Obtenir un article", "de": "Einen Artikel abrufen"}).
+		WithDescLocalized(map[string]string{"fr": "Renvoie un article du catalogue"}))
+
+	spec := func(gen *openapi.Generator, err error) openapi31.Spec {
+		assert.NilError(t, err)
+		schema, err := gen.Schema()
+		assert.NilError(t, err)
+
+		var spec openapi31.Spec
+		assert.NilError(t, json.Unmarshal(schema, &spec))
+		return spec
+	}
+
+	fr := spec(openapi.NewGenerator(api, openapi.Locale("fr")))
+	op := fr.Paths.MapOfPathItemValues["/articles"].Get
+	assert.Equal(t, "Obtenir un article", *op.Summary)
+	assert.Equal(t, "Renvoie un article du catalogue", *op.Description)
+	assert.Equal(t, "Un article du catalogue", fr.Components.Schemas["Article"]["description"])
+
+	// the texts that are not translated keep their default
+	es := spec(openapi.NewGenerator(api, openapi.Locale("es")))
+	assert.Equal(t, "Get an article", *es.Paths.MapOfPathItemValues["/articles"].Get.Summary)
+	assert.Equal(t, "An article of the catalog", es.Components.Schemas["Article"]["description"])
+
+	all := spec(openapi.NewGenerator(api, openapi.Translations()))
+	op = all.Paths.MapOfPathItemValues["/articles"].Get
+	assert.Equal(t, "Get an article", *op.Summary)
+	assert.DeepEqual(t, map[string]any{
+		"fr": map[string]any{"summary": "Obtenir un article", "description": "Renvoie un article du catalogue"},
+		"de": map[string]any{"summary": "Einen Artikel abrufen"},
+	}, op.MapOfAnything["x-translations"])
+	assert.DeepEqual(t, map[string]any{
+		"fr": map[string]any{"description": "Un article du catalogue"},
+		"de": map[string]any{"description": "Ein Artikel des Katalogs"},
+	}, all.Components.Schemas["Article"]["x-translations"])
+}
+
+func TestOpenAPIValidateMarkdown(t *testing.T) {
+	desc := strings.Join([]string{
+		"Creates a foo, see [the limits](#rate-limits) and [the guide](guide.md).",
+		"",
+		"## Rate limits",
+		"",
+		"#### Bursts",
+		"",
+		"```",
+		"# not a heading, [nor a link](nowhere)",
+		"```",
+		"",
+		"See [the dashboard](https://example.com/dashboard) and [the quotas](#quotas).",
+	}, "\n")
+
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(mason.HandlePost(CreateResourceA).Path("/foos").WithOpID("create_foo").WithDesc(desc))
+
+	gen, err := openapi.NewGenerator(api, openapi.ValidateMarkdown())
+	assert.NilError(t, err)
+	_, err = gen.Schema()
+	var verr *openapi.SpecValidationError
+	assert.Assert(t, errors.As(err, &verr))
+
+	type finding struct {
+		Rule, Severity, Path, Message string
+		Line                          int
+	}
+	var got []finding
+	for _, f := range verr.Findings {
+		got = append(got, finding{f.Rule, f.Severity, f.Path, f.Message, f.Line})
+	}
+	path := "$.paths['/foos'].post.description"
+	assert.DeepEqual(t, []finding{
+		{openapi.MarkdownHeadingRule, openapi.SeverityWarn, path, `heading "Bursts" skips from level 2 to 4`, 5},
+		{openapi.MarkdownLinkRule, openapi.SeverityError, path, `relative link "guide.md", use an absolute URL`, 1},
+		{openapi.MarkdownLinkRule, openapi.SeverityError, path, `anchor "#quotas" matches no heading`, 11},
+	}, got)
+
+	// the warnings are not fatal with NonFatalWarnings
+	gen, err = openapi.NewGenerator(api, openapi.ValidateMarkdown(), openapi.RuleSeverity(openapi.MarkdownLinkRule, openapi.SeverityOff), openapi.NonFatalWarnings())
+	assert.NilError(t, err)
+	_, err = gen.Schema()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(gen.Warnings()))
+	assert.Equal(t, openapi.MarkdownHeadingRule, gen.Warnings()[0].Rule)
+
+	// the descriptions are not linted by default
+	gen, err = openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	_, err = gen.Schema()
+	assert.NilError(t, err)
+}
+
+func TestOpenAPIDeprecated(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(mason.HandlePost(CreateResourceA).Path("/foos").WithOpID("create_foo").WithDesc("Create a foo"))
+	grp.Register(mason.HandlePut(CreateResourceA).
+		Path("/foos/import").
+		WithOpID("import_foos").
+		WithDesc("Import foos").
+		WithDeprecation(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+	assert.Assert(t, spec.Paths.MapOfPathItemValues["/foos"].Post.Deprecated == nil)
+	assert.Equal(t, true, *spec.Paths.MapOfPathItemValues["/foos/import"].Put.Deprecated)
+}
+
+func TestOpenAPIChangelogReviews(t *testing.T) {
+	v1 := mason.NewAPI(mason.NewHTTPRuntime())
+	foos := v1.NewRouteGroup("Foos").WithOwner("foo-team")
+	foos.Register(mason.HandlePost(CreateResourceA).Path("/foos").WithOpID("create_foo"))
+	foos.Register(mason.HandlePut(CreateResourceA).Path("/foos/import").WithOpID("import_foos").WithOwner("imports"))
+	v1.NewRouteGroup("Bars").Register(mason.HandlePost(CreateResourceA).Path("/bars").WithOpID("create_bar"))
+
+	v2 := mason.NewAPI(mason.NewHTTPRuntime())
+	foos = v2.NewRouteGroup("Foos").WithOwner("foo-team")
+	foos.Register(mason.HandlePost(CreateResourceA).
+		Path("/foos").
+		WithOpID("create_foo").
+		WithDeprecation(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	bars := v2.NewRouteGroup("Bars")
+	bars.Register(mason.HandlePost(CreateResourceA).Path("/bars").WithOpID("create_bar"))
+	bars.Register(mason.HandlePut(CreateResourceA).Path("/bars/sync").WithOpID("sync_bars"))
+
+	spec := func(api *mason.API) []byte {
+		gen, err := openapi.NewGenerator(api)
+		assert.NilError(t, err)
+		schema, err := gen.Schema()
+		assert.NilError(t, err)
+		return schema
+	}
+	oldSpec, newSpec := spec(v1), spec(v2)
+
+	var doc openapi31.Spec
+	assert.NilError(t, json.Unmarshal(oldSpec, &doc))
+	assert.Equal(t, "foo-team", doc.Paths.MapOfPathItemValues["/foos"].Post.MapOfAnything["x-owner"])
+	assert.Equal(t, "imports", doc.Paths.MapOfPathItemValues["/foos/import"].Put.MapOfAnything["x-owner"])
+	_, ok := doc.Paths.MapOfPathItemValues["/bars"].Post.MapOfAnything["x-owner"]
+	assert.Assert(t, !ok)
+
+	log, err := openapi.NewChangelog(oldSpec, newSpec)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []openapi.OwnerReview{
+		{Owner: "", Operations: []string{"PUT /bars/sync"}},
+		{Owner: "foo-team", Operations: []string{"POST /foos"}},
+		{Owner: "imports", Operations: []string{"PUT /foos/import"}, Breaking: true},
+	}, log.Reviews())
+}
+
+// paginationPlugin documents the cursor param it adds to the GET routes.
+type paginationPlugin struct{}
+
+func (paginationPlugin) Name() string {
+	return "pagination"
+}
+
+func (paginationPlugin) TransformSpec(spec map[string]any) error {
+	paths, ok := spec["paths"].(map[string]any)
+	if !ok {
+		return fmt.Errorf("spec has no paths")
+	}
+	for _, item := range paths {
+		get, ok := item.(map[string]any)["get"].(map[string]any)
+		if !ok {
+			continue
+		}
+		params, _ := get["parameters"].([]any)
+		get["parameters"] = append(params, map[string]any{
+			"name":   "cursor",
+			"in":     "query",
+			"schema": map[string]any{"type": "string"},
+		})
+	}
+
+	return nil
+}
+
+func TestOpenAPISpecPlugin(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.RegisterPlugin(paginationPlugin{})
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(mason.HandlePost(CreateResourceA).Path("/foos").WithOpID("create_foo").WithDesc("Create a foo"))
+	grp.Register(mason.HandleGet(GetResourceA).Path("/foos/{id}").WithOpID("get_foo").WithDesc("Get a foo"))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+	for _, param := range spec.Paths.MapOfPathItemValues["/foos"].Post.Parameters {
+		assert.Assert(t, param.Parameter.Name != "cursor")
+	}
+	params := spec.Paths.MapOfPathItemValues["/foos/{id}"].Get.Parameters
+	last := params[len(params)-1].Parameter
+	assert.Equal(t, "cursor", last.Name)
+	assert.Equal(t, openapi31.ParameterInQuery, last.In)
+}
+
+// TimestampedResourceA is a TestResourceA that knows when it last changed.
+type TimestampedResourceA struct {
+	TestResourceA
+}
+
+func (t *TimestampedResourceA) LastModified() time.Time {
+	return time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestOpenAPIConditionalGet(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(mason.HandleGet(func(ctx context.Context, _ *http.Request, params TestParams) (*TimestampedResourceA, error) {
+		return &TimestampedResourceA{}, nil
+	}).Path("/foos/{id}").WithOpID("get_foo").WithDesc("Get a foo"))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	get := spec.Paths.MapOfPathItemValues["/foos/{id}"].Get
+	_, ok := get.Responses.MapOfResponseOrReferenceValues["200"].Response.Headers["Last-Modified"]
+	assert.Assert(t, ok)
+	notModified, ok := get.Responses.MapOfResponseOrReferenceValues["304"]
+	assert.Assert(t, ok)
+	assert.Equal(t, "The resource was not modified since the cached version.", notModified.Response.Description)
+
+	var found bool
+	for _, param := range get.Parameters {
+		if param.Parameter.Name == "If-Modified-Since" {
+			found = param.Parameter.In == openapi31.ParameterInHeader
+		}
+	}
+	assert.Assert(t, found)
+}
+
+func TestOpenAPIFile(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Exports").Register(mason.HandleFile(func(ctx context.Context, _ *http.Request, params TestParams) (*mason.File, error) {
+		return &mason.File{}, nil
+	}).Path("/exports/{id}").WithOpID("download_export").WithDesc("Download an export"))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	get := spec.Paths.MapOfPathItemValues["/exports/{id}"].Get
+	ok := get.Responses.MapOfResponseOrReferenceValues["200"].Response
+	_, found := ok.Content["application/octet-stream"]
+	assert.Assert(t, found)
+	_, found = ok.Headers["Accept-Ranges"]
+	assert.Assert(t, found)
+
+	partial := get.Responses.MapOfResponseOrReferenceValues["206"].Response
+	assert.Equal(t, "The requested ranges of the resource.", partial.Description)
+	_, found = partial.Content["application/octet-stream"]
+	assert.Assert(t, found)
+	_, found = partial.Headers["Content-Range"]
+	assert.Assert(t, found)
+	_, found = get.Responses.MapOfResponseOrReferenceValues["416"]
+	assert.Assert(t, found)
+
+	var params []string
+	for _, param := range get.Parameters {
+		if param.Parameter.In == openapi31.ParameterInHeader {
+			params = append(params, param.Parameter.Name)
+		}
+	}
+	assert.DeepEqual(t, []string{"Range", "If-Range"}, params)
+}
+
+func TestOpenAPISignedURL(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Exports")
+	grp.Register(mason.HandleFile(func(ctx context.Context, _ *http.Request, params TestParams) (*mason.File, error) {
+		return &mason.File{}, nil
+	}).Path("/exports/{id}").WithOpID("download_export").WithDesc("Download an export").WithMWs(mason.NewURLSigner(api, []byte("secret"))))
+	grp.Register(mason.HandlePost(CreateResourceA).Path("/exports").WithOpID("create_export").WithDesc("Create an export"))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	assert.DeepEqual(t, []map[string][]string{{mason.SignedURLScheme: {}}}, spec.Paths.MapOfPathItemValues["/exports/{id}"].Get.Security)
+	assert.Assert(t, spec.Paths.MapOfPathItemValues["/exports"].Post.Security == nil)
+	scheme := spec.Components.SecuritySchemes[mason.SignedURLScheme].SecurityScheme
+	assert.Equal(t, mason.SignatureParam, scheme.APIKey.Name)
+	assert.Equal(t, openapi31.SecuritySchemeAPIKeyInQuery, scheme.APIKey.In)
+}
+
+func TestOpenAPIRetryAfter(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.RegisterError("rate_limited", http.StatusTooManyRequests, &model.APIError{})
+	api.RegisterError("not_found", http.StatusNotFound, &model.APIError{})
+	api.NewRouteGroup("Foos").Register(mason.HandleGet(GetResourceA).
+		Path("/foos/{id}").
+		WithOpID("get_foo").
+		WithDesc("Get a foo").
+		WithErrors("rate_limited", "not_found"))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	responses := spec.Paths.MapOfPathItemValues["/foos/{id}"].Get.Responses.MapOfResponseOrReferenceValues
+	_, ok := responses["429"].Response.Headers["Retry-After"]
+	assert.Assert(t, ok)
+	_, ok = responses["404"].Response.Headers["Retry-After"]
+	assert.Assert(t, !ok)
+}
+
+func TestOpenAPISurrogateKeys(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(mason.HandleGet(GetResourceA).Path("/foos/{id}").WithOpID("get_foo").WithDesc("Get a foo").
+		WithCache(mason.CachePolicy{MaxAge: time.Minute, SurrogateKeys: []string{"foos", "foo-{id}"}}))
+	grp.Register(mason.HandleGet(GetResourceA).Path("/bars/{id}").WithOpID("get_bar").WithDesc("Get a bar").WithCache(mason.CachePolicy{MaxAge: time.Minute}))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	headers := spec.Paths.MapOfPathItemValues["/foos/{id}"].Get.Responses.MapOfResponseOrReferenceValues["200"].Response.Headers
+	_, ok := headers["Surrogate-Key"]
+	assert.Assert(t, ok)
+
+	headers = spec.Paths.MapOfPathItemValues["/bars/{id}"].Get.Responses.MapOfResponseOrReferenceValues["200"].Response.Headers
+	_, ok = headers["Surrogate-Key"]
+	assert.Assert(t, !ok)
+}
+
+func TestOpenAPIWebhooks(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.RegisterWebhook("foo.created", &TestResourceA{}, mason.WebhookSummary("A foo was created"))
+
+	gen, err := openapi.NewGenerator(api, openapi.FailOnUnusedModels())
+	assert.NilError(t, err)
+	schema, err := gen.Schema()
+	assert.NilError(t, err)
+
+	var spec openapi31.Spec
+	assert.NilError(t, json.Unmarshal(schema, &spec))
+
+	webhook, ok := spec.Webhooks["foo.created"]
+	assert.Assert(t, ok)
+	post := webhook.PathItem.Post
+	assert.Equal(t, "A foo was created", *post.Summary)
+	content := post.RequestBody.RequestBody.Content["application/json"]
+	assert.Equal(t, "#/components/schemas/TestResourceA", content.Schema["$ref"])
+	_, ok = spec.Components.Schemas["TestResourceA"]
+	assert.Assert(t, ok)
+
+	var headers []string
+	for _, param := range post.Parameters {
+		headers = append(headers, param.Parameter.Name)
+	}
+	assert.DeepEqual(t, []string{"Webhook-Id", "Webhook-Event", "Webhook-Timestamp", "Webhook-Signature"}, headers)
+}
@@ -0,0 +1,133 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/swaggest/openapi-go"
+)
+
+// SortOperations orders the paths of the spec after their operations, sorted with cmp, e.g. ByPath or ByTag, for the
+// docs tooling that renders the endpoints in document order. A path comes with its first operation. By default, the
+// paths are sorted alphabetically.
+func SortOperations(cmp func(a, b Record) int) openAPIOption {
+	return func(c *config) {
+		c.sortFn = cmp
+	}
+}
+
+// ByPath sorts the operations by path, then by method.
+func ByPath(a, b Record) int {
+	if c := strings.Compare(a.Path, b.Path); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Method, b.Method)
+}
+
+// ByTag groups the operations by their first tag, in the order of the tags of the spec, then sorts them by path. The
+// operations without tags come last.
+func ByTag(a, b Record) int {
+	switch {
+	case len(a.Tags) == 0 && len(b.Tags) == 0:
+		return ByPath(a, b)
+	case len(a.Tags) == 0:
+		return 1
+	case len(b.Tags) == 0:
+		return -1
+	}
+	if c := strings.Compare(a.Tags[0], b.Tags[0]); c != 0 {
+		return c
+	}
+	return ByPath(a, b)
+}
+
+// orderPaths rewrites the paths object of the spec in the order of the sorted records.
+func orderPaths(spec []byte, records []Record, cmp func(a, b Record) int) ([]byte, error) {
+	sorted := slices.Clone(records)
+	slices.SortStableFunc(sorted, cmp)
+
+	var order []string
+	seen := make(map[string]bool)
+	for _, record := range sorted {
+		_, path, _, err := openapi.SanitizeMethodPath(record.Method, record.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", record.Method, record.Path, err)
+		}
+		if !seen[path] {
+			seen[path] = true
+			order = append(order, path)
+		}
+	}
+
+	start, end, err := valueOffsets(spec, "paths")
+	if err != nil || start < 0 {
+		return spec, err
+	}
+
+	var paths map[string]json.RawMessage
+	if err := json.Unmarshal(spec[start:end], &paths); err != nil {
+		return nil, err
+	}
+	// the paths without records, if any, come last in alphabetical order
+	var rest []string
+	for path := range paths {
+		if !seen[path] {
+			rest = append(rest, path)
+		}
+	}
+	slices.Sort(rest)
+	order = append(order, rest...)
+
+	var buf bytes.Buffer
+	buf.Write(spec[:start])
+	buf.WriteByte('{')
+	first := true
+	for _, path := range order {
+		item, ok := paths[path]
+		if !ok {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		key, err := json.Marshal(path)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(item)
+	}
+	buf.WriteByte('}')
+	buf.Write(spec[end:])
+
+	return buf.Bytes(), nil
+}
+
+// valueOffsets returns the offsets of the value of a top-level key of a JSON object, or -1 if it is missing.
+func valueOffsets(data []byte, key string) (int, int, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return 0, 0, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return 0, 0, err
+		}
+		if tok == key {
+			end := int(dec.InputOffset())
+			return end - len(value), end, nil
+		}
+	}
+
+	return -1, -1, nil
+}
@@ -0,0 +1,32 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tailbits/mason"
+)
+
+// applySpecPlugins hands the spec to the mason.SpecPlugin plugins of the API, in the order they are registered.
+func applySpecPlugins(spec []byte, plugins []mason.Plugin) ([]byte, error) {
+	var doc map[string]any
+	for _, p := range plugins {
+		sp, ok := p.(mason.SpecPlugin)
+		if !ok {
+			continue
+		}
+		if doc == nil {
+			if err := json.Unmarshal(spec, &doc); err != nil {
+				return nil, fmt.Errorf("failed to parse the spec: %w", err)
+			}
+		}
+		if err := sp.TransformSpec(doc); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", p.Name(), err)
+		}
+	}
+	if doc == nil {
+		return spec, nil
+	}
+
+	return json.Marshal(doc)
+}
@@ -0,0 +1,156 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Publisher publishes a generated spec to a target, e.g. a file, a bucket or a docs portal. The publishers skip the
+// specs that did not change since they were last published, by their content hash.
+type Publisher interface {
+	Publish(ctx context.Context, spec []byte) error
+}
+
+// Publish generates the spec and publishes it to every target. The targets are all attempted, and their errors joined.
+func (g *Generator) Publish(ctx context.Context, targets ...Publisher) error {
+	spec, err := g.Schema()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, target := range targets {
+		if err := target.Publish(ctx, spec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SpecHash returns the hex encoded SHA-256 of a spec, which the publishers compare to skip the unchanged specs.
+func SpecHash(spec []byte) string {
+	sum := sha256.Sum256(spec)
+	return hex.EncodeToString(sum[:])
+}
+
+// FilePublisher writes the spec to a file, creating its directory. The file is left untouched when it already holds
+// the spec.
+type FilePublisher struct {
+	Path string
+}
+
+func (p FilePublisher) Publish(ctx context.Context, spec []byte) error {
+	if existing, err := os.ReadFile(p.Path); err == nil && SpecHash(existing) == SpecHash(spec) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create the directory of %s: %w", p.Path, err)
+	}
+	// write to a temporary file first, so the readers never see a partial spec
+	tmp := p.Path + ".tmp"
+	if err := os.WriteFile(tmp, spec, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, p.Path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p.Path, err)
+	}
+
+	return nil
+}
+
+// ObjectStore is a bucket of a storage service, e.g. S3 or GCS, implemented by the caller with the client of the
+// service, so this package does not depend on their SDKs.
+type ObjectStore interface {
+	// Checksum returns the checksum the object was put with, e.g. from its metadata, and false if it does not exist.
+	Checksum(ctx context.Context, key string) (string, bool, error)
+	// Put stores the object, with its checksum, the SpecHash of the data.
+	Put(ctx context.Context, key string, data []byte, contentType string, checksum string) error
+}
+
+// ObjectStorePublisher puts the spec in a bucket under a key, unless the object already holds it.
+type ObjectStorePublisher struct {
+	Store ObjectStore
+	Key   string
+}
+
+func (p ObjectStorePublisher) Publish(ctx context.Context, spec []byte) error {
+	hash := SpecHash(spec)
+	checksum, ok, err := p.Store.Checksum(ctx, p.Key)
+	if err != nil {
+		return fmt.Errorf("failed to read the checksum of %s: %w", p.Key, err)
+	}
+	if ok && checksum == hash {
+		return nil
+	}
+
+	if err := p.Store.Put(ctx, p.Key, spec, "application/json", hash); err != nil {
+		return fmt.Errorf("failed to put %s: %w", p.Key, err)
+	}
+
+	return nil
+}
+
+// PortalPublisher POSTs the spec to a docs portal, with its hash in the Content-Digest header (RFC 9530). The spec is
+// only sent again once it changed, and a 304 Not Modified response from the portal counts as published. It remembers
+// the last published spec, so it is used by pointer.
+type PortalPublisher struct {
+	URL string
+	// Client sends the requests, http.DefaultClient by default.
+	Client *http.Client
+	// Header is added to the requests, e.g. for their authorization.
+	Header http.Header
+
+	mu        sync.Mutex
+	published string
+}
+
+func (p *PortalPublisher) Publish(ctx context.Context, spec []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	hash := SpecHash(spec)
+	if hash == p.published {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(spec))
+	if err != nil {
+		return err
+	}
+	for name, values := range p.Header {
+		req.Header[name] = values
+	}
+	sum := sha256.Sum256(spec)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish the spec to %s: %w", p.URL, err)
+	}
+	defer rsp.Body.Close()
+	_, _ = io.Copy(io.Discard, rsp.Body)
+
+	if rsp.StatusCode >= 300 && rsp.StatusCode != http.StatusNotModified {
+		return fmt.Errorf("failed to publish the spec to %s: status %d", p.URL, rsp.StatusCode)
+	}
+	p.published = hash
+
+	return nil
+}
@@ -1,6 +1,10 @@
 package openapi
 
 import (
+	"net/http"
+	"slices"
+	"time"
+
 	"github.com/tailbits/mason"
 	"github.com/tailbits/mason/model"
 )
@@ -19,6 +23,102 @@ type Record struct {
 	Extensions      map[string]interface{}
 	PathSummary     string
 	PathDescription string
+	FieldSelection  bool
+	// NoContent documents the success response without content, see mason.HandlePostNoContent.
+	NoContent  bool
+	Cache      *mason.CachePolicy
+	Content    *mason.ContentPolicy
+	PathParams map[string]mason.PathParam
+	Timeout    time.Duration
+	// TimeoutStatus is the status of the response sent when the operation exceeds its Timeout.
+	TimeoutStatus int
+	// ResponseDescriptions are the descriptions of the responses by status, see mason.Builder.WithResponseDesc.
+	ResponseDescriptions map[int]string
+	Errors               []ErrorRecord
+	Samples              []mason.Sample
+	// EntityExamples documents the examples of the input and output entities on the operation, see EntityExamples.
+	EntityExamples bool
+	// InputVariantSuffix names the variant of the input component without its readOnly properties, see InputVariants.
+	InputVariantSuffix string
+	// Representations are the alternate representations of the response by content type, nil for raw ones.
+	Representations map[string]*mason.Model
+	Visibility      mason.OperationVisibility
+	// FeatureFlags are the flags a tenant needs to use the operation, documented as x-feature-flags.
+	FeatureFlags []string
+	// Rollout is the flag the operation is dark launched behind, see mason.Builder.WithRollout.
+	Rollout string
+	// Translations are the summary and the description of the operation by language, documented as x-translations
+	// with the Translations option.
+	Translations map[string]mason.Translation
+	// Owner is the team owning the operation, documented as x-owner.
+	Owner string
+	// Deprecated documents the operation as deprecated, see mason.Builder.WithDeprecation.
+	Deprecated bool
+	// SignedURL documents the operation as requiring a signed URL, with the mason.SignedURLScheme security scheme.
+	SignedURL bool
+	// Group is the route group of the operation.
+	Group string
+}
+
+// ErrorRecord is an error of the error catalog returned by the operation.
+type ErrorRecord struct {
+	Code   string
+	Status int
+	Output mason.Model
+	// Retryable documents the Retry-After header of the error response, see mason.ErrorDef.Retryable.
+	Retryable bool
+}
+
+// clone returns a copy of the record that does not share its models with r.
+func (r Record) clone() Record {
+	if r.Input != nil {
+		inp := *r.Input
+		r.Input = &inp
+	}
+	r.Errors = slices.Clone(r.Errors)
+	if r.Representations != nil {
+		reps := make(map[string]*mason.Model, len(r.Representations))
+		for ct, m := range r.Representations {
+			if m != nil {
+				rep := *m
+				m = &rep
+			}
+			reps[ct] = m
+		}
+		r.Representations = reps
+	}
+
+	return r
+}
+
+// file reports whether the operation responds with a mason.File, which answers the Range requests.
+func (r *Record) file() bool {
+	_, ok := r.Output.WithSchema.(*mason.File)
+	return ok
+}
+
+// conditional reports whether the operation answers the conditional GET requests, see model.TimestampedEntity.
+func (r *Record) conditional() bool {
+	_, ok := r.Output.WithSchema.(model.TimestampedEntity)
+	return ok && r.Method == http.MethodGet
+}
+
+// surrogateKeyed reports whether the GET responses of the operation have surrogate keys, from the cache policy or
+// from the output implementing model.SurrogateKeyedEntity.
+func (r *Record) surrogateKeyed() bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	_, ok := r.Output.WithSchema.(model.SurrogateKeyedEntity)
+	return ok || r.Cache != nil && len(r.Cache.SurrogateKeys) > 0
+}
+
+// responseDescription returns the description of the response with the given status.
+func (r *Record) responseDescription(status int) string {
+	if desc, ok := r.ResponseDescriptions[status]; ok {
+		return desc
+	}
+	return mason.DefaultResponseDescription(status)
 }
 
 func (r *Record) AddInputModel(m model.WithSchema) {
@@ -33,6 +133,22 @@ func (r *Record) AddOutputModel(m model.WithSchema) {
 	r.Output = out
 }
 
+func (r *Record) AddRepresentations(reps map[string]model.Entity) {
+	if len(reps) == 0 {
+		return
+	}
+
+	r.Representations = make(map[string]*mason.Model, len(reps))
+	for ct, ent := range reps {
+		if ent == nil {
+			r.Representations[ct] = nil
+			continue
+		}
+		m := mason.NewModel(ent)
+		r.Representations[ct] = &m
+	}
+}
+
 func (r *Record) AddQueryParams(q any) {
 	r.QueryParams = q
 }
@@ -1,15 +1,11 @@
 package openapi
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
+	"runtime"
 	"strings"
+	"sync"
 
-	"github.com/daveshanley/vacuum/model"
-	"github.com/daveshanley/vacuum/motor"
-	"github.com/daveshanley/vacuum/rulesets"
-	"github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/swaggest/jsonschema-go"
 	"github.com/swaggest/openapi-go/openapi31"
 	"github.com/tailbits/mason"
@@ -21,10 +17,28 @@ type Reflector struct {
 	*openapi31.Reflector
 	defs definitionsMap
 	tags map[string]bool
+	// schemas are the JSON schemas of the models of the records being ingested, resolved up front.
+	schemas map[*mason.Model]jsonschema.Schema
+	// refPrefix is the prefix of the refs to the components, see RefPrefix.
+	refPrefix string
+	// rename names the components that are not collected from the records, see RenameComponents.
+	rename mason.NamingStrategy
+	// dialect is the JSON Schema dialect of the spec, which schemaDecl may declare on the components.
+	dialect    string
+	schemaDecl SchemaDeclaration
+	// locale and translations document the localized descriptions of the components, see Locale and Translations.
+	locale       string
+	translations bool
 }
 
+// ingest adds the operations of the records to the spec, in order. The JSON schemas of their models, which dominate
+// the generation time, are resolved concurrently beforehand.
 func (r *Reflector) ingest(records []Record) error {
-	for _, record := range records {
+	r.schemas = resolveSchemas(records)
+	defer func() { r.schemas = nil }()
+
+	for i := range records {
+		record := &records[i]
 		ctx, err := r.newOperationContext(record.Method, record.Path)
 		if err != nil {
 			return fmt.Errorf("failed to create operation context: %w", err)
@@ -42,57 +56,6 @@ func (r *Reflector) ingest(records []Record) error {
 	return nil
 }
 
-func (r *Reflector) validate() error {
-	specBytes, err := r.marshalJSON()
-	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
-	}
-
-	// build and store built-in vacuum default RuleSets.
-	defaultRS := rulesets.BuildDefaultRuleSets()
-
-	// generate the 'recommended' RuleSet
-	recommendedRS := defaultRS.GenerateOpenAPIRecommendedRuleSet()
-
-	// apply the rules in the ruleset to the specification
-	lintingResults := motor.ApplyRulesToRuleSet(
-		&motor.RuleSetExecution{
-			RuleSet: recommendedRS,
-			Spec:    specBytes,
-		})
-
-	// create a new model.RuleResultSet from the results.
-	// structure allows categorization, sorting and searching
-	// in a simple and consistent way.
-	resultSet := model.NewRuleResultSet(lintingResults.Results)
-
-	// sort results by line number (so they are not all jumbled)
-	resultSet.SortResultsByLineNumber()
-
-	// .. do something interesting with the results
-	// print only the results from the 'schemas' category
-	schemasResults := resultSet.GetRuleResultsForCategory("schemas")
-
-	errors := make([]error, 0)
-
-	// for every rule that is violated, it contains a list of violations.
-	// so first iterate through the schemas sesults
-	for _, ruleResult := range schemasResults.RuleResults {
-
-		// iterate over each violation of this rule
-		for _, violation := range ruleResult.Results {
-
-			errors = append(errors, fmt.Errorf(" - [%d:%d] %s", violation.StartNode.Line, violation.StartNode.Column, violation.Message))
-		}
-	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
-	}
-
-	return nil
-}
-
 func (r *Reflector) marshalJSON() ([]byte, error) {
 	return r.Reflector.Spec.MarshalJSON()
 }
@@ -118,6 +81,7 @@ func (r *Reflector) collectDefinitions() error {
 		if err != nil {
 			continue
 		}
+		r.declareSchema(sm)
 		r.Reflector.Spec.Components.WithSchemasItem(defName, sm)
 	}
 
@@ -132,20 +96,31 @@ func (r *Reflector) collectTags(tags []string) {
 	}
 }
 
-func (r *Reflector) addModel(model mason.Model) error {
+func (r *Reflector) addModel(model *mason.Model) error {
 	if model.IsNil() {
 		return nil
 	}
 
-	schema, err := model.JSONSchema()
-	if err != nil {
-		return fmt.Errorf("failed to get JSON schema: %w", err)
+	schema, ok := r.schemas[model]
+	if !ok {
+		var err error
+		if schema, err = model.JSONSchema(); err != nil {
+			return fmt.Errorf("failed to get JSON schema: %w", err)
+		}
 	}
 
-	if err := r.addDefinition(model.Name(), schema); err != nil {
+	if err := r.addDefinition(model.ComponentName(), r.localize(model, schema)); err != nil {
 		return fmt.Errorf("failed to add definition: %w", err)
 	}
 
+	// the component of the wrapper refers to the one of the entity it wraps
+	if inner, ok := mason.UnwrapComponent(model.WithSchema); ok {
+		m := mason.NewModel(inner).WithComponentNaming(model.ComponentNaming()).WithRefPrefix(model.RefPrefix())
+		if err := r.addModel(&m); err != nil {
+			return fmt.Errorf("wrapped entity %s: %w", inner.Name(), err)
+		}
+	}
+
 	return nil
 }
 
@@ -157,8 +132,8 @@ func (r *Reflector) addDefinition(name string, schema jsonschema.Schema) error {
 	}
 
 	if existingDef, ok := r.defs[name]; ok {
-		if !isSchemaIdentical(existingDef, schema) {
-			return fmt.Errorf("definition with name [%s] already exists but with a different definition", name)
+		if diffs := DiffSchemas(existingDef, schema); len(diffs) > 0 {
+			return &DefinitionConflictError{Name: name, Differences: diffs}
 		}
 		if len(existingDef.Examples) > 0 && len(schema.Examples) == 0 {
 			return nil
@@ -186,40 +161,53 @@ func (r *Reflector) newOperationContext(method, path string) (*ContextWrapper, e
 	return NewContextWrapper(oc, r), nil
 }
 
-/* -------------------------------------------------------------------------- */
-
-func printDiff(existingDef jsonschema.Schema, newDef jsonschema.Schema) {
-	dmp := diffmatchpatch.New()
-
-	existing, _ := existingDef.MarshalJSON()
-	new, _ := newDef.MarshalJSON()
-
-	diffs := dmp.DiffMain(string(pretty(existing)), string(pretty(new)), false)
-	diffs = dmp.DiffCleanupSemantic(diffs)
-
-	str := dmp.DiffPrettyText(diffs)
-
-	fmt.Println(str) // nolint:forbidigo
-}
-
-func pretty(schema []byte) []byte {
-	var prettyJSON bytes.Buffer
-	if err := json.Indent(&prettyJSON, schema, "", "  "); err != nil {
-		return schema // Return original if indentation fails
+// resolveSchemas resolves the JSON schemas of the models of the records with a bounded number of workers. The models
+// that fail are left out, so their error is reported in order when they are added.
+func resolveSchemas(records []Record) map[*mason.Model]jsonschema.Schema {
+	var models []*mason.Model
+	for i := range records {
+		record := &records[i]
+		if record.Input != nil && !record.Input.IsNil() {
+			models = append(models, record.Input)
+		}
+		if !record.Output.IsNil() {
+			models = append(models, &record.Output)
+		}
+		for j := range record.Errors {
+			models = append(models, &record.Errors[j].Output)
+		}
+		for _, m := range record.Representations {
+			if m != nil {
+				models = append(models, m)
+			}
+		}
 	}
-	return prettyJSON.Bytes()
-}
-
-func isSchemaIdentical(a jsonschema.Schema, b jsonschema.Schema) bool {
-	a.Examples = nil
-	b.Examples = nil
 
-	aa, _ := a.MarshalJSON()
-	bb, _ := b.MarshalJSON()
+	schemas := make([]jsonschema.Schema, len(models))
+	errs := make([]error, len(models))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(models)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				schemas[i], errs[i] = models[i].JSONSchema()
+			}
+		}()
+	}
+	for i := range models {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-	res := string(aa) == string(bb)
-	if !res {
-		printDiff(a, b)
+	resolved := make(map[*mason.Model]jsonschema.Schema, len(models))
+	for i, m := range models {
+		if errs[i] == nil {
+			resolved[m] = schemas[i]
+		}
 	}
-	return res
+
+	return resolved
 }
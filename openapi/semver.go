@@ -0,0 +1,142 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bump is a semantic version increment.
+type Bump string
+
+const (
+	BumpMajor Bump = "major"
+	BumpMinor Bump = "minor"
+	BumpPatch Bump = "patch"
+)
+
+// VersionAdvice is the version increment recommended for a release of the API, see AdviseVersion.
+type VersionAdvice struct {
+	Bump Bump `json:"bump"`
+	// Previous is the version of the previous spec, and Next the version it is bumped to, when it is a semver.
+	Previous string `json:"previous,omitempty"`
+	Next     string `json:"next,omitempty"`
+	// Justification are the changes that call for the bump, e.g. the breaking ones for a major bump.
+	Justification []Change `json:"justification"`
+}
+
+// AdviseVersion compares the previous spec with the spec of the API, and recommends the next version: a major bump
+// for breaking changes, a minor bump for additions, e.g. of operations or optional fields, and a patch bump
+// otherwise. The previous version is read from the info of the previous spec.
+func (g *Generator) AdviseVersion(previousSpec []byte) (VersionAdvice, error) {
+	spec, err := g.Schema()
+	if err != nil {
+		return VersionAdvice{}, err
+	}
+	log, err := NewChangelog(previousSpec, spec)
+	if err != nil {
+		return VersionAdvice{}, err
+	}
+
+	var previous struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(previousSpec, &previous); err != nil {
+		return VersionAdvice{}, fmt.Errorf("failed to parse the previous spec: %w", err)
+	}
+
+	return AdviseVersion(log, previous.Info.Version), nil
+}
+
+// AdviseVersion recommends the bump of the previous version for the changes of a changelog, see
+// Generator.AdviseVersion. Before 1.0.0, breaking changes only bump the minor version, as the API is not considered
+// stable yet. Next is left empty when the previous version is not a semver, e.g. 2024-10 or empty.
+func AdviseVersion(log Changelog, previous string) VersionAdvice {
+	advice := VersionAdvice{Bump: BumpPatch, Previous: previous}
+	var breaking, additions []Change
+	for _, ch := range log.Changes {
+		switch {
+		case ch.Breaking:
+			breaking = append(breaking, ch)
+		case ch.Kind == ChangeAdded:
+			additions = append(additions, ch)
+		}
+	}
+
+	switch {
+	case len(breaking) > 0:
+		advice.Bump, advice.Justification = BumpMajor, breaking
+	case len(additions) > 0:
+		advice.Bump, advice.Justification = BumpMinor, additions
+	default:
+		advice.Justification = log.Changes
+	}
+	if advice.Justification == nil {
+		advice.Justification = []Change{}
+	}
+
+	major, minor, patch, pre, ok := parseSemver(previous)
+	if !ok {
+		return advice
+	}
+	bump := advice.Bump
+	if bump == BumpMajor && major == 0 {
+		bump = BumpMinor
+	}
+	// a pre-release is bumped to its release when that is enough, e.g. 2.0.0-rc.1 to 2.0.0
+	switch {
+	case bump == BumpMajor && !(pre && minor == 0 && patch == 0):
+		major, minor, patch = major+1, 0, 0
+	case bump == BumpMinor && !(pre && patch == 0):
+		minor, patch = minor+1, 0
+	case bump == BumpPatch && !pre:
+		patch++
+	case bump != BumpPatch:
+		// the pre-release already bumps enough
+	}
+	prefix := ""
+	if strings.HasPrefix(previous, "v") {
+		prefix = "v"
+	}
+	advice.Next = fmt.Sprintf("%s%d.%d.%d", prefix, major, minor, patch)
+
+	return advice
+}
+
+// Reasons returns the descriptions of the justification, prefixed with their operation.
+func (a VersionAdvice) Reasons() []string {
+	reasons := make([]string, 0, len(a.Justification))
+	for _, ch := range a.Justification {
+		reasons = append(reasons, fmt.Sprintf("%s %s: %s", ch.Method, ch.Path, ch.Description))
+	}
+
+	return reasons
+}
+
+// parseSemver parses a version like 1.2.3 or v1.2.3, ignoring its build metadata, and reports whether it is a
+// pre-release, e.g. 1.2.3-rc.1.
+func parseSemver(version string) (major, minor, patch int, pre bool, ok bool) {
+	core, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "+")
+	core, preRelease, pre := strings.Cut(core, "-")
+	if pre && preRelease == "" {
+		return 0, 0, 0, false, false
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return 0, 0, 0, false, false
+	}
+
+	nums := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, 0, 0, false, false
+		}
+		nums[i] = n
+	}
+
+	return nums[0], nums[1], nums[2], pre, true
+}
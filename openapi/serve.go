@@ -0,0 +1,212 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDocsScriptURL is the script of the Scalar API reference rendering the docs UI, pinned to a version of its CDN
+// build so the page does not change with the releases of Scalar.
+const DefaultDocsScriptURL = "https://cdn.jsdelivr.net/npm/@scalar/api-reference@1.25.0"
+
+// docsPage renders the spec with the Scalar API reference, loaded from its script URL.
+var docsPage = template.Must(template.New("docs").Parse(`<!doctype html>
+<html>
+<head>
+<title>{{.Title}}</title>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+<script id="api-reference" data-url="{{.SpecURL}}"></script>
+<script src="{{.ScriptURL}}"{{with .Integrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}></script>
+</body>
+</html>
+`))
+
+type specServerOptions struct {
+	specURL         string
+	scriptURL       string
+	scriptIntegrity string
+	logger          *slog.Logger
+}
+
+type SpecServerOption func(*specServerOptions)
+
+// DocsSpecURL sets the URL the docs UI loads the spec from, openapi.json by default, relative to the docs page.
+func DocsSpecURL(url string) SpecServerOption {
+	return func(o *specServerOptions) {
+		o.specURL = url
+	}
+}
+
+// DocsScript sets the URL of the script of the Scalar API reference, DefaultDocsScriptURL by default, e.g. to serve it
+// from the service itself or to pin another version, with the subresource integrity hash of the script, like
+// sha384-..., checked by the browsers when it is not empty.
+func DocsScript(url string, integrity string) SpecServerOption {
+	return func(o *specServerOptions) {
+		o.scriptURL = url
+		o.scriptIntegrity = integrity
+	}
+}
+
+// SpecServerLogger sets the logger of the errors of the reloads, slog.Default() by default. The errors are logged
+// instead of being sent to the clients.
+func SpecServerLogger(logger *slog.Logger) SpecServerOption {
+	return func(o *specServerOptions) {
+		o.logger = logger
+	}
+}
+
+// SpecServer serves the spec of a generator and a docs UI rendering it. They are generated once, and then served from
+// memory until Reload regenerates them, e.g. for a service registering routes after startup. A reload swaps them
+// atomically, so the requests in flight are served the previous version in full.
+type SpecServer struct {
+	gen     *Generator
+	options specServerOptions
+	// reloading serializes the reloads, the requests read the served version without locking
+	reloading sync.Mutex
+	served    atomic.Pointer[servedSpec]
+}
+
+type servedSpec struct {
+	spec        []byte
+	docs        []byte
+	hash        string
+	generatedAt time.Time
+}
+
+// NewSpecServer generates the spec and the docs UI of the generator, and returns the server serving them.
+func NewSpecServer(gen *Generator, opts ...SpecServerOption) (*SpecServer, error) {
+	options := specServerOptions{specURL: "openapi.json", scriptURL: DefaultDocsScriptURL, logger: slog.Default()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	s := &SpecServer{gen: gen, options: options}
+	served, err := s.generate()
+	if err != nil {
+		return nil, err
+	}
+	s.served.Store(served)
+
+	return s, nil
+}
+
+// Reload collects the routes of the API again, see Generator.Invalidate, and swaps the served spec and docs UI for the
+// regenerated ones. They are left as is when the generation fails.
+func (s *SpecServer) Reload() error {
+	s.reloading.Lock()
+	defer s.reloading.Unlock()
+
+	if err := s.gen.Invalidate(); err != nil {
+		return fmt.Errorf("failed to collect the routes: %w", err)
+	}
+	served, err := s.generate()
+	if err != nil {
+		return err
+	}
+	s.served.Store(served)
+
+	return nil
+}
+
+func (s *SpecServer) generate() (*servedSpec, error) {
+	spec, err := s.gen.Schema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the spec: %w", err)
+	}
+
+	var header struct {
+		Info struct {
+			Title string `json:"title"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(spec, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse the spec: %w", err)
+	}
+
+	var docs bytes.Buffer
+	data := struct{ Title, SpecURL, ScriptURL, Integrity string }{
+		Title:     header.Info.Title,
+		SpecURL:   s.options.specURL,
+		ScriptURL: s.options.scriptURL,
+		Integrity: s.options.scriptIntegrity,
+	}
+	if err := docsPage.Execute(&docs, data); err != nil {
+		return nil, fmt.Errorf("failed to render the docs: %w", err)
+	}
+
+	return &servedSpec{spec: spec, docs: docs.Bytes(), hash: SpecHash(spec), generatedAt: time.Now()}, nil
+}
+
+// Spec returns the handler serving the spec, with its content hash as ETag.
+func (s *SpecServer) Spec() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served := s.served.Load()
+		s.serve(w, r, served, "application/json", served.spec)
+	})
+}
+
+// Docs returns the handler serving the docs UI, which loads the spec from the DocsSpecURL.
+func (s *SpecServer) Docs() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served := s.served.Load()
+		s.serve(w, r, served, "text/html; charset=utf-8", served.docs)
+	})
+}
+
+func (s *SpecServer) serve(w http.ResponseWriter, r *http.Request, served *servedSpec, contentType string, body []byte) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+served.hash+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, "", served.generatedAt, bytes.NewReader(body))
+}
+
+// ReloadStatus is the response of the reload handler.
+type ReloadStatus struct {
+	// Hash is the content hash of the served spec, see SpecHash.
+	Hash        string    `json:"hash"`
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+// ReloadHandler returns the admin hook reloading the spec on POST, for the requests authorize accepts, e.g. the ones
+// with the token of the operators. The other requests are refused with a 403, all of them when authorize is nil. It
+// responds with the ReloadStatus of the served spec, and with a 500 when the generation fails, whose error is logged
+// with the SpecServerLogger.
+func (s *SpecServer) ReloadHandler(authorize func(r *http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authorize == nil || !authorize(r) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := s.Reload(); err != nil {
+			s.options.logger.ErrorContext(r.Context(), "failed to reload the spec", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		served := s.served.Load()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ReloadStatus{Hash: served.hash, GeneratedAt: served.generatedAt})
+	})
+}
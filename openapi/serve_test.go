@@ -0,0 +1,142 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/swaggest/openapi-go/openapi31"
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"github.com/tailbits/mason/openapi"
+	"gotest.tools/v3/assert"
+)
+
+func TestSpecServer(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(mason.HandlePost(CreateResourceA).Path("/foos").WithOpID("create_foo").WithDesc("Create a foo"))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	srv, err := openapi.NewSpecServer(gen, openapi.DocsSpecURL("/docs/openapi.json"))
+	assert.NilError(t, err)
+
+	get := func(h http.Handler, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	paths := func(rec *httptest.ResponseRecorder) int {
+		var spec openapi31.Spec
+		assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+		return len(spec.Paths.MapOfPathItemValues)
+	}
+
+	rec := get(srv.Spec(), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, 1, paths(rec))
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, http.StatusNotModified, get(srv.Spec(), http.Header{"If-None-Match": {etag}}).Code)
+
+	docs := get(srv.Docs(), nil)
+	assert.Equal(t, http.StatusOK, docs.Code)
+	assert.Assert(t, strings.Contains(docs.Body.String(), `data-url="/docs/openapi.json"`))
+
+	// the routes registered after startup are served after a reload only
+	grp.Register(mason.HandlePut(CreateResourceA).Path("/foos/import").WithOpID("import_foos").WithDesc("Import foos"))
+	assert.Equal(t, 1, paths(get(srv.Spec(), nil)))
+
+	reload := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+		req.Header.Set("Authorization", token)
+		rec := httptest.NewRecorder()
+		srv.ReloadHandler(func(r *http.Request) bool {
+			return r.Header.Get("Authorization") == "Bearer ops"
+		}).ServeHTTP(rec, req)
+		return rec
+	}
+	assert.Equal(t, http.StatusForbidden, reload("Bearer guest").Code)
+	assert.Equal(t, 1, paths(get(srv.Spec(), nil)))
+
+	rec = reload("Bearer ops")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var status openapi.ReloadStatus
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+
+	rec = get(srv.Spec(), nil)
+	assert.Equal(t, 2, paths(rec))
+	assert.Equal(t, `"`+status.Hash+`"`, rec.Header().Get("ETag"))
+	assert.Assert(t, rec.Header().Get("ETag") != etag)
+
+	// the reload handler is closed without an authorization
+	req := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+	closed := httptest.NewRecorder()
+	srv.ReloadHandler(nil).ServeHTTP(closed, req)
+	assert.Equal(t, http.StatusForbidden, closed.Code)
+}
+
+func TestSpecServer_DocsScript(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("Foos").Register(mason.HandlePost(CreateResourceA).Path("/foos").WithOpID("create_foo").WithDesc("Create a foo"))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+
+	docs := func(opts ...openapi.SpecServerOption) string {
+		srv, err := openapi.NewSpecServer(gen, opts...)
+		assert.NilError(t, err)
+		rec := httptest.NewRecorder()
+		srv.Docs().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Body.String()
+	}
+
+	assert.Assert(t, strings.Contains(docs(), `<script src="`+openapi.DefaultDocsScriptURL+`"></script>`))
+	assert.Assert(t, strings.Contains(docs(openapi.DocsScript("/static/scalar.js", "sha384-abc")),
+		`<script src="/static/scalar.js" integrity="sha384-abc" crossorigin="anonymous"></script>`))
+}
+
+// brokenEntity has a schema that cannot be parsed, so the spec cannot be generated once it is registered.
+type brokenEntity struct {
+	TestResourceA
+}
+
+func (b *brokenEntity) Name() string {
+	return "Broken"
+}
+
+func (b *brokenEntity) Schema() []byte {
+	return []byte(`{"type": `)
+}
+
+func TestSpecServer_ReloadError(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Foos")
+	grp.Register(mason.HandlePost(CreateResourceA).Path("/foos").WithOpID("create_foo").WithDesc("Create a foo"))
+
+	gen, err := openapi.NewGenerator(api)
+	assert.NilError(t, err)
+	var logs strings.Builder
+	srv, err := openapi.NewSpecServer(gen, openapi.SpecServerLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	assert.NilError(t, err)
+
+	getBroken := func(ctx context.Context, r *http.Request, params model.Nil) (*brokenEntity, error) {
+		return &brokenEntity{}, nil
+	}
+	grp.Register(mason.HandleGet(getBroken).Path("/broken").WithOpID("get_broken").WithDesc("Get a broken entity"))
+
+	rec := httptest.NewRecorder()
+	srv.ReloadHandler(func(r *http.Request) bool { return true }).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "Internal Server Error\n", rec.Body.String())
+	assert.Assert(t, strings.Contains(logs.String(), "failed to reload the spec"))
+}
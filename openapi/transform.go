@@ -1,11 +1,13 @@
 package openapi
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
 
 	"github.com/swaggest/jsonschema-go"
 	"github.com/swaggest/openapi-go/openapi31"
+	"github.com/tailbits/mason"
 )
 
 const version string = "2.0.0"
@@ -15,10 +17,18 @@ var url = "https://example.com"
 var email = "hello@example.com"
 var serverURL = "https://api.example.com"
 
+// Schema generates the spec. It is cached until the records or the config change, see Invalidate.
 func (g *Generator) Schema() ([]byte, error) {
+	return g.cachedSchema(g.generate)
+}
+
+func (g *Generator) generate() ([]byte, error) {
 	if err := g.ingest(g.records); err != nil {
 		return nil, fmt.Errorf("failed to ingest records: %w", err)
 	}
+	if err := g.addWebhooks(g.webhooks); err != nil {
+		return nil, fmt.Errorf("failed to add webhooks: %w", err)
+	}
 
 	collectedTags := []string{}
 	for tag := range g.tags {
@@ -35,19 +45,54 @@ func (g *Generator) Schema() ([]byte, error) {
 	if err := g.collectDefinitions(); err != nil {
 		return nil, fmt.Errorf("failed to collect definitions: %w", err)
 	}
+	g.unused = g.unusedModels()
+	if g.config.strictModels && len(g.unused) > 0 {
+		return nil, &UnusedModelsError{Models: g.unused}
+	}
 
-	if g.config.validate {
-		return g.marshalJSON()
+	spec, err := g.marshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if spec, err = applySpecPlugins(spec, g.api.Plugins()); err != nil {
+		return nil, err
+	}
+	if g.config.sortFn != nil {
+		if spec, err = orderPaths(spec, g.records, g.config.sortFn); err != nil {
+			return nil, fmt.Errorf("failed to order paths: %w", err)
+		}
+	}
+	if g.config.refPrefix != mason.DefaultRefPrefix {
+		// the reflector refers to the components of the operations with the default prefix
+		spec = bytes.ReplaceAll(spec, []byte(`"$ref":"`+mason.DefaultRefPrefix), []byte(`"$ref":"`+g.config.refPrefix))
+	}
+	if g.config.validate || g.config.validator == nil && !g.config.markdown {
+		return spec, nil
 	}
 
-	if err := g.validate(); err != nil {
-		return nil, fmt.Errorf("failed to validate the generated spec: %w", err)
+	var findings []Finding
+	if g.config.markdown {
+		if findings, err = lintMarkdown(spec); err != nil {
+			return nil, fmt.Errorf("failed to lint the descriptions: %w", err)
+		}
+	}
+	if g.config.validator != nil {
+		validated, err := g.config.validator.Validate(spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate the generated spec: %w", err)
+		}
+		findings = append(findings, validated...)
+	}
+	fatal, warnings := g.config.classify(findings)
+	g.warnings = warnings
+	if len(fatal) > 0 {
+		return nil, fmt.Errorf("failed to validate the generated spec: %w", &SpecValidationError{Findings: fatal})
 	}
 
-	return g.marshalJSON()
+	return spec, nil
 }
 
-func newReflector() *Reflector {
+func newReflector(c config) *Reflector {
 	reflector := openapi31.NewReflector()
 	reflector.Spec = &openapi31.Spec{Openapi: "3.1.0"}
 	reflector.Spec.Info.
@@ -59,11 +104,18 @@ func newReflector() *Reflector {
 		URL: serverURL,
 	})
 
-	reflector.Reflector.DefaultOptions = append(reflector.Reflector.DefaultOptions, jsonschema.DefinitionsPrefix("#/components/schemas/"))
+	reflector.Reflector.DefaultOptions = append(reflector.Reflector.DefaultOptions, jsonschema.DefinitionsPrefix(c.refPrefix))
 
 	return &Reflector{
-		Reflector: reflector,
-		defs:      make(definitionsMap),
-		tags:      make(map[string]bool),
+		Reflector:  reflector,
+		defs:       make(definitionsMap),
+		tags:       make(map[string]bool),
+		refPrefix:  c.refPrefix,
+		rename:     c.rename,
+		dialect:    c.dialectOf(),
+		schemaDecl: c.schemaDecl,
+
+		locale:       c.locale,
+		translations: c.translations,
 	}
 }
@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tailbits/mason"
+)
+
+// FailOnUnusedModels fails the generation when entities registered with the API are not documented by the spec, see
+// Generator.UnusedModels.
+func FailOnUnusedModels() openAPIOption {
+	return func(c *config) {
+		c.strictModels = true
+	}
+}
+
+// UnusedModels returns the names of the entities registered with the API, or the APIs of the combined generators, that
+// the last generated spec does not document, sorted. Besides dead models, these are the entities of the operations
+// left out of the spec, e.g. by Filter or Visibility, and the errors no route declares. See mason.API.UnusedModels to
+// check the API regardless of the generator.
+func (g *Generator) UnusedModels() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return slices.Clone(g.unused)
+}
+
+// unusedModels returns the entities registered with the APIs of the generator that have no component in the spec.
+func (g *Generator) unusedModels() []string {
+	apis := []*mason.API{g.api}
+	if len(g.sources) > 0 {
+		apis = nil
+		for _, source := range g.sources {
+			if !slices.Contains(apis, source.api) {
+				apis = append(apis, source.api)
+			}
+		}
+	}
+
+	var unused []string
+	for _, api := range apis {
+		for _, name := range api.ModelNames() {
+			if _, ok := g.defs[g.config.rename(name)]; !ok && !slices.Contains(unused, name) {
+				unused = append(unused, name)
+			}
+		}
+	}
+	slices.Sort(unused)
+
+	return unused
+}
+
+// UnusedModelsError is returned by the generation when entities are not documented, see FailOnUnusedModels.
+type UnusedModelsError struct {
+	Models []string
+}
+
+func (e *UnusedModelsError) Error() string {
+	return fmt.Sprintf("unused models: %s", strings.Join(e.Models, ", "))
+}
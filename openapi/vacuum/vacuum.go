@@ -0,0 +1,74 @@
+// Package vacuum validates the generated OpenAPI specs with the vacuum linter. It is kept apart from the openapi
+// package, so the linter is only linked into the binaries that validate their specs.
+package vacuum
+
+import (
+	"github.com/daveshanley/vacuum/model"
+	"github.com/daveshanley/vacuum/motor"
+	"github.com/daveshanley/vacuum/rulesets"
+	"github.com/tailbits/mason/openapi"
+)
+
+// Validator lints the specs with the recommended ruleset of vacuum.
+type Validator struct{}
+
+var _ openapi.Validator = Validator{}
+
+// Validate lints the spec with the recommended ruleset, and returns the findings of the schemas category.
+func (Validator) Validate(specBytes []byte) ([]openapi.Finding, error) {
+	// build and store built-in vacuum default RuleSets.
+	defaultRS := rulesets.BuildDefaultRuleSets()
+
+	// generate the 'recommended' RuleSet
+	recommendedRS := defaultRS.GenerateOpenAPIRecommendedRuleSet()
+
+	// apply the rules in the ruleset to the specification
+	lintingResults := motor.ApplyRulesToRuleSet(
+		&motor.RuleSetExecution{
+			RuleSet: recommendedRS,
+			Spec:    specBytes,
+		})
+
+	// create a new model.RuleResultSet from the results.
+	// structure allows categorization, sorting and searching
+	// in a simple and consistent way.
+	resultSet := model.NewRuleResultSet(lintingResults.Results)
+
+	// sort results by line number (so they are not all jumbled)
+	resultSet.SortResultsByLineNumber()
+
+	// only the results from the 'schemas' category are checked
+	schemasResults := resultSet.GetRuleResultsForCategory("schemas")
+
+	var findings []openapi.Finding
+	for _, ruleResult := range schemasResults.RuleResults {
+		for _, violation := range ruleResult.Results {
+			findings = append(findings, newFinding(violation))
+		}
+	}
+
+	return findings, nil
+}
+
+func newFinding(violation *model.RuleFunctionResult) openapi.Finding {
+	f := openapi.Finding{
+		Rule:     violation.RuleId,
+		Severity: violation.RuleSeverity,
+		Path:     violation.Path,
+		Message:  violation.Message,
+	}
+	if violation.Rule != nil {
+		if f.Rule == "" {
+			f.Rule = violation.Rule.Id
+		}
+		if f.Severity == "" {
+			f.Severity = violation.Rule.Severity
+		}
+	}
+	if violation.StartNode != nil {
+		f.Line = violation.StartNode.Line
+		f.Column = violation.StartNode.Column
+	}
+
+	return f
+}
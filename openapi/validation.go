@@ -0,0 +1,90 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The severities of the findings, see RuleSeverity.
+const (
+	SeverityError = "error"
+	SeverityWarn  = "warn"
+	SeverityInfo  = "info"
+	SeverityHint  = "hint"
+	SeverityOff   = "off"
+)
+
+// Finding is a violation of a linting rule by the generated spec.
+type Finding struct {
+	// Rule is the ID of the violated rule, e.g. oas3-valid-schema-example.
+	Rule string
+	// Severity is the severity of the rule, e.g. error or warn.
+	Severity string
+	// Path is the JSON path of the violation in the spec.
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (f Finding) Error() string {
+	return fmt.Sprintf("[%d:%d] %s: %s", f.Line, f.Column, f.Rule, f.Message)
+}
+
+// Validator lints the generated spec, e.g. the vacuum.Validator. The severity of the findings decides whether they
+// fail the generation, see RuleSeverity and NonFatalWarnings.
+type Validator interface {
+	Validate(spec []byte) ([]Finding, error)
+}
+
+// SpecValidationError is returned by Schema when the generated spec violates the linting rules of the schemas
+// category. Each finding can be matched with errors.As.
+type SpecValidationError struct {
+	Findings []Finding
+}
+
+func (e *SpecValidationError) Error() string {
+	msgs := make([]string, len(e.Findings))
+	for i, f := range e.Findings {
+		msgs[i] = f.Error()
+	}
+
+	return fmt.Sprintf("validation failed with %d findings: %s", len(e.Findings), strings.Join(msgs, "; "))
+}
+
+func (e *SpecValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Findings))
+	for i, f := range e.Findings {
+		errs[i] = f
+	}
+
+	return errs
+}
+
+// classify applies the severity overrides to the findings, and splits them into the fatal ones and the warnings.
+func (c config) classify(findings []Finding) (fatal []Finding, warnings []Finding) {
+	for _, f := range findings {
+		if severity, ok := c.severities[f.Rule]; ok {
+			f.Severity = severity
+		}
+
+		switch {
+		case f.Severity == SeverityOff:
+		case c.nonFatal && f.Severity != SeverityError:
+			warnings = append(warnings, f)
+		default:
+			fatal = append(fatal, f)
+		}
+	}
+
+	return fatal, warnings
+}
+
+// Warnings returns the non-fatal findings of the last generated spec, see NonFatalWarnings.
+func (g *Generator) Warnings() []Finding {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return slices.Clone(g.warnings)
+}
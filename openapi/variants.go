@@ -0,0 +1,226 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+	"github.com/swaggest/openapi-go"
+	"github.com/swaggest/openapi-go/openapi31"
+	"github.com/tailbits/mason"
+)
+
+// InputVariants documents the request bodies whose schema has readOnly properties, e.g. ids and timestamps set by the
+// server, with a variant of their component that leaves them out. The variant is named after the component with the
+// suffix, e.g. "ItemInput" for "Item" with the suffix "Input". The nested components with readOnly properties get a
+// variant too, and the response bodies keep the original components.
+func InputVariants(suffix string) openAPIOption {
+	return func(c *config) {
+		c.inputSuffix = suffix
+	}
+}
+
+// addInputVariant adds the variant of the component of the model without its readOnly properties, along with the
+// variants of the nested components it refers to, and returns its name. It returns an empty name if the model has no
+// readOnly properties.
+func (r *Reflector) addInputVariant(m *mason.Model, suffix string) (string, error) {
+	schema, ok := r.schemas[m]
+	if !ok {
+		var err error
+		if schema, err = m.JSONSchema(); err != nil {
+			return "", fmt.Errorf("failed to get JSON schema: %w", err)
+		}
+	}
+
+	root := m.ComponentName()
+	schemas := map[string]map[string]any{}
+	if err := addSchemaTree(schemas, root, schema); err != nil {
+		return "", err
+	}
+	for name, def := range schema.Definitions {
+		if def.TypeObject == nil {
+			continue
+		}
+		if err := addSchemaTree(schemas, name, *def.TypeObject); err != nil {
+			return "", err
+		}
+	}
+
+	// the components with readOnly properties need a variant, and so do the ones referring to them
+	variants := map[string]bool{}
+	for name, s := range schemas {
+		if stripReadOnly(s) {
+			variants[name] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for name, s := range schemas {
+			if variants[name] {
+				continue
+			}
+			walkSchemaRefs(s, func(ref string) string {
+				if variants[strings.TrimPrefix(ref, m.RefPrefix())] {
+					variants[name] = true
+					changed = true
+				}
+				return ref
+			})
+		}
+	}
+	if !variants[root] {
+		return "", nil
+	}
+
+	for name := range variants {
+		s := schemas[name]
+		walkSchemaRefs(s, func(ref string) string {
+			if target := strings.TrimPrefix(ref, m.RefPrefix()); variants[target] {
+				return m.RefPrefix() + target + suffix
+			}
+			return ref
+		})
+
+		var variant jsonschema.Schema
+		if err := remarshal(s, &variant); err != nil {
+			return "", fmt.Errorf("failed to derive the input variant of %s: %w", name, err)
+		}
+		if err := r.addDefinition(name+suffix, variant); err != nil {
+			return "", fmt.Errorf("failed to add definition: %w", err)
+		}
+	}
+
+	return root + suffix, nil
+}
+
+// addSchemaTree adds a copy of the schema without its definitions, as a generic tree that is safe to modify.
+func addSchemaTree(schemas map[string]map[string]any, name string, schema jsonschema.Schema) error {
+	schema.Definitions = nil
+
+	var tree map[string]any
+	if err := remarshal(schema, &tree); err != nil {
+		return fmt.Errorf("failed to copy the schema of %s: %w", name, err)
+	}
+	schemas[name] = tree
+
+	return nil
+}
+
+func remarshal(from any, to any) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, to)
+}
+
+// stripReadOnly removes the readOnly properties of the schema and its subschemas, and reports whether any was found.
+func stripReadOnly(schema map[string]any) bool {
+	stripped := false
+	if props, ok := schema["properties"].(map[string]any); ok {
+		for name, prop := range props {
+			if p, ok := prop.(map[string]any); ok && p["readOnly"] == true {
+				delete(props, name)
+				stripped = true
+				// the examples would not validate against the variant with additionalProperties false
+				if examples, ok := schema["examples"].([]any); ok {
+					for _, ex := range examples {
+						if e, ok := ex.(map[string]any); ok {
+							delete(e, name)
+						}
+					}
+				}
+			}
+		}
+		if required, ok := schema["required"].([]any); ok {
+			schema["required"] = slices.DeleteFunc(required, func(name any) bool {
+				s, _ := name.(string)
+				_, ok := props[s]
+				return !ok
+			})
+		}
+	}
+
+	forEachSubschema(schema, func(sub map[string]any) {
+		if stripReadOnly(sub) {
+			stripped = true
+		}
+	})
+
+	return stripped
+}
+
+// walkSchemaRefs replaces the refs of the schema and its subschemas with the result of f.
+func walkSchemaRefs(schema map[string]any, f func(string) string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema["$ref"] = f(ref)
+	}
+	forEachSubschema(schema, func(sub map[string]any) {
+		walkSchemaRefs(sub, f)
+	})
+}
+
+// forEachSubschema calls f with the direct subschemas of the schema. The values of keywords holding instances, like
+// examples, are left out.
+func forEachSubschema(schema map[string]any, f func(map[string]any)) {
+	for key, val := range schema {
+		switch key {
+		case "properties", "patternProperties", "$defs", "dependentSchemas":
+			if subs, ok := val.(map[string]any); ok {
+				for _, sub := range subs {
+					if s, ok := sub.(map[string]any); ok {
+						f(s)
+					}
+				}
+			}
+		case "allOf", "anyOf", "oneOf", "prefixItems":
+			if subs, ok := val.([]any); ok {
+				for _, sub := range subs {
+					if s, ok := sub.(map[string]any); ok {
+						f(s)
+					}
+				}
+			}
+		case "items", "additionalItems", "additionalProperties", "contains", "not", "if", "then", "else",
+			"propertyNames", "unevaluatedItems", "unevaluatedProperties":
+			switch v := val.(type) {
+			case map[string]any:
+				f(v)
+			case []any:
+				for _, sub := range v {
+					if s, ok := sub.(map[string]any); ok {
+						f(s)
+					}
+				}
+			}
+		}
+	}
+}
+
+// withSchemaRef replaces the ref to the component of the model with a ref to the named component.
+func withSchemaRef(m mason.Model, name string) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		customize := cu.Customize
+		cu.Customize = func(cor openapi.ContentOrReference) {
+			if customize != nil {
+				customize(cor)
+			}
+
+			rb, ok := cor.(*openapi31.RequestBodyOrReference)
+			if !ok || rb.RequestBody == nil {
+				return
+			}
+			for ct, mt := range rb.RequestBody.Content {
+				// the operations may still refer to the components with the default prefix, see RefPrefix
+				ref, _ := mt.Schema["$ref"].(string)
+				if ref == mason.DefaultRefPrefix+m.ComponentName() || ref == m.RefPrefix()+m.ComponentName() {
+					mt.Schema["$ref"] = strings.TrimSuffix(ref, m.ComponentName()) + name
+					rb.RequestBody.Content[ct] = mt
+				}
+			}
+		}
+	}
+}
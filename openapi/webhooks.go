@@ -0,0 +1,91 @@
+package openapi
+
+import (
+	"fmt"
+
+	"github.com/swaggest/openapi-go/openapi31"
+	"github.com/tailbits/mason"
+)
+
+// WebhookRecord is an event sent to the webhooks of the clients, see mason.API.RegisterWebhook.
+type WebhookRecord struct {
+	Name        string
+	Summary     string
+	Description string
+	Payload     mason.Model
+}
+
+// webhookRecords collects the webhook events of the API, with the naming strategies and the ref prefix of the spec.
+func webhookRecords(a *mason.API, naming mason.Naming, refPrefix string) []WebhookRecord {
+	events := a.Webhooks()
+	records := make([]WebhookRecord, 0, len(events))
+	for _, event := range events {
+		records = append(records, WebhookRecord{
+			Name:        event.Name,
+			Summary:     event.Summary,
+			Description: event.Description,
+			Payload:     mason.NewModel(event.Payload).WithComponentNaming(naming.Components).WithRefPrefix(refPrefix),
+		})
+	}
+
+	return records
+}
+
+// webhookHeaders are the headers of the webhook requests, see mason.WebhookSender.
+var webhookHeaders = []struct {
+	name string
+	desc string
+}{
+	{mason.WebhookIDHeader, "The ID of the delivery, the same for all its attempts."},
+	{mason.WebhookEventHeader, "The name of the event."},
+	{mason.WebhookTimestampHeader, "The time of the attempt, in seconds since the epoch."},
+	{mason.WebhookSignatureHeader, "The HMAC-SHA256 of the ID, the timestamp and the body, separated by dots, in base64 and prefixed with `v1,`."},
+}
+
+// addWebhooks documents the webhook events in the webhooks section of the spec, as POST requests with the payload
+// of the event.
+func (r *Reflector) addWebhooks(webhooks []WebhookRecord) error {
+	if len(webhooks) > 0 && r.Spec.Components == nil {
+		// the components of the payloads are collected even without operations
+		r.Spec.Components = &openapi31.Components{}
+	}
+	for i := range webhooks {
+		webhook := &webhooks[i]
+		if err := r.addModel(&webhook.Payload); err != nil {
+			return fmt.Errorf("webhook %s: %w", webhook.Name, err)
+		}
+
+		op := openapi31.Operation{}
+		if webhook.Summary != "" {
+			op.WithSummary(webhook.Summary)
+		}
+		if webhook.Description != "" {
+			op.WithDescription(webhook.Description)
+		}
+		for _, h := range webhookHeaders {
+			param := openapi31.Parameter{
+				Name:     h.name,
+				In:       openapi31.ParameterInHeader,
+				Required: ptr(true),
+				Schema:   map[string]interface{}{"type": "string"},
+			}
+			param.WithDescription(h.desc)
+			op.Parameters = append(op.Parameters, openapi31.ParameterOrReference{Parameter: &param})
+		}
+		op.WithRequestBody(openapi31.RequestBodyOrReference{RequestBody: &openapi31.RequestBody{
+			Required: ptr(true),
+			Content: map[string]openapi31.MediaType{
+				"application/json": {Schema: map[string]interface{}{"$ref": mason.DefaultRefPrefix + webhook.Payload.ComponentName()}},
+			},
+		}})
+		op.WithResponses(openapi31.Responses{MapOfResponseOrReferenceValues: map[string]openapi31.ResponseOrReference{
+			"2XX": {Response: &openapi31.Response{Description: "The event was received. Other statuses are retried, except for the 4xx other than 408 and 429."}},
+		}})
+
+		item := openapi31.PathItem{}
+		item.WithPost(op)
+		r.Spec.WithWebhooksItem(webhook.Name, openapi31.PathItemOrReference{PathItem: &item})
+	}
+
+	return nil
+}
@@ -1,6 +1,11 @@
 package mason
 
-import "github.com/tailbits/mason/model"
+import (
+	"fmt"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
 
 type Operation struct {
 	OperationID string                 `json:"operationID,omitempty"`
@@ -14,6 +19,45 @@ type Operation struct {
 	SuccessCode int                    `json:"code,omitempty"`
 	Tags        []string               `json:"tags,omitempty"`
 	Extensions  map[string]interface{} `json:"mapOfAnything,omitempty"`
+	// FieldSelection is true when the operation accepts the fields query param.
+	FieldSelection bool `json:"fieldSelection,omitempty"`
+	// NoContent is true when the operation responds with an empty body, see HandlePostNoContent.
+	NoContent bool `json:"noContent,omitempty"`
+	// Cache is the cache policy of the operation, if its responses are cacheable.
+	Cache *CachePolicy `json:"cache,omitempty"`
+	// Content is the content policy of the operation, if it consumes or produces other charsets or content codings.
+	Content *ContentPolicy `json:"content,omitempty"`
+	// PathParams holds the constraints of the path params, by name.
+	PathParams map[string]PathParam `json:"pathParams,omitempty"`
+	// Timeout is the time the operation has to respond, if it is limited.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// TimeoutStatus is the status of the response sent when the operation exceeds its timeout.
+	TimeoutStatus int `json:"timeoutStatus,omitempty"`
+	// ResponseDescriptions are the descriptions of the responses, by status, overriding DefaultResponseDescription.
+	ResponseDescriptions map[int]string `json:"responseDescriptions,omitempty"`
+	// Errors are the codes of the errors the operation returns, from the error catalog.
+	Errors []string `json:"errors,omitempty"`
+	// Representations are the entities of the alternate representations of the response, by content type. Raw
+	// representations have no entity.
+	Representations map[string]model.Entity `json:"representations,omitempty"`
+	// Middlewares are the names of the middlewares of the operation, in order, see WithMWs.
+	Middlewares []string `json:"middlewares,omitempty"`
+	// Visibility is the audience of the operation, public when empty.
+	Visibility OperationVisibility `json:"visibility,omitempty"`
+	// FeatureFlags are the flags a tenant needs to use the operation, e.g. plan:pro, see EnabledFor.
+	FeatureFlags []string `json:"featureFlags,omitempty"`
+	// Rollout is the flag the operation is dark launched behind, see WithRollout.
+	Rollout string `json:"rollout,omitempty"`
+	// Translations are the summary and the description of the operation by language, see Localized.
+	Translations map[string]Translation `json:"translations,omitempty"`
+	// Owner is the team or person responsible for the operation, see Audit.
+	Owner string `json:"owner,omitempty"`
+	// DeprecatedSince is when the operation was deprecated, zero when it is not.
+	DeprecatedSince time.Time `json:"deprecatedSince,omitzero"`
+	// BetaSince is when the operation was released in beta, if known.
+	BetaSince time.Time `json:"betaSince,omitzero"`
+	// SignedURL is true when the operation requires a URL signed by a URLSigner.
+	SignedURL bool `json:"signedURL,omitempty"`
 }
 
 type Option func(*Operation)
@@ -61,20 +105,81 @@ func WithExtension(val map[string]interface{}) Option {
 	}
 }
 
-func (a *API) registerOp(m Operation, group string) {
-	path := m.Path
-	method := m.Method
+func WithFieldSelectionParam(enabled bool) Option {
+	return func(m *Operation) {
+		m.FieldSelection = enabled
+	}
+}
+
+func WithNoContentResponse(enabled bool) Option {
+	return func(m *Operation) {
+		m.NoContent = enabled
+	}
+}
+
+func WithCachePolicy(policy *CachePolicy) Option {
+	return func(m *Operation) {
+		m.Cache = policy
+	}
+}
+
+func WithContentPolicy(policy *ContentPolicy) Option {
+	return func(m *Operation) {
+		m.Content = policy
+	}
+}
+
+func WithPathParams(params map[string]PathParam) Option {
+	return func(m *Operation) {
+		m.PathParams = params
+	}
+}
+
+func WithTimeoutDuration(d time.Duration) Option {
+	return func(m *Operation) {
+		m.Timeout = d
+	}
+}
+
+func WithOperationTimeoutStatus(status int) Option {
+	return func(m *Operation) {
+		m.TimeoutStatus = status
+	}
+}
+
+func WithResponseDescriptions(descs map[int]string) Option {
+	return func(m *Operation) {
+		m.ResponseDescriptions = descs
+	}
+}
 
-	if grp, ok := (a.registry)[group]; ok {
-		grp[toKey(method, path)] = m
+func WithErrorCodes(codes ...string) Option {
+	return func(m *Operation) {
+		m.Errors = codes
+	}
+}
 
-		return
+func WithRepresentations(reps map[string]model.Entity) Option {
+	return func(m *Operation) {
+		m.Representations = reps
 	}
+}
+
+func WithMiddlewareNames(names ...string) Option {
+	return func(m *Operation) {
+		m.Middlewares = names
+	}
+}
+
+func (a *API) registerOp(m Operation, group string) {
+	a.mustBeExtendable(m.Method + " " + m.Path)
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	rsc := Resource{
-		toKey(method, path): m,
+	if _, ok := a.registry[group][toKey(m.Method, m.Path)]; ok {
+		a.conflicts = append(a.conflicts, fmt.Errorf("%s %s: operation is registered twice", m.Method, m.Path))
 	}
-	(a.registry)[group] = rsc
+	a.registry.AddOp(group, m)
 }
 
 func registerResponseEntity[O model.Entity, Q any](api *API, method string, group string, path string, opts ...Option) {
@@ -0,0 +1,126 @@
+package mason
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// WithAutoOptions answers OPTIONS requests on the paths of the registered routes, with a 204 and an Allow header
+// listing their methods. Routes registered for OPTIONS take precedence.
+func WithAutoOptions() HTTPRuntimeOption {
+	return func(r *HTTPRuntime) {
+		r.autoOptions = true
+	}
+}
+
+// addRoute keeps track of the methods registered for a path.
+func (r *HTTPRuntime) addRoute(method string, path string) {
+	r.addPath(path)
+
+	if r.methods == nil {
+		r.methods = make(map[string][]string)
+	}
+	if !slices.Contains(r.methods[path], method) {
+		r.methods[path] = append(r.methods[path], method)
+	}
+}
+
+// removeRoute forgets a method of a path, and the path once it has no method left.
+func (r *HTTPRuntime) removeRoute(method string, path string) {
+	r.methods[path] = slices.DeleteFunc(r.methods[path], func(m string) bool {
+		return m == method
+	})
+	if len(r.methods[path]) > 0 {
+		return
+	}
+
+	delete(r.methods, path)
+	r.paths = slices.DeleteFunc(r.paths, func(p string) bool {
+		return p == path
+	})
+}
+
+// AllowedMethods returns the methods of the routes matching the request path, sorted, including the ones answered
+// automatically, like HEAD with WithAutoHead. It returns nil when no route matches the path.
+func (r *HTTPRuntime) AllowedMethods(path string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.allowedMethods(path, nil)
+}
+
+// requestAllowedMethods returns the methods allowed for the request, without the routes it is gated out of.
+func (r *HTTPRuntime) requestAllowedMethods(req *http.Request) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.allowedMethods(req.URL.Path, req)
+}
+
+// allowedMethods returns the methods of the routes matching the path, without the routes the request is gated out of
+// when it is not nil.
+func (r *HTTPRuntime) allowedMethods(path string, req *http.Request) []string {
+	var allowed []string
+	for _, route := range r.paths {
+		if !matchRoutePath(route, path, r.caseInsensitive) {
+			continue
+		}
+		for _, method := range r.methods[route] {
+			if enabled, ok := r.gates[method+" "+route]; ok && req != nil && !enabled(req) {
+				continue
+			}
+			if !slices.Contains(allowed, method) {
+				allowed = append(allowed, method)
+			}
+		}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	if r.autoHead && slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if r.autoOptions && !slices.Contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	slices.Sort(allowed)
+
+	return allowed
+}
+
+// serveOptions answers an OPTIONS request, unless no route matches the path or a route handles OPTIONS itself.
+func (r *HTTPRuntime) serveOptions(w http.ResponseWriter, req *http.Request) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, route := range r.paths {
+		if matchRoutePath(route, req.URL.Path, r.caseInsensitive) && slices.Contains(r.methods[route], http.MethodOptions) {
+			return false
+		}
+	}
+
+	allowed := r.allowedMethods(req.URL.Path, req)
+	if allowed == nil {
+		return false
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusNoContent)
+
+	return true
+}
+
+// methodNotAllowed responds with a 405, the Allow header and a model.APIError.
+func (r *HTTPRuntime) methodNotAllowed(w http.ResponseWriter, req *http.Request, allowed []string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+	msg := fmt.Sprintf("Method %s is not allowed on %s", req.Method, req.URL.Path)
+	if err := r.Respond(req.Context(), w, model.NewAPIError("method_not_allowed", msg), http.StatusMethodNotAllowed); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
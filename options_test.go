@@ -0,0 +1,89 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestAutoOptions(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "done"}, nil
+	}
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	newRuntime := func(opts ...mason.HTTPRuntimeOption) *mason.HTTPRuntime {
+		rtm := mason.NewHTTPRuntime(opts...)
+		api := mason.NewAPI(rtm)
+		api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item"))
+		api.NewRouteGroup("items").Register(mason.HandlePut(createItem).Path("/items/{id}").WithOpID("update_item"))
+		api.NewRouteGroup("items").Register(mason.HandlePost(createItem).Path("/items").WithOpID("create_item"))
+		rtm.Handle(http.MethodOptions, "/custom", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusOK)
+			return nil
+		})
+		return rtm
+	}
+
+	t.Run("allow header", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newRuntime(mason.WithAutoOptions(), mason.WithAutoHead()).ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/items/1", nil))
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "GET, HEAD, OPTIONS, PUT", rec.Header().Get("Allow"))
+	})
+
+	t.Run("unknown path", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newRuntime(mason.WithAutoOptions()).ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/unknown", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("registered options route", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newRuntime(mason.WithAutoOptions()).ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/custom", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newRuntime().ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/items", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "POST", rec.Header().Get("Allow"))
+	})
+
+	t.Run("method not allowed body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newRuntime(mason.WithAutoOptions()).ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/items/1", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "GET, OPTIONS, PUT", rec.Header().Get("Allow"))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var body model.APIError
+		assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "method_not_allowed", body.Code)
+	})
+	t.Run("mux route", func(t *testing.T) {
+		rtm := newRuntime()
+		rtm.HandleFunc("POST /items/import", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items/import", nil))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+}
@@ -0,0 +1,28 @@
+package mason
+
+import "strings"
+
+// WithOwner sets the team owning the routes of the group and of its nested groups, unless a route sets its own, see
+// Builder.WithOwner. It must be set before the routes are registered.
+func (g *RouteGroup) WithOwner(team string) *RouteGroup {
+	g.rtm.updateGroupMetadata(g.FullPath(), func(meta *GroupMetadata) {
+		meta.Owner = team
+	})
+	return g
+}
+
+// operationOwner returns the owner of a route, or else the one of its closest group.
+func (a *API) operationOwner(group string, route string) string {
+	if route != "" {
+		return route
+	}
+
+	segments := strings.Split(group, "/")
+	for i := len(segments); i > 0; i-- {
+		if meta, ok := a.groupMeta[strings.Join(segments[:i], "/")]; ok && meta.Owner != "" {
+			return meta.Owner
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,33 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestGroupOwner(t *testing.T) {
+	listItems := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{}, nil
+	}
+
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	catalog := api.NewRouteGroup("catalog").WithOwner("catalog-team")
+	items := catalog.NewRouteGroup("items")
+	items.Register(mason.HandleGet(listItems).Path("/items").WithOpID("list_items"))
+	items.Register(mason.HandleGet(listItems).Path("/items/featured").WithOpID("list_featured").WithOwner("growth"))
+	api.NewRouteGroup("misc").Register(mason.HandleGet(listItems).Path("/misc").WithOpID("list_misc"))
+
+	owner := func(opID string) string {
+		op, ok := api.GetOperationByID(opID)
+		assert.Assert(t, ok)
+		return op.Owner
+	}
+	assert.Equal(t, "catalog-team", owner("list_items"))
+	assert.Equal(t, "growth", owner("list_featured"))
+	assert.Equal(t, "", owner("list_misc"))
+}
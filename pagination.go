@@ -0,0 +1,105 @@
+package mason
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// MaxPageLimit is the largest page size accepted by PageParams.
+var MaxPageLimit = 100
+
+// PageParams are the query params for cursor-based pagination. Embed it in a query param struct to paginate a route.
+type PageParams struct {
+	// Limit is the maximum number of items to return
+	Limit int `json:"limit" default:"20"`
+	// After returns the items after this cursor
+	After string `json:"after"`
+	// Before returns the items before this cursor
+	Before string `json:"before"`
+}
+
+var _ QueryValidator = (*PageParams)(nil)
+
+// Validate checks the page size, and that at most one well-formed cursor is given.
+func (p *PageParams) Validate() error {
+	errs := []model.FieldError{}
+
+	if p.Limit < 1 || p.Limit > MaxPageLimit {
+		errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param 'limit' must be between 1 and %d", MaxPageLimit)})
+	}
+	if p.After != "" && p.Before != "" {
+		errs = append(errs, model.FieldError{Message: "Param 'after' cannot be combined with 'before'"})
+	}
+	for name, cursor := range map[string]string{"after": p.After, "before": p.Before} {
+		if cursor == "" {
+			continue
+		}
+		if _, err := base64.RawURLEncoding.DecodeString(cursor); err != nil {
+			errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' is not a valid cursor", name)})
+		}
+	}
+
+	if len(errs) > 0 {
+		res := model.ValidationError{Errors: errs}
+		model.SortErrors(&res)
+		return res
+	}
+
+	return nil
+}
+
+// EncodeCursor returns an opaque cursor for the given position, e.g. a struct with the sort key of the last item.
+func EncodeCursor(position any) (string, error) {
+	b, err := json.Marshal(position)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor decodes a cursor created by EncodeCursor into position.
+func DecodeCursor(cursor string, position any) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("unable to decode cursor: %w", err)
+	}
+	if err := json.Unmarshal(b, position); err != nil {
+		return fmt.Errorf("unable to decode cursor: %w", err)
+	}
+
+	return nil
+}
+
+// setPageHeaders sets the Link (RFC 8288) and X-Next-Cursor headers for a paginated response.
+func setPageHeaders(w http.ResponseWriter, r *http.Request, p model.Paginated) {
+	links := []string{}
+
+	pageURL := func(param string, cursor string) string {
+		u := *r.URL
+		q := u.Query()
+		q.Del("after")
+		q.Del("before")
+		q.Set(param, cursor)
+		u.RawQuery = q.Encode()
+		return (&url.URL{Path: u.Path, RawQuery: u.RawQuery}).String()
+	}
+
+	if next := p.NextCursor(); next != "" {
+		w.Header().Set("X-Next-Cursor", next)
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL("after", next)))
+	}
+	if prev := p.PrevCursor(); prev != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL("before", prev)))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
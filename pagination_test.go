@@ -0,0 +1,66 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+type ListItemsParams struct {
+	mason.PageParams
+	Query string `json:"q"`
+}
+
+type itemCursor struct {
+	Title string `json:"title"`
+}
+
+func ListItems(ctx context.Context, r *http.Request, params ListItemsParams) (*model.Connection[*Item], error) {
+	next, err := mason.EncodeCursor(itemCursor{Title: "b"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Connection[*Item]{
+		Data:     []*Item{{Title: "a"}, {Title: "b"}},
+		PageInfo: model.PageInfo{NextCursor: &next},
+	}, nil
+}
+
+func TestPagination(t *testing.T) {
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(ListItems).Path("/items").WithOpID("list_items"))
+
+	t.Run("sets link headers", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?q=x&limit=2", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		next := rec.Header().Get("X-Next-Cursor")
+
+		var cursor itemCursor
+		assert.NilError(t, mason.DecodeCursor(next, &cursor))
+		assert.Equal(t, "b", cursor.Title)
+		assert.Equal(t, `</items?after=`+next+`&limit=2&q=x>; rel="next"`, rec.Header().Get("Link"))
+	})
+
+	t.Run("validates page params", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?limit=1000&after=a&before=b", nil))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("decodes embedded params with defaults", func(t *testing.T) {
+		params, err := mason.DecodeQueryParams[ListItemsParams](httptest.NewRequest(http.MethodGet, "/items?q=x", nil))
+		assert.NilError(t, err)
+		assert.Equal(t, 20, params.Limit)
+		assert.Equal(t, "x", params.Query)
+	})
+}
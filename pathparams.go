@@ -0,0 +1,129 @@
+package mason
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// Path param types, which are checked by the runtime and documented on the path param schema.
+const (
+	PathParamString  = "string"
+	PathParamInteger = "integer"
+	PathParamNumber  = "number"
+	PathParamBoolean = "boolean"
+	PathParamUUID    = "uuid"
+)
+
+// ErrNotFound is the code of the error of the requests whose path params do not match the pattern of the route.
+const ErrNotFound = "not_found"
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// PathParam constrains the values of a path param. Requests with a value that does not match the pattern get a 404,
+// as the route does not exist for them, and values of the wrong type get a 422.
+type PathParam struct {
+	// Type is one of the PathParam* types, string by default.
+	Type string `json:"type,omitempty"`
+	// Pattern is a regular expression the whole value must match, e.g. [0-9]+.
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// parsePathConstraints strips inline constraints like {id:[0-9]+} from a route path, and returns them by param name.
+func parsePathConstraints(p string) (string, map[string]PathParam) {
+	params := make(map[string]PathParam)
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+		name, pattern, found := strings.Cut(seg[1:len(seg)-1], ":")
+		if !found {
+			continue
+		}
+		params[name] = PathParam{Pattern: pattern}
+		segments[i] = "{" + name + "}"
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// compiledPathParam is a PathParam with its pattern compiled, as checked on every request.
+type compiledPathParam struct {
+	name    string
+	typ     string
+	pattern *regexp.Regexp
+}
+
+func compilePathParams(path string, params map[string]PathParam) ([]compiledPathParam, error) {
+	compiled := make([]compiledPathParam, 0, len(params))
+	for name, param := range params {
+		if !strings.Contains(path, "{"+name+"}") {
+			return nil, fmt.Errorf("path param %q is not part of the path %s", name, path)
+		}
+
+		switch param.Type {
+		case "", PathParamString, PathParamInteger, PathParamNumber, PathParamBoolean, PathParamUUID:
+		default:
+			return nil, fmt.Errorf("path param %q has an unknown type %q", name, param.Type)
+		}
+
+		cp := compiledPathParam{name: name, typ: param.Type}
+		if param.Pattern != "" {
+			re, err := regexp.Compile("^(?:" + param.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("path param %q has an invalid pattern: %w", name, err)
+			}
+			cp.pattern = re
+		}
+		compiled = append(compiled, cp)
+	}
+
+	return compiled, nil
+}
+
+// checkPathParams enforces the path param constraints of the route. It responds with a 404 itself when a pattern does
+// not match, and returns false, and it returns a model.ValidationError when a value is not of the declared type.
+func (rb *RouteBuilderBase) checkPathParams(ctx context.Context, api *API, w http.ResponseWriter, r *http.Request) (bool, error) {
+	errs := []model.FieldError{}
+	for _, param := range rb.compiledParams {
+		value := r.PathValue(param.name)
+		if param.pattern != nil && !param.pattern.MatchString(value) {
+			err := model.NewAPIError(ErrNotFound, "The resource was not found")
+			return false, api.Respond(ctx, w, err, http.StatusNotFound)
+		}
+
+		if !pathParamHasType(value, param.typ) {
+			errs = append(errs, model.FieldError{Message: fmt.Sprintf("Path param '%s' must be of type %s", param.name, param.typ)})
+		}
+	}
+
+	if len(errs) > 0 {
+		res := model.ValidationError{Errors: errs}
+		model.SortErrors(&res)
+		return false, res
+	}
+
+	return true, nil
+}
+
+func pathParamHasType(value string, typ string) bool {
+	var err error
+	switch typ {
+	case PathParamInteger:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case PathParamNumber:
+		_, err = strconv.ParseFloat(value, 64)
+	case PathParamBoolean:
+		_, err = strconv.ParseBool(value)
+	case PathParamUUID:
+		return uuidPattern.MatchString(value)
+	}
+
+	return err == nil
+}
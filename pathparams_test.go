@@ -0,0 +1,59 @@
+package mason_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"gotest.tools/v3/assert"
+)
+
+func TestPathParamConstraints(t *testing.T) {
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).Path("/items/{id:[0-9]+}").WithOpID("get_item"))
+	api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).
+		Path("/items/{id}/versions/{version}").
+		WithOpID("get_item_version").
+		WithPathParam("version", mason.PathParam{Type: mason.PathParamInteger}))
+
+	op, ok := api.GetOperation(http.MethodGet, "/items/{id}")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, map[string]mason.PathParam{"id": {Pattern: "[0-9]+"}}, op.PathParams)
+
+	tests := []struct {
+		target string
+		status int
+	}{
+		{target: "/items/123", status: http.StatusOK},
+		{target: "/items/abc", status: http.StatusNotFound},
+		{target: "/items/abc/versions/2", status: http.StatusOK},
+		{target: "/items/abc/versions/latest", status: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	t.Run("responds to unmatched patterns with an API error", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/abc", nil))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `{"code":"not_found","message":"The resource was not found"}`+"\n", rec.Body.String())
+	})
+
+	t.Run("rejects constraints on unknown params", func(t *testing.T) {
+		defer func() {
+			assert.Assert(t, recover() != nil)
+		}()
+		api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).
+			Path("/other/{id}").
+			WithOpID("get_other").
+			WithPathParam("nope", mason.PathParam{Type: mason.PathParamInteger}))
+	})
+}
@@ -0,0 +1,188 @@
+package mason
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// TrailingSlash defines how the HTTPRuntime handles requests whose path only differs from a route by a trailing slash.
+type TrailingSlash int
+
+const (
+	// TrailingSlashDefault keeps the behavior of http.ServeMux: only patterns ending in a slash redirect.
+	TrailingSlashDefault TrailingSlash = iota
+	// TrailingSlashStrict requires the trailing slash to match the route, and responds with a 404 otherwise.
+	TrailingSlashStrict
+	// TrailingSlashRedirect redirects to the path of the route: with a 301 for GET and HEAD requests, and with a
+	// 308 for other methods, so the method and body are preserved.
+	TrailingSlashRedirect
+)
+
+type HTTPRuntimeOption func(*HTTPRuntime)
+
+// WithTrailingSlash sets how requests with a mismatching trailing slash are handled.
+func WithTrailingSlash(ts TrailingSlash) HTTPRuntimeOption {
+	return func(r *HTTPRuntime) {
+		r.trailingSlash = ts
+	}
+}
+
+// WithCaseInsensitivePaths matches the static segments of route paths regardless of their case. Requests are served
+// as if they used the case of the route, and path params keep their original case.
+func WithCaseInsensitivePaths() HTTPRuntimeOption {
+	return func(r *HTTPRuntime) {
+		r.caseInsensitive = true
+	}
+}
+
+func (r *HTTPRuntime) addPath(path string) {
+	if !slices.Contains(r.paths, path) {
+		r.paths = append(r.paths, path)
+	}
+}
+
+// ServeHTTP answers OPTIONS requests and requests with a method that no route of the path handles, applies the path
+// handling options, and dispatches the request to the mux. The requests matching a pattern of the mux, including the
+// ones registered on the mux directly, are left to it.
+func (r *HTTPRuntime) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.autoOptions && req.Method == http.MethodOptions && r.serveOptions(w, req) {
+		return
+	}
+	if _, pattern := r.ServeMux.Handler(req); pattern == "" {
+		if allowed := r.requestAllowedMethods(req); allowed != nil && !slices.Contains(allowed, req.Method) {
+			r.methodNotAllowed(w, req, allowed)
+			return
+		}
+	}
+
+	if r.trailingSlash == TrailingSlashDefault && !r.caseInsensitive {
+		r.ServeMux.ServeHTTP(w, req)
+		return
+	}
+
+	path := req.URL.Path
+	if route, ok := r.findPath(path); ok {
+		if canonical := canonicalPath(route, path); canonical != path {
+			req = req.Clone(req.Context())
+			req.URL.Path = canonical
+			req.URL.RawPath = ""
+		}
+		r.ServeMux.ServeHTTP(w, req)
+		return
+	}
+
+	if path != "/" && r.trailingSlash != TrailingSlashDefault {
+		alt := strings.TrimSuffix(path, "/")
+		if !strings.HasSuffix(path, "/") {
+			alt = path + "/"
+		}
+
+		if route, ok := r.findPath(alt); ok {
+			if r.trailingSlash == TrailingSlashStrict {
+				http.NotFound(w, req)
+				return
+			}
+
+			target := canonicalPath(route, alt)
+			if req.URL.RawQuery != "" {
+				target += "?" + req.URL.RawQuery
+			}
+
+			code := http.StatusPermanentRedirect
+			if req.Method == http.MethodGet || req.Method == http.MethodHead {
+				code = http.StatusMovedPermanently
+			}
+			http.Redirect(w, req, target, code)
+			return
+		}
+	}
+
+	r.ServeMux.ServeHTTP(w, req)
+}
+
+// findPath returns the registered route path matching the request path, taking the trailing slash into account. When
+// several routes match, the most specific one is returned, like the mux would serve it.
+func (r *HTTPRuntime) findPath(path string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	best, found := "", false
+	for _, route := range r.paths {
+		if matchRoutePath(route, path, r.caseInsensitive) && (!found || compareRoutes(route, best) < 0) {
+			best, found = route, true
+		}
+	}
+
+	return best, found
+}
+
+// compareRoutes orders two routes by precedence, like the mux: at the first segment where they differ in kind, a
+// static segment wins over a wildcard, and a wildcard over one matching the rest of the path. It returns a negative
+// number when a wins.
+func compareRoutes(a string, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := segmentKind(as[i]) - segmentKind(bs[i]); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// segmentKind ranks a segment of a route: 0 for a static segment, 1 for a wildcard, 2 for a wildcard matching the rest
+// of the path.
+func segmentKind(seg string) int {
+	switch {
+	case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}"):
+		return 2
+	case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// matchRoutePath is like matchPath, but the trailing slash of the path must match the route.
+func matchRoutePath(route string, path string, foldCase bool) bool {
+	rs := strings.Split(route, "/")
+	ps := strings.Split(path, "/")
+
+	for i, seg := range rs {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}") {
+			return true
+		}
+		if i >= len(ps) {
+			return false
+		}
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if ps[i] == "" {
+				return false
+			}
+			continue
+		}
+		if seg != ps[i] && !(foldCase && strings.EqualFold(seg, ps[i])) {
+			return false
+		}
+	}
+
+	return len(rs) == len(ps)
+}
+
+// canonicalPath replaces the static segments of the path with the ones of the route, e.g. to fix their case.
+func canonicalPath(route string, path string) string {
+	rs := strings.Split(route, "/")
+	ps := strings.Split(path, "/")
+
+	for i, seg := range rs {
+		if i >= len(ps) || strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}") {
+			break
+		}
+		if !strings.HasPrefix(seg, "{") {
+			ps[i] = seg
+		}
+	}
+
+	return strings.Join(ps, "/")
+}
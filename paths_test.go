@@ -0,0 +1,67 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestHTTPRuntimePaths(t *testing.T) {
+	serve := func(rtm *mason.HTTPRuntime, method string, target string) *httptest.ResponseRecorder {
+		api := mason.NewAPI(rtm)
+		api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).Path("/items/{id}").WithOpID("get_item"))
+		api.NewRouteGroup("items").Register(mason.HandlePost(CreateItem).Path("/items").WithOpID("create_item"))
+
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(`{"title": "a"}`)))
+		return rec
+	}
+
+	tests := []struct {
+		name     string
+		opts     []mason.HTTPRuntimeOption
+		method   string
+		target   string
+		status   int
+		location string
+	}{
+		{name: "exact match", method: http.MethodGet, target: "/items/1", status: http.StatusOK},
+		{name: "trailing slash by default", method: http.MethodGet, target: "/items/1/", status: http.StatusNotFound},
+		{name: "strict", opts: []mason.HTTPRuntimeOption{mason.WithTrailingSlash(mason.TrailingSlashStrict)}, method: http.MethodGet, target: "/items/1/", status: http.StatusNotFound},
+		{name: "redirect GET", opts: []mason.HTTPRuntimeOption{mason.WithTrailingSlash(mason.TrailingSlashRedirect)}, method: http.MethodGet, target: "/items/1/?fields=title", status: http.StatusMovedPermanently, location: "/items/1?fields=title"},
+		{name: "redirect POST", opts: []mason.HTTPRuntimeOption{mason.WithTrailingSlash(mason.TrailingSlashRedirect)}, method: http.MethodPost, target: "/items/", status: http.StatusPermanentRedirect, location: "/items"},
+		{name: "case sensitive by default", method: http.MethodGet, target: "/Items/1", status: http.StatusNotFound},
+		{name: "case insensitive", opts: []mason.HTTPRuntimeOption{mason.WithCaseInsensitivePaths()}, method: http.MethodGet, target: "/Items/1", status: http.StatusOK},
+		{name: "case insensitive redirect", opts: []mason.HTTPRuntimeOption{mason.WithCaseInsensitivePaths(), mason.WithTrailingSlash(mason.TrailingSlashRedirect)}, method: http.MethodGet, target: "/ITEMS/Abc/", status: http.StatusMovedPermanently, location: "/items/Abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(mason.NewHTTPRuntime(tt.opts...), tt.method, tt.target)
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.location, rec.Header().Get("Location"))
+		})
+	}
+}
+
+func TestHTTPRuntimePaths_Precedence(t *testing.T) {
+	getLatest := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "latest"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime(mason.WithCaseInsensitivePaths())
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).Path("/items/{id}").WithOpID("get_item"))
+	api.NewRouteGroup("items").Register(mason.HandleGet(getLatest).Path("/items/latest").WithOpID("get_latest_item"))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ITEMS/Latest", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"title":"latest"}`+"\n", rec.Body.String())
+}
@@ -0,0 +1,83 @@
+package mason
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Plugin extends an API with a third-party integration, e.g. metrics, authentication or pagination, distributed as a
+// module of its own, see API.RegisterPlugin. A plugin implements the hooks it needs: RegistrationPlugin,
+// DecodePlugin, ResponsePlugin and SpecPlugin.
+type Plugin interface {
+	// Name identifies the plugin, and must be unique within an API.
+	Name() string
+}
+
+// RegistrationPlugin is a Plugin configuring the routes as they are registered, e.g. to add a middleware or an
+// extension to every route.
+type RegistrationPlugin interface {
+	Plugin
+	// OnRegister runs with the builder of each route, before the route is validated and registered.
+	OnRegister(b Builder)
+}
+
+// DecodePlugin is a Plugin running before the requests of every route are decoded, like a BeforeDecode hook.
+type DecodePlugin interface {
+	Plugin
+	BeforeDecode(r *http.Request) error
+}
+
+// ResponsePlugin is a Plugin post-processing the encoded responses of every route, like an AfterEncode hook.
+type ResponsePlugin interface {
+	Plugin
+	AfterEncode(ctx context.Context, status int, body []byte) ([]byte, error)
+}
+
+// SpecPlugin is a Plugin editing the generated OpenAPI spec, e.g. to document the query params it adds to the
+// routes. TransformSpec gets the spec as decoded from JSON, before it is validated.
+type SpecPlugin interface {
+	Plugin
+	TransformSpec(spec map[string]any) error
+}
+
+// RegisterPlugin adds a plugin to the API. It applies to the routes registered after it, so plugins are registered
+// first. The plugins wrap the hooks of the routes: their BeforeDecode hooks run before the ones of the route, and
+// their AfterEncode hooks after them, in the order the plugins are registered. A plugin registered twice, or with the
+// name of another one, panics.
+func (a *API) RegisterPlugin(p Plugin) *API {
+	a.mustBeMutable("plugin " + p.Name())
+	for _, other := range a.plugins {
+		if other.Name() == p.Name() {
+			panic(fmt.Sprintf("plugin %s is registered twice", p.Name()))
+		}
+	}
+
+	a.plugins = append(a.plugins, p)
+	return a
+}
+
+// Plugins returns the plugins of the API, in the order they are registered.
+func (a *API) Plugins() []Plugin {
+	return a.plugins
+}
+
+// applyPlugins hands the builder of the route to the plugins, and adds their hooks to the ones of the route.
+func (rb *RouteBuilderBase) applyPlugins(api *API, b Builder) {
+	var decode []BeforeDecodeHook
+	for _, p := range api.plugins {
+		if rp, ok := p.(RegistrationPlugin); ok {
+			rp.OnRegister(b)
+		}
+		if dp, ok := p.(DecodePlugin); ok {
+			decode = append(decode, dp.BeforeDecode)
+		}
+	}
+	rb.beforeDecodeHooks = append(decode, rb.beforeDecodeHooks...)
+
+	for _, p := range api.plugins {
+		if rp, ok := p.(ResponsePlugin); ok {
+			rb.afterEncodeHooks = append(rb.afterEncodeHooks, rp.AfterEncode)
+		}
+	}
+}
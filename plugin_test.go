@@ -0,0 +1,81 @@
+package mason_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+// tracePlugin tags the routes with an extension, and records the order in which its hooks run.
+type tracePlugin struct {
+	name  string
+	calls *[]string
+}
+
+func (p tracePlugin) Name() string {
+	return p.name
+}
+
+func (p tracePlugin) OnRegister(b mason.Builder) {
+	b.WithExtensions("x-"+p.name, b.OpID())
+}
+
+func (p tracePlugin) BeforeDecode(r *http.Request) error {
+	*p.calls = append(*p.calls, p.name+" decode")
+	return nil
+}
+
+func (p tracePlugin) AfterEncode(ctx context.Context, status int, body []byte) ([]byte, error) {
+	*p.calls = append(*p.calls, p.name+" encode")
+	return bytes.TrimSpace(body), nil
+}
+
+func TestRegisterPlugin(t *testing.T) {
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	var calls []string
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.RegisterPlugin(tracePlugin{name: "metrics", calls: &calls}).
+		RegisterPlugin(tracePlugin{name: "auth", calls: &calls})
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID("create_item").
+		BeforeDecode(func(r *http.Request) error {
+			calls = append(calls, "route decode")
+			return nil
+		}).
+		AfterEncode(func(ctx context.Context, status int, body []byte) ([]byte, error) {
+			calls = append(calls, "route encode")
+			return body, nil
+		}))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"title": "new"}`)))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"title":"new"}`, rec.Body.String())
+	assert.DeepEqual(t, []string{
+		"metrics decode", "auth decode", "route decode",
+		"route encode", "metrics encode", "auth encode",
+	}, calls)
+
+	op, ok := api.GetOperationByID("create_item")
+	assert.Assert(t, ok)
+	assert.Equal(t, "create_item", op.Extensions["x-metrics"])
+	assert.Equal(t, "create_item", op.Extensions["x-auth"])
+
+	assert.Equal(t, 2, len(api.Plugins()))
+	assert.Assert(t, cmpPanics(func() {
+		api.RegisterPlugin(tracePlugin{name: "auth", calls: &calls})
+	}))
+}
@@ -0,0 +1,304 @@
+// Package postman exports a mason API as a Postman (v2.1) collection.
+// The output can also be imported by Bruno, which understands the Postman format.
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/internal/casing"
+	"github.com/tailbits/mason/model"
+)
+
+const schemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// BaseURLVar and TokenVar are the collection variables used for the server URL and the auth token.
+const (
+	BaseURLVar = "baseUrl"
+	TokenVar   = "token"
+)
+
+type Collection struct {
+	Info     Info       `json:"info"`
+	Items    []Item     `json:"item"`
+	Variable []Variable `json:"variable,omitempty"`
+	Auth     *Auth      `json:"auth,omitempty"`
+}
+
+type Info struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// Item is either a folder (with nested Items) or a request.
+type Item struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Items       []Item   `json:"item,omitempty"`
+	Request     *Request `json:"request,omitempty"`
+}
+
+type Request struct {
+	Method      string     `json:"method"`
+	Header      []Variable `json:"header"`
+	URL         URL        `json:"url"`
+	Body        *Body      `json:"body,omitempty"`
+	Description string     `json:"description,omitempty"`
+}
+
+type URL struct {
+	Raw      string     `json:"raw"`
+	Host     []string   `json:"host"`
+	Path     []string   `json:"path"`
+	Query    []Variable `json:"query,omitempty"`
+	Variable []Variable `json:"variable,omitempty"`
+}
+
+type Body struct {
+	Mode    string         `json:"mode"`
+	Raw     string         `json:"raw"`
+	Options map[string]any `json:"options,omitempty"`
+}
+
+type Variable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type Auth struct {
+	Type   string     `json:"type"`
+	Bearer []Variable `json:"bearer,omitempty"`
+}
+
+type config struct {
+	name       string
+	serverURL  string
+	bearer     bool
+	visibility []mason.OperationVisibility
+}
+
+// ExportOption configures the export of a collection.
+type ExportOption func(*config)
+
+// Name sets the name of the collection.
+func Name(name string) ExportOption {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// ServerURL sets the default value of the baseUrl collection variable.
+func ServerURL(serverURL string) ExportOption {
+	return func(c *config) {
+		c.serverURL = serverURL
+	}
+}
+
+// Visibility sets the visibility tiers of the exported operations, only public ones by default.
+func Visibility(tiers ...mason.OperationVisibility) ExportOption {
+	return func(c *config) {
+		c.visibility = tiers
+	}
+}
+
+// BearerAuth adds collection-level bearer auth that reads the token from the token variable.
+func BearerAuth() ExportOption {
+	return func(c *config) {
+		c.bearer = true
+	}
+}
+
+// Export converts the operations registered on the API into a Postman collection, with one folder per route group.
+func Export(api *mason.API, opts ...ExportOption) (*Collection, error) {
+	cfg := config{
+		name:       "API",
+		serverURL:  "http://localhost",
+		visibility: []mason.OperationVisibility{mason.VisibilityPublic},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	folders := make(map[string][]Item)
+	var ferr error
+	api.ForEachOperation(func(group string, op mason.Operation) {
+		if !op.VisibleIn(cfg.visibility...) {
+			return
+		}
+		item, err := toItem(op)
+		if err != nil && ferr == nil {
+			ferr = fmt.Errorf("operation %s: %w", op.OperationID, err)
+		}
+		folders[group] = append(folders[group], item)
+	})
+	if ferr != nil {
+		return nil, ferr
+	}
+
+	groups := make([]string, 0, len(folders))
+	for group := range folders {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	col := &Collection{
+		Info: Info{
+			Name:   cfg.name,
+			Schema: schemaURL,
+		},
+		Variable: []Variable{{Key: BaseURLVar, Value: cfg.serverURL, Type: "string"}},
+	}
+
+	for _, group := range groups {
+		items := folders[group]
+		sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+		folder := Item{
+			Name:  casing.KebabToTitleCase(strings.ReplaceAll(group, "/", " / ")),
+			Items: items,
+		}
+		if meta, ok := api.GroupMetadata(group); ok {
+			folder.Description = meta.Description
+		}
+		col.Items = append(col.Items, folder)
+	}
+
+	if cfg.bearer {
+		col.Variable = append(col.Variable, Variable{Key: TokenVar, Value: "", Type: "string"})
+		col.Auth = &Auth{
+			Type:   "bearer",
+			Bearer: []Variable{{Key: "token", Value: "{{" + TokenVar + "}}", Type: "string"}},
+		}
+	}
+
+	return col, nil
+}
+
+// Marshal returns the collection as indented JSON, ready to be imported.
+func (c *Collection) Marshal() ([]byte, error) {
+	return json.MarshalIndent(c, "", "  ")
+}
+
+func toItem(op mason.Operation) (Item, error) {
+	name := op.Summary
+	if name == "" {
+		name = op.OperationID
+	}
+
+	req := &Request{
+		Method:      op.Method,
+		Header:      []Variable{{Key: "Accept", Value: "application/json"}},
+		URL:         toURL(op.Path, op.QueryParams),
+		Description: op.Description,
+	}
+
+	if op.Input != nil && !isNil(op.Input) {
+		raw, err := prettyExample(op.Input)
+		if err != nil {
+			return Item{}, err
+		}
+		req.Header = append(req.Header, Variable{Key: "Content-Type", Value: "application/json"})
+		req.Body = &Body{
+			Mode:    "raw",
+			Raw:     raw,
+			Options: map[string]any{"raw": map[string]string{"language": "json"}},
+		}
+	}
+
+	return Item{Name: name, Request: req}, nil
+}
+
+func toURL(path string, queryParams any) URL {
+	segments := []string{}
+	vars := []Variable{}
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			name := strings.TrimSuffix(strings.Trim(seg, "{}"), "...")
+			vars = append(vars, Variable{Key: name, Value: ""})
+			seg = ":" + name
+		}
+		segments = append(segments, seg)
+	}
+
+	query := queryVariables(queryParams)
+
+	raw := "{{" + BaseURLVar + "}}/" + strings.Join(segments, "/")
+	if len(query) > 0 {
+		values := url.Values{}
+		for _, q := range query {
+			values.Add(q.Key, q.Value)
+		}
+		raw += "?" + values.Encode()
+	}
+
+	return URL{
+		Raw:      raw,
+		Host:     []string{"{{" + BaseURLVar + "}}"},
+		Path:     segments,
+		Query:    query,
+		Variable: vars,
+	}
+}
+
+// queryVariables lists the query params of a route, disabled unless they are required or have a default value.
+func queryVariables(queryParams any) []Variable {
+	if queryParams == nil {
+		return nil
+	}
+	t := reflect.TypeOf(queryParams)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	vars := []Variable{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		tags := mason.ParseQueryParamTags(field.Tag)
+		value := tags.Default
+		if value == "" {
+			value = tags.Example
+		}
+		vars = append(vars, Variable{
+			Key:         tag,
+			Value:       value,
+			Description: field.Tag.Get("doc"),
+			Disabled:    tags.Default == "" && !tags.Required,
+		})
+	}
+
+	return vars
+}
+
+func prettyExample(ent model.WithSchema) (string, error) {
+	var v any
+	if err := json.Unmarshal(ent.Example(), &v); err != nil {
+		return "", fmt.Errorf("error unmarshalling example for %s: %w", ent.Name(), err)
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func isNil(ent model.WithSchema) bool {
+	_, ok := ent.(model.Nil)
+	return ok
+}
@@ -0,0 +1,78 @@
+package postman_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"github.com/tailbits/mason/postman"
+	"gotest.tools/v3/assert"
+)
+
+func TestExport(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("Widgets")
+	grp.Register(mason.HandlePost(CreateWidget).
+		Path("/widgets/{id}").
+		WithOpID("create_widget").
+		WithSummary("Create a widget"))
+
+	col, err := postman.Export(api, postman.Name("Widgets API"), postman.ServerURL("https://api.example.com"), postman.BearerAuth())
+	assert.NilError(t, err)
+
+	assert.Equal(t, "Widgets API", col.Info.Name)
+	assert.Equal(t, 1, len(col.Items))
+	assert.Equal(t, "Widgets", col.Items[0].Name)
+
+	req := col.Items[0].Items[0].Request
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "{{baseUrl}}/widgets/:id?dry_run=false&filter=color%3Dred%26size%3E1", req.URL.Raw)
+	assert.Equal(t, "id", req.URL.Variable[0].Key)
+	assert.Assert(t, req.Body != nil)
+
+	var body map[string]any
+	assert.NilError(t, json.Unmarshal([]byte(req.Body.Raw), &body))
+	assert.Equal(t, "gear", body["name"])
+
+	assert.Equal(t, "bearer", col.Auth.Type)
+	_, err = col.Marshal()
+	assert.NilError(t, err)
+}
+
+type WidgetQuery struct {
+	DryRun bool   `json:"dry_run" default:"false"`
+	Filter string `json:"filter" example:"color=red&size>1"`
+}
+
+func CreateWidget(ctx context.Context, _ *http.Request, w *Widget, q WidgetQuery) (*Widget, error) {
+	return w, nil
+}
+
+var _ model.Entity = (*Widget)(nil)
+
+type Widget struct {
+	Label string `json:"name"`
+}
+
+func (w *Widget) Example() []byte {
+	return []byte(`{"name": "gear"}`)
+}
+
+func (w *Widget) Marshal() (json.RawMessage, error) {
+	return json.Marshal(w)
+}
+
+func (w *Widget) Name() string {
+	return "Widget"
+}
+
+func (w *Widget) Schema() []byte {
+	return []byte(`{"type": "object", "properties": {"name": {"type": "string"}}}`)
+}
+
+func (w *Widget) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, w)
+}
@@ -0,0 +1,465 @@
+// Package protoschema is an experimental exporter of the schemas of the entities of a mason API to Protocol Buffers
+// (proto3) message definitions, to ease the migration of endpoints to gRPC. The schemas are collected like for a JSON
+// Schema bundle, see schemabundle.Export, and each one becomes a top-level message.
+//
+// The JSON Schema types map to proto3 as follows:
+//
+//	JSON Schema                               proto3
+//	string                                    string
+//	string, format date-time                  google.protobuf.Timestamp
+//	string, format byte or binary             bytes
+//	string with enum                          enum <Field>, with a <FIELD>_UNSPECIFIED zero value
+//	integer                                   int64
+//	integer, format int32                     int32
+//	number                                    double
+//	number, format float                      float
+//	boolean                                   bool
+//	array                                     repeated <items>
+//	object with properties                    nested message <Field>
+//	object with additionalProperties only     map<string, <additionalProperties>>
+//	$ref to a schema                          message <name>
+//	not required, or with the null type       optional <scalar>
+//
+// The constructs without a mapping, e.g. oneOf, nested arrays or untyped schemas, map to google.protobuf.Value, and
+// are reported as Warnings. The fields are numbered in the alphabetical order of the properties, so adding a property
+// renumbers the ones after it: the output is a starting point, not a stable wire contract.
+package protoschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/internal/casing"
+	"github.com/tailbits/mason/schemabundle"
+)
+
+const (
+	timestampType = "google.protobuf.Timestamp"
+	valueType     = "google.protobuf.Value"
+)
+
+var imports = map[string]string{
+	timestampType: "google/protobuf/timestamp.proto",
+	valueType:     "google/protobuf/struct.proto",
+}
+
+// File is a .proto file holding the messages of the entities.
+type File struct {
+	Package   string
+	GoPackage string
+	Messages  []Message
+	// Warnings are the constructs of the schemas that have no mapping.
+	Warnings []Warning
+}
+
+// Message is a proto message, with its nested messages and enums.
+type Message struct {
+	Name     string
+	Comment  string
+	Fields   []Field
+	Messages []Message
+	Enums    []Enum
+}
+
+// Field is a field of a message. JSONName is set when the JSON property does not match the default JSON name of the
+// field.
+type Field struct {
+	Name     string
+	JSONName string
+	Type     string
+	Number   int
+	Repeated bool
+	Optional bool
+	Comment  string
+}
+
+// Enum is a proto enum, with the zero value first.
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+// Warning reports a construct of a schema without a mapping, at the JSON pointer Path of the schema.
+type Warning struct {
+	Schema string
+	Path   string
+	Reason string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s%s: %s", w.Schema, w.Path, w.Reason)
+}
+
+type config struct {
+	pkg       string
+	goPackage string
+}
+
+type exportOption func(*config)
+
+// Package sets the package of the .proto file, "mason.v1" by default.
+func Package(name string) exportOption {
+	return func(c *config) {
+		c.pkg = name
+	}
+}
+
+// GoPackage sets the go_package option of the .proto file.
+func GoPackage(path string) exportOption {
+	return func(c *config) {
+		c.goPackage = path
+	}
+}
+
+// Export converts the schemas of the entities registered on the API to proto messages.
+func Export(api *mason.API, opts ...exportOption) (*File, error) {
+	cfg := config{pkg: "mason.v1"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	bundle, err := schemabundle.Export(api)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{Package: cfg.pkg, GoPackage: cfg.goPackage}
+	for _, name := range bundle.Names() {
+		schema, _ := bundle.Schema(name)
+		c := converter{file: file, schema: messageName(name)}
+		file.Messages = append(file.Messages, c.message(messageName(name), schema, ""))
+	}
+
+	return file, nil
+}
+
+// converter converts the schema of a top-level message.
+type converter struct {
+	file   *File
+	schema string
+}
+
+func (c *converter) warn(path string, reason string) {
+	c.file.Warnings = append(c.file.Warnings, Warning{Schema: c.schema, Path: path, Reason: reason})
+}
+
+func (c *converter) message(name string, schema map[string]any, path string) Message {
+	msg := Message{Name: name, Comment: description(schema)}
+
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		if types(schema)[0] != "object" {
+			// the other types are wrapped, like the well-known wrapper types
+			c.warn(path, "is not an object, wrapped in the value field")
+			field := c.field(&msg, "value", schema, path, true)
+			field.Number = 1
+			msg.Fields = append(msg.Fields, field)
+		}
+		return msg
+	}
+
+	required := map[string]bool{}
+	if req, ok := schema["required"].([]any); ok {
+		for _, r := range req {
+			if s, ok := r.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(props))
+	for prop := range props {
+		names = append(names, prop)
+	}
+	sort.Strings(names)
+
+	for i, prop := range names {
+		propSchema, _ := props[prop].(map[string]any)
+		field := c.field(&msg, prop, propSchema, path+"/properties/"+prop, required[prop])
+		field.Number = i + 1
+		msg.Fields = append(msg.Fields, field)
+	}
+
+	return msg
+}
+
+// field converts a property, adding the nested messages and enums it needs to the message.
+func (c *converter) field(msg *Message, prop string, schema map[string]any, path string, required bool) Field {
+	field := Field{Name: fieldName(prop), Comment: description(schema)}
+	if field.Name != prop {
+		field.JSONName = prop
+	}
+
+	nullable := false
+	typ, repeated := c.fieldType(msg, prop, schema, path, &nullable)
+	field.Type = typ
+	field.Repeated = repeated
+	field.Optional = !repeated && isScalar(msg, typ) && (nullable || !required)
+
+	return field
+}
+
+func (c *converter) fieldType(msg *Message, prop string, schema map[string]any, path string, nullable *bool) (string, bool) {
+	if schema == nil {
+		c.warn(path, "has no schema")
+		return valueType, false
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		target, ok := strings.CutPrefix(ref, "#/definitions/")
+		if !ok || strings.Contains(target, "/") {
+			c.warn(path, "refers to "+ref+", which is not a schema of the bundle")
+			return valueType, false
+		}
+		return messageName(target), false
+	}
+	for _, keyword := range []string{"oneOf", "anyOf", "allOf"} {
+		if _, ok := schema[keyword]; ok {
+			c.warn(path, "uses "+keyword)
+			return valueType, false
+		}
+	}
+
+	typs := types(schema)
+	if len(typs) > 1 && typs[len(typs)-1] == "null" {
+		*nullable = true
+		typs = typs[:len(typs)-1]
+	}
+	if len(typs) > 1 {
+		c.warn(path, "has several types")
+		return valueType, false
+	}
+
+	format, _ := schema["format"].(string)
+	switch typs[0] {
+	case "string":
+		if enum, ok := schema["enum"].([]any); ok {
+			return c.enum(msg, prop, enum, path), false
+		}
+		switch format {
+		case "date-time":
+			return timestampType, false
+		case "byte", "binary":
+			return "bytes", false
+		}
+		return "string", false
+	case "integer":
+		if format == "int32" {
+			return "int32", false
+		}
+		return "int64", false
+	case "number":
+		if format == "float" {
+			return "float", false
+		}
+		return "double", false
+	case "boolean":
+		return "bool", false
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items != nil && types(items)[0] == "array" {
+			c.warn(path, "is an array of arrays")
+			return valueType, true
+		}
+		var itemNullable bool
+		typ, _ := c.fieldType(msg, prop, items, path+"/items", &itemNullable)
+		return typ, true
+	case "object":
+		if _, ok := schema["properties"]; ok {
+			nested := c.message(messageName(prop), schema, path)
+			// the description documents the field
+			nested.Comment = ""
+			msg.Messages = append(msg.Messages, nested)
+			return nested.Name, false
+		}
+		if values, ok := schema["additionalProperties"].(map[string]any); ok {
+			var valueNullable bool
+			typ, repeated := c.fieldType(msg, prop, values, path+"/additionalProperties", &valueNullable)
+			if repeated || strings.HasPrefix(typ, "map<") {
+				c.warn(path, "is a map of arrays or maps")
+				typ = valueType
+			}
+			return "map<string, " + typ + ">", false
+		}
+		c.warn(path, "is an object without properties")
+		return valueType, false
+	}
+
+	c.warn(path, "has no type")
+	return valueType, false
+}
+
+// enum adds the enum of a string property to the message. The values that are not valid identifiers are reported.
+func (c *converter) enum(msg *Message, prop string, values []any, path string) string {
+	enum := Enum{Name: messageName(prop)}
+	prefix := strings.ToUpper(casing.ToSnakeCase(enum.Name))
+	enum.Values = append(enum.Values, prefix+"_UNSPECIFIED")
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok || s == "" {
+			c.warn(path, fmt.Sprintf("has the enum value %v, which is not a string", v))
+			continue
+		}
+		enum.Values = append(enum.Values, prefix+"_"+strings.ToUpper(identifier(casing.ToSnakeCase(s))))
+	}
+	msg.Enums = append(msg.Enums, enum)
+
+	return enum.Name
+}
+
+// types returns the types of a schema, the null type last, or "" when it has none.
+func types(schema map[string]any) []string {
+	var typs []string
+	switch t := schema["type"].(type) {
+	case string:
+		typs = append(typs, t)
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				typs = append(typs, s)
+			}
+		}
+		if len(typs) < len(t) {
+			typs = append(typs, "null")
+		}
+	}
+	if len(typs) == 0 {
+		if _, ok := schema["properties"]; ok {
+			return []string{"object"}
+		}
+		return []string{""}
+	}
+
+	return typs
+}
+
+// isScalar reports whether the type has no field presence without optional: the scalar types and the enums.
+func isScalar(msg *Message, typ string) bool {
+	switch typ {
+	case "string", "bytes", "int32", "int64", "float", "double", "bool":
+		return true
+	}
+	for _, enum := range msg.Enums {
+		if enum.Name == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+func description(schema map[string]any) string {
+	desc, _ := schema["description"].(string)
+	return desc
+}
+
+func messageName(name string) string {
+	return identifier(casing.ToPascalCase(identifier(name)))
+}
+
+func fieldName(prop string) string {
+	return identifier(casing.ToSnakeCase(prop))
+}
+
+// identifier replaces the characters that are not valid in a proto identifier with underscores.
+func identifier(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || r == '_' || i > 0 && unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	return b.String()
+}
+
+// Marshal renders the .proto file.
+func (f *File) Marshal() []byte {
+	var b strings.Builder
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n", f.Package)
+
+	used := map[string]bool{}
+	var collect func(msgs []Message)
+	collect = func(msgs []Message) {
+		for _, msg := range msgs {
+			for _, field := range msg.Fields {
+				for typ, path := range imports {
+					if strings.Contains(field.Type, typ) {
+						used[path] = true
+					}
+				}
+			}
+			collect(msg.Messages)
+		}
+	}
+	collect(f.Messages)
+	if len(used) > 0 {
+		paths := make([]string, 0, len(used))
+		for path := range used {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		b.WriteString("\n")
+		for _, path := range paths {
+			fmt.Fprintf(&b, "import \"%s\";\n", path)
+		}
+	}
+
+	if f.GoPackage != "" {
+		fmt.Fprintf(&b, "\noption go_package = \"%s\";\n", f.GoPackage)
+	}
+
+	for _, msg := range f.Messages {
+		b.WriteString("\n")
+		writeMessage(&b, msg, "")
+	}
+
+	return []byte(b.String())
+}
+
+func writeMessage(b *strings.Builder, msg Message, indent string) {
+	writeComment(b, msg.Comment, indent)
+	fmt.Fprintf(b, "%smessage %s {\n", indent, msg.Name)
+	inner := indent + "  "
+	for _, enum := range msg.Enums {
+		fmt.Fprintf(b, "%senum %s {\n", inner, enum.Name)
+		for i, value := range enum.Values {
+			fmt.Fprintf(b, "%s  %s = %d;\n", inner, value, i)
+		}
+		fmt.Fprintf(b, "%s}\n", inner)
+	}
+	for _, nested := range msg.Messages {
+		writeMessage(b, nested, inner)
+	}
+	for _, field := range msg.Fields {
+		writeComment(b, field.Comment, inner)
+		b.WriteString(inner)
+		switch {
+		case field.Repeated:
+			b.WriteString("repeated ")
+		case field.Optional:
+			b.WriteString("optional ")
+		}
+		fmt.Fprintf(b, "%s %s = %d", field.Type, field.Name, field.Number)
+		if field.JSONName != "" {
+			fmt.Fprintf(b, " [json_name = \"%s\"]", field.JSONName)
+		}
+		b.WriteString(";\n")
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+func writeComment(b *strings.Builder, comment string, indent string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		fmt.Fprintf(b, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
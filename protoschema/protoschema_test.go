@@ -0,0 +1,104 @@
+package protoschema_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"github.com/tailbits/mason/protoschema"
+	"gotest.tools/v3/assert"
+)
+
+func TestExport(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("tickets").Register(mason.HandlePost(CreateTicket).Path("/tickets").WithOpID("create_ticket"))
+
+	file, err := protoschema.Export(api, protoschema.Package("tickets.v1"), protoschema.GoPackage("example.com/tickets/v1"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, `syntax = "proto3";
+
+package tickets.v1;
+
+import "google/protobuf/struct.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "example.com/tickets/v1";
+
+message Comment {
+  string body = 1;
+}
+
+// A support ticket.
+message Ticket {
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_OPEN = 1;
+    STATUS_IN_PROGRESS = 2;
+  }
+  message Owner {
+    optional string name = 1;
+  }
+  repeated Comment comments = 1;
+  google.protobuf.Timestamp created_at = 2 [json_name = "createdAt"];
+  google.protobuf.Value details = 3;
+  int64 id = 4;
+  map<string, string> labels = 5;
+  // The owner of the ticket.
+  Owner owner = 6;
+  optional double score = 7;
+  optional Status status = 8;
+  repeated string tags = 9;
+}
+`, string(file.Marshal()))
+
+	assert.Equal(t, 1, len(file.Warnings))
+	assert.Equal(t, "Ticket/properties/details: uses oneOf", file.Warnings[0].String())
+}
+
+func CreateTicket(ctx context.Context, r *http.Request, in *Ticket, _ model.Nil) (*Ticket, error) {
+	return in, nil
+}
+
+type Ticket struct{}
+
+func (t *Ticket) Name() string {
+	return "Ticket"
+}
+
+func (t *Ticket) Example() []byte {
+	return []byte(`{}`)
+}
+
+func (t *Ticket) Schema() []byte {
+	return []byte(`{
+		"description": "A support ticket.",
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer"},
+			"createdAt": {"type": "string", "format": "date-time"},
+			"status": {"type": "string", "enum": ["open", "in_progress"]},
+			"score": {"type": ["number", "null"]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"owner": {"description": "The owner of the ticket.", "type": "object", "properties": {"name": {"type": "string"}}},
+			"comments": {"type": "array", "items": {"$ref": "#/definitions/Comment"}},
+			"details": {"oneOf": [{"type": "string"}, {"type": "integer"}]}
+		},
+		"required": ["id", "createdAt", "labels"],
+		"definitions": {
+			"Comment": {"type": "object", "properties": {"body": {"type": "string"}}, "required": ["body"]}
+		}
+	}`)
+}
+
+func (t *Ticket) Marshal() (json.RawMessage, error) {
+	return json.Marshal(t)
+}
+
+func (t *Ticket) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, t)
+}
@@ -0,0 +1,131 @@
+package mason
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// Provider builds a dependency of a handler for a request, e.g. a logger with the request ID, or the tenant of the
+// caller.
+type Provider[T any] func(r *http.Request) (T, error)
+
+// Provide registers the provider of the dependencies of type T. Handlers get them with Use, instead of reaching for
+// global variables. A provider registered for a type that already has one replaces it.
+func Provide[T any](api *API, provider Provider[T]) {
+	api.mustBeMutable("provider of " + reflect.TypeFor[T]().String())
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.providers == nil {
+		api.providers = make(map[reflect.Type]func(r *http.Request) (any, error))
+	}
+
+	api.providers[reflect.TypeFor[T]()] = func(r *http.Request) (any, error) {
+		return provider(r)
+	}
+}
+
+// Use returns the dependency of type T for the current request. The provider runs at most once per request, on the
+// first call, so the handler and its helpers share the same dependency, e.g. a database transaction. Providers can Use
+// other dependencies with the context of their request, but not themselves. A provider of an interface type returning
+// nil is an error.
+func Use[T any](ctx context.Context) (T, error) {
+	var zero T
+
+	s, ok := ctx.Value(scopeKey{}).(*scope)
+	if !ok {
+		return zero, fmt.Errorf("no request scope in context, Use must be called with the context of a handler")
+	}
+
+	dep, err := s.resolve(ctx, reflect.TypeFor[T]())
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := dep.(T)
+	if !ok {
+		// a provider of an interface type returned a nil interface
+		return zero, fmt.Errorf("provider of %s returned nil", reflect.TypeFor[T]())
+	}
+
+	return typed, nil
+}
+
+type scopeKey struct{}
+
+// resolvingKey holds the types whose providers are running, for the providers calling Use.
+type resolvingKey struct{}
+
+type resolving struct {
+	t      reflect.Type
+	parent *resolving
+}
+
+// scope holds the dependencies resolved for a request.
+type scope struct {
+	api *API
+	// r is the request with the scope in its context, which the providers get
+	r    *http.Request
+	mu   sync.Mutex
+	deps map[reflect.Type]*dependency
+}
+
+// dependency is resolved once, by the first call to Use, and the other calls wait for it.
+type dependency struct {
+	once sync.Once
+	dep  any
+	err  error
+}
+
+// withScope adds a request scope to the context, so handlers can Use the provided dependencies.
+func (a *API) withScope(ctx context.Context, r *http.Request) (context.Context, *http.Request) {
+	a.mu.RLock()
+	n := len(a.providers)
+	a.mu.RUnlock()
+	if n == 0 {
+		return ctx, r
+	}
+
+	s := &scope{api: a, deps: make(map[reflect.Type]*dependency)}
+	ctx = context.WithValue(ctx, scopeKey{}, s)
+	s.r = r.WithContext(ctx)
+
+	return ctx, s.r
+}
+
+// resolve runs the provider of the type, without holding the lock of the scope, so it can Use other dependencies.
+func (s *scope) resolve(ctx context.Context, t reflect.Type) (any, error) {
+	chain, _ := ctx.Value(resolvingKey{}).(*resolving)
+	for p := chain; p != nil; p = p.parent {
+		if p.t == t {
+			return nil, fmt.Errorf("provider of %v depends on itself", t)
+		}
+	}
+
+	s.api.mu.RLock()
+	provider, ok := s.api.providers[t]
+	s.api.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no provider registered for %v", t)
+	}
+
+	s.mu.Lock()
+	d, ok := s.deps[t]
+	if !ok {
+		d = &dependency{}
+		s.deps[t] = d
+	}
+	s.mu.Unlock()
+
+	d.once.Do(func() {
+		r := s.r.WithContext(context.WithValue(ctx, resolvingKey{}, &resolving{t: t, parent: chain}))
+		if d.dep, d.err = provider(r); d.err != nil {
+			d.err = fmt.Errorf("failed to provide %v: %w", t, d.err)
+		}
+	})
+
+	return d.dep, d.err
+}
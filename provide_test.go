@@ -0,0 +1,122 @@
+package mason_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+type tenant struct {
+	ID string
+}
+
+func TestProvide(t *testing.T) {
+	calls := 0
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		tn, err := mason.Use[*tenant](ctx)
+		if err != nil {
+			return nil, err
+		}
+		// the provider runs once per request
+		again, err := mason.Use[*tenant](r.Context())
+		if err != nil {
+			return nil, err
+		}
+		if tn != again {
+			return nil, errors.New("tenant provided twice")
+		}
+
+		return &Item{Title: tn.ID}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	mason.Provide(api, func(r *http.Request) (*tenant, error) {
+		calls++
+		return &tenant{ID: r.Header.Get("X-Tenant")}, nil
+	})
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+		Path("/items").
+		WithOpID("get_item"))
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("X-Tenant", "acme")
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"title":"acme"}`+"\n", rec.Body.String())
+	assert.Equal(t, 1, calls)
+}
+
+func TestUse_NoProvider(t *testing.T) {
+	_, err := mason.Use[*tenant](context.Background())
+	assert.ErrorContains(t, err, "no request scope")
+}
+
+func TestUse_NilInterface(t *testing.T) {
+	var useErr error
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		_, useErr = mason.Use[error](ctx)
+		return &Item{}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	mason.Provide(api, func(r *http.Request) (error, error) {
+		return nil, nil
+	})
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items").WithOpID("get_item"))
+
+	rtm.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.ErrorContains(t, useErr, "provider of error returned nil")
+}
+
+func TestUse_DependentProviders(t *testing.T) {
+	type user struct {
+		Tenant *tenant
+	}
+	type loop struct{}
+
+	var loopErr error
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		u, err := mason.Use[*user](ctx)
+		if err != nil {
+			return nil, err
+		}
+		_, loopErr = mason.Use[*loop](ctx)
+		return &Item{Title: u.Tenant.ID}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	mason.Provide(api, func(r *http.Request) (*tenant, error) {
+		return &tenant{ID: r.Header.Get("X-Tenant")}, nil
+	})
+	mason.Provide(api, func(r *http.Request) (*user, error) {
+		tn, err := mason.Use[*tenant](r.Context())
+		if err != nil {
+			return nil, err
+		}
+		return &user{Tenant: tn}, nil
+	})
+	mason.Provide(api, func(r *http.Request) (*loop, error) {
+		return mason.Use[*loop](r.Context())
+	})
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items").WithOpID("get_item"))
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("X-Tenant", "acme")
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"title":"acme"}`+"\n", rec.Body.String())
+	assert.ErrorContains(t, loopErr, "provider of *mason_test.loop depends on itself")
+}
@@ -0,0 +1,194 @@
+package mason
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// QueryDecoder can be implemented by query param field types that are not a single scalar value.
+// DecodeQuery receives the field's json name and struct tag, along with all the query values of the request.
+type QueryDecoder interface {
+	DecodeQuery(name string, tag reflect.StructTag, form url.Values) error
+}
+
+// SortField is a single sort key, e.g. -created_at is {Field: "created_at", Desc: true}.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Sort is a parsed sort=-created_at,name query param. The sortable fields are declared with a struct tag:
+//
+//	Sort mason.Sort `json:"sort" sortable:"created_at,name"`
+type Sort []SortField
+
+var _ QueryDecoder = (*Sort)(nil)
+
+func (s *Sort) DecodeQuery(name string, tag reflect.StructTag, form url.Values) error {
+	value := form.Get(name)
+	if value == "" {
+		value = tag.Get("default")
+	}
+	if value == "" {
+		return nil
+	}
+
+	allowed := SortableFields(tag)
+	errs := []model.FieldError{}
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		field := SortField{Field: strings.TrimPrefix(key, "-"), Desc: strings.HasPrefix(key, "-")}
+		if !slices.Contains(allowed, field.Field) {
+			errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' cannot sort by %s", name, field.Field)})
+			continue
+		}
+		*s = append(*s, field)
+	}
+
+	if len(errs) > 0 {
+		return model.ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// SortableFields returns the fields declared in the sortable struct tag.
+func SortableFields(tag reflect.StructTag) []string {
+	return splitList(tag.Get("sortable"), ",")
+}
+
+// FilterOp is a comparison operator in a filter clause.
+type FilterOp string
+
+const (
+	FilterEq   FilterOp = "eq"
+	FilterNe   FilterOp = "ne"
+	FilterGt   FilterOp = "gt"
+	FilterGte  FilterOp = "gte"
+	FilterLt   FilterOp = "lt"
+	FilterLte  FilterOp = "lte"
+	FilterIn   FilterOp = "in"
+	FilterLike FilterOp = "like"
+)
+
+// FilterClause is a single filter[field][op]=value condition. For FilterIn, Values holds the comma separated values.
+type FilterClause struct {
+	Field  string
+	Op     FilterOp
+	Value  string
+	Values []string
+}
+
+// Filter is a parsed set of filter[field][op]=value query params. filter[field]=value is shorthand for the eq operator.
+// The filterable fields and their operators are declared with a struct tag:
+//
+//	Filter mason.Filter `json:"filter" filterable:"status:eq,in;created_at:gt,lt"`
+type Filter []FilterClause
+
+var _ QueryDecoder = (*Filter)(nil)
+
+func (f *Filter) DecodeQuery(name string, tag reflect.StructTag, form url.Values) error {
+	allowed := FilterableFields(tag)
+	prefix := name + "["
+
+	keys := make([]string, 0)
+	for key := range form {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	errs := []model.FieldError{}
+	for _, key := range keys {
+		field, op, ok := parseFilterKey(strings.TrimPrefix(key, name))
+		if !ok {
+			errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' is not a valid filter", key)})
+			continue
+		}
+
+		ops, ok := allowed[field]
+		if !ok {
+			errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' cannot filter by %s", name, field)})
+			continue
+		}
+		if !slices.Contains(ops, op) {
+			errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' does not support operator %s for %s", name, op, field)})
+			continue
+		}
+
+		value := form.Get(key)
+		clause := FilterClause{Field: field, Op: op, Value: value}
+		if op == FilterIn {
+			clause.Values = splitList(value, ",")
+		}
+		*f = append(*f, clause)
+	}
+
+	if len(errs) > 0 {
+		return model.ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// Get returns the first clause for the field and operator.
+func (f Filter) Get(field string, op FilterOp) (FilterClause, bool) {
+	for _, c := range f {
+		if c.Field == field && c.Op == op {
+			return c, true
+		}
+	}
+
+	return FilterClause{}, false
+}
+
+// FilterableFields returns the fields and operators declared in the filterable struct tag.
+func FilterableFields(tag reflect.StructTag) map[string][]FilterOp {
+	fields := make(map[string][]FilterOp)
+	for _, decl := range splitList(tag.Get("filterable"), ";") {
+		field, opList, found := strings.Cut(decl, ":")
+		if !found {
+			fields[field] = []FilterOp{FilterEq}
+			continue
+		}
+		for _, op := range splitList(opList, ",") {
+			fields[field] = append(fields[field], FilterOp(op))
+		}
+	}
+
+	return fields
+}
+
+// parseFilterKey parses [field][op] or [field].
+func parseFilterKey(key string) (string, FilterOp, bool) {
+	if !strings.HasPrefix(key, "[") || !strings.HasSuffix(key, "]") {
+		return "", "", false
+	}
+
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(key, "["), "]"), "][")
+	switch len(parts) {
+	case 1:
+		return parts[0], FilterEq, parts[0] != ""
+	case 2:
+		return parts[0], FilterOp(parts[1]), parts[0] != "" && parts[1] != ""
+	default:
+		return "", "", false
+	}
+}
+
+func splitList(s string, sep string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
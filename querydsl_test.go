@@ -0,0 +1,56 @@
+package mason_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+type SearchParams struct {
+	Sort   mason.Sort   `json:"sort" sortable:"created_at,name"`
+	Filter mason.Filter `json:"filter" filterable:"status:eq,in;created_at:gt,lt"`
+}
+
+func TestSortAndFilter(t *testing.T) {
+	t.Run("parses sort and filter", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?sort=-created_at,name&filter[status][in]=a,b&filter[created_at][gt]=2025-01-01", nil)
+
+		params, err := mason.DecodeQueryParams[SearchParams](req)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, mason.Sort{{Field: "created_at", Desc: true}, {Field: "name"}}, params.Sort)
+		assert.DeepEqual(t, mason.Filter{
+			{Field: "created_at", Op: mason.FilterGt, Value: "2025-01-01"},
+			{Field: "status", Op: mason.FilterIn, Value: "a,b", Values: []string{"a", "b"}},
+		}, params.Filter)
+	})
+
+	t.Run("shorthand equality filter", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?filter[status]=active", nil)
+
+		params, err := mason.DecodeQueryParams[SearchParams](req)
+		assert.NilError(t, err)
+
+		clause, ok := params.Filter.Get("status", mason.FilterEq)
+		assert.Assert(t, ok)
+		assert.Equal(t, "active", clause.Value)
+	})
+
+	for _, query := range []string{
+		"sort=title",
+		"filter[title]=x",
+		"filter[status][gt]=x",
+		"filter[status][eq][x]=y",
+	} {
+		t.Run("rejects "+query, func(t *testing.T) {
+			_, err := mason.DecodeQueryParams[SearchParams](httptest.NewRequest("GET", "/?"+query, nil))
+
+			var fe model.ValidationError
+			assert.Assert(t, errors.As(err, &fe))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package mason
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+type queryOptions struct {
+	lenient      bool
+	onMatch      func(r *http.Request, key, param string)
+	onDeprecated []func(r *http.Request, alias, param string)
+}
+
+type QueryOption func(options *queryOptions)
+
+// LenientQueryKeys matches the query keys that differ from the json tag of a param only in case, underscores or
+// dashes, e.g. PageSize, pageSize or page-size for page_size, to ease the migrations from legacy APIs. The exact keys
+// take precedence. onMatch, if not nil, is called for every key matched this way, e.g. to log a deprecation warning.
+func LenientQueryKeys(onMatch func(r *http.Request, key, param string)) QueryOption {
+	return func(options *queryOptions) {
+		options.lenient = true
+		options.onMatch = onMatch
+	}
+}
+
+// OnDeprecatedQueryParam calls fn for every query key decoded under a deprecated alias of a param, see the aliases
+// tag of QueryParamTags. The generated handlers use it to set the Deprecation and Warning headers of the response.
+func OnDeprecatedQueryParam(fn func(r *http.Request, alias, param string)) QueryOption {
+	return func(options *queryOptions) {
+		options.onDeprecated = append(options.onDeprecated, fn)
+	}
+}
+
+// deprecatedQueryParams sets the Deprecation header of the response, with a Warning naming the param to use instead,
+// for the requests using a deprecated alias.
+func deprecatedQueryParams(w http.ResponseWriter) QueryOption {
+	return OnDeprecatedQueryParam(func(r *http.Request, alias, param string) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Add("Warning", fmt.Sprintf(`299 - "Query param '%s' is deprecated, use '%s' instead"`, alias, param))
+	})
+}
+
+// resolveQueryAliases renames the keys of the form that are aliases of a param of t to the name of the param, unless
+// the param is set as well, including the deep object params like per_page[x].
+func resolveQueryAliases(t reflect.Type, r *http.Request, options queryOptions) {
+	if t == nil || t.Kind() != reflect.Struct || len(r.Form) == 0 {
+		return
+	}
+
+	aliases := make(map[string]string)
+	forEachQueryField(t, func(tag string, field reflect.StructField) {
+		for _, alias := range ParseQueryParamTags(field.Tag).Aliases {
+			aliases[alias] = tag
+		}
+	})
+	if len(aliases) == 0 {
+		return
+	}
+
+	renamed := make(url.Values)
+	for key, values := range r.Form {
+		alias, rest, _ := strings.Cut(key, "[")
+		param, ok := aliases[alias]
+		if !ok {
+			continue
+		}
+		resolved := param
+		if rest != "" {
+			resolved += "[" + rest
+		}
+		if _, exact := r.Form[resolved]; exact {
+			continue
+		}
+
+		renamed[resolved] = append(renamed[resolved], values...)
+		delete(r.Form, key)
+		for _, fn := range options.onDeprecated {
+			fn(r, alias, param)
+		}
+	}
+	for key, values := range renamed {
+		r.Form[key] = values
+	}
+}
+
+// matchQueryKeys renames the keys of the form that leniently match a param of t to the name of the param, including
+// the deep object params like Filter[status].
+func matchQueryKeys(t reflect.Type, r *http.Request, options queryOptions) {
+	if t == nil || t.Kind() != reflect.Struct || len(r.Form) == 0 {
+		return
+	}
+
+	params := make(map[string]string)
+	forEachQueryField(t, func(tag string, _ reflect.StructField) {
+		params[normalizeQueryKey(tag)] = tag
+	})
+
+	renamed := make(url.Values)
+	for key, values := range r.Form {
+		name, rest, _ := strings.Cut(key, "[")
+		param, ok := params[normalizeQueryKey(name)]
+		if !ok || param == name {
+			continue
+		}
+		matched := param
+		if rest != "" {
+			matched += "[" + rest
+		}
+		if _, exact := r.Form[matched]; exact {
+			continue
+		}
+
+		renamed[matched] = append(renamed[matched], values...)
+		delete(r.Form, key)
+		if options.onMatch != nil {
+			options.onMatch(r, key, param)
+		}
+	}
+	for key, values := range renamed {
+		r.Form[key] = values
+	}
+}
+
+func normalizeQueryKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
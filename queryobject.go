@@ -0,0 +1,43 @@
+package mason
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	queryDecoderType = reflect.TypeOf((*QueryDecoder)(nil)).Elem()
+	queryTimeType    = reflect.TypeOf(time.Time{})
+)
+
+// IsObjectQueryParam reports whether a query param field of type t is an object, serialized as a deepObject, e.g.
+// price[min]=1&price[max]=5 for a struct with min and max fields. The structs decoding themselves, see QueryDecoder,
+// and time.Time are not objects.
+func IsObjectQueryParam(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == queryTimeType {
+		return false
+	}
+
+	return !reflect.PointerTo(t).Implements(queryDecoderType)
+}
+
+// objectQueryForm returns the params of the object param with the name, without the name, e.g. min for price[min].
+func objectQueryForm(form url.Values, name string) url.Values {
+	sub := make(url.Values)
+	for key, values := range form {
+		rest, ok := strings.CutPrefix(key, name+"[")
+		if !ok {
+			continue
+		}
+		// price[min] is min, and price[range][min] is range[min]
+		field, nested, _ := strings.Cut(rest, "]")
+		sub[field+nested] = values
+	}
+
+	return sub
+}
@@ -0,0 +1,109 @@
+package mason
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/tailbits/mason/model"
+)
+
+// WithQuerySchema is implemented by query param types that describe their params with a JSON schema, mirroring
+// model.WithSchema. The schema is an object with one property per param, e.g.
+//
+//	{"type": "object", "properties": {"status": {"type": "string", "enum": ["open", "closed"]}}, "required": ["status"]}
+//
+// DecodeQueryParams validates the params against it, and the generators document it verbatim.
+type WithQuerySchema interface {
+	QuerySchema() []byte
+}
+
+// QuerySchemaOf returns the query schema of a query param type, if it implements WithQuerySchema with a value or a
+// pointer receiver.
+func QuerySchemaOf(queryParams any) ([]byte, bool) {
+	if queryParams == nil {
+		return nil, false
+	}
+	if qs, ok := queryParams.(WithQuerySchema); ok {
+		return qs.QuerySchema(), true
+	}
+	t := reflect.TypeOf(queryParams)
+	if t.Kind() == reflect.Ptr {
+		return nil, false
+	}
+	if qs, ok := reflect.New(t).Interface().(WithQuerySchema); ok {
+		return qs.QuerySchema(), true
+	}
+
+	return nil, false
+}
+
+// querySchemaProperties is the part of a query schema needed to convert the params to JSON.
+type querySchemaProperties struct {
+	Properties map[string]struct {
+		Type  string `json:"type"`
+		Items *struct {
+			Type string `json:"type"`
+		} `json:"items"`
+	} `json:"properties"`
+}
+
+// validateQuerySchema validates the query params against the schema, once converted to a JSON object with the types
+// of the properties.
+func validateQuerySchema(schema []byte, form url.Values) error {
+	var props querySchemaProperties
+	if err := json.Unmarshal(schema, &props); err != nil {
+		return fmt.Errorf("invalid query schema: %w", err)
+	}
+
+	obj := make(map[string]any)
+	for name, prop := range props.Properties {
+		values := form[name]
+		if len(values) == 0 {
+			continue
+		}
+		if prop.Type == "array" {
+			itemType := ""
+			if prop.Items != nil {
+				itemType = prop.Items.Type
+			}
+			items := make([]any, len(values))
+			for i, v := range values {
+				items[i] = queryValue(itemType, v)
+			}
+			obj[name] = items
+			continue
+		}
+		obj[name] = queryValue(prop.Type, values[0])
+	}
+
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	return model.Validate(schema, body)
+}
+
+// queryValue converts a query param to the JSON type of its schema. Values that do not convert are kept as strings,
+// so the validation reports them.
+func queryValue(typ string, v string) any {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,122 @@
+package mason
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// QueryParamTags are the struct tags of a query param field, which both DecodeQueryParams and the generated docs
+// follow, e.g.
+//
+//	Status string `json:"status" enum:"open,closed" default:"open" example:"closed"`
+//	PageSize int `json:"page_size" aliases:"per_page"`
+type QueryParamTags struct {
+	// Default is the value of the param when it is absent, from the default tag.
+	Default string
+	// Enum lists the allowed values of the param, from the comma separated enum tag.
+	Enum []string
+	// Example is an example value of the param, from the example tag.
+	Example string
+	// Required params must be present, from required:"true".
+	Required bool
+	// Deprecated params are still decoded, but marked as deprecated in the docs, from deprecated:"true".
+	Deprecated bool
+	// Aliases are the former names of the param, from the comma separated aliases tag. They are still decoded, but
+	// the responses carry a Deprecation header and the docs mark them as deprecated, so renames can be rolled out
+	// gradually.
+	Aliases []string
+}
+
+// ParseQueryParamTags reads the tags of a query param field.
+func ParseQueryParamTags(tag reflect.StructTag) QueryParamTags {
+	return QueryParamTags{
+		Default:    tag.Get("default"),
+		Enum:       splitList(tag.Get("enum"), ","),
+		Example:    tag.Get("example"),
+		Required:   tag.Get("required") == "true",
+		Deprecated: tag.Get("deprecated") == "true",
+		Aliases:    splitList(tag.Get("aliases"), ","),
+	}
+}
+
+// checkQueryParamTags checks the query params against the required and enum tags of the fields of t, including the
+// fields of the object params that are present.
+func checkQueryParamTags(t reflect.Type, form url.Values) error {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	errs := queryParamTagErrors(t, form, "")
+	if len(errs) > 0 {
+		res := model.ValidationError{Errors: errs}
+		model.SortErrors(&res)
+		return res
+	}
+
+	return nil
+}
+
+// queryParamTagErrors checks the params of t, whose names are prefixed like price[min] for the fields of an object
+// param.
+func queryParamTagErrors(t reflect.Type, form url.Values, prefix string) []model.FieldError {
+	errs := []model.FieldError{}
+	forEachQueryField(t, func(tag string, field reflect.StructField) {
+		name := tag
+		if prefix != "" {
+			name = prefix + "[" + tag + "]"
+		}
+
+		tags := ParseQueryParamTags(field.Tag)
+		if IsObjectQueryParam(field.Type) {
+			sub := objectQueryForm(form, tag)
+			if len(sub) == 0 {
+				if tags.Required {
+					errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' is required", name)})
+				}
+				return
+			}
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			errs = append(errs, queryParamTagErrors(ft, sub, name)...)
+			return
+		}
+
+		values := form[tag]
+		if len(values) == 0 || values[0] == "" {
+			if tags.Required && tags.Default == "" && !hasDeepObjectParam(form, tag) {
+				errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' is required", name)})
+			}
+			return
+		}
+
+		if len(tags.Enum) == 0 {
+			return
+		}
+		for _, v := range values {
+			if !slices.Contains(tags.Enum, v) {
+				errs = append(errs, model.FieldError{Message: fmt.Sprintf("Param '%s' must be one of %s", name, strings.Join(tags.Enum, ", "))})
+				return
+			}
+		}
+	})
+
+	return errs
+}
+
+// hasDeepObjectParam reports whether the form has a deep object param like filter[status]=x for the name.
+func hasDeepObjectParam(form url.Values, name string) bool {
+	for key := range form {
+		if strings.HasPrefix(key, name+"[") {
+			return true
+		}
+	}
+
+	return false
+}
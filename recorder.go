@@ -0,0 +1,299 @@
+package mason
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tailbits/mason/model"
+)
+
+// DefaultRedactedFields are the JSON fields the Recorder redacts by default.
+var DefaultRedactedFields = []string{"password", "secret", "token", "access_token", "refresh_token", "api_key", "apiKey"}
+
+// redacted replaces the values of the redacted fields in the recorded bodies.
+const redacted = model.Redacted
+
+// Sample is a request and response pair recorded for an operation.
+type Sample struct {
+	OperationID string          `json:"operationID"`
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	Request     json.RawMessage `json:"request,omitempty"`
+	Status      int             `json:"status"`
+	Response    json.RawMessage `json:"response,omitempty"`
+}
+
+type recorderOptions struct {
+	maxSamples int
+	redact     []string
+	sensitive  *API
+	sanitize   func(Sample) Sample
+}
+
+type RecorderOption func(*recorderOptions)
+
+// MaxSamples sets how many samples are kept per operation, 5 by default. Later samples are dropped.
+func MaxSamples(n int) RecorderOption {
+	return func(o *recorderOptions) {
+		o.maxSamples = n
+	}
+}
+
+// RedactFields sets the JSON fields, at any depth, whose values are replaced in the recorded bodies. It replaces
+// DefaultRedactedFields.
+func RedactFields(fields ...string) RecorderOption {
+	return func(o *recorderOptions) {
+		o.redact = fields
+	}
+}
+
+// RedactSensitive also redacts the fields the schemas of the operations of the API mark as sensitive, see
+// model.SensitiveKeyword, whatever their names.
+func RedactSensitive(api *API) RecorderOption {
+	return func(o *recorderOptions) {
+		o.sensitive = api
+	}
+}
+
+// SanitizeSamples sets a function that sanitizes the samples after the fields are redacted, e.g. to mask emails.
+func SanitizeSamples(fn func(Sample) Sample) RecorderOption {
+	return func(o *recorderOptions) {
+		o.sanitize = fn
+	}
+}
+
+var _ Middleware = (*Recorder)(nil)
+
+// Recorder is a middleware that records the successful requests and responses of the routes it wraps, so they can
+// be exported as the examples of the spec, or as golden files. It keeps the samples in memory, and is meant for
+// development and test environments, not production traffic.
+type Recorder struct {
+	options recorderOptions
+	mu      sync.Mutex
+	samples map[string][]Sample
+}
+
+func NewRecorder(opts ...RecorderOption) *Recorder {
+	options := recorderOptions{
+		maxSamples: 5,
+		redact:     DefaultRedactedFields,
+		sanitize:   func(s Sample) Sample { return s },
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Recorder{
+		options: options,
+		samples: make(map[string][]Sample),
+	}
+}
+
+func (rec *Recorder) GetHandler(builder Builder) func(WebHandler) WebHandler {
+	return func(next WebHandler) WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			opID := builder.OpID()
+			if rec.full(opID) {
+				return next(ctx, w, r)
+			}
+
+			body, err := readBody(r, true)
+			if err != nil {
+				return err
+			}
+
+			rw := &recordingWriter{header: make(http.Header)}
+			if err := next(ctx, rw, r); err != nil {
+				return err
+			}
+
+			if rw.status() < 300 {
+				reqBody, rspBody := body, rw.body.Bytes()
+				if api := rec.options.sensitive; api != nil {
+					// the bodies that are not JSON fail to redact, and are not recorded anyway
+					reqBody, _ = api.RedactRequest(opID, reqBody)
+					rspBody, _ = api.RedactResponse(opID, rspBody)
+				}
+				rec.add(Sample{
+					OperationID: opID,
+					Method:      r.Method,
+					URL:         rec.redactURL(r.URL),
+					Request:     rec.redact(reqBody),
+					Status:      rw.status(),
+					Response:    rec.redact(rspBody),
+				})
+			}
+			rw.flush(w)
+
+			return nil
+		}
+	}
+}
+
+// Samples returns the samples recorded for an operation, in the order they were recorded.
+func (rec *Recorder) Samples(opID string) []Sample {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return slices.Clone(rec.samples[opID])
+}
+
+// WriteGoldenFiles writes the samples to dir, one JSON file per sample, named after the operation and the number of
+// the sample, e.g. get_item_1.json. The characters of the operation IDs that are not letters, digits, dashes or
+// underscores are replaced by underscores, so the files stay in dir.
+func (rec *Recorder) WriteGoldenFiles(dir string) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	for opID, samples := range rec.samples {
+		for i, sample := range samples {
+			data, err := json.MarshalIndent(sample, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal sample of %s: %w", opID, err)
+			}
+
+			name := filepath.Join(dir, fmt.Sprintf("%s_%d.json", goldenFileName(opID), i+1))
+			if err := os.WriteFile(name, append(data, '\n'), 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (rec *Recorder) full(opID string) bool {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return len(rec.samples[opID]) >= rec.options.maxSamples
+}
+
+func (rec *Recorder) add(sample Sample) {
+	sample = rec.options.sanitize(sample)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if len(rec.samples[sample.OperationID]) < rec.options.maxSamples {
+		rec.samples[sample.OperationID] = append(rec.samples[sample.OperationID], sample)
+	}
+}
+
+// goldenFileName returns the operation ID with the characters that are not letters, digits, dashes or underscores
+// replaced by underscores, e.g. the path separators and the dots.
+func goldenFileName(opID string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, opID)
+}
+
+// redactURL returns the request URI of the URL, with the values of the query params named like the redacted fields
+// replaced.
+func (rec *Recorder) redactURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for key, values := range query {
+		if !rec.redacts(key) {
+			continue
+		}
+		for i := range values {
+			values[i] = redacted
+		}
+		changed = true
+	}
+	if !changed {
+		return u.RequestURI()
+	}
+
+	redactedURL := *u
+	redactedURL.RawQuery = query.Encode()
+	return redactedURL.RequestURI()
+}
+
+// redacts reports whether the values of the field are redacted.
+func (rec *Recorder) redacts(field string) bool {
+	return slices.ContainsFunc(rec.options.redact, func(f string) bool { return strings.EqualFold(f, field) })
+}
+
+// redact replaces the values of the redacted fields of a JSON body. Bodies that are not JSON are not recorded.
+func (rec *Recorder) redact(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil
+	}
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			for k, v := range n {
+				if rec.redacts(k) {
+					n[k] = redactValue(v)
+					continue
+				}
+				walk(v)
+			}
+		case []interface{}:
+			for _, v := range n {
+				walk(v)
+			}
+		}
+	}
+	walk(doc)
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+// redactValue replaces a redacted value with a value of the same type, so the samples still match the schemas: the
+// strings with the redacted marker, the numbers with 0 and the booleans with false. The values of the objects and the
+// arrays are replaced one by one.
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return redacted
+	case float64:
+		return 0
+	case bool:
+		return false
+	case map[string]interface{}:
+		for k, field := range v {
+			v[k] = redactValue(field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,88 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	rec := mason.NewRecorder(mason.MaxSamples(2), mason.RedactFields("title"))
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID("create_item").
+		WithMWs(rec))
+
+	post := func(body string) int {
+		w := httptest.NewRecorder()
+		rtm.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body)))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnprocessableEntity, post(`{}`))
+	assert.Equal(t, http.StatusCreated, post(`{"title": "secret"}`))
+	assert.Equal(t, http.StatusCreated, post(`{"title": "b"}`))
+	assert.Equal(t, http.StatusCreated, post(`{"title": "c"}`))
+
+	samples := rec.Samples("create_item")
+	assert.Equal(t, 2, len(samples))
+	assert.Equal(t, http.StatusCreated, samples[0].Status)
+	assert.Equal(t, "/items", samples[0].URL)
+	assert.Equal(t, `{"title":"[REDACTED]"}`, string(samples[0].Request))
+	assert.Equal(t, `{"title":"[REDACTED]"}`, string(samples[0].Response))
+
+	dir := t.TempDir()
+	assert.NilError(t, rec.WriteGoldenFiles(dir))
+
+	data, err := os.ReadFile(filepath.Join(dir, "create_item_2.json"))
+	assert.NilError(t, err)
+
+	var golden mason.Sample
+	assert.NilError(t, json.Unmarshal(data, &golden))
+	assert.Equal(t, "create_item", golden.OperationID)
+	assert.Equal(t, http.MethodPost, golden.Method)
+}
+
+func TestRecorder_Redaction(t *testing.T) {
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	rec := mason.NewRecorder()
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID(`items\create:v2`).
+		WithMWs(rec))
+
+	w := httptest.NewRecorder()
+	body := `{"title": "a", "secret": {"pin": 1234, "enabled": true, "hint": "b"}}`
+	rtm.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/items?token=abc&page=2", strings.NewReader(body)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	samples := rec.Samples(`items\create:v2`)
+	assert.Equal(t, 1, len(samples))
+	assert.Equal(t, "/items?page=2&token=%5BREDACTED%5D", samples[0].URL)
+	assert.Equal(t, `{"secret":{"enabled":false,"hint":"[REDACTED]","pin":0},"title":"a"}`, string(samples[0].Request))
+
+	dir := t.TempDir()
+	assert.NilError(t, rec.WriteGoldenFiles(dir))
+	_, err := os.Stat(filepath.Join(dir, "items_create_v2_1.json"))
+	assert.NilError(t, err)
+}
@@ -0,0 +1,37 @@
+package mason
+
+import (
+	"github.com/tailbits/mason/model"
+)
+
+// Redact replaces the values of the fields of a JSON body that the schema of the entity marks as sensitive with
+// model.Redacted, e.g. before the body is logged or kept in an audit record. See model.SensitiveKeyword.
+func (a *API) Redact(ent model.WithSchema, body []byte) ([]byte, error) {
+	schema, err := a.DereferenceSchema(ent.Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	return model.Redact(schema, body)
+}
+
+// RedactRequest redacts the sensitive fields of a request body of an operation, e.g. in a logging middleware. The
+// bodies of unknown operations, or without an input, are returned as is.
+func (a *API) RedactRequest(opID string, body []byte) ([]byte, error) {
+	op, ok := a.GetOperationByID(opID)
+	if !ok || op.Input == nil || isNilEntity(op.Input) {
+		return body, nil
+	}
+
+	return a.Redact(op.Input, body)
+}
+
+// RedactResponse redacts the sensitive fields of a success response body of an operation, see RedactRequest.
+func (a *API) RedactResponse(opID string, body []byte) ([]byte, error) {
+	op, ok := a.GetOperationByID(opID)
+	if !ok || op.Output == nil || isNilEntity(op.Output) {
+		return body, nil
+	}
+
+	return a.Redact(op.Output, body)
+}
@@ -0,0 +1,76 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+type Credentials struct {
+	Login  string `json:"login"`
+	Secret string `json:"passphrase"`
+}
+
+func (c *Credentials) Example() []byte {
+	return []byte(`{"login": "ada", "passphrase": "hunter22"}`)
+}
+
+func (c *Credentials) Marshal() (json.RawMessage, error) {
+	return json.Marshal(c)
+}
+
+func (c *Credentials) Name() string {
+	return "Credentials"
+}
+
+func (c *Credentials) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"login": {"type": "string"},
+			"passphrase": {"type": "string", "x-sensitive": true}
+		},
+		"required": ["login", "passphrase"]
+	}`)
+}
+
+func (c *Credentials) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, c)
+}
+
+func TestRedactSensitive(t *testing.T) {
+	rotate := func(ctx context.Context, r *http.Request, creds *Credentials, params model.Nil) (*Credentials, error) {
+		return &Credentials{Login: creds.Login, Secret: "rotated"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	rec := mason.NewRecorder(mason.RedactSensitive(api))
+	api.NewRouteGroup("credentials").Register(mason.HandlePost(rotate).
+		Path("/credentials").
+		WithOpID("rotate_credentials").
+		WithMWs(rec))
+
+	w := httptest.NewRecorder()
+	rtm.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/credentials", strings.NewReader(`{"login": "ada", "passphrase": "hunter22"}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	samples := rec.Samples("rotate_credentials")
+	assert.Equal(t, 1, len(samples))
+	assert.Equal(t, `{"login":"ada","passphrase":"[REDACTED]"}`, string(samples[0].Request))
+	assert.Equal(t, `{"login":"ada","passphrase":"[REDACTED]"}`, string(samples[0].Response))
+
+	logged, err := api.RedactRequest("rotate_credentials", []byte(`{"login": "ada", "passphrase": "hunter22"}`))
+	assert.NilError(t, err)
+	assert.Equal(t, `{"login":"ada","passphrase":"[REDACTED]"}`, string(logged))
+	logged, err = api.RedactResponse("unknown", []byte(`{"passphrase": "kept"}`))
+	assert.NilError(t, err)
+	assert.Equal(t, `{"passphrase": "kept"}`, string(logged))
+}
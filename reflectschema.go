@@ -0,0 +1,40 @@
+package mason
+
+import (
+	"reflect"
+
+	"github.com/swaggest/jsonschema-go"
+	"github.com/tailbits/mason/model"
+)
+
+// reflectsSchema reports whether the schema of the model is reflected from the struct of its entity, see
+// model.WithReflectedSchema.
+func (m Model) reflectsSchema() bool {
+	if r, ok := m.WithSchema.(model.WithReflectedSchema); ok && r.ReflectSchema() {
+		return true
+	}
+	if len(m.Schema()) > 0 {
+		return false
+	}
+
+	t := reflect.TypeOf(m.WithSchema)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t != nil && t.Kind() == reflect.Struct
+}
+
+// reflectSchema builds the schema of the entity from its fields and their tags. The schemas of the nested structs are
+// inlined, so their Go type names do not end up as components. The classification tag of a field, e.g.
+// classification:"pii", is documented as its x-data-classification.
+func reflectSchema(ent model.WithSchema) (jsonschema.Schema, error) {
+	var r jsonschema.Reflector
+
+	return r.Reflect(ent, jsonschema.InlineRefs, jsonschema.InterceptProp(func(params jsonschema.InterceptPropParams) error {
+		if c := params.Field.Tag.Get("classification"); params.Processed && c != "" {
+			params.PropertySchema.WithExtraPropertiesItem(model.ClassificationKeyword, c)
+		}
+		return nil
+	}))
+}
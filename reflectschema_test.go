@@ -0,0 +1,98 @@
+package mason_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestModel_ReflectedSchema(t *testing.T) {
+	sch, err := mason.NewModel(&Coupon{}).JSONSchema()
+	assert.NilError(t, err)
+
+	data, err := json.Marshal(sch)
+	assert.NilError(t, err)
+	var got struct {
+		Properties map[string]map[string]any `json:"properties"`
+		Examples   []map[string]any          `json:"examples"`
+	}
+	assert.NilError(t, json.Unmarshal(data, &got))
+
+	assert.Equal(t, float64(3), got.Properties["code"]["minLength"])
+	assert.Equal(t, "^[A-Z0-9]+$", got.Properties["code"]["pattern"])
+	assert.Equal(t, float64(100), got.Properties["percent"]["maximum"])
+	assert.DeepEqual(t, []any{"once", "forever"}, got.Properties["duration"]["enum"])
+	assert.Equal(t, "string", got.Properties["restriction"]["properties"].(map[string]any)["region"].(map[string]any)["type"])
+	assert.DeepEqual(t, []map[string]any{{"code": "SUMMER", "percent": float64(10)}}, got.Examples)
+}
+
+func TestModel_ReflectedSchemaOptIn(t *testing.T) {
+	sch, err := mason.NewModel(&ReflectedCoupon{}).JSONSchema()
+	assert.NilError(t, err)
+
+	code := sch.Properties["code"].TypeObject
+	assert.Assert(t, code != nil)
+	assert.Equal(t, int64(3), code.MinLength)
+
+	// the entities that neither opt in nor lack a schema keep theirs
+	sch, err = mason.NewModel(&Account{}).JSONSchema()
+	assert.NilError(t, err)
+	assert.Assert(t, sch.Properties["iban"].TypeObject.MinLength == 0)
+}
+
+var _ model.Entity = (*Coupon)(nil)
+
+type Coupon struct {
+	Code        string            `json:"code" minLength:"3" pattern:"^[A-Z0-9]+$"`
+	Percent     int               `json:"percent" minimum:"1" maximum:"100"`
+	Duration    string            `json:"duration,omitempty" enum:"once,forever"`
+	Restriction CouponRestriction `json:"restriction,omitempty"`
+}
+
+type CouponRestriction struct {
+	Region string `json:"region"`
+}
+
+func (c *Coupon) Example() []byte {
+	return []byte(`{"code": "SUMMER", "percent": 10}`)
+}
+
+func (c *Coupon) Marshal() (json.RawMessage, error) {
+	return json.Marshal(c)
+}
+
+func (c *Coupon) Name() string {
+	return "Coupon"
+}
+
+func (c *Coupon) Schema() []byte {
+	return nil
+}
+
+func (c *Coupon) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, c)
+}
+
+var (
+	_ model.Entity              = (*ReflectedCoupon)(nil)
+	_ model.WithReflectedSchema = (*ReflectedCoupon)(nil)
+)
+
+type ReflectedCoupon struct {
+	Coupon
+}
+
+func (c *ReflectedCoupon) Name() string {
+	return "ReflectedCoupon"
+}
+
+func (c *ReflectedCoupon) Schema() []byte {
+	return []byte(`{"type": "object", "properties": {"code": {"type": "string"}}}`)
+}
+
+func (c *ReflectedCoupon) ReflectSchema() bool {
+	return true
+}
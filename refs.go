@@ -0,0 +1,288 @@
+package mason
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+)
+
+// maxRefDocumentSize limits the size of documents fetched by HTTPRefResolver.
+const maxRefDocumentSize = 10 << 20
+
+// RefResolver loads the document behind an external $ref, e.g. a URL or a relative file. The ref is passed without
+// its fragment, which is resolved by the caller.
+type RefResolver interface {
+	Resolve(ref string) ([]byte, error)
+}
+
+// RefResolverFunc adapts a function to the RefResolver interface.
+type RefResolverFunc func(ref string) ([]byte, error)
+
+func (f RefResolverFunc) Resolve(ref string) ([]byte, error) {
+	return f(ref)
+}
+
+// FileRefResolver resolves refs to files relative to dir, e.g. a checkout of a shared schema repository.
+// Refs cannot escape dir.
+func FileRefResolver(dir string) RefResolver {
+	fsys := os.DirFS(dir)
+	return RefResolverFunc(func(ref string) ([]byte, error) {
+		name := path.Clean(strings.TrimPrefix(ref, "./"))
+		if !fs.ValidPath(name) {
+			return nil, fmt.Errorf("invalid file ref %s", ref)
+		}
+		return fs.ReadFile(fsys, name)
+	})
+}
+
+// HTTPRefResolver resolves refs to http(s) URLs with the given client, or http.DefaultClient when nil.
+func HTTPRefResolver(client *http.Client) RefResolver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return RefResolverFunc(func(ref string) ([]byte, error) {
+		rsp, err := client.Get(ref)
+		if err != nil {
+			return nil, err
+		}
+		defer rsp.Body.Close()
+
+		if rsp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d fetching %s", rsp.StatusCode, ref)
+		}
+
+		return io.ReadAll(io.LimitReader(rsp.Body, maxRefDocumentSize))
+	})
+}
+
+// WithRefResolver enables external $refs in entity schemas. Only refs starting with one of the allowed prefixes are
+// resolved, e.g. "https://schemas.example.com/" or "shared/"; any other external ref is an error.
+func (a *API) WithRefResolver(resolver RefResolver, allow ...string) *API {
+	a.refResolver = resolver
+	a.refAllow = allow
+	a.derefCache.Clear()
+
+	return a
+}
+
+// ResolveExternalRefs inlines the schemas behind external $refs (URLs and files) as definitions of the schema, and
+// rewrites the refs to point to them. Refs inside the external schemas are resolved relative to their document.
+// The definitions are named after the last segment of the fragment, or else after the file name. Refs to the $id of
+// a registered entity point to its definition instead, and are dereferenced like refs by name. Local refs are left
+// untouched, and the schema is returned as is when it has no external refs.
+func (a *API) ResolveExternalRefs(schema []byte) ([]byte, error) {
+	if len(schema) == 0 {
+		return schema, nil
+	}
+
+	var sch jsonschema.Schema
+	if err := json.Unmarshal(schema, &sch); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: schema[%s] %w", string(schema), err)
+	}
+
+	type pendingSchema struct {
+		schema *jsonschema.Schema
+		// base is the ref of the document the schema comes from, empty for the root schema
+		base string
+	}
+
+	names := make(map[string]string) // absolute ref -> definition name
+	docs := make(map[string]interface{})
+	queue := []pendingSchema{{schema: &sch}}
+	byID := false
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		// refs are collected before resolving them, as resolving adds definitions to the root schema
+		refs := []*string{}
+		walkRefs(current.schema, func(ref *string) {
+			refs = append(refs, ref)
+		})
+
+		for _, ref := range refs {
+			abs, external := absoluteRef(current.base, *ref)
+			if !external {
+				continue
+			}
+			abs = strings.TrimSuffix(abs, "#")
+
+			// registered entities are referenced by $id like by name
+			if name, ok := a.modelNameByID(abs); ok {
+				*ref = "#/definitions/" + name
+				byID = true
+				continue
+			}
+
+			name, ok := names[abs]
+			if !ok {
+				ext, err := a.loadRef(abs, docs)
+				if err != nil {
+					return nil, err
+				}
+
+				name = refName(abs)
+				if _, exists := sch.Definitions[name]; exists {
+					return nil, fmt.Errorf("external schema %s conflicts with definition %s", abs, name)
+				}
+				names[abs] = name
+
+				sch.WithDefinitionsItem(name, ext.ToSchemaOrBool())
+				doc, _, _ := strings.Cut(abs, "#")
+				queue = append(queue, pendingSchema{schema: ext, base: doc})
+			}
+
+			*ref = "#/definitions/" + name
+		}
+	}
+
+	if len(names) == 0 && !byID {
+		return schema, nil
+	}
+
+	return json.Marshal(sch)
+}
+
+// refAllowed reports whether a ref is under an allowed prefix. The URLs must have the scheme and the host of the
+// prefix, and the paths must be under the one of the prefix, at a segment boundary once cleaned, so that neither
+// https://schemas.example.com.evil.com/ nor shared-evil/ nor shared/../secrets/ pass for https://schemas.example.com/
+// or shared/. Opaque refs, such as URNs, are matched by their prefix.
+func refAllowed(ref string, prefix string) bool {
+	r, err := url.Parse(ref)
+	if err != nil {
+		return false
+	}
+	p, err := url.Parse(prefix)
+	if err != nil {
+		return false
+	}
+	if !strings.EqualFold(r.Scheme, p.Scheme) || !strings.EqualFold(r.Host, p.Host) || r.User != nil {
+		return false
+	}
+	if r.Opaque != "" || p.Opaque != "" {
+		return strings.HasPrefix(ref, prefix)
+	}
+
+	refPath, prefixPath := path.Clean("/"+r.Path), path.Clean("/"+p.Path)
+	if prefixPath == "/" {
+		return true
+	}
+
+	return refPath == prefixPath || strings.HasPrefix(refPath, prefixPath+"/")
+}
+
+// loadRef loads the schema behind an absolute ref, following the JSON pointer in its fragment.
+func (a *API) loadRef(ref string, docs map[string]interface{}) (*jsonschema.Schema, error) {
+	if a.refResolver == nil {
+		return nil, fmt.Errorf("external ref %s cannot be resolved: no ref resolver configured", ref)
+	}
+
+	if !slices.ContainsFunc(a.refAllow, func(prefix string) bool { return refAllowed(ref, prefix) }) {
+		return nil, fmt.Errorf("external ref %s is not allowed", ref)
+	}
+
+	docRef, fragment, _ := strings.Cut(ref, "#")
+	doc, ok := docs[docRef]
+	if !ok {
+		raw, err := a.refResolver.Resolve(docRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", docRef, err)
+		}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: document[%s] %w", docRef, err)
+		}
+		docs[docRef] = doc
+	}
+
+	node, err := jsonPointer(doc, fragment)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+
+	raw, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+
+	var sch jsonschema.Schema
+	if err := json.Unmarshal(raw, &sch); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: schema[%s] %w", ref, err)
+	}
+	// definitions of the document are reached through refs relative to it, like any other part of the document
+	sch.Definitions = nil
+	delete(sch.ExtraProperties, "$defs")
+
+	return &sch, nil
+}
+
+// absoluteRef resolves a ref against the document it was found in, and reports whether it is external.
+func absoluteRef(base string, ref string) (string, bool) {
+	if base == "" {
+		return ref, !strings.HasPrefix(ref, "#")
+	}
+
+	if b, err := url.Parse(base); err == nil && b.IsAbs() {
+		if r, err := url.Parse(ref); err == nil {
+			return b.ResolveReference(r).String(), true
+		}
+	}
+
+	refPath, fragment, hasFragment := strings.Cut(ref, "#")
+	abs := base
+	if refPath != "" {
+		abs = path.Join(path.Dir(base), refPath)
+	}
+	if hasFragment {
+		abs += "#" + fragment
+	}
+
+	return abs, true
+}
+
+// refName names the definition of an external schema, e.g. Address for common.json#/definitions/Address.
+func refName(ref string) string {
+	doc, fragment, _ := strings.Cut(ref, "#")
+	if fragment = strings.Trim(fragment, "/"); fragment != "" {
+		segments := strings.Split(fragment, "/")
+		return unescapePointer(segments[len(segments)-1])
+	}
+
+	name := path.Base(doc)
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// jsonPointer returns the value at the JSON pointer (RFC 6901) in a decoded JSON document.
+func jsonPointer(doc interface{}, pointer string) (interface{}, error) {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return doc, nil
+	}
+
+	node := doc
+	for _, token := range strings.Split(pointer, "/") {
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("pointer /%s does not resolve to a schema", pointer)
+		}
+		node, ok = obj[unescapePointer(token)]
+		if !ok {
+			return nil, fmt.Errorf("pointer /%s not found", pointer)
+		}
+	}
+
+	return node, nil
+}
+
+func unescapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
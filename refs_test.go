@@ -0,0 +1,109 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+const sharedSchemas = `{
+	"definitions": {
+		"Address": {
+			"type": "object",
+			"properties": {
+				"street": {"$ref": "#/definitions/Street"},
+				"country": {"$ref": "countries.json"}
+			},
+			"required": ["street"]
+		},
+		"Street": {"type": "string"}
+	}
+}`
+
+func TestExternalRefs(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "shared"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "shared", "common.json"), []byte(sharedSchemas), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "shared", "countries.json"), []byte(`{"type": "string", "enum": ["DE", "US"]}`), 0o644))
+
+	newAPI := func(allow ...string) *mason.API {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		api.NewRouteGroup("customers").Register(mason.HandlePost(CreateCustomer).Path("/customers").WithOpID("create_customer"))
+		return api.WithRefResolver(mason.FileRefResolver(dir), allow...)
+	}
+
+	t.Run("resolves refs relative to their document", func(t *testing.T) {
+		schema, err := newAPI("shared/").DereferenceSchema((&Customer{}).Schema())
+		assert.NilError(t, err)
+
+		var sch struct {
+			Definitions map[string]json.RawMessage `json:"definitions"`
+		}
+		assert.NilError(t, json.Unmarshal(schema, &sch))
+		for _, name := range []string{"Address", "Street", "countries"} {
+			_, ok := sch.Definitions[name]
+			assert.Assert(t, ok, "missing definition %s", name)
+		}
+
+		assert.NilError(t, model.Validate(schema, []byte(`{"address": {"street": "Main St", "country": "DE"}}`)))
+		assert.ErrorContains(t, model.Validate(schema, []byte(`{"address": {"street": "Main St", "country": "FR"}}`)), "")
+	})
+
+	t.Run("rejects refs that are not allowed", func(t *testing.T) {
+		_, err := newAPI("other/").DereferenceSchema((&Customer{}).Schema())
+		assert.ErrorContains(t, err, "is not allowed")
+	})
+
+	t.Run("matches the allowed prefixes by path segment", func(t *testing.T) {
+		_, err := newAPI("shar").DereferenceSchema((&Customer{}).Schema())
+		assert.ErrorContains(t, err, "is not allowed")
+
+		_, err = newAPI("shared").DereferenceSchema((&Customer{}).Schema())
+		assert.NilError(t, err)
+
+		_, err = newAPI("https://shared/").DereferenceSchema((&Customer{}).Schema())
+		assert.ErrorContains(t, err, "is not allowed")
+	})
+}
+
+func CreateCustomer(ctx context.Context, r *http.Request, in *Customer, _ model.Nil) (*Customer, error) {
+	return in, nil
+}
+
+var _ model.Entity = (*Customer)(nil)
+
+type Customer struct {
+	Address map[string]string `json:"address"`
+}
+
+func (c *Customer) Name() string {
+	return "Customer"
+}
+
+func (c *Customer) Example() []byte {
+	return []byte(`{"address": {"street": "Main St"}}`)
+}
+
+func (c *Customer) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"address": {"$ref": "shared/common.json#/definitions/Address"}
+		}
+	}`)
+}
+
+func (c *Customer) Marshal() (json.RawMessage, error) {
+	return json.Marshal(c)
+}
+
+func (c *Customer) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, c)
+}
@@ -1,19 +1,46 @@
 package mason
 
+import (
+	"maps"
+	"strings"
+)
+
 type Registry map[string]Resource
 
+// Registry returns a copy of the registry, which Extend and Deregister do not change.
 func (a *API) Registry() Registry {
-	return a.registry
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	registry := make(Registry, len(a.registry))
+	for group, resource := range a.registry {
+		registry[group] = maps.Clone(resource)
+	}
+
+	return registry
 }
 
 func (a *API) Operations() []Operation {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	return a.registry.Ops()
 }
 
 func (a *API) GetOperation(method string, path string) (Operation, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	return a.registry.FindOp(method, path)
 }
 
+func (a *API) GetOperationByID(opID string) (Operation, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.registry.FindByOpID(opID)
+}
+
 func (a *API) HasOperation(method string, path string) bool {
 	_, ok := a.GetOperation(method, path)
 	return ok
@@ -23,6 +50,24 @@ func toKey(method string, path string) string {
 	return method + ":" + path
 }
 
+// normalizePath strips the names of the params from a path template, e.g. /users/{id} becomes /users/{}.
+// Wildcard params keep their suffix, so /files/{path...} becomes /files/{...}.
+func normalizePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+		if strings.HasSuffix(seg, "...}") {
+			segments[i] = "{...}"
+			continue
+		}
+		segments[i] = "{}"
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
+
 // TaggedOps returns all models that have all the tags provided
 func (mgm *Registry) TaggedOps(tags ...string) []Operation {
 	models := make([]Operation, 0, len(*mgm)*2)
@@ -56,15 +101,73 @@ func (mgm *Registry) TaggedOps(tags ...string) []Operation {
 	return models
 }
 
+// AddOp adds the operation to the given group, replacing any operation with the same method and path.
+func (mgm *Registry) AddOp(group string, op Operation) {
+	key := toKey(op.Method, op.Path)
+
+	if grp, ok := (*mgm)[group]; ok {
+		grp[key] = op
+
+		return
+	}
+
+	(*mgm)[group] = Resource{
+		key: op,
+	}
+}
+
+// RemoveOp removes the operation of the method and path from the given group, and the group once it is empty.
+func (mgm *Registry) RemoveOp(group string, method string, path string) {
+	grp, ok := (*mgm)[group]
+	if !ok {
+		return
+	}
+
+	delete(grp, toKey(method, path))
+	if len(grp) == 0 {
+		delete(*mgm, group)
+	}
+}
+
+// FindOp returns the operation for the method and path template. Templates match regardless of the names of their
+// params, so /users/{user_id} finds the operation registered as /users/{id}.
 func (mgm *Registry) FindOp(method string, path string) (Operation, bool) {
 	for _, modelGroup := range *mgm {
 		if model, ok := modelGroup[toKey(method, path)]; ok {
 			return model, true
 		}
 	}
+
+	normalized := normalizePath(path)
+	for _, modelGroup := range *mgm {
+		for _, model := range modelGroup {
+			if model.Method == method && normalizePath(model.Path) == normalized {
+				return model, true
+			}
+		}
+	}
+
 	return Operation{}, false
 }
 
+// FindByOpID returns the operation with the given operationID.
+func (mgm *Registry) FindByOpID(opID string) (Operation, bool) {
+	_, op, ok := mgm.findByOpID(opID)
+	return op, ok
+}
+
+// findByOpID returns the operation with the given operationID, and its group.
+func (mgm *Registry) findByOpID(opID string) (string, Operation, bool) {
+	for group, modelGroup := range *mgm {
+		for _, model := range modelGroup {
+			if model.OperationID == opID {
+				return group, model, true
+			}
+		}
+	}
+	return "", Operation{}, false
+}
+
 func (mgm *Registry) Ops() []Operation {
 	var models []Operation
 	for _, modelGroup := range *mgm {
@@ -0,0 +1,405 @@
+package mason
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+// registryVersion is the version of the portable registry format, bumped on incompatible changes.
+const registryVersion = 1
+
+var (
+	_ json.Marshaler   = Registry{}
+	_ json.Unmarshaler = (*Registry)(nil)
+)
+
+type portableRegistry struct {
+	Version int                            `json:"version"`
+	Groups  map[string][]portableOperation `json:"groups"`
+}
+
+type portableOperation struct {
+	OperationID    string                 `json:"operationID,omitempty"`
+	Method         string                 `json:"method"`
+	Path           string                 `json:"path"`
+	Input          *portableEntity        `json:"input,omitempty"`
+	Output         *portableEntity        `json:"output,omitempty"`
+	QueryParams    []portableParam        `json:"queryParams,omitempty"`
+	Description    string                 `json:"description,omitempty"`
+	Summary        string                 `json:"summary,omitempty"`
+	SuccessCode    int                    `json:"successCode,omitempty"`
+	Tags           []string               `json:"tags,omitempty"`
+	Extensions     map[string]interface{} `json:"extensions,omitempty"`
+	FieldSelection bool                   `json:"fieldSelection,omitempty"`
+	NoContent      bool                   `json:"noContent,omitempty"`
+	Cache          *CachePolicy           `json:"cache,omitempty"`
+	Content        *ContentPolicy         `json:"content,omitempty"`
+	PathParams     map[string]PathParam   `json:"pathParams,omitempty"`
+	Timeout        time.Duration          `json:"timeout,omitempty"`
+	ResponseDescs  map[int]string         `json:"responseDescriptions,omitempty"`
+	Errors         []string               `json:"errors,omitempty"`
+	// Representations holds a null entity for raw representations.
+	Representations map[string]*portableEntity `json:"representations,omitempty"`
+	Middlewares     []string                   `json:"middlewares,omitempty"`
+	Visibility      OperationVisibility        `json:"visibility,omitempty"`
+	FeatureFlags    []string                   `json:"featureFlags,omitempty"`
+	Rollout         string                     `json:"rollout,omitempty"`
+	Translations    map[string]Translation     `json:"translations,omitempty"`
+	Owner           string                     `json:"owner,omitempty"`
+	DeprecatedSince time.Time                  `json:"deprecatedSince,omitzero"`
+	BetaSince       time.Time                  `json:"betaSince,omitzero"`
+	SignedURL       bool                       `json:"signedURL,omitempty"`
+}
+
+type portableEntity struct {
+	Name    string          `json:"name"`
+	Schema  json.RawMessage `json:"schema,omitempty"`
+	Example json.RawMessage `json:"example,omitempty"`
+}
+
+// portableParam is a field of a query param struct. The struct tag is kept as is, so the docs, defaults and the
+// sort and filter declarations survive the round trip.
+type portableParam struct {
+	Field string `json:"field"`
+	Type  string `json:"type"`
+	Tag   string `json:"tag"`
+	// Fields are the fields of the object params, see IsObjectQueryParam.
+	Fields []portableParam `json:"fields,omitempty"`
+}
+
+// MarshalJSON exports the registry as a portable artifact: the operations with their metadata, and the schemas and
+// examples of their entities. It can be loaded with UnmarshalJSON by a service that does not have the handlers, e.g.
+// a gateway, a docs portal or a test harness.
+func (mgm Registry) MarshalJSON() ([]byte, error) {
+	reg := portableRegistry{
+		Version: registryVersion,
+		Groups:  make(map[string][]portableOperation, len(mgm)),
+	}
+
+	for group, resource := range mgm {
+		ops := make([]portableOperation, 0, len(resource))
+		for _, op := range resource {
+			pop, err := toPortableOperation(op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", op.Method, op.Path, err)
+			}
+			ops = append(ops, pop)
+		}
+		reg.Groups[group] = ops
+	}
+
+	return json.Marshal(reg)
+}
+
+// UnmarshalJSON loads a registry exported with MarshalJSON. The entities are schema-backed, and keep their data as
+// raw JSON, since the Go types behind them are not available.
+func (mgm *Registry) UnmarshalJSON(data []byte) error {
+	var reg portableRegistry
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return fmt.Errorf("failed to parse registry: %w", err)
+	}
+	if reg.Version != registryVersion {
+		return fmt.Errorf("unsupported registry version %d", reg.Version)
+	}
+
+	if *mgm == nil {
+		*mgm = make(Registry)
+	}
+	for group, ops := range reg.Groups {
+		for _, pop := range ops {
+			op, err := pop.operation()
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", pop.Method, pop.Path, err)
+			}
+			mgm.AddOp(group, op)
+		}
+	}
+
+	return nil
+}
+
+func toPortableOperation(op Operation) (portableOperation, error) {
+	params, err := toPortableParams(op.QueryParams)
+	if err != nil {
+		return portableOperation{}, err
+	}
+
+	return portableOperation{
+		OperationID:     op.OperationID,
+		Method:          op.Method,
+		Path:            op.Path,
+		Input:           toPortableEntity(op.Input),
+		Output:          toPortableEntity(op.Output),
+		QueryParams:     params,
+		Description:     op.Description,
+		Summary:         op.Summary,
+		SuccessCode:     op.SuccessCode,
+		Tags:            op.Tags,
+		Extensions:      op.Extensions,
+		FieldSelection:  op.FieldSelection,
+		NoContent:       op.NoContent,
+		Cache:           op.Cache,
+		Content:         op.Content,
+		PathParams:      op.PathParams,
+		Timeout:         op.Timeout,
+		ResponseDescs:   op.ResponseDescriptions,
+		Errors:          op.Errors,
+		Representations: toPortableRepresentations(op.Representations),
+		Middlewares:     op.Middlewares,
+		Visibility:      op.Visibility,
+		FeatureFlags:    op.FeatureFlags,
+		Rollout:         op.Rollout,
+		Translations:    op.Translations,
+		Owner:           op.Owner,
+		DeprecatedSince: op.DeprecatedSince,
+		BetaSince:       op.BetaSince,
+		SignedURL:       op.SignedURL,
+	}, nil
+}
+
+func (pop portableOperation) operation() (Operation, error) {
+	params, err := queryParamsFromPortable(pop.QueryParams)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	return Operation{
+		OperationID:          pop.OperationID,
+		Method:               pop.Method,
+		Path:                 pop.Path,
+		Input:                pop.Input.entity(),
+		Output:               pop.Output.entity(),
+		QueryParams:          params,
+		Description:          pop.Description,
+		Summary:              pop.Summary,
+		SuccessCode:          pop.SuccessCode,
+		Tags:                 pop.Tags,
+		Extensions:           pop.Extensions,
+		FieldSelection:       pop.FieldSelection,
+		NoContent:            pop.NoContent,
+		Cache:                pop.Cache,
+		Content:              pop.Content,
+		PathParams:           pop.PathParams,
+		Timeout:              pop.Timeout,
+		ResponseDescriptions: pop.ResponseDescs,
+		Errors:               pop.Errors,
+		Representations:      pop.representations(),
+		Middlewares:          pop.Middlewares,
+		Visibility:           pop.Visibility,
+		FeatureFlags:         pop.FeatureFlags,
+		Rollout:              pop.Rollout,
+		Translations:         pop.Translations,
+		Owner:                pop.Owner,
+		DeprecatedSince:      pop.DeprecatedSince,
+		BetaSince:            pop.BetaSince,
+		SignedURL:            pop.SignedURL,
+	}, nil
+}
+
+func toPortableEntity(ent model.Entity) *portableEntity {
+	if ent == nil || reflect.ValueOf(ent).Kind() == reflect.Ptr && reflect.ValueOf(ent).IsNil() {
+		return nil
+	}
+
+	return &portableEntity{
+		Name:    ent.Name(),
+		Schema:  ent.Schema(),
+		Example: ent.Example(),
+	}
+}
+
+func (pe *portableEntity) entity() model.Entity {
+	if pe == nil {
+		return nil
+	}
+	if pe.Name == (model.Nil{}).Name() {
+		return model.Nil{}
+	}
+
+	ent := &RawEntity{name: pe.Name, schema: pe.Schema, example: pe.Example}
+	ent.data = append(ent.data, pe.Example...)
+
+	return ent
+}
+
+func toPortableRepresentations(reps map[string]model.Entity) map[string]*portableEntity {
+	if len(reps) == 0 {
+		return nil
+	}
+
+	portable := make(map[string]*portableEntity, len(reps))
+	for ct, ent := range reps {
+		portable[ct] = toPortableEntity(ent)
+	}
+
+	return portable
+}
+
+func (pop portableOperation) representations() map[string]model.Entity {
+	if len(pop.Representations) == 0 {
+		return nil
+	}
+
+	reps := make(map[string]model.Entity, len(pop.Representations))
+	for ct, pe := range pop.Representations {
+		reps[ct] = pe.entity()
+	}
+
+	return reps
+}
+
+var _ model.Entity = (*RawEntity)(nil)
+
+// RawEntity is an entity loaded from a portable registry. Its data is kept as raw JSON, since there is no Go type
+// behind it.
+type RawEntity struct {
+	name    string
+	schema  []byte
+	example []byte
+	data    json.RawMessage
+}
+
+func (e *RawEntity) Name() string {
+	return e.name
+}
+
+func (e *RawEntity) Schema() []byte {
+	return e.schema
+}
+
+func (e *RawEntity) Example() []byte {
+	return e.example
+}
+
+func (e *RawEntity) Marshal() (json.RawMessage, error) {
+	return e.data, nil
+}
+
+func (e *RawEntity) Unmarshal(data json.RawMessage) error {
+	e.data = append(e.data[:0], data...)
+	return nil
+}
+
+var (
+	sortParamType   = reflect.TypeOf(Sort{})
+	filterParamType = reflect.TypeOf(Filter{})
+	timeParamType   = reflect.TypeOf(time.Time{})
+)
+
+// portableParamTypes maps the types of the portable format to the Go types used when rebuilding a query param struct.
+var portableParamTypes = map[string]reflect.Type{
+	"string":    reflect.TypeOf(""),
+	"integer":   reflect.TypeOf(0),
+	"boolean":   reflect.TypeOf(false),
+	"date-time": timeParamType,
+	"sort":      sortParamType,
+	"filter":    filterParamType,
+}
+
+func toPortableParams(queryParams any) ([]portableParam, error) {
+	if queryParams == nil {
+		return nil, nil
+	}
+	t := reflect.TypeOf(queryParams)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	return toPortableFields(t)
+}
+
+func toPortableFields(t reflect.Type) ([]portableParam, error) {
+	var params []portableParam
+	var err error
+	forEachQueryField(t, func(tag string, field reflect.StructField) {
+		if err != nil {
+			return
+		}
+
+		if IsObjectQueryParam(field.Type) {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			var fields []portableParam
+			if fields, err = toPortableFields(ft); err == nil {
+				params = append(params, portableParam{Field: field.Name, Type: "object", Tag: string(field.Tag), Fields: fields})
+			}
+			return
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		var typ string
+		switch {
+		case ft == sortParamType:
+			typ = "sort"
+		case ft == filterParamType:
+			typ = "filter"
+		case ft == timeParamType:
+			typ = "date-time"
+		case ft.Kind() == reflect.String:
+			typ = "string"
+		case ft.Kind() == reflect.Int:
+			typ = "integer"
+		case ft.Kind() == reflect.Bool:
+			typ = "boolean"
+		default:
+			err = fmt.Errorf("unsupported query param type %v for %q", field.Type, tag)
+			return
+		}
+
+		params = append(params, portableParam{Field: field.Name, Type: typ, Tag: string(field.Tag)})
+	})
+
+	return params, err
+}
+
+// queryParamsFromPortable rebuilds a query param struct, so the params can be documented and decoded like the
+// original ones.
+func queryParamsFromPortable(params []portableParam) (any, error) {
+	if len(params) == 0 {
+		return model.Nil{}, nil
+	}
+
+	t, err := portableStructType(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return reflect.New(t).Elem().Interface(), nil
+}
+
+func portableStructType(params []portableParam) (reflect.Type, error) {
+	fields := make([]reflect.StructField, 0, len(params))
+	for _, p := range params {
+		t, ok := portableParamTypes[p.Type]
+		if p.Type == "object" {
+			var err error
+			if t, err = portableStructType(p.Fields); err != nil {
+				return nil, err
+			}
+			ok = true
+		}
+		if !ok {
+			return nil, fmt.Errorf("unsupported query param type %q for %s", p.Type, p.Field)
+		}
+		fields = append(fields, reflect.StructField{
+			Name: p.Field,
+			Type: t,
+			Tag:  reflect.StructTag(p.Tag),
+		})
+	}
+
+	return reflect.StructOf(fields), nil
+}
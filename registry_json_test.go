@@ -0,0 +1,80 @@
+package mason_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"gotest.tools/v3/assert"
+)
+
+func TestRegistryJSON(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("items").Register(mason.HandlePost(CreateItem).Path("/items").WithOpID("create_item").WithTags("items"))
+	api.NewRouteGroup("items").Register(mason.HandleGet(ListItems).Path("/items").WithOpID("list_items").WithFieldSelection())
+
+	data, err := json.Marshal(api.Registry())
+	assert.NilError(t, err)
+
+	var reg mason.Registry
+	assert.NilError(t, json.Unmarshal(data, &reg))
+
+	create, ok := reg.FindOp(http.MethodPost, "/items")
+	assert.Assert(t, ok)
+	assert.Equal(t, "create_item", create.OperationID)
+	assert.Equal(t, http.StatusCreated, create.SuccessCode)
+	assert.DeepEqual(t, []string{"items"}, create.Tags)
+	assert.Equal(t, "Item", create.Input.Name())
+	var schema bytes.Buffer
+	assert.NilError(t, json.Compact(&schema, (&Item{}).Schema()))
+	assert.Equal(t, schema.String(), string(create.Input.Schema()))
+
+	list, ok := reg.FindOp(http.MethodGet, "/items")
+	assert.Assert(t, ok)
+	assert.Assert(t, list.FieldSelection)
+	assert.Assert(t, list.Input == nil)
+
+	params := reflect.TypeOf(list.QueryParams)
+	assert.Equal(t, reflect.Struct, params.Kind())
+	limit, ok := params.FieldByName("Limit")
+	assert.Assert(t, ok)
+	assert.Equal(t, "limit", limit.Tag.Get("json"))
+	assert.Equal(t, "20", limit.Tag.Get("default"))
+
+	// the loaded registry exports to the same artifact
+	again, err := json.Marshal(reg)
+	assert.NilError(t, err)
+	assert.Equal(t, len(data), len(again))
+}
+
+type priceParams struct {
+	Price priceRange `json:"price"`
+}
+
+func TestRegistryJSON_ObjectParams(t *testing.T) {
+	listByPrice := func(ctx context.Context, r *http.Request, params priceParams) (*Item, error) {
+		return &Item{Title: "first"}, nil
+	}
+
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("items").Register(mason.HandleGet(listByPrice).Path("/items").WithOpID("list_items"))
+
+	data, err := json.Marshal(api.Registry())
+	assert.NilError(t, err)
+
+	var reg mason.Registry
+	assert.NilError(t, json.Unmarshal(data, &reg))
+	list, ok := reg.FindOp(http.MethodGet, "/items")
+	assert.Assert(t, ok)
+
+	price, ok := reflect.TypeOf(list.QueryParams).FieldByName("Price")
+	assert.Assert(t, ok)
+	assert.Assert(t, mason.IsObjectQueryParam(price.Type))
+	minField, ok := price.Type.FieldByName("Min")
+	assert.Assert(t, ok)
+	assert.Equal(t, "true", minField.Tag.Get("required"))
+}
@@ -0,0 +1,177 @@
+package mason
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// Representation is an alternate representation of the response of a route, sent instead of the JSON response when
+// the Accept header of the request prefers its content type, see WithRepresentation.
+type Representation struct {
+	// Entity documents the body of the representation. Representations without an entity are documented as strings.
+	Entity model.Entity
+	// Encode writes the result of the handler in the representation.
+	Encode func(ctx context.Context, w io.Writer, result any) error
+}
+
+// EntityRepresentation represents the result of the handler as another entity, encoded as JSON, e.g. for a vendor
+// specific media type.
+func EntityRepresentation[O model.Entity, E model.Entity](convert func(result O) (E, error)) Representation {
+	return Representation{
+		Entity: model.New[E](),
+		Encode: func(ctx context.Context, w io.Writer, result any) error {
+			o, ok := result.(O)
+			if !ok {
+				return fmt.Errorf("unexpected result of type %T", result)
+			}
+			ent, err := convert(o)
+			if err != nil {
+				return err
+			}
+			return json.NewEncoder(w).Encode(ent)
+		},
+	}
+}
+
+// RawRepresentation represents the result of the handler with a custom encoder, e.g. as CSV.
+func RawRepresentation[O model.Entity](encode func(ctx context.Context, w io.Writer, result O) error) Representation {
+	return Representation{
+		Encode: func(ctx context.Context, w io.Writer, result any) error {
+			o, ok := result.(O)
+			if !ok {
+				return fmt.Errorf("unexpected result of type %T", result)
+			}
+			return encode(ctx, w, o)
+		},
+	}
+}
+
+type representation struct {
+	contentType string
+	Representation
+}
+
+// setRepresentation adds a representation, replacing the one registered for the same content type.
+func (rb *RouteBuilderBase) setRepresentation(contentType string, rep Representation) {
+	if rep.Encode == nil {
+		panic(fmt.Sprintf("representation %s of %s %s has no encoder", contentType, rb.method, rb.path))
+	}
+
+	for i, existing := range rb.representations {
+		if existing.contentType == contentType {
+			rb.representations[i].Representation = rep
+			return
+		}
+	}
+	rb.representations = append(rb.representations, representation{contentType: contentType, Representation: rep})
+}
+
+// representationEntities returns the documented entities of the representations, by content type.
+func (rb *RouteBuilderBase) representationEntities() map[string]model.Entity {
+	if len(rb.representations) == 0 {
+		return nil
+	}
+
+	entities := make(map[string]model.Entity, len(rb.representations))
+	for _, rep := range rb.representations {
+		entities[rep.contentType] = rep.Entity
+	}
+
+	return entities
+}
+
+// negotiate returns the representation preferred by the Accept header of the request, if it is not JSON.
+func (rb *RouteBuilderBase) negotiate(r *http.Request) (representation, bool) {
+	offered := make([]string, 0, len(rb.representations)+1)
+	offered = append(offered, "application/json")
+	for _, rep := range rb.representations {
+		offered = append(offered, rep.contentType)
+	}
+
+	preferred := negotiateContentType(r.Header.Get("Accept"), offered)
+	for _, rep := range rb.representations {
+		if rep.contentType == preferred {
+			return rep, true
+		}
+	}
+
+	return representation{}, false
+}
+
+// respondWith encodes the result in the representation, before writing anything, like HTTPRuntime.Respond.
+func (rb *RouteBuilderBase) respondWith(ctx context.Context, w http.ResponseWriter, rep representation, result any) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := encodeSafely(result, func() error { return rep.Encode(ctx, buf, result) }); err != nil {
+		return fmt.Errorf("failed to encode %s response: %w", rep.contentType, err)
+	}
+
+	w.Header().Set("Content-Type", rep.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(rb.successCode)
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+// negotiateContentType returns the offered content type with the highest quality in the Accept header, or the first
+// one when the header is empty. The quality of a content type is the one of the most specific media range matching
+// it, e.g. text/csv over text/* over */*. Ties are broken by the order of the offers.
+func negotiateContentType(accept string, offered []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offered[0]
+	}
+
+	type mediaRange struct {
+		typ, subtype string
+		q            float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mt)), "/")
+		if !ok {
+			continue
+		}
+
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: quality(params)})
+	}
+
+	best, bestQ := "", 0.0
+	for _, ct := range offered {
+		typ, subtype, _ := strings.Cut(ct, "/")
+
+		q, specificity := 0.0, -1
+		for _, mr := range ranges {
+			var s int
+			switch {
+			case mr.typ == typ && mr.subtype == subtype:
+				s = 2
+			case mr.typ == typ && mr.subtype == "*":
+				s = 1
+			case mr.typ == "*" && mr.subtype == "*":
+				s = 0
+			default:
+				continue
+			}
+			if s > specificity {
+				q, specificity = mr.q, s
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = ct, q
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,61 @@
+package mason_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestRepresentations(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "done"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+		Path("/item").
+		WithOpID("get_item").
+		WithRepresentation("text/csv", mason.RawRepresentation(func(ctx context.Context, w io.Writer, item *Item) error {
+			_, err := fmt.Fprintf(w, "title\n%s\n", item.Title)
+			return err
+		})).
+		WithRepresentation("application/vnd.error+json", mason.EntityRepresentation(func(item *Item) (*model.APIError, error) {
+			return model.NewAPIError("item", item.Title), nil
+		})))
+
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", "application/json", `{"title":"done"}` + "\n"},
+		{"*/*", "application/json", `{"title":"done"}` + "\n"},
+		{"text/csv", "text/csv", "title\ndone\n"},
+		{"application/json;q=0.5, text/*", "text/csv", "title\ndone\n"},
+		{"text/*;q=0.2, application/json", "application/json", `{"title":"done"}` + "\n"},
+		{"application/vnd.error+json", "application/vnd.error+json", `{"code":"item","message":"done"}` + "\n"},
+		{"image/png", "application/json", `{"title":"done"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/item", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			rtm.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+			assert.Equal(t, tt.body, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package mason
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+var _ http.ResponseWriter = (*ResponseWriter)(nil)
+
+// ResponseWriter wraps the http.ResponseWriter passed to the handlers and middlewares of an HTTPRuntime, and records
+// the status and the number of bytes written, e.g. for metrics and access logs. Middlewares can get it with
+// AsResponseWriter.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	written int64
+	// limitExceeded is set when the response exceeds the size limit of its route
+	limitExceeded bool
+}
+
+// NewResponseWriter wraps w, unless it is a ResponseWriter already.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// AsResponseWriter returns the ResponseWriter behind w, if any.
+func AsResponseWriter(w http.ResponseWriter) (*ResponseWriter, bool) {
+	rw, ok := w.(*ResponseWriter)
+	return rw, ok
+}
+
+func (w *ResponseWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+// Status returns the status written, or 0 when the headers have not been written yet.
+func (w *ResponseWriter) Status() int {
+	return w.status
+}
+
+// BytesWritten returns the number of body bytes written.
+func (w *ResponseWriter) BytesWritten() int64 {
+	return w.written
+}
+
+// LimitExceeded reports whether the response exceeded the size limit of its route, see ResponseLimit.
+func (w *ResponseWriter) LimitExceeded() bool {
+	return w.limitExceeded
+}
+
+// Written reports whether the headers have been written, after which the status cannot change anymore.
+func (w *ResponseWriter) Written() bool {
+	return w.status != 0
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	// large buffers are dropped, so a single big response does not stay in memory
+	if buf.Cap() > 64<<10 {
+		return
+	}
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,69 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"gotest.tools/v3/assert"
+)
+
+func TestRespond_EncodeError(t *testing.T) {
+	rtm := mason.NewHTTPRuntime()
+	rtm.Handle(http.MethodGet, "/broken", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-Request-ID", "abc")
+		return rtm.Respond(ctx, w, map[string]any{"fn": func() {}}, http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/broken", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Assert(t, rec.Body.String() != "" && rec.Body.String()[0] != '{')
+}
+
+func TestRespond_ErrorAfterWrite(t *testing.T) {
+	rtm := mason.NewHTTPRuntime()
+	rtm.Handle(http.MethodGet, "/items", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if err := rtm.Respond(ctx, w, &Item{Title: "done"}, http.StatusOK); err != nil {
+			return err
+		}
+		return context.Canceled
+	})
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `{"title":"done"}`+"\n", rec.Body.String())
+}
+
+func TestResponseWriter(t *testing.T) {
+	var status int
+	var written int64
+	metrics := func(next mason.WebHandler) mason.WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			err := next(ctx, w, r)
+			rw, ok := mason.AsResponseWriter(w)
+			assert.Assert(t, ok)
+			status, written = rw.Status(), rw.BytesWritten()
+			return err
+		}
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	rtm.Handle(http.MethodGet, "/items", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return rtm.Respond(ctx, w, &Item{Title: "done"}, http.StatusAccepted)
+	}, metrics)
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, int64(rec.Body.Len()), written)
+	assert.Equal(t, "17", rec.Header().Get("Content-Length"))
+}
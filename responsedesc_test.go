@@ -0,0 +1,39 @@
+package mason_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithResponseDesc(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("items").Register(mason.HandleGet(ListItems).
+		Path("/items").
+		WithOpID("list_items").
+		WithResponseDesc(http.StatusOK, "The items of the page."))
+
+	reg := api.Registry()
+	op, ok := reg.FindOp(http.MethodGet, "/items")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, map[int]string{http.StatusOK: "The items of the page."}, op.ResponseDescriptions)
+
+	// the descriptions survive the registry round trip
+	data, err := json.Marshal(reg)
+	assert.NilError(t, err)
+	var loaded mason.Registry
+	assert.NilError(t, json.Unmarshal(data, &loaded))
+	op, ok = loaded.FindOp(http.MethodGet, "/items")
+	assert.Assert(t, ok)
+	assert.Equal(t, "The items of the page.", op.ResponseDescriptions[http.StatusOK])
+}
+
+func TestDefaultResponseDescription(t *testing.T) {
+	assert.Equal(t, "The resource was created.", mason.DefaultResponseDescription(http.StatusCreated))
+	assert.Equal(t, "The request timed out.", mason.DefaultResponseDescription(http.StatusGatewayTimeout))
+	assert.Equal(t, "I'm a teapot.", mason.DefaultResponseDescription(http.StatusTeapot))
+	assert.Equal(t, "Status 599.", mason.DefaultResponseDescription(599))
+}
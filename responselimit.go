@@ -0,0 +1,204 @@
+package mason
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/tailbits/mason/model"
+)
+
+// Codes of the errors sent instead of the responses exceeding the size limit of their route, see ResponseLimit.
+const (
+	ErrResponseTooLarge  = "response_too_large"
+	ErrResponseTruncated = "response_truncated"
+)
+
+// errResponseLimit is returned by the writes cut at the size limit of the route.
+var errResponseLimit = errors.New("response size limit exceeded")
+
+// ResponseLimitAction is what happens to a response exceeding the size limit of its route.
+type ResponseLimitAction int
+
+const (
+	// LimitLog sends the response as is, and only reports it to the hooks added with OnResponseLimit.
+	LimitLog ResponseLimitAction = iota
+	// LimitTruncate cuts the bodies of unknown size at the limit. The bodies of known size are dropped, as a cut JSON
+	// body cannot be decoded, and a model.APIError with the ErrResponseTruncated code is sent instead, with a 500.
+	LimitTruncate
+	// LimitFail sends a model.APIError with the ErrResponseTooLarge code instead, with a 500.
+	LimitFail
+)
+
+func (a ResponseLimitAction) String() string {
+	switch a {
+	case LimitLog:
+		return "log"
+	case LimitTruncate:
+		return "truncate"
+	case LimitFail:
+		return "fail"
+	default:
+		return fmt.Sprintf("ResponseLimitAction(%d)", int(a))
+	}
+}
+
+// ResponseLimit is the maximum size of the response bodies of a route, protecting against e.g. unbounded list
+// responses. The size of the bodies encoded by the runtime is known before anything is written, so they are replaced
+// as a whole. The bodies of unknown size, e.g. streams, are measured as they are written, and the ones exceeding the
+// limit are cut at the limit by LimitTruncate and LimitFail, since their status is already sent. The File responses of
+// the download routes are not limited, as they are sized by the files themselves.
+type ResponseLimit struct {
+	// MaxBytes is the maximum size of the body. A negative size disables the default limit of the API.
+	MaxBytes int64
+	Action   ResponseLimitAction
+}
+
+// ResponseLimitExceeded is a response exceeding the size limit of its route, reported to the hooks added with
+// OnResponseLimit.
+type ResponseLimitExceeded struct {
+	OperationID string
+	Method      string
+	Path        string
+	Limit       ResponseLimit
+	// Size is the size of the body, or the number of bytes written with the write exceeding the limit for the bodies
+	// of unknown size.
+	Size int64
+	// Partial reports whether the body was of unknown size, and so was partly sent when it exceeded the limit.
+	Partial bool
+}
+
+// ResponseLimitHook observes the responses exceeding the size limit of their route, e.g. to count them per operation
+// in the metrics.
+type ResponseLimitHook func(ctx context.Context, exceeded ResponseLimitExceeded)
+
+// WithDefaultResponseLimit sets the size limit of the responses of the routes that do not set one
+// WithResponseLimit. It applies to the routes registered after it is called.
+func (a *API) WithDefaultResponseLimit(limit ResponseLimit) *API {
+	a.defaultResponseLimit = limit
+	return a
+}
+
+// OnResponseLimit adds a hook observing the responses exceeding the size limit of their route. Hooks run in the order
+// they are added, before the response is replaced or cut.
+func (a *API) OnResponseLimit(hook ResponseLimitHook) *API {
+	a.responseLimitHooks = append(a.responseLimitHooks, hook)
+	return a
+}
+
+// withResponseLimit measures the response of the handler, and enforces the size limit of the route.
+func withResponseLimit(api *API, next WebHandler, rb *RouteBuilderBase) WebHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		lw := &limitWriter{ResponseWriter: w, limit: rb.responseLimit}
+		lw.report = func(size int64, partial bool) {
+			if rw, ok := AsResponseWriter(w); ok {
+				rw.limitExceeded = true
+			}
+
+			exceeded := ResponseLimitExceeded{
+				OperationID: rb.opID,
+				Method:      rb.method,
+				Path:        rb.path,
+				Limit:       rb.responseLimit,
+				Size:        size,
+				Partial:     partial,
+			}
+			for _, hook := range api.responseLimitHooks {
+				hook(ctx, exceeded)
+			}
+		}
+
+		err := next(ctx, lw, r)
+		if lw.held == 0 {
+			return err
+		}
+
+		// the headers of the dropped body do not describe the error
+		for _, h := range []string{"Content-Length", "Content-Disposition", "ETag", "Last-Modified"} {
+			w.Header().Del(h)
+		}
+		w.Header().Set("Cache-Control", "no-store")
+
+		code := ErrResponseTooLarge
+		if rb.responseLimit.Action == LimitTruncate {
+			code = ErrResponseTruncated
+		}
+		msg := fmt.Sprintf("The response exceeds the limit of %d bytes", rb.responseLimit.MaxBytes)
+
+		return api.Respond(ctx, w, model.NewAPIError(code, msg), http.StatusInternalServerError)
+	}
+}
+
+// limitWriter enforces a ResponseLimit on the response written through it.
+type limitWriter struct {
+	http.ResponseWriter
+	limit  ResponseLimit
+	report func(size int64, partial bool)
+
+	wroteHeader bool
+	written     int64
+	exceeded    bool
+	// held is the status of the response whose body is dropped, the error is sent instead
+	held int
+}
+
+func (w *limitWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	size, err := strconv.ParseInt(w.Header().Get("Content-Length"), 10, 64)
+	if err == nil && size > w.limit.MaxBytes {
+		w.exceed(size, false)
+		if w.limit.Action != LimitLog {
+			w.held = code
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *limitWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.held != 0 {
+		return len(b), nil
+	}
+
+	remaining := w.limit.MaxBytes - w.written
+	if int64(len(b)) > remaining {
+		w.exceed(w.written+int64(len(b)), true)
+		if w.limit.Action != LimitLog {
+			if remaining <= 0 {
+				return 0, errResponseLimit
+			}
+			n, err := w.ResponseWriter.Write(b[:remaining])
+			w.written += int64(n)
+			if err != nil {
+				return n, err
+			}
+			return n, errResponseLimit
+		}
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+// exceed reports the response the first time it exceeds the limit.
+func (w *limitWriter) exceed(size int64, partial bool) {
+	if w.exceeded {
+		return
+	}
+	w.exceeded = true
+	w.report(size, partial)
+}
+
+func (w *limitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,174 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+// limitMetrics records whether the responses exceeded their size limit, like a metrics middleware.
+type limitMetrics struct {
+	exceeded []bool
+}
+
+func (m *limitMetrics) GetHandler(builder mason.Builder) func(mason.WebHandler) mason.WebHandler {
+	return func(next mason.WebHandler) mason.WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			err := next(ctx, w, r)
+			rw, ok := mason.AsResponseWriter(w)
+			m.exceeded = append(m.exceeded, ok && rw.LimitExceeded())
+			return err
+		}
+	}
+}
+
+// streamingRuntime writes the responses without a Content-Length, so their size is unknown until they are written.
+type streamingRuntime struct {
+	*mason.HTTPRuntime
+}
+
+func (r streamingRuntime) Respond(ctx context.Context, w http.ResponseWriter, data any, status int) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(data)
+}
+
+func TestResponseLimit(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: r.URL.Query().Get("title")}, nil
+	}
+
+	var reports []mason.ResponseLimitExceeded
+	metrics := &limitMetrics{}
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).OnResponseLimit(func(ctx context.Context, exceeded mason.ResponseLimitExceeded) {
+		reports = append(reports, exceeded)
+	})
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandleGet(getItem).Path("/items/fail").WithOpID("fail").
+		WithResponseLimit(mason.ResponseLimit{MaxBytes: 32, Action: mason.LimitFail}).
+		WithMWs(metrics))
+	grp.Register(mason.HandleGet(getItem).Path("/items/truncate").WithOpID("truncate").
+		WithResponseLimit(mason.ResponseLimit{MaxBytes: 32, Action: mason.LimitTruncate}))
+	grp.Register(mason.HandleGet(getItem).Path("/items/log").WithOpID("log").
+		WithResponseLimit(mason.ResponseLimit{MaxBytes: 32, Action: mason.LimitLog}).
+		WithMWs(metrics))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	apiError := func(rec *httptest.ResponseRecorder) model.APIError {
+		var apiErr model.APIError
+		assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+		return apiErr
+	}
+	long := strings.Repeat("x", 40)
+
+	t.Run("within limit", func(t *testing.T) {
+		reports, metrics.exceeded = nil, nil
+		rec := get("/items/fail?title=short")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"title":"short"}`+"\n", rec.Body.String())
+		assert.Equal(t, 0, len(reports))
+		assert.DeepEqual(t, []bool{false}, metrics.exceeded)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		reports, metrics.exceeded = nil, nil
+		rec := get("/items/fail?title=" + long)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, mason.ErrResponseTooLarge, apiError(rec).Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+		assert.Equal(t, 1, len(reports))
+		assert.Equal(t, "fail", reports[0].OperationID)
+		assert.Equal(t, int64(len(`{"title":""}`+"\n")+len(long)), reports[0].Size)
+		assert.Equal(t, mason.LimitFail, reports[0].Limit.Action)
+		assert.Assert(t, !reports[0].Partial)
+		assert.DeepEqual(t, []bool{true}, metrics.exceeded)
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		reports = nil
+		rec := get("/items/truncate?title=" + long)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, mason.ErrResponseTruncated, apiError(rec).Code)
+		assert.Equal(t, 1, len(reports))
+	})
+
+	t.Run("log", func(t *testing.T) {
+		reports, metrics.exceeded = nil, nil
+		rec := get("/items/log?title=" + long)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"title":"`+long+`"}`+"\n", rec.Body.String())
+		assert.Equal(t, 1, len(reports))
+		assert.DeepEqual(t, []bool{true}, metrics.exceeded)
+	})
+}
+
+func TestResponseLimit_Default(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: strings.Repeat("x", 40)}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).WithDefaultResponseLimit(mason.ResponseLimit{MaxBytes: 32, Action: mason.LimitFail})
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandleGet(getItem).Path("/items").WithOpID("get_items"))
+	grp.Register(mason.HandleGet(getItem).Path("/items/export").WithOpID("export_items").
+		WithResponseLimit(mason.ResponseLimit{MaxBytes: -1}))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec = httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/export", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestResponseLimit_UnknownSize(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: strings.Repeat("x", 40)}, nil
+	}
+
+	var reports []mason.ResponseLimitExceeded
+	rtm := streamingRuntime{mason.NewHTTPRuntime()}
+	api := mason.NewAPI(rtm).OnResponseLimit(func(ctx context.Context, exceeded mason.ResponseLimitExceeded) {
+		reports = append(reports, exceeded)
+	})
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items").WithOpID("get_items").
+		WithResponseLimit(mason.ResponseLimit{MaxBytes: 32, Action: mason.LimitTruncate}))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 32, rec.Body.Len())
+
+	assert.Equal(t, 1, len(reports))
+	assert.Assert(t, reports[0].Partial)
+}
+
+func TestResponseLimit_File(t *testing.T) {
+	export := func(ctx context.Context, r *http.Request, params model.Nil) (*mason.File, error) {
+		return &mason.File{Content: strings.NewReader(strings.Repeat("x", 64)), Filename: "export.csv"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).WithDefaultResponseLimit(mason.ResponseLimit{MaxBytes: 32, Action: mason.LimitFail})
+	api.NewRouteGroup("exports").Register(mason.HandleFile(export).Path("/exports").WithOpID("download_export"))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/exports", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 64, rec.Body.Len())
+}
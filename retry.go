@@ -0,0 +1,80 @@
+package mason
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+// RetryableError is an error the clients can retry after a delay, e.g. of a rate limit, see RetryAfter.
+type RetryableError interface {
+	error
+	RetryDelay() time.Duration
+}
+
+type retryableError struct {
+	error
+	delay time.Duration
+}
+
+func (e retryableError) RetryDelay() time.Duration {
+	return e.delay
+}
+
+func (e retryableError) Unwrap() error {
+	return e.error
+}
+
+// RetryAfter wraps an error of the error catalog with the delay the clients should wait before retrying, e.g. the
+// time left in the window of a rate limit. It overrides the delay of its definition, see ErrorRetryAfter.
+func RetryAfter(err error, delay time.Duration) error {
+	return retryableError{error: err, delay: delay}
+}
+
+// ErrorOption configures an error of the error catalog, see RegisterError.
+type ErrorOption func(*ErrorDef)
+
+// ErrorRetryAfter sets the delay the clients should wait before retrying the errors with the code, unless the error
+// carries its own, see RetryAfter.
+func ErrorRetryAfter(delay time.Duration) ErrorOption {
+	return func(def *ErrorDef) {
+		def.RetryAfter = delay
+	}
+}
+
+// Retryable reports whether the errors with the code can be retried after a delay: the ones with a RetryAfter, the
+// rate limits and the unavailable services. They are documented with the Retry-After header.
+func (def ErrorDef) Retryable() bool {
+	return def.RetryAfter > 0 || def.Status == http.StatusTooManyRequests || def.Status == http.StatusServiceUnavailable
+}
+
+// retryDelay returns the delay before retrying an error of the definition, zero when it has none.
+func (def ErrorDef) retryDelay(err error) time.Duration {
+	var retryable RetryableError
+	if errors.As(err, &retryable) {
+		return retryable.RetryDelay()
+	}
+
+	return def.RetryAfter
+}
+
+// setRetryAfter sets the Retry-After header of an error response, and the retry hint of its body when it is a
+// model.APIError.
+func setRetryAfter(w http.ResponseWriter, body any, delay time.Duration) any {
+	if delay <= 0 {
+		return body
+	}
+
+	seconds := retryAfterSeconds(delay)
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	if apiErr, ok := body.(*model.APIError); ok {
+		hinted := *apiErr
+		hinted.RetryAfter = seconds
+		return &hinted
+	}
+
+	return body
+}
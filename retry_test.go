@@ -0,0 +1,82 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestRetryAfter(t *testing.T) {
+	var handlerErr error
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return nil, handlerErr
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.RegisterError("rate_limited", http.StatusTooManyRequests, &model.APIError{}, mason.ErrorRetryAfter(time.Minute))
+	api.RegisterError("quota_exceeded", http.StatusPaymentRequired, &QuotaError{})
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+		Path("/items/{id}").
+		WithOpID("get_item").
+		WithErrors("rate_limited", "quota_exceeded"))
+
+	get := func() (*httptest.ResponseRecorder, model.APIError) {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+
+		var apiErr model.APIError
+		_ = json.Unmarshal(rec.Body.Bytes(), &apiErr)
+		return rec, apiErr
+	}
+
+	t.Run("delay of the definition", func(t *testing.T) {
+		handlerErr = model.NewAPIError("rate_limited", "Too many requests")
+		rec, apiErr := get()
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+		assert.Equal(t, 60, apiErr.RetryAfter)
+	})
+
+	t.Run("delay of the error", func(t *testing.T) {
+		handlerErr = mason.RetryAfter(model.NewAPIError("rate_limited", "Too many requests"), 1500*time.Millisecond)
+		rec, apiErr := get()
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+		assert.Equal(t, 2, apiErr.RetryAfter)
+	})
+
+	t.Run("error entity", func(t *testing.T) {
+		handlerErr = mason.RetryAfter(&QuotaError{Limit: 10}, time.Hour)
+		rec, _ := get()
+		assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+		assert.Equal(t, "3600", rec.Header().Get("Retry-After"))
+		assert.Equal(t, `{"limit":10}`+"\n", rec.Body.String())
+	})
+
+	t.Run("without delay", func(t *testing.T) {
+		handlerErr = &QuotaError{Limit: 10}
+		rec, _ := get()
+		assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+		assert.Equal(t, "", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("maintenance", func(t *testing.T) {
+		handlerErr = nil
+		api.Maintenance().StartOperation("get_item", 30*time.Second)
+		defer api.Maintenance().StopOperation("get_item")
+
+		rec, apiErr := get()
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+		assert.Equal(t, mason.ErrMaintenance, apiErr.Code)
+		assert.Equal(t, 30, apiErr.RetryAfter)
+	})
+}
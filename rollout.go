@@ -0,0 +1,97 @@
+package mason
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/tailbits/mason/model"
+)
+
+// ErrNotRolledOut is the code of the error sent to the requests to a route whose rollout flag is off, when the API
+// is configured to refuse them with WithRolloutStatus(http.StatusForbidden).
+const ErrNotRolledOut = "not_rolled_out"
+
+// FlagProvider decides whether the flags of the routes are on for a request, e.g. with the SDK of a feature flag
+// service, so a route can be dark launched and rolled out to some users first, see Builder.WithRollout.
+type FlagProvider interface {
+	Enabled(r *http.Request, flag string) bool
+}
+
+// FlagFunc adapts a function to the FlagProvider interface.
+type FlagFunc func(r *http.Request, flag string) bool
+
+func (f FlagFunc) Enabled(r *http.Request, flag string) bool {
+	return f(r, flag)
+}
+
+// WithFlagProvider sets the provider of the rollout flags of the routes. Without one, the routes with a rollout flag
+// are off.
+func (a *API) WithFlagProvider(p FlagProvider) *API {
+	a.flags = p
+	return a
+}
+
+// WithRolloutStatus sets the status of the responses of the routes whose rollout flag is off, either
+// http.StatusNotFound, the default, to hide them, or http.StatusForbidden to refuse them with a model.APIError.
+func (a *API) WithRolloutStatus(status int) *API {
+	a.rolloutStatus = status
+	return a
+}
+
+func WithOperationRollout(flag string) Option {
+	return func(m *Operation) {
+		m.Rollout = flag
+	}
+}
+
+// RouteGate is implemented by the runtimes that can leave the routes that are off for a request out of the methods they
+// list, e.g. in the Allow header of the OPTIONS and 405 responses, so a dark launched route is not revealed by them.
+type RouteGate interface {
+	// Gate leaves the route of the method and path out of the methods listed for the requests it is not enabled for.
+	Gate(method string, path string, enabled func(r *http.Request) bool)
+}
+
+// handle registers the handler of the route on the runtime. The requests to a route whose rollout flag is off are
+// answered before its middlewares run, and the runtime leaves the route out of their allowed methods if it is a
+// RouteGate.
+func (rb *RouteBuilderBase) handle(api *API, h WebHandler) {
+	if rb.rollout == "" {
+		api.Handle(rb.method, rb.path, h, rb.mw...)
+		return
+	}
+
+	gate := func(next WebHandler) WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if ok, err := rb.checkRollout(ctx, api, w, r); !ok {
+				return err
+			}
+			return next(ctx, w, r)
+		}
+	}
+	api.Handle(rb.method, rb.path, h, append([]func(WebHandler) WebHandler{gate}, rb.mw...)...)
+	if g, ok := api.Runtime.(RouteGate); ok {
+		g.Gate(rb.method, rb.path, func(r *http.Request) bool {
+			return rb.rolledOut(api, r)
+		})
+	}
+}
+
+// rolledOut reports whether the rollout flag of the route is on for the request.
+func (rb *RouteBuilderBase) rolledOut(api *API, r *http.Request) bool {
+	return rb.rollout == "" || api.flags != nil && api.flags.Enabled(r, rb.rollout)
+}
+
+// checkRollout responds itself when the rollout flag of the route is off, and returns false.
+func (rb *RouteBuilderBase) checkRollout(ctx context.Context, api *API, w http.ResponseWriter, r *http.Request) (bool, error) {
+	if rb.rolledOut(api, r) {
+		return true, nil
+	}
+
+	if api.rolloutStatus == http.StatusForbidden {
+		err := model.NewAPIError(ErrNotRolledOut, "The operation is not available yet")
+		return false, api.Respond(ctx, w, err, http.StatusForbidden)
+	}
+	http.NotFound(w, r)
+
+	return false, nil
+}
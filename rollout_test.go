@@ -0,0 +1,105 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestRollout(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "launched"}, nil
+	}
+
+	setup := func(provider mason.FlagProvider) (*mason.HTTPRuntime, *mason.API) {
+		rtm := mason.NewHTTPRuntime()
+		api := mason.NewAPI(rtm)
+		if provider != nil {
+			api.WithFlagProvider(provider)
+		}
+		api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/next").WithOpID("get_next_item").WithRollout("next-items"))
+		return rtm, api
+	}
+	get := func(rtm *mason.HTTPRuntime, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items/next", nil)
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// the routes are dark without a provider
+	rtm, _ := setup(nil)
+	assert.Equal(t, http.StatusNotFound, get(rtm, "beta-tester").Code)
+
+	rtm, api := setup(mason.FlagFunc(func(r *http.Request, flag string) bool {
+		return flag == "next-items" && r.Header.Get("X-User") == "beta-tester"
+	}))
+	assert.Equal(t, http.StatusOK, get(rtm, "beta-tester").Code)
+	assert.Equal(t, http.StatusNotFound, get(rtm, "customer").Code)
+
+	api.WithRolloutStatus(http.StatusForbidden)
+	rec := get(rtm, "customer")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	var apiErr model.APIError
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+	assert.Equal(t, mason.ErrNotRolledOut, apiErr.Code)
+
+	assert.Equal(t, "next-items", api.Operations()[0].Rollout)
+}
+
+// countingMW counts the requests reaching the middlewares of a route, like an authentication middleware would.
+type countingMW struct {
+	calls int
+}
+
+func (m *countingMW) GetHandler(builder mason.Builder) func(mason.WebHandler) mason.WebHandler {
+	return func(next mason.WebHandler) mason.WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			m.calls++
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func TestRollout_Gated(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "launched"}, nil
+	}
+	putItem := func(ctx context.Context, r *http.Request, in *Item, params model.Nil) (*Item, error) {
+		return in, nil
+	}
+
+	mw := &countingMW{}
+	rtm := mason.NewHTTPRuntime(mason.WithAutoOptions())
+	api := mason.NewAPI(rtm).WithFlagProvider(mason.FlagFunc(func(r *http.Request, flag string) bool {
+		return r.Header.Get("X-User") == "beta-tester"
+	}))
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandleGet(getItem).Path("/items/next").WithOpID("get_next_item").WithMWs(mw))
+	grp.Register(mason.HandlePut(putItem).Path("/items/next").WithOpID("put_next_item").WithRollout("next-items").WithMWs(mw))
+
+	serve := func(method string, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/items/next", nil)
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPut, "customer").Code)
+	assert.Equal(t, 0, mw.calls)
+
+	assert.Equal(t, "GET, OPTIONS", serve(http.MethodOptions, "customer").Header().Get("Allow"))
+	assert.Equal(t, "GET, OPTIONS, PUT", serve(http.MethodOptions, "beta-tester").Header().Get("Allow"))
+
+	rec := serve(http.MethodDelete, "customer")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, OPTIONS", rec.Header().Get("Allow"))
+}
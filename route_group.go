@@ -1,13 +1,14 @@
 package mason
 
 import (
+	"io/fs"
 	"path"
-
-	"github.com/tailbits/mason/internal/casing"
 )
 
 type RouteGroup struct {
-	name         string
+	name string
+	// defaultName names the group when it has no name, see WithDefaultName
+	defaultName  string
 	rtm          *API
 	parent       *RouteGroup
 	skipValidate bool
@@ -18,20 +19,44 @@ func (g *RouteGroup) Name() string {
 }
 
 func (g *RouteGroup) FullPath() string {
-	if g.name == "" {
+	name := g.name
+	if name == "" {
+		name = g.defaultName
+	}
+
+	return g.fullPath(name)
+}
+
+func (g *RouteGroup) fullPath(name string) string {
+	if name == "" {
 		return ""
 	}
 
-	pth := casing.ToKebabCase(g.name)
+	naming := g.rtm.naming.Groups
+	pth := naming(name)
 	for p := g.parent; p != nil; p = p.parent {
-		pth = path.Join(casing.ToKebabCase(p.name), pth)
+		pth = path.Join(naming(p.name), pth)
 	}
 
 	return pth
 }
 
+// Register registers the route in the group. Routes of a group without a name are grouped after their resource,
+// e.g. item for the routes of an Item entity, see WithDefaultName.
 func (g *RouteGroup) Register(builder Builder) {
-	builder.WithGroup(g.FullPath()).Register(g.rtm)
+	group := g.FullPath()
+	if group == "" {
+		group = g.fullPath(inferGroupName(builder.ResourceID()))
+	}
+
+	builder.WithGroup(group).Register(g.rtm)
+}
+
+// WithDefaultName names the group when it has no name of its own, instead of inferring it from the resource of
+// each route.
+func (g *RouteGroup) WithDefaultName(name string) *RouteGroup {
+	g.defaultName = name
+	return g
 }
 
 func (g *RouteGroup) WithSummary(summary string) *RouteGroup {
@@ -44,6 +69,25 @@ func (g *RouteGroup) WithDescription(description string) *RouteGroup {
 	return g
 }
 
+// WithDescriptionFile sets the description of the group from a Markdown file, e.g. of an embed.FS. It panics when the
+// file cannot be read.
+func (g *RouteGroup) WithDescriptionFile(fsys fs.FS, path string) *RouteGroup {
+	return g.WithDescription(readDescFile(fsys, path))
+}
+
+// WithExtensions sets default extensions for the operations of the group and its nested groups. They are deep merged,
+// with the extensions of nested groups and routes taking precedence.
+func (g *RouteGroup) WithExtensions(ext map[string]any) *RouteGroup {
+	checkExtensionKeys(ext)
+	g.rtm.updateGroupMetadata(g.FullPath(), func(meta *GroupMetadata) {
+		if meta.Extensions == nil {
+			meta.Extensions = make(map[string]any)
+		}
+		mergeExtensions(meta.Extensions, ext)
+	})
+	return g
+}
+
 // SkipRESTValidation relaxes the constraint that all routes in a group must handle the same resource.
 func (g *RouteGroup) SkipRESTValidation(name string) *RouteGroup {
 	if name == "" {
@@ -1,9 +1,14 @@
 package mason_test
 
 import (
+	"context"
+	"io/fs"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
 	"gotest.tools/assert"
 	"gotest.tools/assert/cmp"
 )
@@ -88,6 +93,52 @@ func TestGroup_Nesting(t *testing.T) {
 	})
 }
 
+func TestGroup_Naming(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime()).WithNaming(mason.Naming{Groups: mason.SnakeCase})
+	child := api.NewRouteGroup("UserAccounts").NewRouteGroup("APIKeys")
+
+	assert.Equal(t, "user_accounts/api_keys", child.FullPath())
+}
+
+func TestGroup_DefaultName(t *testing.T) {
+	t.Run("default name", func(t *testing.T) {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		builder := &MockBuilder{resourceID: "UserAccount", operationID: "op"}
+
+		api.NewRouteGroup("").WithDefaultName("Accounts").Register(builder)
+
+		assert.Equal(t, "accounts", builder.groupPath)
+	})
+
+	t.Run("explicit name wins", func(t *testing.T) {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		builder := &MockBuilder{resourceID: "UserAccount", operationID: "op"}
+
+		api.NewRouteGroup("users").WithDefaultName("accounts").Register(builder)
+
+		assert.Equal(t, "users", builder.groupPath)
+	})
+
+	t.Run("inferred from the resource", func(t *testing.T) {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		builder := &MockBuilder{resourceID: "UserAccount", operationID: "op"}
+
+		api.NewRouteGroup("teams").NewRouteGroup("").Register(builder)
+
+		assert.Equal(t, "teams/user-account", builder.groupPath)
+	})
+
+	t.Run("inferred without a group", func(t *testing.T) {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		mason.HandleGet(func(ctx context.Context, r *http.Request, _ model.Nil) (*Item, error) {
+			return &Item{}, nil
+		}).Path("/item").WithOpID("get_item").Register(api)
+
+		_, ok := api.Registry()["item"]
+		assert.Assert(t, ok)
+	})
+}
+
 func TestGroupRegistration(t *testing.T) {
 	entity := &MockEntity{name: "test-resource"}
 
@@ -155,11 +206,41 @@ func (m *MockBuilder) Path(p string) mason.Builder {
 	panic("unimplemented")
 }
 
+// WithResponseDesc implements apiv2.Builder.
+func (m *MockBuilder) WithResponseDesc(status int, desc string) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithVisibility implements apiv2.Builder.
+func (m *MockBuilder) WithVisibility(v mason.OperationVisibility) mason.Builder {
+	panic("unimplemented")
+}
+
+// Visibility implements apiv2.Builder.
+func (m *MockBuilder) Visibility() mason.OperationVisibility {
+	panic("unimplemented")
+}
+
 // RegisterBeta implements apiv2.Builder.
 func (m *MockBuilder) RegisterBeta(api *mason.API) {
 	m.Register(api)
 }
 
+// WithFeatureFlag implements apiv2.Builder.
+func (m *MockBuilder) WithFeatureFlag(flags ...string) mason.Builder {
+	panic("unimplemented")
+}
+
+// FeatureFlags implements apiv2.Builder.
+func (m *MockBuilder) FeatureFlags() []string {
+	panic("unimplemented")
+}
+
+// WithRollout implements apiv2.Builder.
+func (m *MockBuilder) WithRollout(flag string) mason.Builder {
+	panic("unimplemented")
+}
+
 // SkipIf implements apiv2.Builder.
 func (m *MockBuilder) SkipIf(skip bool) mason.Builder {
 	panic("unimplemented")
@@ -200,7 +281,112 @@ func (m *MockBuilder) WithSummary(s string) mason.Builder {
 	panic("unimplemented")
 }
 
+// WithOwner implements apiv2.Builder.
+func (m *MockBuilder) WithOwner(owner string) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithDeprecation implements apiv2.Builder.
+func (m *MockBuilder) WithDeprecation(since time.Time) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithBetaSince implements apiv2.Builder.
+func (m *MockBuilder) WithBetaSince(since time.Time) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithDescFile implements apiv2.Builder.
+func (m *MockBuilder) WithDescFile(fsys fs.FS, path string) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithSummaryLocalized implements apiv2.Builder.
+func (m *MockBuilder) WithSummaryLocalized(summaries map[string]string) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithDescLocalized implements apiv2.Builder.
+func (m *MockBuilder) WithDescLocalized(descs map[string]string) mason.Builder {
+	panic("unimplemented")
+}
+
 // WithTags implements apiv2.Builder.
 func (m *MockBuilder) WithTags(tags ...string) mason.Builder {
 	panic("unimplemented")
 }
+
+// WithFieldSelection implements apiv2.Builder.
+func (m *MockBuilder) WithFieldSelection() mason.Builder {
+	panic("unimplemented")
+}
+
+// WithCache implements apiv2.Builder.
+func (m *MockBuilder) WithCache(policy mason.CachePolicy) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithPurge implements apiv2.Builder.
+func (m *MockBuilder) WithPurge(keys ...string) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithPathParam implements apiv2.Builder.
+func (m *MockBuilder) WithPathParam(name string, param mason.PathParam) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithTimeout implements apiv2.Builder.
+func (m *MockBuilder) WithTimeout(d time.Duration) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithResponseLimit implements apiv2.Builder.
+func (m *MockBuilder) WithResponseLimit(limit mason.ResponseLimit) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithErrors implements apiv2.Builder.
+func (m *MockBuilder) WithErrors(codes ...string) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithValidationOptions implements apiv2.Builder.
+func (m *MockBuilder) WithValidationOptions(opts ...model.ValidationOption) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithContent implements apiv2.Builder.
+func (m *MockBuilder) WithContent(policy mason.ContentPolicy) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithoutBodyValidation implements apiv2.Builder.
+func (m *MockBuilder) WithoutBodyValidation() mason.Builder {
+	panic("unimplemented")
+}
+
+// WithQueryOptions implements apiv2.Builder.
+func (m *MockBuilder) WithQueryOptions(opts ...mason.QueryOption) mason.Builder {
+	panic("unimplemented")
+}
+
+// BeforeDecode implements apiv2.Builder.
+func (m *MockBuilder) BeforeDecode(hook mason.BeforeDecodeHook) mason.Builder {
+	panic("unimplemented")
+}
+
+// AfterEncode implements apiv2.Builder.
+func (m *MockBuilder) AfterEncode(hook mason.AfterEncodeHook) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithExtensionsMap implements apiv2.Builder.
+func (m *MockBuilder) WithExtensionsMap(ext map[string]any) mason.Builder {
+	panic("unimplemented")
+}
+
+// WithRepresentation implements apiv2.Builder.
+func (m *MockBuilder) WithRepresentation(contentType string, rep mason.Representation) mason.Builder {
+	panic("unimplemented")
+}
@@ -0,0 +1,146 @@
+package mason
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RouteInfo describes a registered route, e.g. for startup logs or an admin endpoint.
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	OperationID string   `json:"operationID"`
+	Group       string   `json:"group"`
+	Tags        []string `json:"tags,omitempty"`
+	Middlewares []string `json:"middlewares,omitempty"`
+	SuccessCode int      `json:"successCode"`
+}
+
+// Routes returns the documented routes of the API, sorted by path and method.
+func (a *API) Routes() []RouteInfo {
+	var routes []RouteInfo
+	a.ForEachOperation(func(group string, op Operation) {
+		routes = append(routes, RouteInfo{
+			Method:      op.Method,
+			Path:        op.Path,
+			OperationID: op.OperationID,
+			Group:       group,
+			Tags:        op.Tags,
+			Middlewares: op.Middlewares,
+			SuccessCode: op.SuccessCode,
+		})
+	})
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes
+}
+
+// RoutesHandler serves the routes of the API as JSON, e.g. for a routes admin endpoint. The routes are listed when the
+// handler is called, so it can be registered before the other routes.
+func (a *API) RoutesHandler() WebHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return a.Respond(ctx, w, a.Routes(), http.StatusOK)
+	}
+}
+
+// PrintRoutes writes the routes as a table. When width is positive, the widest columns are truncated so the lines fit
+// in it, e.g. the width of the terminal.
+func PrintRoutes(w io.Writer, routes []RouteInfo, width int) error {
+	rows := [][]string{{"METHOD", "PATH", "CODE", "OPERATION", "GROUP", "TAGS", "MIDDLEWARES"}}
+	for _, r := range routes {
+		rows = append(rows, []string{
+			r.Method,
+			r.Path,
+			strconv.Itoa(r.SuccessCode),
+			r.OperationID,
+			r.Group,
+			strings.Join(r.Tags, ","),
+			strings.Join(r.Middlewares, ","),
+		})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+	if width > 0 {
+		shrinkColumns(widths, width)
+	}
+
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cell = truncate(cell, widths[i])
+			if i < len(row)-1 {
+				cell += strings.Repeat(" ", widths[i]-len([]rune(cell)))
+			}
+			cells[i] = cell
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, "  "), " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// minColumnWidth is the width columns are not shrunk below, so their header stays readable.
+const minColumnWidth = 6
+
+// shrinkColumns narrows the widest columns until the table fits in width, or cannot shrink further. The method and
+// code columns are never shrunk.
+func shrinkColumns(widths []int, width int) {
+	total := 2 * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > width {
+		widest := -1
+		for i, w := range widths {
+			if i == 0 || i == 2 || w <= minColumnWidth {
+				continue
+			}
+			if widest == -1 || w > widths[widest] {
+				widest = i
+			}
+		}
+		if widest == -1 {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// truncate shortens s to n runes, marking the truncation with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// middlewareName names a middleware after its type, e.g. mason.CacheMiddleware.
+func middlewareName(m Middleware) string {
+	t := reflect.TypeOf(m)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String()
+}
@@ -0,0 +1,71 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestRoutes(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "done"}, nil
+	}
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+		Path("/items/{id}").
+		WithOpID("get_item").
+		WithTags("items").
+		WithCache(mason.CachePolicy{MaxAge: 60}).
+		WithMWs(mason.Cache(mason.NewMemoryCacheStore())))
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID("create_item_from_a_very_long_operation_id"))
+	rtm.Handle(http.MethodGet, "/admin/routes", api.RoutesHandler())
+
+	routes := api.Routes()
+	assert.DeepEqual(t, []mason.RouteInfo{
+		{Method: "POST", Path: "/items", OperationID: "create_item_from_a_very_long_operation_id", Group: "items", Tags: []string{}, SuccessCode: 201},
+		{Method: "GET", Path: "/items/{id}", OperationID: "get_item", Group: "items", Tags: []string{"items"}, Middlewares: []string{"mason.CacheMiddleware"}, SuccessCode: 200},
+	}, routes)
+
+	t.Run("print", func(t *testing.T) {
+		var out strings.Builder
+		assert.NilError(t, mason.PrintRoutes(&out, routes, 0))
+		assert.Equal(t, strings.Join([]string{
+			"METHOD  PATH         CODE  OPERATION                                  GROUP  TAGS   MIDDLEWARES",
+			"POST    /items       201   create_item_from_a_very_long_operation_id  items",
+			"GET     /items/{id}  200   get_item                                   items  items  mason.CacheMiddleware",
+			"",
+		}, "\n"), out.String())
+	})
+
+	t.Run("print with width", func(t *testing.T) {
+		var out strings.Builder
+		assert.NilError(t, mason.PrintRoutes(&out, routes, 80))
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			assert.Assert(t, len([]rune(line)) <= 80, line)
+		}
+		assert.Assert(t, strings.Contains(out.String(), "…"))
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/routes", nil))
+
+		want, err := json.Marshal(routes)
+		assert.NilError(t, err)
+		assert.Equal(t, string(want)+"\n", rec.Body.String())
+	})
+}
@@ -0,0 +1,297 @@
+package mason
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+const jsonRPCVersion = "2.0"
+
+// JSON-RPC 2.0 error codes.
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+	RPCServerError    = -32000
+)
+
+type RPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+var (
+	_ Runtime      = (*RPCRuntime)(nil)
+	_ RouteRemover = (*RPCRuntime)(nil)
+	_ RouteGate    = (*RPCRuntime)(nil)
+)
+
+// RPCRuntime wraps a Runtime, and additionally exposes every registered operation as a JSON-RPC 2.0 method
+// named after its operationID. The params of a call are split into query params, path params and the request body,
+// so the same decoding and schema validation runs as for the REST route.
+type RPCRuntime struct {
+	Runtime
+	routes routeTable
+}
+
+func NewRPCRuntime(rtm Runtime) *RPCRuntime {
+	return &RPCRuntime{
+		Runtime: rtm,
+	}
+}
+
+func (r *RPCRuntime) Handle(method string, path string, handler WebHandler, mws ...func(WebHandler) WebHandler) {
+	// the wrapped runtime panics on a route registered twice, which must not replace the one served already
+	r.Runtime.Handle(method, path, handler, mws...)
+	r.routes.add(method, path, handler, mws...)
+}
+
+// Unhandle removes the route from the JSON-RPC methods and from the wrapped runtime, which must be a RouteRemover.
+func (r *RPCRuntime) Unhandle(method string, path string) bool {
+	return r.routes.unhandle(r.Runtime, method, path)
+}
+
+// Gate gates the route on the wrapped runtime, if it is a RouteGate.
+func (r *RPCRuntime) Gate(method string, path string, enabled func(r *http.Request) bool) {
+	gate(r.Runtime, method, path, enabled)
+}
+
+// Unwrap returns the wrapped runtime, which serves the REST requests.
+func (r *RPCRuntime) Unwrap() Runtime {
+	return r.Runtime
+}
+
+// Handler returns the http.Handler that serves JSON-RPC calls, including batches, for the operations on the API.
+func (r *RPCRuntime) Handler(api *API) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var raw json.RawMessage
+		if err := json.NewDecoder(req.Body).Decode(&raw); err != nil {
+			r.write(w, rpcError(nil, RPCParseError, "Parse error", nil))
+			return
+		}
+
+		trimmed := bytes.TrimSpace(raw)
+		if len(trimmed) > 0 && trimmed[0] == '[' {
+			var batch []json.RawMessage
+			if err := json.Unmarshal(trimmed, &batch); err != nil || len(batch) == 0 {
+				r.write(w, rpcError(nil, RPCInvalidRequest, "Invalid Request", nil))
+				return
+			}
+
+			responses := make([]*RPCResponse, 0, len(batch))
+			for _, call := range batch {
+				if rsp := r.call(req.Context(), api, call); rsp != nil {
+					responses = append(responses, rsp)
+				}
+			}
+			if len(responses) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			r.write(w, responses)
+			return
+		}
+
+		rsp := r.call(req.Context(), api, trimmed)
+		if rsp == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		r.write(w, rsp)
+	})
+}
+
+func (r *RPCRuntime) write(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// call executes a single JSON-RPC request. It returns nil for notifications, which get no response.
+func (r *RPCRuntime) call(ctx context.Context, api *API, raw json.RawMessage) *RPCResponse {
+	var req RPCRequest
+	if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != jsonRPCVersion || req.Method == "" {
+		return rpcError(nil, RPCInvalidRequest, "Invalid Request", nil)
+	}
+
+	rsp := r.invoke(ctx, api, req)
+	if req.ID == nil {
+		return nil
+	}
+
+	return rsp
+}
+
+func (r *RPCRuntime) invoke(ctx context.Context, api *API, req RPCRequest) *RPCResponse {
+	op, ok := api.GetOperationByID(req.Method)
+	if !ok {
+		return rpcError(req.ID, RPCMethodNotFound, "Method not found", nil)
+	}
+	handler, ok := r.routes.get(op.Method, op.Path)
+	if !ok {
+		return rpcError(req.ID, RPCMethodNotFound, "Method not found", nil)
+	}
+
+	params := make(map[string]json.RawMessage)
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return rpcError(req.ID, RPCInvalidParams, "Invalid params", "params must be an object")
+		}
+	}
+
+	httpReq, err := toHTTPRequest(ctx, op, params)
+	if err != nil {
+		return rpcError(req.ID, RPCInvalidParams, "Invalid params", err.Error())
+	}
+
+	rec := &recordingWriter{header: make(http.Header)}
+	if err := handler(ctx, rec, httpReq); err != nil {
+		// like for the top-level requests, the errors of the catalog are rendered with their entity, and the other
+		// errors are not revealed, as their message may be internal
+		if def, body, ok := api.matchError(err); ok {
+			return rpcError(req.ID, RPCServerError, http.StatusText(def.Status), body)
+		}
+		var fe model.ValidationError
+		if errors.As(api.translateError(httpReq, err), &fe) {
+			return rpcError(req.ID, RPCInvalidParams, "Invalid params", fe.Errors)
+		}
+		return rpcError(req.ID, RPCInternalError, "Internal error", nil)
+	}
+
+	if rec.status() >= 400 {
+		return rpcError(req.ID, RPCServerError, http.StatusText(rec.status()), json.RawMessage(rec.body.Bytes()))
+	}
+
+	result := bytes.TrimSpace(rec.body.Bytes())
+	if len(result) == 0 {
+		result = []byte("null")
+	}
+
+	return &RPCResponse{JSONRPC: jsonRPCVersion, Result: result, ID: req.ID}
+}
+
+// toHTTPRequest builds the request the REST handler expects, moving path and query params out of the params object.
+func toHTTPRequest(ctx context.Context, op Operation, params map[string]json.RawMessage) (*http.Request, error) {
+	path := op.Path
+	pathValues := make(map[string]string)
+	for _, seg := range strings.Split(op.Path, "/") {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+		name := strings.TrimSuffix(strings.Trim(seg, "{}"), "...")
+		raw, ok := params[name]
+		if !ok {
+			return nil, fmt.Errorf("missing path param %q", name)
+		}
+		delete(params, name)
+
+		value := paramString(raw)
+		pathValues[name] = value
+		path = strings.Replace(path, seg, url.PathEscape(value), 1)
+	}
+
+	query := url.Values{}
+	for _, name := range queryParamNames(op.QueryParams) {
+		if raw, ok := params[name]; ok {
+			setQueryParam(query, name, raw)
+			delete(params, name)
+		}
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	target := path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, op.Method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for name, value := range pathValues {
+		httpReq.SetPathValue(name, value)
+	}
+
+	return httpReq, nil
+}
+
+func queryParamNames(params any) []string {
+	if params == nil {
+		return nil
+	}
+	t := reflect.TypeOf(params)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	names := []string{}
+	forEachQueryField(t, func(tag string, _ reflect.StructField) {
+		names = append(names, tag)
+	})
+
+	return names
+}
+
+// setQueryParam sets a JSON param in the query, with the objects serialized as deep objects, e.g. price[min]=1.
+func setQueryParam(query url.Values, name string, raw json.RawMessage) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err == nil && obj != nil {
+		for key, value := range obj {
+			setQueryParam(query, name+"["+key+"]", value)
+		}
+		return
+	}
+
+	query.Set(name, paramString(raw))
+}
+
+// paramString converts a JSON param to its string form, unquoting strings.
+func paramString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	return string(raw)
+}
+
+func rpcError(id json.RawMessage, code int, msg string, data any) *RPCResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+
+	return &RPCResponse{
+		JSONRPC: jsonRPCVersion,
+		Error:   &RPCError{Code: code, Message: msg, Data: data},
+		ID:      id,
+	}
+}
@@ -0,0 +1,73 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestRPCRuntime(t *testing.T) {
+	rpc := mason.NewRPCRuntime(mason.NewHTTPRuntime())
+	api := mason.NewAPI(rpc)
+	api.NewRouteGroup("items").Register(mason.HandlePost(CreateItem).Path("/items").WithOpID("create_item"))
+
+	call := func(body string) string {
+		req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		rpc.Handler(api).ServeHTTP(rec, req)
+		return strings.TrimSpace(rec.Body.String())
+	}
+
+	t.Run("single call", func(t *testing.T) {
+		got := call(`{"jsonrpc": "2.0", "method": "create_item", "params": {"title": "one"}, "id": 1}`)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":{"title":"one"},"id":1}`, got)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		var rsp mason.RPCResponse
+		assert.NilError(t, json.Unmarshal([]byte(call(`{"jsonrpc": "2.0", "method": "create_item", "params": {"title": 1}, "id": 2}`)), &rsp))
+		assert.Equal(t, mason.RPCInvalidParams, rsp.Error.Code)
+	})
+
+	t.Run("batch with notification and unknown method", func(t *testing.T) {
+		var rsps []mason.RPCResponse
+		assert.NilError(t, json.Unmarshal([]byte(call(`[
+			{"jsonrpc": "2.0", "method": "create_item", "params": {"title": "a"}},
+			{"jsonrpc": "2.0", "method": "nope", "id": "x"}
+		]`)), &rsps))
+		assert.Equal(t, 1, len(rsps))
+		assert.Equal(t, mason.RPCMethodNotFound, rsps[0].Error.Code)
+	})
+}
+
+func TestRPCRuntime_Errors(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		if r.PathValue("id") == "quota" {
+			return nil, &QuotaError{Limit: 10}
+		}
+		return nil, errors.New("connection refused by db-primary:5432")
+	}
+
+	rpc := mason.NewRPCRuntime(mason.NewHTTPRuntime())
+	api := mason.NewAPI(rpc)
+	api.RegisterError("quota_exceeded", http.StatusTooManyRequests, &QuotaError{})
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item").WithErrors("quota_exceeded"))
+
+	call := func(id string) string {
+		body := `{"jsonrpc": "2.0", "method": "get_item", "params": {"id": "` + id + `"}, "id": 1}`
+		rec := httptest.NewRecorder()
+		rpc.Handler(api).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body)))
+		return strings.TrimSpace(rec.Body.String())
+	}
+
+	assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32000,"message":"Too Many Requests","data":{"limit":10}},"id":1}`, call("quota"))
+	assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":1}`, call("db"))
+}
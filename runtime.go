@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
 
 	"github.com/tailbits/mason/model"
 )
@@ -23,56 +25,245 @@ type Runtime interface {
 	WebResponder
 }
 
+// RouteRemover is implemented by the runtimes that can stop serving a route after it is registered, which
+// API.Deregister requires.
+type RouteRemover interface {
+	// Unhandle removes the route of the method and path, and reports whether it was registered.
+	Unhandle(method string, path string) bool
+}
+
+// baseRuntime returns the runtime wrapped by the wrapping runtimes, like BatchRuntime and RPCRuntime, which serve the
+// requests with it.
+func baseRuntime(rtm Runtime) Runtime {
+	for {
+		w, ok := rtm.(interface{ Unwrap() Runtime })
+		if !ok {
+			return rtm
+		}
+		rtm = w.Unwrap()
+	}
+}
+
+// routeTable holds the handlers of the routes registered on a wrapping runtime, like BatchRuntime and RPCRuntime, with
+// their middlewares applied. It is guarded, as Extend and Deregister change the routes while requests are served.
+type routeTable struct {
+	mu     sync.RWMutex
+	routes map[string]WebHandler
+}
+
+func (t *routeTable) add(method string, path string, handler WebHandler, mws ...func(WebHandler) WebHandler) {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.routes == nil {
+		t.routes = make(map[string]WebHandler)
+	}
+	t.routes[toKey(method, path)] = handler
+}
+
+func (t *routeTable) get(method string, path string) (WebHandler, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	handler, ok := t.routes[toKey(method, path)]
+	return handler, ok
+}
+
+func (t *routeTable) remove(method string, path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.routes, toKey(method, path))
+}
+
+// unhandle removes the route from the table, and from the wrapped runtime if it is a RouteRemover.
+func (t *routeTable) unhandle(rtm Runtime, method string, path string) bool {
+	remover, ok := rtm.(RouteRemover)
+	if !ok {
+		return false
+	}
+	t.remove(method, path)
+
+	return remover.Unhandle(method, path)
+}
+
+// gate gates the route on the wrapped runtime, if it is a RouteGate.
+func gate(rtm Runtime, method string, path string, enabled func(r *http.Request) bool) {
+	if g, ok := rtm.(RouteGate); ok {
+		g.Gate(method, path, enabled)
+	}
+}
+
 // ==========================================================================
 // HTTPRuntime is a concrete implementation of the Runtime interface for HTTP-based applications.
 
-var _ Runtime = (*HTTPRuntime)(nil)
+var (
+	_ Runtime      = (*HTTPRuntime)(nil)
+	_ RouteRemover = (*HTTPRuntime)(nil)
+	_ RouteGate    = (*HTTPRuntime)(nil)
+)
 
 type HTTPRuntime struct {
 	*http.ServeMux
+	trailingSlash   TrailingSlash
+	caseInsensitive bool
+	autoHead        bool
+	autoOptions     bool
+	// methods are the methods registered for each route path
+	methods map[string][]string
+	// paths are the registered route paths, used to match requests that the mux would not match as is
+	paths []string
+	// mu guards the routes, which can be added and removed while requests are served
+	mu sync.RWMutex
+	// handlers are the handlers of the routes, by mux pattern. The mux cannot unregister a pattern, so it dispatches
+	// to the current handler of the pattern, and the requests of a removed route get a 404.
+	handlers map[string]http.HandlerFunc
+	// patterns are the patterns registered on the mux, including the ones of removed routes
+	patterns map[string]bool
+	// gates report whether the routes with a rollout flag are on for a request, by mux pattern
+	gates map[string]func(req *http.Request) bool
 }
 
 func (r *HTTPRuntime) Handle(method string, path string, handler WebHandler, mws ...func(WebHandler) WebHandler) {
-	r.HandleFunc(fmt.Sprintf("%s %s", method, path), func(w http.ResponseWriter, req *http.Request) {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+
+	serve := func(w http.ResponseWriter, req *http.Request) {
 		if req.Method != method {
-			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
-			return
+			if !r.autoHead || method != http.MethodGet || req.Method != http.MethodHead {
+				r.methodNotAllowed(w, req, r.requestAllowedMethods(req))
+				return
+			}
+
+			hw := &headWriter{ResponseWriter: w}
+			defer hw.flush()
+			w = hw
 		}
 
+		rw := NewResponseWriter(w)
 		ctx := req.Context()
-		if err := handler(ctx, w, req); err != nil {
+		if err := handler(ctx, rw, req); err != nil {
+			// the response is already on its way, so the error cannot change it anymore
+			if rw.Written() {
+				return
+			}
+
 			var fe model.ValidationError
 			if errors.As(err, &fe) {
 				// Return well-formatted validation errors
-				if err := r.Respond(ctx, w, fe, http.StatusUnprocessableEntity); err != nil {
-					http.Error(w, err.Error(), http.StatusInternalServerError)
+				if err := r.Respond(ctx, rw, fe, http.StatusUnprocessableEntity); err != nil {
+					http.Error(rw, err.Error(), http.StatusInternalServerError)
 				}
 
 				return
 			}
 
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
 		}
-	})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the pattern is registered on the mux first, as the mux panics on conflicting patterns. A route served already is
+	// not replaced, like the mux refuses a pattern registered twice, so a failed API.Extend leaves it as it was.
+	pattern := fmt.Sprintf("%s %s", method, path)
+	if _, ok := r.handlers[pattern]; ok {
+		panic(fmt.Sprintf("route %s is already registered", pattern))
+	}
+	if !r.patterns[pattern] {
+		r.HandleFunc(pattern, func(w http.ResponseWriter, req *http.Request) {
+			r.mu.RLock()
+			serve, ok := r.handlers[pattern]
+			r.mu.RUnlock()
+
+			if !ok {
+				// the route was removed, but the path may still have routes for other methods
+				if allowed := r.requestAllowedMethods(req); allowed != nil {
+					r.methodNotAllowed(w, req, allowed)
+					return
+				}
+				http.NotFound(w, req)
+				return
+			}
+			serve(w, req)
+		})
+	}
+
+	r.addRoute(method, path)
+	if r.handlers == nil {
+		r.handlers = make(map[string]http.HandlerFunc)
+		r.patterns = make(map[string]bool)
+	}
+	r.handlers[pattern] = serve
+	r.patterns[pattern] = true
 }
 
+// Unhandle removes the route of the method and path. Its requests are answered with a 405 when the path has routes
+// for other methods, and with a 404 otherwise. The route can be registered again.
+func (r *HTTPRuntime) Unhandle(method string, path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pattern := fmt.Sprintf("%s %s", method, path)
+	if _, ok := r.handlers[pattern]; !ok {
+		return false
+	}
+	delete(r.handlers, pattern)
+	delete(r.gates, pattern)
+	r.removeRoute(method, path)
+
+	return true
+}
+
+// Gate leaves the route of the method and path out of the allowed methods of the requests it is not enabled for.
+func (r *HTTPRuntime) Gate(method string, path string, enabled func(req *http.Request) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.gates == nil {
+		r.gates = make(map[string]func(req *http.Request) bool)
+	}
+	r.gates[fmt.Sprintf("%s %s", method, path)] = enabled
+}
+
+// Respond encodes the data into a buffer before writing anything, so an encoding error leaves the response untouched,
+// and can be reported with a proper error status instead of a half-written JSON body. The errors and the panics of
+// the encoder, e.g. of a broken MarshalJSON, are returned as an EncodeError.
 func (r *HTTPRuntime) Respond(ctx context.Context, w http.ResponseWriter, data any, status int) error {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+	if data == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		return nil
+	}
 
-	if data != nil {
-		if err := json.NewEncoder(w).Encode(data); err != nil {
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusInternalServerError)
-			return fmt.Errorf("failed to encode response data: %w", err)
-		}
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := encodeSafely(data, func() error { return json.NewEncoder(buf).Encode(data) }); err != nil {
+		return err
 	}
 
-	return nil
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(status)
+	_, err := w.Write(buf.Bytes())
+
+	return err
 }
 
-func NewHTTPRuntime() *HTTPRuntime {
-	return &HTTPRuntime{
+func NewHTTPRuntime(opts ...HTTPRuntimeOption) *HTTPRuntime {
+	rtm := &HTTPRuntime{
 		ServeMux: http.NewServeMux(),
 	}
+	for _, opt := range opts {
+		opt(rtm)
+	}
+
+	return rtm
 }
@@ -0,0 +1,266 @@
+// Package schemabundle exports the schemas of the entities of a mason API as a standalone JSON Schema bundle, for the
+// consumers that validate payloads without an OpenAPI spec, e.g. queue consumers and data pipelines.
+package schemabundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/tailbits/mason"
+)
+
+// DefaultDialect is the dialect of the single file bundle, which holds the schemas in its $defs.
+const DefaultDialect = "https://json-schema.org/draft/2020-12/schema"
+
+const definitionsPrefix = "#/definitions/"
+
+// Bundle holds the self-contained schemas of the registered entities, and of the definitions they share, by name.
+type Bundle struct {
+	schemas map[string]map[string]any
+	dialect string
+	id      string
+}
+
+type config struct {
+	dialect string
+	id      string
+}
+
+type exportOption func(*config)
+
+// Dialect sets the $schema of the single file bundle, DefaultDialect by default.
+func Dialect(uri string) exportOption {
+	return func(c *config) {
+		c.dialect = uri
+	}
+}
+
+// ID sets the $id of the single file bundle.
+func ID(uri string) exportOption {
+	return func(c *config) {
+		c.id = uri
+	}
+}
+
+// Export collects the schemas of the entities registered on the API. Their external refs and the entities they refer
+// to are resolved like for validation, see mason.API.DereferenceSchema, and the definitions of a schema that are not
+// registered entities are exported next to them. The refs to the $id of an entity are replaced with refs to its schema
+// in the bundle. Two schemas defining the same name differently is an error.
+func Export(api *mason.API, opts ...exportOption) (*Bundle, error) {
+	cfg := config{dialect: DefaultDialect}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	b := &Bundle{
+		schemas: make(map[string]map[string]any),
+		dialect: cfg.dialect,
+		id:      cfg.id,
+	}
+
+	names := api.ModelNames()
+	registered := make(map[string]bool, len(names))
+	for _, name := range names {
+		registered[name] = true
+	}
+
+	for _, name := range names {
+		ent, _ := api.GetModel(name)
+		if len(ent.Schema()) == 0 {
+			continue
+		}
+
+		raw, err := api.DereferenceSchema(ent.Schema())
+		if err != nil {
+			return nil, fmt.Errorf("entity %s: %w", name, err)
+		}
+		var schema map[string]any
+		if err := json.Unmarshal(raw, &schema); err != nil {
+			return nil, fmt.Errorf("entity %s: %w", name, err)
+		}
+
+		defs, _ := schema["definitions"].(map[string]any)
+		delete(schema, "definitions")
+		localizeIDRefs(api, schema)
+		if err := b.add(name, schema); err != nil {
+			return nil, err
+		}
+
+		for defName, def := range defs {
+			defSchema, ok := def.(map[string]any)
+			if !ok || registered[defName] {
+				continue
+			}
+			localizeIDRefs(api, defSchema)
+			if err := b.add(defName, defSchema); err != nil {
+				return nil, fmt.Errorf("entity %s: %w", name, err)
+			}
+		}
+	}
+
+	return b, nil
+}
+
+// localizeIDRefs replaces the refs to the $id of registered entities with refs to their definition, and removes the $id
+// of the schema, which would change the base of the relative refs of the bundle.
+func localizeIDRefs(api *mason.API, schema map[string]any) {
+	delete(schema, "$id")
+	walkRefs(schema, func(ref string) string {
+		if strings.HasPrefix(ref, "#") {
+			return ref
+		}
+		id, fragment, _ := strings.Cut(ref, "#")
+		ent, ok := api.GetModelByID(id)
+		if !ok {
+			return ref
+		}
+		return definitionsPrefix + ent.Name() + fragment
+	})
+}
+
+func (b *Bundle) add(name string, schema map[string]any) error {
+	if existing, ok := b.schemas[name]; ok && !reflect.DeepEqual(existing, schema) {
+		return fmt.Errorf("definition %s is defined differently by several schemas", name)
+	}
+	b.schemas[name] = schema
+
+	return nil
+}
+
+// Names returns the names of the schemas of the bundle, sorted.
+func (b *Bundle) Names() []string {
+	names := make([]string, 0, len(b.schemas))
+	for name := range b.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Schema returns a copy of the schema with the name, e.g. to convert it to another format. Its refs to the other
+// schemas of the bundle are of the form #/definitions/<name>.
+func (b *Bundle) Schema(name string) (map[string]any, bool) {
+	schema, ok := b.schemas[name]
+	if !ok {
+		return nil, false
+	}
+
+	return clone(schema), true
+}
+
+// Marshal returns the bundle as a single indented JSON Schema document, holding the schemas in its $defs. The
+// $schema keywords of the schemas are left out, as the document declares the dialect.
+func (b *Bundle) Marshal() ([]byte, error) {
+	defs := make(map[string]any, len(b.schemas))
+	for name, schema := range b.schemas {
+		s := clone(schema)
+		delete(s, "$schema")
+		walkRefs(s, func(ref string) string {
+			if target, ok := strings.CutPrefix(ref, definitionsPrefix); ok {
+				return "#/$defs/" + target
+			}
+			if rest, ok := strings.CutPrefix(ref, "#"); ok {
+				// refs relative to the schema itself, e.g. recursive ones
+				return "#/$defs/" + name + rest
+			}
+			return ref
+		})
+		defs[name] = s
+	}
+
+	doc := map[string]any{
+		"$schema": b.dialect,
+		"$defs":   defs,
+	}
+	if b.id != "" {
+		doc["$id"] = b.id
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Files returns the bundle as one indented JSON Schema document per schema, named after it, e.g. Item.json. The
+// schemas refer to each other with relative refs, e.g. Item.json#/properties/id.
+func (b *Bundle) Files() (map[string][]byte, error) {
+	files := make(map[string][]byte, len(b.schemas))
+	for name, schema := range b.schemas {
+		s := clone(schema)
+		walkRefs(s, func(ref string) string {
+			target, ok := strings.CutPrefix(ref, definitionsPrefix)
+			if !ok {
+				return ref
+			}
+			if i := strings.Index(target, "/"); i >= 0 {
+				return fileName(target[:i]) + "#" + target[i:]
+			}
+			return fileName(target)
+		})
+
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		files[fileName(name)] = data
+	}
+
+	return files, nil
+}
+
+// WriteDir writes the Files of the bundle to the directory, which is created if needed.
+func (b *Bundle) WriteDir(dir string) error {
+	files, err := b.Files()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fileName(name string) string {
+	return name + ".json"
+}
+
+func clone(schema map[string]any) map[string]any {
+	data, _ := json.Marshal(schema)
+	var c map[string]any
+	_ = json.Unmarshal(data, &c)
+
+	return c
+}
+
+// walkRefs replaces the refs of the schema and its subschemas with the result of f. The values of the keywords
+// holding instances, like examples, are left as they are.
+func walkRefs(v any, f func(string) string) {
+	switch val := v.(type) {
+	case map[string]any:
+		for key, sub := range val {
+			switch key {
+			case "$ref":
+				if ref, ok := sub.(string); ok {
+					val[key] = f(ref)
+				}
+			case "examples", "example", "default", "enum", "const":
+			default:
+				walkRefs(sub, f)
+			}
+		}
+	case []any:
+		for _, sub := range val {
+			walkRefs(sub, f)
+		}
+	}
+}
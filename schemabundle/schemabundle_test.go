@@ -0,0 +1,153 @@
+package schemabundle_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"github.com/tailbits/mason/schemabundle"
+	"gotest.tools/v3/assert"
+)
+
+func newAPI() *mason.API {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("orders")
+	grp.Register(mason.HandlePost(CreateOrder).Path("/orders").WithOpID("create_order"))
+	grp.Register(mason.HandleGet(GetCustomer).Path("/customers/{id}").WithOpID("get_customer"))
+	grp.Register(mason.HandleGet(GetInvoice).Path("/invoices/{id}").WithOpID("get_invoice"))
+	return api
+}
+
+func TestExport(t *testing.T) {
+	bundle, err := schemabundle.Export(newAPI(), schemabundle.ID("https://schemas.example.com/bundle.json"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"Customer", "Invoice", "Line", "Order"}, bundle.Names())
+
+	data, err := bundle.Marshal()
+	assert.NilError(t, err)
+
+	var doc struct {
+		Schema string                    `json:"$schema"`
+		ID     string                    `json:"$id"`
+		Defs   map[string]map[string]any `json:"$defs"`
+	}
+	assert.NilError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, schemabundle.DefaultDialect, doc.Schema)
+	assert.Equal(t, "https://schemas.example.com/bundle.json", doc.ID)
+
+	order := doc.Defs["Order"]["properties"].(map[string]any)
+	assert.Equal(t, "#/$defs/Customer", order["customer"].(map[string]any)["$ref"])
+	assert.Equal(t, "#/$defs/Line", order["lines"].(map[string]any)["items"].(map[string]any)["$ref"])
+	assert.Equal(t, "#/$defs/Order/properties/customer", order["billing"].(map[string]any)["$ref"])
+
+	// the refs to the $id of an entity point to its definition
+	invoice := doc.Defs["Invoice"]["properties"].(map[string]any)
+	assert.Equal(t, "#/$defs/Customer", invoice["customer"].(map[string]any)["$ref"])
+	assert.Assert(t, doc.Defs["Customer"]["$id"] == nil)
+	assert.Assert(t, doc.Defs["Customer"]["$schema"] == nil)
+
+	// the bundle validates payloads against a definition
+	schema := []byte(`{"$ref": "#/$defs/Order", "$defs": ` + string(mustMarshal(t, doc.Defs)) + `}`)
+	assert.NilError(t, model.Validate(schema, []byte(`{"customer": {"name": "Ann"}, "lines": [{"sku": "A1"}]}`)))
+	assert.ErrorContains(t, model.Validate(schema, []byte(`{"customer": {"name": 1}}`)), "")
+}
+
+func TestExport_Files(t *testing.T) {
+	bundle, err := schemabundle.Export(newAPI())
+	assert.NilError(t, err)
+
+	dir := t.TempDir()
+	assert.NilError(t, bundle.WriteDir(dir))
+
+	data, err := os.ReadFile(filepath.Join(dir, "Order.json"))
+	assert.NilError(t, err)
+	var order map[string]any
+	assert.NilError(t, json.Unmarshal(data, &order))
+	props := order["properties"].(map[string]any)
+	assert.Equal(t, "Customer.json", props["customer"].(map[string]any)["$ref"])
+	assert.Equal(t, "Line.json", props["lines"].(map[string]any)["items"].(map[string]any)["$ref"])
+	assert.Equal(t, "#/properties/customer", props["billing"].(map[string]any)["$ref"])
+
+	for _, name := range []string{"Customer.json", "Invoice.json", "Line.json"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.NilError(t, err)
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	assert.NilError(t, err)
+	return data
+}
+
+func CreateOrder(ctx context.Context, r *http.Request, in *Order, _ model.Nil) (*Order, error) {
+	return in, nil
+}
+
+func GetCustomer(ctx context.Context, r *http.Request, _ model.Nil) (*Customer, error) {
+	return &Customer{}, nil
+}
+
+func GetInvoice(ctx context.Context, r *http.Request, _ model.Nil) (*Invoice, error) {
+	return &Invoice{}, nil
+}
+
+type Order struct{}
+
+func (o *Order) Name() string    { return "Order" }
+func (o *Order) Example() []byte { return []byte(`{}`) }
+func (o *Order) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"customer": {"$ref": "#/definitions/Customer"},
+			"billing": {"$ref": "#/properties/customer"},
+			"lines": {"type": "array", "items": {"$ref": "#/definitions/Line"}}
+		},
+		"definitions": {
+			"Line": {"type": "object", "properties": {"sku": {"type": "string"}}}
+		}
+	}`)
+}
+func (o *Order) Marshal() (json.RawMessage, error) { return json.Marshal(o) }
+func (o *Order) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, o)
+}
+
+type Customer struct{}
+
+func (c *Customer) Name() string    { return "Customer" }
+func (c *Customer) Example() []byte { return []byte(`{}`) }
+func (c *Customer) Schema() []byte {
+	return []byte(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"$id": "https://schemas.example.com/customer",
+		"type": "object",
+		"properties": {"name": {"type": "string"}}
+	}`)
+}
+func (c *Customer) Marshal() (json.RawMessage, error) { return json.Marshal(c) }
+func (c *Customer) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, c)
+}
+
+type Invoice struct{}
+
+func (i *Invoice) Name() string    { return "Invoice" }
+func (i *Invoice) Example() []byte { return []byte(`{}`) }
+func (i *Invoice) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {"customer": {"$ref": "https://schemas.example.com/customer"}}
+	}`)
+}
+func (i *Invoice) Marshal() (json.RawMessage, error) { return json.Marshal(i) }
+func (i *Invoice) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, i)
+}
@@ -0,0 +1,119 @@
+package mason
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+	"github.com/tailbits/mason/model"
+)
+
+// SchemaRefError is a $ref in the schema of a registered entity that cannot be resolved, or that resolves to
+// different schemas.
+type SchemaRefError struct {
+	Entity string
+	Ref    string
+	Reason string
+}
+
+func (e *SchemaRefError) Error() string {
+	return fmt.Sprintf("entity %s: ref %s %s", e.Entity, e.Ref, e.Reason)
+}
+
+// CheckSchemas checks the $refs of the schemas of all registered entities against the registered models, and
+// reports every problem at once, as SchemaRefErrors:
+//   - refs to definitions that are neither defined by the schema nor registered,
+//   - refs to definitions that the schema defines differently from the registered entity of the same name,
+//   - external refs, when no ref resolver is configured.
+//
+// Refs to the $id of a registered entity are valid. It is called by Build, and can be called from tests.
+func (a *API) CheckSchemas() error {
+	var errs []error
+	for _, name := range a.modelNames() {
+		errs = append(errs, a.checkSchema(name, a.models[name])...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (a *API) modelNames() []string {
+	names := make([]string, 0, len(a.models))
+	for name := range a.models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (a *API) checkSchema(name string, ent model.Entity) []error {
+	if ent == nil || reflect.ValueOf(ent).Kind() == reflect.Ptr && reflect.ValueOf(ent).IsNil() || len(ent.Schema()) == 0 {
+		return nil
+	}
+
+	var sch jsonschema.Schema
+	if err := json.Unmarshal(ent.Schema(), &sch); err != nil {
+		return []error{fmt.Errorf("entity %s: invalid schema: %w", name, err)}
+	}
+	normalizeDefs(&sch)
+
+	var errs []error
+	seen := make(map[string]bool)
+	walkRefs(&sch, func(ref *string) {
+		if seen[*ref] {
+			return
+		}
+		seen[*ref] = true
+
+		refErr := func(reason string) {
+			errs = append(errs, &SchemaRefError{Entity: name, Ref: *ref, Reason: reason})
+		}
+
+		def, local := strings.CutPrefix(*ref, "#/definitions/")
+		if !local {
+			abs := strings.TrimSuffix(*ref, "#")
+			if _, ok := a.schemaIDs[abs]; ok || strings.HasPrefix(*ref, "#") {
+				return
+			}
+			if a.refResolver == nil {
+				refErr("is external, and no ref resolver is configured")
+			}
+			return
+		}
+
+		localDef, defined := sch.Definitions[def]
+		registered, ok := a.models[def]
+		switch {
+		case !defined && !ok:
+			refErr("does not match a definition or a registered entity")
+		case defined && ok && def != name && !sameSchema(localDef, registered.Schema()):
+			refErr("is defined differently from the registered entity " + def)
+		}
+	})
+
+	return errs
+}
+
+// sameSchema compares a definition with the schema of an entity, ignoring the keywords that do not constrain values.
+func sameSchema(def jsonschema.SchemaOrBool, schema []byte) bool {
+	raw, err := json.Marshal(def)
+	if err != nil {
+		return false
+	}
+
+	var left, right map[string]interface{}
+	if json.Unmarshal(raw, &left) != nil || json.Unmarshal(schema, &right) != nil {
+		return false
+	}
+	for _, m := range []map[string]interface{}{left, right} {
+		for _, key := range []string{"definitions", "$defs", "$id", "$schema", "examples", "description", "title"} {
+			delete(m, key)
+		}
+	}
+
+	return reflect.DeepEqual(left, right)
+}
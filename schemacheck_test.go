@@ -0,0 +1,83 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestCheckSchemas(t *testing.T) {
+	listItems := func(ctx context.Context, r *http.Request, params model.Nil) (*model.Connection[*Item], error) {
+		return &model.Connection[*Item]{}, nil
+	}
+	getLegacy := func(ctx context.Context, r *http.Request, params model.Nil) (*LegacyItem, error) {
+		return &LegacyItem{}, nil
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		api.NewRouteGroup("items").Register(mason.HandleGet(listItems).Path("/items").WithOpID("list_items"))
+		api.NewRouteGroup("items").Register(mason.HandlePost(func(ctx context.Context, r *http.Request, item *Item, _ model.Nil) (*Item, error) {
+			return item, nil
+		}).Path("/items").WithOpID("create_item"))
+
+		assert.NilError(t, api.CheckSchemas())
+	})
+
+	t.Run("dangling and ambiguous refs", func(t *testing.T) {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		api.NewRouteGroup("items").Register(mason.HandleGet(listItems).Path("/items").WithOpID("list_items"))
+		api.NewRouteGroup("items").Register(mason.HandlePost(func(ctx context.Context, r *http.Request, item *Item, _ model.Nil) (*Item, error) {
+			return item, nil
+		}).Path("/items").WithOpID("create_item"))
+		api.NewRouteGroup("legacy").Register(mason.HandleGet(getLegacy).Path("/legacy").WithOpID("get_legacy"))
+
+		err := api.CheckSchemas()
+		assert.ErrorContains(t, err, "entity LegacyItem: ref #/definitions/Item is defined differently from the registered entity Item")
+		assert.ErrorContains(t, err, "entity LegacyItem: ref #/definitions/Owner does not match a definition or a registered entity")
+
+		var refErr *mason.SchemaRefError
+		assert.Assert(t, errors.As(err, &refErr))
+		assert.Equal(t, "LegacyItem", refErr.Entity)
+	})
+}
+
+var _ model.Entity = (*LegacyItem)(nil)
+
+// LegacyItem inlines an outdated copy of Item, and references an entity that is not registered.
+type LegacyItem struct{}
+
+func (i *LegacyItem) Name() string {
+	return "LegacyItem"
+}
+
+func (i *LegacyItem) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"item": {"$ref": "#/definitions/Item"},
+			"owner": {"$ref": "#/definitions/Owner"}
+		},
+		"definitions": {
+			"Item": {"type": "object", "properties": {"name": {"type": "string"}}}
+		}
+	}`)
+}
+
+func (i *LegacyItem) Example() []byte {
+	return []byte(`{}`)
+}
+
+func (i *LegacyItem) Marshal() (json.RawMessage, error) {
+	return json.Marshal(i)
+}
+
+func (i *LegacyItem) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, i)
+}
@@ -0,0 +1,54 @@
+package mason
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// schemaID returns the $id declared by the schema of an entity, if any.
+func schemaID(ent model.Entity) string {
+	var sch struct {
+		ID string `json:"$id"`
+	}
+	if err := json.Unmarshal(ent.Schema(), &sch); err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(sch.ID, "#")
+}
+
+// indexSchemaID indexes the $id of an entity, so other entities can reference it by $id rather than by name. An $id
+// declared by two different entities panics, as refs to it would be ambiguous.
+func (a *API) indexSchemaID(ent model.Entity) {
+	id := schemaID(ent)
+	if id == "" {
+		return
+	}
+
+	if name, ok := a.schemaIDs[id]; ok && name != ent.Name() {
+		panic(fmt.Sprintf("schema $id %s is declared by both %s and %s", id, name, ent.Name()))
+	}
+	a.schemaIDs[id] = ent.Name()
+}
+
+// GetModelByID returns the registered entity whose schema declares the $id.
+func (a *API) GetModelByID(id string) (model.Entity, bool) {
+	name, ok := a.modelNameByID(strings.TrimSuffix(id, "#"))
+	if !ok {
+		return nil, false
+	}
+
+	return a.GetModel(name)
+}
+
+// modelNameByID returns the name of the registered entity whose schema declares the $id, without the trailing #.
+func (a *API) modelNameByID(id string) (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	name, ok := a.schemaIDs[id]
+	return name, ok
+}
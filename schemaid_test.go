@@ -0,0 +1,127 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestSchemaID(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("accounts").Register(mason.HandleGet(func(ctx context.Context, r *http.Request, _ model.Nil) (*Account, error) {
+		return &Account{}, nil
+	}).Path("/accounts/{id}").WithOpID("get_account"))
+	api.NewRouteGroup("invoices").Register(mason.HandlePost(func(ctx context.Context, r *http.Request, in *Invoice, _ model.Nil) (*Invoice, error) {
+		return in, nil
+	}).Path("/invoices").WithOpID("create_invoice"))
+
+	ent, ok := api.GetModelByID("https://schemas.example.com/account#")
+	assert.Assert(t, ok)
+	assert.Equal(t, "BillingAccount", ent.Name())
+
+	schema, err := api.DereferenceSchema((&Invoice{}).Schema())
+	assert.NilError(t, err)
+
+	var sch struct {
+		Properties  map[string]map[string]string `json:"properties"`
+		Definitions map[string]map[string]any    `json:"definitions"`
+	}
+	assert.NilError(t, json.Unmarshal(schema, &sch))
+	assert.Equal(t, "#/definitions/BillingAccount", sch.Properties["account"]["$ref"])
+	_, hasID := sch.Definitions["BillingAccount"]["$id"]
+	assert.Assert(t, !hasID)
+
+	assert.NilError(t, model.Validate(schema, []byte(`{"account": {"iban": "DE00"}}`)))
+	assert.ErrorContains(t, model.Validate(schema, []byte(`{"account": {"iban": 1}}`)), "")
+}
+
+func TestSchemaID_Conflict(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("accounts").Register(mason.HandleGet(func(ctx context.Context, r *http.Request, _ model.Nil) (*Account, error) {
+		return &Account{}, nil
+	}).Path("/accounts/{id}").WithOpID("get_account"))
+
+	defer func() {
+		assert.Assert(t, recover() != nil)
+	}()
+	api.NewRouteGroup("other").Register(mason.HandleGet(func(ctx context.Context, r *http.Request, _ model.Nil) (*OtherAccount, error) {
+		return &OtherAccount{}, nil
+	}).Path("/other/{id}").WithOpID("get_other"))
+}
+
+var _ model.Entity = (*Account)(nil)
+
+type Account struct {
+	IBAN string `json:"iban"`
+}
+
+func (a *Account) Name() string {
+	return "BillingAccount"
+}
+
+func (a *Account) Example() []byte {
+	return []byte(`{"iban": "DE00"}`)
+}
+
+func (a *Account) Schema() []byte {
+	return []byte(`{
+		"$id": "https://schemas.example.com/account",
+		"type": "object",
+		"properties": {
+			"iban": {"type": "string"}
+		}
+	}`)
+}
+
+func (a *Account) Marshal() (json.RawMessage, error) {
+	return json.Marshal(a)
+}
+
+func (a *Account) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, a)
+}
+
+// OtherAccount declares the $id of Account under another name.
+type OtherAccount struct {
+	Account
+}
+
+func (a *OtherAccount) Name() string {
+	return "OtherAccount"
+}
+
+var _ model.Entity = (*Invoice)(nil)
+
+type Invoice struct {
+	Account *Account `json:"account,omitempty"`
+}
+
+func (i *Invoice) Name() string {
+	return "Invoice"
+}
+
+func (i *Invoice) Example() []byte {
+	return []byte(`{}`)
+}
+
+func (i *Invoice) Schema() []byte {
+	return []byte(`{
+		"type": "object",
+		"properties": {
+			"account": {"$ref": "https://schemas.example.com/account"}
+		}
+	}`)
+}
+
+func (i *Invoice) Marshal() (json.RawMessage, error) {
+	return json.Marshal(i)
+}
+
+func (i *Invoice) Unmarshal(data json.RawMessage) error {
+	return json.Unmarshal(data, i)
+}
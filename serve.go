@@ -0,0 +1,143 @@
+package mason
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Default timeouts of the server started by Serve.
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultWriteTimeout      = 60 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+	DefaultShutdownTimeout   = 15 * time.Second
+)
+
+// ServeOptions configures the server started by Serve. Zero values fall back to the defaults.
+type ServeOptions struct {
+	// Addr is the address to listen on, :8080 by default.
+	Addr string
+	// Listener, when set, is used instead of listening on Addr.
+	Listener net.Listener
+
+	// TLSConfig enables TLS. CertFile and KeyFile are loaded when the config has no certificates.
+	TLSConfig *tls.Config
+	CertFile  string
+	KeyFile   string
+
+	// H2C serves HTTP/2 without TLS too, e.g. behind a proxy that terminates TLS.
+	H2C bool
+
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout is how long in-flight requests may drain after a shutdown is triggered.
+	ShutdownTimeout time.Duration
+	// DrainDelay keeps accepting requests for a while after a shutdown is triggered, so load balancers can notice
+	// the instance going away before it stops listening.
+	DrainDelay time.Duration
+
+	// OnShutdown is called when a shutdown is triggered, e.g. to fail readiness checks.
+	OnShutdown func()
+}
+
+func (o ServeOptions) withDefaults() ServeOptions {
+	if o.Addr == "" {
+		o.Addr = ":8080"
+	}
+	if o.ReadHeaderTimeout == 0 {
+		o.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if o.ReadTimeout == 0 {
+		o.ReadTimeout = DefaultReadTimeout
+	}
+	if o.WriteTimeout == 0 {
+		o.WriteTimeout = DefaultWriteTimeout
+	}
+	if o.IdleTimeout == 0 {
+		o.IdleTimeout = DefaultIdleTimeout
+	}
+	if o.ShutdownTimeout == 0 {
+		o.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	return o
+}
+
+// Serve serves the handler, usually an HTTPRuntime, until the context is cancelled or the process receives SIGINT or
+// SIGTERM, and then shuts down gracefully. It returns nil after a graceful shutdown.
+func Serve(ctx context.Context, handler http.Handler, opts ServeOptions) error {
+	opts = opts.withDefaults()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr:              opts.Addr,
+		Handler:           handler,
+		TLSConfig:         opts.TLSConfig,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+		BaseContext:       func(net.Listener) context.Context { return context.WithoutCancel(ctx) },
+	}
+	if opts.H2C {
+		server.Protocols = new(http.Protocols)
+		// HTTP/2 over TLS is kept for the servers that also serve TLS
+		server.Protocols.SetHTTP1(true)
+		server.Protocols.SetHTTP2(true)
+		server.Protocols.SetUnencryptedHTTP2(true)
+	}
+
+	ln := opts.Listener
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", opts.Addr); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", opts.Addr, err)
+		}
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if opts.TLSConfig != nil || opts.CertFile != "" {
+			errCh <- server.ServeTLS(ln, opts.CertFile, opts.KeyFile)
+			return
+		}
+		errCh <- server.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	if opts.OnShutdown != nil {
+		opts.OnShutdown()
+	}
+	if opts.DrainDelay > 0 {
+		time.Sleep(opts.DrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opts.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package mason_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"gotest.tools/v3/assert"
+)
+
+func TestServe(t *testing.T) {
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).Path("/items/{id}").WithOpID("get_item"))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	shutdown := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- mason.Serve(ctx, rtm, mason.ServeOptions{
+			Listener:   ln,
+			OnShutdown: func() { close(shutdown) },
+		})
+	}()
+
+	rsp, err := http.Get("http://" + ln.Addr().String() + "/items/1")
+	assert.NilError(t, err)
+	assert.NilError(t, rsp.Body.Close())
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	cancel()
+	assert.NilError(t, <-done)
+	<-shutdown
+}
@@ -0,0 +1,156 @@
+package mason
+
+import (
+	"bytes"
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxShadowBody is the size of the largest response a ShadowValidator buffers, the larger ones are not validated.
+const maxShadowBody = 1 << 20
+
+type shadowOptions struct {
+	concurrency int
+}
+
+type ShadowOption func(*shadowOptions)
+
+// ShadowConcurrency sets how many responses are validated at once, 4 by default. The samples taken while all of them
+// are busy are dropped, so a slow validation never backs up the traffic.
+func ShadowConcurrency(n int) ShadowOption {
+	return func(o *shadowOptions) {
+		o.concurrency = n
+	}
+}
+
+var _ Middleware = (*ShadowValidator)(nil)
+
+// ShadowValidator is a middleware that validates a sample of the live responses against the schemas of their
+// operations, to catch the handlers drifting from the spec in production without the latency of validating every
+// response. The responses are sent as is, and validated in the background after the handler returns. The outcomes are
+// reported to the validation hooks of the API, see API.OnValidation, with the response phase.
+type ShadowValidator struct {
+	api     *API
+	rate    float64
+	sem     chan struct{}
+	pending sync.WaitGroup
+}
+
+// ShadowValidation returns a ShadowValidator validating the given share of the responses, e.g. 0.01 for 1%.
+func ShadowValidation(api *API, rate float64, opts ...ShadowOption) *ShadowValidator {
+	options := shadowOptions{
+		concurrency: 4,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &ShadowValidator{
+		api:  api,
+		rate: rate,
+		sem:  make(chan struct{}, max(options.concurrency, 1)),
+	}
+}
+
+func (s *ShadowValidator) GetHandler(builder Builder) func(WebHandler) WebHandler {
+	return func(next WebHandler) WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if s.rate <= 0 || rand.Float64() >= s.rate {
+				return next(ctx, w, r)
+			}
+
+			tw := &teeWriter{ResponseWriter: w}
+			if err := next(ctx, tw, r); err != nil {
+				return err
+			}
+
+			select {
+			case s.sem <- struct{}{}:
+			default:
+				return nil
+			}
+			// the request and the writer are not used once the handler returned
+			ctx = context.WithoutCancel(ctx)
+			r, contentType := r.Clone(ctx), tw.Header().Get("Content-Type")
+			s.pending.Add(1)
+			go func() {
+				defer func() {
+					<-s.sem
+					s.pending.Done()
+				}()
+				s.validate(ctx, builder.OpID(), r, contentType, tw)
+			}()
+
+			return nil
+		}
+	}
+}
+
+// Wait waits for the validations in progress, e.g. before the server shuts down.
+func (s *ShadowValidator) Wait() {
+	s.pending.Wait()
+}
+
+// validate validates the response of an operation, unless it is an error, or too large to be buffered.
+func (s *ShadowValidator) validate(ctx context.Context, opID string, r *http.Request, contentType string, tw *teeWriter) {
+	status := tw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status >= 400 || tw.truncated {
+		return
+	}
+
+	op, found := s.api.GetOperationByID(opID)
+	if !found || op.NoContent {
+		return
+	}
+
+	start := time.Now()
+	err := s.api.checkResponse(op, r, status, http.Header{"Content-Type": {contentType}}, tw.body.Bytes())
+	s.api.report(ctx, ValidationOutcome{
+		OperationID: op.OperationID,
+		Method:      op.Method,
+		Path:        op.Path,
+		Phase:       "response",
+		Duration:    time.Since(start),
+	}, err)
+}
+
+// teeWriter writes the response through, keeping a copy of its status and body.
+type teeWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *teeWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.truncated {
+		if w.body.Len()+len(b) > maxShadowBody {
+			w.truncated = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *teeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,73 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestShadowValidation(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "shadowed"}, nil
+	}
+
+	var mu sync.Mutex
+	var outcomes []mason.ValidationOutcome
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.OnValidation(func(ctx context.Context, outcome mason.ValidationOutcome) {
+		mu.Lock()
+		defer mu.Unlock()
+		outcomes = append(outcomes, outcome)
+	})
+	shadow := mason.ShadowValidation(api, 1)
+	skipped := mason.ShadowValidation(api, 0)
+
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandleGet(getItem).Path("/items/valid").WithOpID("get_valid_item").WithMWs(shadow))
+	grp.Register(mason.HandleGet(getItem).
+		Path("/items/drifted").
+		WithOpID("get_drifted_item").
+		WithMWs(shadow).
+		AfterEncode(func(ctx context.Context, status int, body []byte) ([]byte, error) {
+			// the handler drifted from the schema, the title is required
+			return []byte(`{"name": "shadowed"}`), nil
+		}))
+	grp.Register(mason.HandleGet(getItem).
+		Path("/items/unsampled").
+		WithOpID("get_unsampled_item").
+		WithMWs(skipped).
+		AfterEncode(func(ctx context.Context, status int, body []byte) ([]byte, error) {
+			return []byte(`{}`), nil
+		}))
+
+	for _, path := range []string{"/items/valid", "/items/drifted", "/items/unsampled"} {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	shadow.Wait()
+	skipped.Wait()
+
+	byOp := map[string]mason.ValidationOutcome{}
+	for _, outcome := range outcomes {
+		byOp[outcome.OperationID] = outcome
+	}
+	assert.Equal(t, 2, len(outcomes))
+
+	valid := byOp["get_valid_item"]
+	assert.Equal(t, "response", valid.Phase)
+	assert.Assert(t, valid.Valid)
+
+	drifted := byOp["get_drifted_item"]
+	assert.Equal(t, "response", drifted.Phase)
+	assert.Equal(t, "/items/drifted", drifted.Path)
+	assert.Assert(t, !drifted.Valid)
+	assert.DeepEqual(t, []string{"title"}, drifted.Fields)
+}
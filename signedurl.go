@@ -0,0 +1,127 @@
+package mason
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+const (
+	// SignatureParam is the query param holding the signature of a signed URL, see URLSigner.
+	SignatureParam = "signature"
+	// ExpiresParam is the query param holding the expiry of a signed URL, in seconds since the epoch.
+	ExpiresParam = "expires"
+	// SignedURLScheme is the name of the security scheme documenting the operations that require a signed URL.
+	SignedURLScheme = "signedURL"
+)
+
+// The codes of the errors sent for the requests refused by a URLSigner, with the StatusForbidden status.
+const (
+	ErrSignatureInvalid = "signature_invalid"
+	ErrSignatureExpired = "signature_expired"
+)
+
+func WithOperationSignedURL(signed bool) Option {
+	return func(m *Operation) {
+		m.SignedURL = signed
+	}
+}
+
+var _ Middleware = (*URLSigner)(nil)
+
+// URLSigner produces time-limited URLs of the operations, e.g. for the download links sent in emails, and is the
+// middleware verifying them on the routes that require one. A URL is signed with an HMAC-SHA256 over the method of
+// the operation, its path with the params filled in, the query params and the expiry, so none of them can be changed.
+type URLSigner struct {
+	api *API
+	key []byte
+	now func() time.Time
+}
+
+// NewURLSigner returns a signer of the URLs of the operations of the API, with the secret key.
+func NewURLSigner(api *API, key []byte) *URLSigner {
+	return &URLSigner{api: api, key: key, now: time.Now}
+}
+
+// Sign returns the URL of the operation with the operationID, like API.URLFor, signed and valid for the ttl.
+func (s *URLSigner) Sign(opID string, params map[string]string, query url.Values, ttl time.Duration) (string, error) {
+	op, ok := s.api.GetOperationByID(opID)
+	if !ok {
+		return "", fmt.Errorf("operation [%s] not found", opID)
+	}
+
+	path, err := expandPath(op.Path, params)
+	if err != nil {
+		return "", fmt.Errorf("operation [%s]: %w", opID, err)
+	}
+
+	signed := url.Values{}
+	for name, values := range query {
+		signed[name] = append([]string(nil), values...)
+	}
+	signed.Set(ExpiresParam, strconv.FormatInt(s.now().Add(ttl).Unix(), 10))
+	signed.Set(SignatureParam, s.signature(op.Method, path, signed))
+
+	return path + "?" + signed.Encode(), nil
+}
+
+// Verify checks the signature and the expiry of the URL of the request. The HEAD requests are verified like the GET
+// requests, so the clients can check a download link.
+func (s *URLSigner) Verify(r *http.Request) error {
+	query := r.URL.Query()
+	expires, err := strconv.ParseInt(query.Get(ExpiresParam), 10, 64)
+	if err != nil {
+		return model.NewAPIError(ErrSignatureInvalid, "The URL is not signed")
+	}
+
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	expected := s.signature(method, r.URL.EscapedPath(), query)
+	if !hmac.Equal([]byte(query.Get(SignatureParam)), []byte(expected)) {
+		return model.NewAPIError(ErrSignatureInvalid, "The signature of the URL is invalid")
+	}
+	if s.now().Unix() > expires {
+		return model.NewAPIError(ErrSignatureExpired, "The signed URL has expired")
+	}
+
+	return nil
+}
+
+// signature returns the HMAC of the method, the path and the query params, without the signature.
+func (s *URLSigner) signature(method string, path string, query url.Values) string {
+	unsigned := url.Values{}
+	for name, values := range query {
+		if name != SignatureParam {
+			unsigned[name] = values
+		}
+	}
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(method + "\n" + path + "\n" + unsigned.Encode()))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// GetHandler refuses the requests whose URL is not signed, has an invalid signature or has expired, with a 403 and a
+// model.APIError with the ErrSignatureInvalid or ErrSignatureExpired code.
+func (s *URLSigner) GetHandler(builder Builder) func(WebHandler) WebHandler {
+	return func(next WebHandler) WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if err := s.Verify(r); err != nil {
+				return s.api.Respond(ctx, w, err, http.StatusForbidden)
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestURLSigner(t *testing.T) {
+	export := func(ctx context.Context, r *http.Request, params model.Nil) (*mason.File, error) {
+		return &mason.File{Content: strings.NewReader("id,title\n"), Filename: "export.csv"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime(mason.WithAutoHead())
+	api := mason.NewAPI(rtm)
+	signer := mason.NewURLSigner(api, []byte("secret"))
+	api.NewRouteGroup("exports").Register(mason.HandleFile(export).
+		Path("/exports/{id}").
+		WithOpID("download_export").
+		WithMWs(signer))
+
+	get := func(method string, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+	code := func(rec *httptest.ResponseRecorder) string {
+		var apiErr model.APIError
+		assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+		return apiErr.Code
+	}
+
+	signed, err := signer.Sign("download_export", map[string]string{"id": "a b"}, url.Values{"format": {"csv"}}, time.Hour)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(signed, "/exports/a%20b?expires="))
+
+	t.Run("valid", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			rec := get(method, signed)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		for _, target := range []string{
+			strings.Replace(signed, "a%20b", "c", 1),
+			strings.Replace(signed, "format=csv", "format=json", 1),
+			"/exports/a%20b",
+		} {
+			rec := get(http.MethodGet, target)
+			assert.Equal(t, http.StatusForbidden, rec.Code, target)
+			assert.Equal(t, mason.ErrSignatureInvalid, code(rec))
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired, err := signer.Sign("download_export", map[string]string{"id": "1"}, nil, -time.Minute)
+		assert.NilError(t, err)
+
+		rec := get(http.MethodGet, expired)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, mason.ErrSignatureExpired, code(rec))
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		_, err := signer.Sign("list_exports", nil, nil, time.Hour)
+		assert.ErrorContains(t, err, "operation [list_exports] not found")
+	})
+
+	op, ok := api.GetOperationByID("download_export")
+	assert.Assert(t, ok)
+	assert.Assert(t, op.SignedURL)
+}
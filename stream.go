@@ -0,0 +1,141 @@
+package mason
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+
+	"github.com/tailbits/mason/model"
+)
+
+// NDJSONContentType is the content type of newline delimited JSON request bodies, see Stream.
+const NDJSONContentType = "application/x-ndjson"
+
+// MaxStreamLineSize limits the size of a single line of a Stream.
+var MaxStreamLineSize = 10 << 20
+
+// HandlerStream handles a request body of newline delimited entities, which are decoded as they are read.
+type HandlerStream[T model.Entity, O model.Entity, Q any] func(ctx context.Context, r *http.Request, items *Stream[T], params Q) (response O, err error)
+
+// HandleStream registers a POST route with a newline delimited JSON body, e.g. for bulk ingestion, so large bodies
+// are not loaded into memory at once.
+func HandleStream[T model.Entity, O model.Entity, Q any](handler HandlerStream[T, O, Q]) *RouteBuilderWithBody[*Stream[T], O, Q] {
+	return HandlePost(HandlerWithBody[*Stream[T], O, Q](handler))
+}
+
+type streamDecoder interface {
+	init(api *API, r *http.Request, validation []model.ValidationOption, skipValidation bool)
+}
+
+var (
+	_ model.Entity      = (*Stream[model.Nil])(nil)
+	_ model.DerivedType = (*Stream[model.Nil])(nil)
+	_ streamDecoder     = (*Stream[model.Nil])(nil)
+)
+
+// Stream is a request body of newline delimited entities of type T. Each line is validated against the schema of T
+// as it is read, unless the validation is skipped, see SkipBodyValidation, and empty lines are skipped. It is
+// documented as the schema of T with the NDJSONContentType.
+type Stream[T model.Entity] struct {
+	api            *API
+	body           io.Reader
+	validation     []model.ValidationOption
+	skipValidation bool
+}
+
+func (s *Stream[T]) init(api *API, r *http.Request, validation []model.ValidationOption, skipValidation bool) {
+	s.api = api
+	s.body = r.Body
+	s.validation = validation
+	s.skipValidation = skipValidation
+}
+
+func (s *Stream[T]) Name() string {
+	return model.New[T]().Name()
+}
+
+func (s *Stream[T]) Schema() []byte {
+	return model.New[T]().Schema()
+}
+
+func (s *Stream[T]) Example() []byte {
+	return model.New[T]().Example()
+}
+
+// Marshal is not supported, as the entities of a stream are only read once.
+func (s *Stream[T]) Marshal() (json.RawMessage, error) {
+	return nil, fmt.Errorf("stream of %s cannot be marshalled", s.Name())
+}
+
+// Unmarshal is a no-op, as streams are decoded as they are read.
+func (s *Stream[T]) Unmarshal(data json.RawMessage) error {
+	return nil
+}
+
+func (s *Stream[T]) Unwrap() model.WithSchema {
+	return model.New[T]()
+}
+
+// ContentType returns the content type of the request body.
+func (s *Stream[T]) ContentType() string {
+	return NDJSONContentType
+}
+
+// All iterates over the entities of the stream. It stops at the first invalid line, yielding an error that wraps the
+// model.ValidationError, with the number of the line. A stream can only be iterated once.
+func (s *Stream[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if s.body == nil {
+			yield(zero, fmt.Errorf("stream of %s has already been read", s.Name()))
+			return
+		}
+		body := s.body
+		s.body = nil
+
+		var schema []byte
+		if !s.skipValidation {
+			var err error
+			if schema, err = s.api.DereferenceSchema(s.Schema()); err != nil {
+				yield(zero, fmt.Errorf("dereferenceSchema ent[%s]: %w", s.Name(), err))
+				return
+			}
+		}
+
+		n := 0
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64<<10), MaxStreamLineSize)
+		for scanner.Scan() {
+			n++
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			if !s.skipValidation {
+				if err := model.Validate(schema, line, s.validation...); err != nil {
+					yield(zero, fmt.Errorf("line %d: %w", n, err))
+					return
+				}
+			}
+
+			ent, err := unmarshalEntity[T](line)
+			if err != nil {
+				yield(zero, fmt.Errorf("line %d: %w", n, err))
+				return
+			}
+			if !yield(ent, nil) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(zero, fmt.Errorf("unable to read the body: %w", err))
+		}
+	}
+}
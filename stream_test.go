@@ -0,0 +1,79 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestHandleStream(t *testing.T) {
+	importItems := func(ctx context.Context, r *http.Request, items *mason.Stream[*Item], params model.Nil) (*Item, error) {
+		titles := []string{}
+		for item, err := range items.All() {
+			if err != nil {
+				return nil, err
+			}
+			titles = append(titles, item.Title)
+		}
+		return &Item{Title: strings.Join(titles, ",")}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleStream(importItems).
+		Path("/items/import").
+		WithOpID("import_items"))
+
+	t.Run("valid", func(t *testing.T) {
+		body := "{\"title\": \"a\"}\n\n{\"title\": \"b\"}\n{\"title\": \"c\"}"
+		req := httptest.NewRequest(http.MethodPost, "/items/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", mason.NDJSONContentType)
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `{"title":"a,b,c"}`+"\n", rec.Body.String())
+	})
+
+	t.Run("conformance", func(t *testing.T) {
+		var mismatches []mason.Mismatch
+		handler := mason.Conformance(api, mason.RejectMismatches(), mason.OnMismatch(func(_ *http.Request, m mason.Mismatch) {
+			mismatches = append(mismatches, m)
+		}))(rtm)
+
+		body := "{\"title\": \"a\"}\n{\"title\": \"b\"}\n"
+		req := httptest.NewRequest(http.MethodPost, "/items/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", mason.NDJSONContentType)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, 0, len(mismatches))
+	})
+
+	t.Run("invalid line", func(t *testing.T) {
+		body := "{\"title\": \"a\"}\n{\"title\": 2}\n"
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items/import", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+		var verr model.ValidationError
+		assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &verr))
+		assert.Equal(t, 1, len(verr.Errors))
+	})
+
+	t.Run("read once", func(t *testing.T) {
+		var stream mason.Stream[*Item]
+		for _, err := range stream.All() {
+			assert.ErrorContains(t, err, "already been read")
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package mason
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/tailbits/mason/model"
+)
+
+// SurrogateKeyHeader is the response header listing the surrogate keys of a response, separated by spaces, which CDNs
+// like Fastly use to purge the cached responses by key.
+const SurrogateKeyHeader = "Surrogate-Key"
+
+// PurgeHook purges the cached responses tagged with any of the surrogate keys, e.g. by calling the purge API of a
+// CDN, see API.OnPurge. It handles its own errors, e.g. by retrying in the background, as the responses of the
+// changes are already sent.
+type PurgeHook func(ctx context.Context, keys []string)
+
+// OnPurge adds a hook purging the cached responses by surrogate key, run by Purge and after the successful responses
+// of the routes registered WithPurge. Hooks run in the order they are added. A MemoryCacheStore can be purged with
+// its Purge method.
+func (a *API) OnPurge(hook PurgeHook) *API {
+	a.purgeHooks = append(a.purgeHooks, hook)
+	return a
+}
+
+// Purge hands the surrogate keys to the purge hooks, e.g. after a change made outside of the routes of the API.
+func (a *API) Purge(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	for _, hook := range a.purgeHooks {
+		hook(ctx, keys)
+	}
+}
+
+// setSurrogateKeys sets the Surrogate-Key header of a GET response, with the keys of the cache policy of the route
+// and of the result, if it implements model.SurrogateKeyedEntity.
+func (rb *RouteBuilderBase) setSurrogateKeys(w http.ResponseWriter, r *http.Request, result model.WithSchema) {
+	if rb.method != http.MethodGet {
+		return
+	}
+
+	var keys []string
+	if rb.cache != nil {
+		keys = expandSurrogateKeys(rb.cache.SurrogateKeys, r)
+	}
+	if sk, ok := result.(model.SurrogateKeyedEntity); ok {
+		keys = append(keys, sk.SurrogateKeys()...)
+	}
+	if keys = uniqueKeys(keys); len(keys) > 0 {
+		w.Header().Set(SurrogateKeyHeader, strings.Join(keys, " "))
+	}
+}
+
+// purge purges the keys of the route registered WithPurge, after its successful response.
+func (rb *RouteBuilderBase) purge(ctx context.Context, api *API, r *http.Request) {
+	if len(rb.purgeKeys) > 0 {
+		api.Purge(ctx, uniqueKeys(expandSurrogateKeys(rb.purgeKeys, r))...)
+	}
+}
+
+// validateSurrogateKeys checks that the keys of the route only reference its path params, and that the keys are
+// purged by the routes changing resources.
+func (rb *RouteBuilderBase) validateSurrogateKeys() error {
+	if len(rb.purgeKeys) > 0 && rb.method == http.MethodGet {
+		return fmt.Errorf("purge is only supported on routes changing resources, not on %s %s", rb.method, rb.path)
+	}
+
+	keys := rb.purgeKeys
+	if rb.cache != nil {
+		keys = append(slices.Clone(keys), rb.cache.SurrogateKeys...)
+	}
+	for _, key := range keys {
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return fmt.Errorf("surrogate key %q of %s %s must be a non-empty word", key, rb.method, rb.path)
+		}
+		for _, name := range surrogateKeyParams(key) {
+			name = strings.TrimSuffix(name, "...")
+			if !strings.Contains(rb.path, "{"+name+"}") && !strings.Contains(rb.path, "{"+name+"...}") {
+				return fmt.Errorf("surrogate key %q references the path param %q, which is not part of the path %s", key, name, rb.path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// surrogateKeyParams returns the names of the path params referenced by a surrogate key, like id in item-{id}.
+func surrogateKeyParams(key string) []string {
+	var names []string
+	for {
+		start := strings.Index(key, "{")
+		if start < 0 {
+			return names
+		}
+		end := strings.Index(key[start:], "}")
+		if end < 0 {
+			return names
+		}
+		names = append(names, key[start+1:start+end])
+		key = key[start+end+1:]
+	}
+}
+
+// expandSurrogateKeys replaces the path params referenced by the keys with their values in the request, like
+// {id} or {path...} for the params matching the rest of the path.
+func expandSurrogateKeys(keys []string, r *http.Request) []string {
+	expanded := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, name := range surrogateKeyParams(key) {
+			value := surrogateKeyValue(r.PathValue(strings.TrimSuffix(name, "...")))
+			key = strings.ReplaceAll(key, "{"+name+"}", value)
+		}
+		expanded = append(expanded, key)
+	}
+
+	return expanded
+}
+
+// surrogateKeyValue escapes the whitespace of a path value, which would split the key it expands in the Surrogate-Key
+// header.
+func surrogateKeyValue(value string) string {
+	if !strings.ContainsFunc(value, unicode.IsSpace) {
+		return value
+	}
+
+	var b strings.Builder
+	for _, c := range value {
+		if unicode.IsSpace(c) {
+			b.WriteString(url.PathEscape(string(c)))
+			continue
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// uniqueKeys removes the empty and the repeated keys, keeping the order of the first occurrences.
+func uniqueKeys(keys []string) []string {
+	unique := keys[:0]
+	for _, key := range keys {
+		if key != "" && !slices.Contains(unique, key) {
+			unique = append(unique, key)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,119 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+// taggedItem is an Item knowing the surrogate keys of its list.
+type taggedItem struct {
+	Item
+}
+
+func (i *taggedItem) SurrogateKeys() []string {
+	return []string{"list-" + i.Title, "items"}
+}
+
+func TestSurrogateKeys(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*taggedItem, error) {
+		return &taggedItem{Item{Title: "a"}}, nil
+	}
+	putItem := func(ctx context.Context, r *http.Request, in *Item, params model.Nil) (*Item, error) {
+		return in, nil
+	}
+
+	store := mason.NewMemoryCacheStore()
+	var purged [][]string
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).
+		OnPurge(store.Purge).
+		OnPurge(func(ctx context.Context, keys []string) {
+			purged = append(purged, keys)
+		})
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item").
+		WithCache(mason.CachePolicy{MaxAge: time.Minute, SurrogateKeys: []string{"items", "item-{id}"}}).
+		WithMWs(mason.Cache(store)))
+	grp.Register(mason.HandlePut(putItem).Path("/items/{id}").WithOpID("put_item").WithPurge("item-{id}"))
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+		return rec
+	}
+
+	rec := get()
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "items item-1 list-a", rec.Header().Get(mason.SurrogateKeyHeader))
+	assert.Equal(t, "HIT", get().Header().Get("X-Cache"))
+
+	rec = httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/items/2", strings.NewReader(`{"title": "b"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.DeepEqual(t, [][]string{{"item-2"}}, purged)
+	assert.Equal(t, "HIT", get().Header().Get("X-Cache"))
+
+	rec = httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/items/1", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, 1, len(purged))
+
+	rec = httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/items/1", strings.NewReader(`{"title": "b"}`)))
+	assert.DeepEqual(t, [][]string{{"item-2"}, {"item-1"}}, purged)
+	assert.Equal(t, "MISS", get().Header().Get("X-Cache"))
+
+	api.Purge(context.Background(), "items")
+	assert.Equal(t, "MISS", get().Header().Get("X-Cache"))
+}
+
+func TestSurrogateKeys_Invalid(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{}, nil
+	}
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	grp := api.NewRouteGroup("items")
+
+	assert.Assert(t, cmpPanics(func() {
+		grp.Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item").WithPurge("items"))
+	}))
+	assert.Assert(t, cmpPanics(func() {
+		grp.Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item").
+			WithCache(mason.CachePolicy{MaxAge: time.Minute, SurrogateKeys: []string{"item-{slug}"}}))
+	}))
+	assert.Assert(t, cmpPanics(func() {
+		grp.Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item").
+			WithCache(mason.CachePolicy{MaxAge: time.Minute, SurrogateKeys: []string{"all items"}}))
+	}))
+}
+
+func TestSurrogateKeys_PathValues(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "a"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	grp := api.NewRouteGroup("items")
+	grp.Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item").
+		WithCache(mason.CachePolicy{MaxAge: time.Minute, SurrogateKeys: []string{"item-{id}"}}))
+	grp.Register(mason.HandleGet(getItem).Path("/files/{path...}").WithOpID("get_file").
+		WithCache(mason.CachePolicy{MaxAge: time.Minute, SurrogateKeys: []string{"file-{path...}"}}))
+
+	get := func(target string) string {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec.Header().Get(mason.SurrogateKeyHeader)
+	}
+
+	assert.Equal(t, "item-a%20b", get("/items/a%20b"))
+	assert.Equal(t, "file-docs/a.txt", get("/files/docs/a.txt"))
+}
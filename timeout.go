@@ -0,0 +1,185 @@
+package mason
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+// WithDefaultTimeout sets the timeout of the routes that do not set one WithTimeout. It applies to the routes
+// registered after it is called.
+func (a *API) WithDefaultTimeout(d time.Duration) *API {
+	a.defaultTimeout = d
+	return a
+}
+
+// WithTimeoutStatus sets the status of the responses sent when a route exceeds its timeout, http.StatusGatewayTimeout
+// by default, e.g. http.StatusServiceUnavailable. It applies to the routes registered after it is called.
+func (a *API) WithTimeoutStatus(status int) *API {
+	a.timeoutStatus = status
+	return a
+}
+
+// routeTimeoutStatus returns the status of the responses sent when a route exceeds its timeout.
+func (a *API) routeTimeoutStatus() int {
+	if a.timeoutStatus == 0 {
+		return http.StatusGatewayTimeout
+	}
+	return a.timeoutStatus
+}
+
+// withTimeout runs the handler with a deadline. When the deadline is exceeded before anything is written, a
+// model.TimeoutError is sent instead, and the later writes of the handler fail with http.ErrHandlerTimeout. The
+// responses are written as they come, so they can be streamed and flushed, and once a response is started it is left
+// to the handler. A panic of the handler is raised again with its value, once its stack is logged, and a panic after
+// the deadline is only logged.
+func withTimeout(api *API, next WebHandler, d time.Duration, status int) WebHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		tctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		tw := &timeoutWriter{w: w, header: w.Header().Clone()}
+		done := make(chan error, 1)
+		panicked := make(chan any, 1)
+		go func() {
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				stack := debug.Stack()
+
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				if tw.timedOut {
+					logPanic(ctx, "handler panicked after its timeout", p, stack)
+					return
+				}
+				// the stack of the handler is lost once the panic is raised again, on another goroutine
+				logPanic(ctx, "handler panicked", p, stack)
+				panicked <- p
+			}()
+			done <- next(tctx, tw, r.WithContext(tctx))
+		}()
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case err := <-done:
+			// the handler returned, so its headers can be kept for the response of the error
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			if !tw.wroteHeader {
+				tw.copyHeader()
+			}
+			if err == nil || !errors.Is(err, context.DeadlineExceeded) || tctx.Err() == nil || tw.wroteHeader {
+				return err
+			}
+		case <-tctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			// the handler may have panicked as the deadline was exceeded, and the panic is already logged
+			select {
+			case p := <-panicked:
+				panic(p)
+			default:
+			}
+			tw.timedOut = true
+			if !errors.Is(tctx.Err(), context.DeadlineExceeded) || tw.wroteHeader {
+				return tctx.Err()
+			}
+		}
+
+		return api.Respond(ctx, w, &model.TimeoutError{
+			Message: "The request did not complete in time",
+			Timeout: d.String(),
+		}, status)
+	}
+}
+
+// logPanic logs a panic of a handler, with its stack if it is known. The http.ErrAbortHandler panics, which abort the
+// response on purpose, are not logged.
+func logPanic(ctx context.Context, msg string, p any, stack []byte) {
+	if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+		return
+	}
+
+	attrs := []any{slog.Any("panic", p)}
+	if stack != nil {
+		attrs = append(attrs, slog.String("stack", string(stack)))
+	}
+	slog.ErrorContext(ctx, msg, attrs...)
+}
+
+// timeoutWriter writes the response of a handler running with a deadline. The handler sets the headers on a copy, so
+// the ones it sets after the deadline do not race with the response of the timeout, and its writes after the deadline
+// are refused.
+type timeoutWriter struct {
+	w      http.ResponseWriter
+	header http.Header
+
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.writeHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeader(http.StatusOK)
+	return tw.w.Write(b)
+}
+
+// Flush sends the response written so far, so the handlers can stream it.
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return
+	}
+	tw.writeHeader(http.StatusOK)
+	_ = http.NewResponseController(tw.w).Flush()
+}
+
+// writeHeader sends the status and the headers of the handler, once and only before the deadline.
+func (tw *timeoutWriter) writeHeader(code int) {
+	if tw.wroteHeader || tw.timedOut {
+		return
+	}
+	tw.wroteHeader = true
+	tw.copyHeader()
+	tw.w.WriteHeader(code)
+}
+
+// copyHeader replaces the headers of the response with the ones of the handler.
+func (tw *timeoutWriter) copyHeader() {
+	dst := tw.w.Header()
+	for k := range dst {
+		delete(dst, k)
+	}
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+}
@@ -0,0 +1,226 @@
+package mason_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+)
+
+func TestTimeout(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		select {
+		case <-time.After(time.Duration(len(r.URL.Query().Get("wait"))) * 100 * time.Millisecond):
+			return &Item{Title: "done"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).WithDefaultTimeout(time.Hour)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+		Path("/items").
+		WithOpID("get_item").
+		WithTimeout(50 * time.Millisecond))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"title":"done"}`+"\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?wait=x", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+
+	var rsp model.TimeoutError
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+	assert.Equal(t, "50ms", rsp.Timeout)
+
+	op, ok := api.GetOperationByID("get_item")
+	assert.Assert(t, ok)
+	assert.Equal(t, 50*time.Millisecond, op.Timeout)
+}
+
+func TestTimeout_Default(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).WithDefaultTimeout(20 * time.Millisecond)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).
+		Path("/items").
+		WithOpID("get_item"))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+}
+
+func TestTimeout_File(t *testing.T) {
+	export := func(ctx context.Context, r *http.Request, params model.Nil) (*mason.File, error) {
+		_, ok := ctx.Deadline()
+		assert.Assert(t, ok)
+		return &mason.File{Content: strings.NewReader("id,title\n1,a\n"), Filename: "export.csv"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).WithDefaultTimeout(time.Minute)
+	api.NewRouteGroup("exports").Register(mason.HandleFile(export).Path("/exports").WithOpID("download_export"))
+
+	req := httptest.NewRequest(http.MethodGet, "/exports", nil)
+	req.Header.Set("Range", "bytes=0-7")
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "id,title", rec.Body.String())
+}
+
+func TestTimeout_Status(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).WithTimeoutStatus(http.StatusServiceUnavailable)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items").WithOpID("get_item").WithTimeout(10 * time.Millisecond))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	op, ok := api.GetOperationByID("get_item")
+	assert.Assert(t, ok)
+	assert.Equal(t, http.StatusServiceUnavailable, op.TimeoutStatus)
+}
+
+func TestTimeout_Panic(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		panic("broken handler")
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).WithDefaultTimeout(time.Minute)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items").WithOpID("get_item"))
+
+	var p any
+	func() {
+		defer func() { p = recover() }()
+		rtm.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+	}()
+	assert.Equal(t, "broken handler", p)
+
+	t.Run("abort", func(t *testing.T) {
+		abort := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+			panic(http.ErrAbortHandler)
+		}
+		api.NewRouteGroup("items").Register(mason.HandleGet(abort).Path("/abort").WithOpID("abort"))
+
+		var p any
+		func() {
+			defer func() { p = recover() }()
+			rtm.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+		}()
+		assert.Equal(t, http.ErrAbortHandler, p)
+	})
+
+	t.Run("after the timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		panicked := make(chan struct{})
+		late := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+			<-release
+			defer close(panicked)
+			panic("late")
+		}
+		api.NewRouteGroup("items").Register(mason.HandleGet(late).Path("/late").WithOpID("late").WithTimeout(time.Millisecond))
+
+		logs := &syncBuffer{}
+		defer slog.SetDefault(slog.Default())
+		slog.SetDefault(slog.New(slog.NewTextHandler(logs, nil)))
+
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/late", nil))
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+
+		close(release)
+		<-panicked
+		poll.WaitOn(t, func(poll.LogT) poll.Result {
+			if strings.Contains(logs.String(), "handler panicked after its timeout") {
+				return poll.Success()
+			}
+			return poll.Continue("panic not logged")
+		})
+	})
+}
+
+func TestTimeout_Headers(t *testing.T) {
+	listItems := func(ctx context.Context, r *http.Request, params aliasedParams) (*Item, error) {
+		return nil, model.ValidationError{Errors: []model.FieldError{{Message: "invalid"}}}
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).WithDefaultTimeout(time.Minute)
+	api.NewRouteGroup("items").Register(mason.HandleGet(listItems).Path("/items").WithOpID("list_items"))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?per_page=10", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+}
+
+// flushingRuntime flushes the responses once written, like a runtime streaming them.
+type flushingRuntime struct {
+	*mason.HTTPRuntime
+}
+
+func (r flushingRuntime) Respond(ctx context.Context, w http.ResponseWriter, data any, status int) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		return err
+	}
+	return http.NewResponseController(w).Flush()
+}
+
+func TestTimeout_Flush(t *testing.T) {
+	rtm := flushingRuntime{mason.NewHTTPRuntime()}
+	api := mason.NewAPI(rtm).WithDefaultTimeout(time.Minute)
+	api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).Path("/items").WithOpID("get_item"))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Assert(t, rec.Flushed)
+}
+
+// syncBuffer is a buffer that can be written by the goroutines of the handlers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
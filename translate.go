@@ -0,0 +1,73 @@
+package mason
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/tailbits/mason/model"
+)
+
+// WithTranslator localizes the messages of validation errors, in the languages preferred by the Accept-Language
+// header of the request.
+func (a *API) WithTranslator(t model.Translator) *API {
+	a.translator = t
+	return a
+}
+
+// translateError localizes the validation errors, if a translator is set. Other errors are returned as is.
+func (a *API) translateError(r *http.Request, err error) error {
+	if a.translator == nil {
+		return err
+	}
+
+	var fe model.ValidationError
+	if !errors.As(err, &fe) {
+		return err
+	}
+
+	return fe.Translate(a.translator, acceptedLanguages(r.Header.Get("Accept-Language"))...)
+}
+
+// acceptedLanguages returns the languages of an Accept-Language header, by preference. A regional language is
+// followed by its base language, e.g. de-CH by de.
+func acceptedLanguages(header string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+
+	var accepted []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := quality(params)
+		if q <= 0 {
+			continue
+		}
+		accepted = append(accepted, language{tag: strings.ToLower(tag), q: q})
+	}
+	slices.SortStableFunc(accepted, func(a, b language) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	langs := make([]string, 0, len(accepted))
+	for _, l := range accepted {
+		langs = append(langs, l.tag)
+		if base, _, ok := strings.Cut(l.tag, "-"); ok {
+			langs = append(langs, base)
+		}
+	}
+
+	return langs
+}
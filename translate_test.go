@@ -0,0 +1,44 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestTranslator(t *testing.T) {
+	createItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).WithTranslator(model.MessageTemplates{
+		"de": {"required": "Parameter '{property}' fehlt"},
+		"fr": {"required": "Le paramètre '{property}' est manquant"},
+	})
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItem).
+		Path("/items").
+		WithOpID("create_item"))
+
+	post := func(lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{}`))
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("fr;q=0.5, de-CH, en;q=0.8")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, `{"errors":[{"message":"Parameter 'title' fehlt"}]}`+"\n", rec.Body.String())
+
+	rec = post("es")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, `{"errors":[{"message":"Param 'title' is missing"}]}`+"\n", rec.Body.String())
+}
@@ -0,0 +1,83 @@
+package mason
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+	"github.com/tailbits/mason/model"
+)
+
+// UnusedModels returns the names of the registered entities that no operation refers to, sorted. An entity is used
+// when it is the body, an alternate representation or a declared error of an operation, or the payload of a webhook
+// event, or when the schema of a used entity refers to it, by definition name or by $id. The unused entities are
+// typically dead models, or the errors no route declares WithErrors.
+func (a *API) UnusedModels() []string {
+	used := make(map[string]bool)
+	var visit func(ent model.Entity)
+	visit = func(ent model.Entity) {
+		if isNilEntity(ent) || used[ent.Name()] {
+			return
+		}
+		used[ent.Name()] = true
+		for _, name := range a.schemaRefs(ent) {
+			if ref, ok := a.models[name]; ok {
+				visit(ref)
+			}
+		}
+	}
+
+	a.ForEachOperation(func(_ string, op Operation) {
+		visit(op.Input)
+		visit(op.Output)
+		for _, rep := range op.Representations {
+			visit(rep)
+		}
+		for _, code := range op.Errors {
+			if def, ok := a.GetError(code); ok {
+				visit(def.Entity)
+			}
+		}
+	})
+	for _, event := range a.webhooks {
+		visit(event.Payload)
+	}
+
+	var unused []string
+	for _, name := range a.ModelNames() {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+
+	return unused
+}
+
+// schemaRefs returns the names of the registered entities the schema of the entity refers to.
+func (a *API) schemaRefs(ent model.Entity) []string {
+	var sch jsonschema.Schema
+	if len(ent.Schema()) == 0 || json.Unmarshal(ent.Schema(), &sch) != nil {
+		return nil
+	}
+	normalizeDefs(&sch)
+
+	var names []string
+	walkRefs(&sch, func(ref *string) {
+		if def, ok := strings.CutPrefix(*ref, "#/definitions/"); ok {
+			names = append(names, def)
+		} else if name, ok := a.schemaIDs[strings.TrimSuffix(*ref, "#")]; ok {
+			names = append(names, name)
+		}
+	})
+
+	return names
+}
+
+func isNilEntity(ent model.Entity) bool {
+	if ent == nil || reflect.ValueOf(ent).Kind() == reflect.Ptr && reflect.ValueOf(ent).IsNil() {
+		return true
+	}
+	_, ok := ent.(model.Nil)
+	return ok
+}
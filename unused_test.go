@@ -0,0 +1,28 @@
+package mason_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestUnusedModels(t *testing.T) {
+	newAPI := func(errs ...string) *mason.API {
+		api := mason.NewAPI(mason.NewHTTPRuntime())
+		api.RegisterError("not_found", http.StatusNotFound, &model.APIError{})
+		api.RegisterError("quota_exceeded", http.StatusTooManyRequests, &QuotaError{})
+		api.NewRouteGroup("items").Register(mason.HandlePost(CreateItem).
+			Path("/items").
+			WithOpID("create_item").
+			WithErrors(errs...))
+		return api
+	}
+
+	assert.DeepEqual(t, []string{"APIError", "QuotaError"}, newAPI().UnusedModels())
+	assert.DeepEqual(t, []string{"QuotaError"}, newAPI("not_found").UnusedModels())
+	assert.Equal(t, 0, len(newAPI("not_found", "quota_exceeded").UnusedModels()))
+	assert.DeepEqual(t, []string{"APIError", "Item", "QuotaError"}, newAPI().ModelNames())
+}
@@ -0,0 +1,73 @@
+package mason
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// URLFor builds the path of the operation with the given operationID, filling in its path params, e.g. to set the
+// Location header of a created resource, or to add links to a response. Query params are appended when given.
+func (a *API) URLFor(opID string, params map[string]string, query url.Values) (string, error) {
+	op, ok := a.GetOperationByID(opID)
+	if !ok {
+		return "", fmt.Errorf("operation [%s] not found", opID)
+	}
+
+	path, err := expandPath(op.Path, params)
+	if err != nil {
+		return "", fmt.Errorf("operation [%s]: %w", opID, err)
+	}
+
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	return path, nil
+}
+
+// expandPath replaces the params of a path template with their escaped values. Wildcard params like {path...} may
+// contain slashes, which are kept as is.
+func expandPath(template string, params map[string]string) (string, error) {
+	used := make(map[string]bool)
+	segments := strings.Split(template, "/")
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+
+		name := strings.Trim(seg, "{}")
+		wildcard := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+
+		value, ok := params[name]
+		if !ok || value == "" {
+			return "", fmt.Errorf("missing path param %q", name)
+		}
+		used[name] = true
+
+		if wildcard {
+			parts := strings.Split(value, "/")
+			for j, part := range parts {
+				parts[j] = url.PathEscape(part)
+			}
+			segments[i] = strings.Join(parts, "/")
+			continue
+		}
+		segments[i] = url.PathEscape(value)
+	}
+
+	unknown := []string{}
+	for name := range params {
+		if !used[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return "", fmt.Errorf("unknown path params %v", unknown)
+	}
+
+	return strings.Join(segments, "/"), nil
+}
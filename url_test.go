@@ -0,0 +1,42 @@
+package mason_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"gotest.tools/v3/assert"
+)
+
+func TestURLFor(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.NewRouteGroup("items").Register(mason.HandleGet(GetItem).Path("/items/{id}").WithOpID("get_item"))
+
+	t.Run("finds operations by ID and normalized path", func(t *testing.T) {
+		op, ok := api.GetOperationByID("get_item")
+		assert.Assert(t, ok)
+		assert.Equal(t, "/items/{id}", op.Path)
+
+		op, ok = api.GetOperation(http.MethodGet, "/items/{item_id}")
+		assert.Assert(t, ok)
+		assert.Equal(t, "get_item", op.OperationID)
+	})
+
+	t.Run("builds the URL of an operation", func(t *testing.T) {
+		got, err := api.URLFor("get_item", map[string]string{"id": "a b"}, url.Values{"fields": {"title"}})
+		assert.NilError(t, err)
+		assert.Equal(t, "/items/a%20b?fields=title", got)
+	})
+
+	t.Run("rejects missing and unknown params", func(t *testing.T) {
+		_, err := api.URLFor("get_item", nil, nil)
+		assert.ErrorContains(t, err, `missing path param "id"`)
+
+		_, err = api.URLFor("get_item", map[string]string{"id": "1", "other": "2"}, nil)
+		assert.ErrorContains(t, err, "unknown path params [other]")
+
+		_, err = api.URLFor("nope", nil, nil)
+		assert.ErrorContains(t, err, "not found")
+	})
+}
@@ -0,0 +1,66 @@
+package mason_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+func TestValidationOptions(t *testing.T) {
+	createItems := func(ctx context.Context, r *http.Request, batch *model.BatchRequest, params model.Nil) (*Item, error) {
+		return &Item{}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm).WithValidationOptions(model.MaxErrors(1))
+	api.NewRouteGroup("items").Register(mason.HandlePost(createItems).
+		Path("/items").
+		WithOpID("create_item"))
+	api.NewRouteGroup("all").Register(mason.HandlePost(createItems).
+		Path("/all").
+		WithOpID("create_all").
+		WithValidationOptions(model.MaxErrors(0)))
+
+	post := func(path string) model.ValidationError {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"operations": [{"method": "TRACE", "path": "items"}]}`)))
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+		var fe model.ValidationError
+		assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &fe))
+		return fe
+	}
+
+	assert.Equal(t, 1, len(post("/items").Errors))
+	assert.Equal(t, 2, len(post("/all").Errors))
+}
+
+func TestValidationOptions_Partial(t *testing.T) {
+	updateItem := func(ctx context.Context, r *http.Request, item *Item, params model.Nil) (*Item, error) {
+		return item, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandlePatch(updateItem).
+		Path("/items/{id}").
+		WithOpID("update_item").
+		WithSuccessCode(http.StatusOK).
+		WithValidationOptions(model.Partial(true)))
+
+	patch := func(body string) int {
+		rec := httptest.NewRecorder()
+		rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/items/1", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, patch(`{}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, patch(`{"title": 1}`))
+}
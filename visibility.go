@@ -0,0 +1,29 @@
+package mason
+
+import "slices"
+
+// OperationVisibility is the audience of an operation. Generators only document public operations by default, and
+// can be configured to include the other tiers, e.g. to publish an internal spec next to the public one.
+type OperationVisibility string
+
+const (
+	VisibilityPublic   OperationVisibility = "public"
+	VisibilityBeta     OperationVisibility = "beta"
+	VisibilityInternal OperationVisibility = "internal"
+)
+
+// VisibleIn reports whether the operation belongs to one of the visibility tiers. Operations without a visibility are
+// public.
+func (op Operation) VisibleIn(tiers ...OperationVisibility) bool {
+	v := op.Visibility
+	if v == "" {
+		v = VisibilityPublic
+	}
+	return slices.Contains(tiers, v)
+}
+
+func WithOperationVisibility(v OperationVisibility) Option {
+	return func(m *Operation) {
+		m.Visibility = v
+	}
+}
@@ -0,0 +1,62 @@
+package mason_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tailbits/mason"
+	"github.com/tailbits/mason/model"
+	"gotest.tools/v3/assert"
+)
+
+// betaGate rejects the requests to beta routes without the X-Beta header.
+type betaGate struct{}
+
+func (betaGate) GetHandler(builder mason.Builder) func(mason.WebHandler) mason.WebHandler {
+	return func(next mason.WebHandler) mason.WebHandler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if builder.Visibility() == mason.VisibilityBeta && r.Header.Get("X-Beta") == "" {
+				w.WriteHeader(http.StatusForbidden)
+				return nil
+			}
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func TestVisibility(t *testing.T) {
+	getItem := func(ctx context.Context, r *http.Request, params model.Nil) (*Item, error) {
+		return &Item{Title: "done"}, nil
+	}
+
+	rtm := mason.NewHTTPRuntime()
+	api := mason.NewAPI(rtm)
+	api.NewRouteGroup("items").Register(mason.HandleGet(getItem).Path("/items/{id}").WithOpID("get_item").WithMWs(betaGate{}))
+	mason.HandleGet(getItem).Path("/items/preview").WithOpID("preview_item").WithGroup("items").WithMWs(betaGate{}).RegisterBeta(api)
+
+	preview, ok := api.GetOperationByID("preview_item")
+	assert.Assert(t, ok)
+	assert.Equal(t, mason.VisibilityBeta, preview.Visibility)
+	assert.Assert(t, preview.VisibleIn(mason.VisibilityBeta))
+	assert.Assert(t, !preview.VisibleIn(mason.VisibilityPublic))
+
+	item, ok := api.GetOperationByID("get_item")
+	assert.Assert(t, ok)
+	assert.Assert(t, item.VisibleIn(mason.VisibilityPublic))
+
+	rec := httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/preview", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/items/preview", nil)
+	req.Header.Set("X-Beta", "1")
+	rec = httptest.NewRecorder()
+	rtm.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	rtm.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,67 @@
+package mason
+
+import (
+	"fmt"
+
+	"github.com/tailbits/mason/model"
+)
+
+// WebhookEvent is a type of event the API sends to the webhooks of its clients, with the entity of its payload. The
+// events are documented in the webhooks section of the spec.
+type WebhookEvent struct {
+	Name        string
+	Summary     string
+	Description string
+	Payload     model.Entity
+}
+
+// WebhookOption configures a WebhookEvent.
+type WebhookOption func(e *WebhookEvent)
+
+// WebhookSummary sets the summary of the event in the spec.
+func WebhookSummary(summary string) WebhookOption {
+	return func(e *WebhookEvent) {
+		e.Summary = summary
+	}
+}
+
+// WebhookDescription sets the description of the event in the spec.
+func WebhookDescription(desc string) WebhookOption {
+	return func(e *WebhookEvent) {
+		e.Description = desc
+	}
+}
+
+// RegisterWebhook adds a type of event to the webhooks of the API, e.g. item.created, with the entity of its payload.
+// Only the events registered can be sent by a WebhookSender. Registering an event twice panics.
+func (a *API) RegisterWebhook(name string, payload model.Entity, opts ...WebhookOption) {
+	if name == "" {
+		panic("webhook event name is required")
+	}
+	if _, ok := a.GetWebhook(name); ok {
+		panic(fmt.Sprintf("webhook event %s is already registered", name))
+	}
+
+	event := WebhookEvent{Name: name, Payload: payload}
+	for _, opt := range opts {
+		opt(&event)
+	}
+	a.webhooks = append(a.webhooks, event)
+	a.registerModel(payload)
+}
+
+// GetWebhook returns the type of event with the name.
+func (a *API) GetWebhook(name string) (WebhookEvent, bool) {
+	for _, event := range a.webhooks {
+		if event.Name == name {
+			return event, true
+		}
+	}
+
+	return WebhookEvent{}, false
+}
+
+// Webhooks returns the types of events of the webhooks, in order of registration.
+func (a *API) Webhooks() []WebhookEvent {
+	return append([]WebhookEvent(nil), a.webhooks...)
+}
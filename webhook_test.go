@@ -0,0 +1,128 @@
+package mason_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tailbits/mason"
+	"gotest.tools/v3/assert"
+)
+
+func TestWebhookSender(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.RegisterWebhook("item.created", &Item{}, mason.WebhookSummary("An item was created"))
+
+	log := &mason.MemoryDeliveryLog{}
+	key := []byte("secret")
+	sender := mason.NewWebhookSender(api, key, mason.WithWebhookRetries(3, time.Millisecond), mason.WithDeliveryLog(log))
+
+	var statuses []int
+	var requests []*http.Request
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests, bodies = append(requests, r), append(bodies, body)
+		w.WriteHeader(statuses[len(requests)-1])
+	}))
+	defer srv.Close()
+
+	t.Run("retried", func(t *testing.T) {
+		statuses, requests, bodies = []int{http.StatusServiceUnavailable, http.StatusNoContent}, nil, nil
+		delivery, err := sender.Send(context.Background(), srv.URL, "item.created", &Item{Title: "a"})
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(delivery.Attempts))
+		assert.Equal(t, http.StatusServiceUnavailable, delivery.Attempts[0].Status)
+		assert.ErrorContains(t, delivery.Attempts[0].Err, "status 503")
+		assert.Equal(t, 2, delivery.Attempts[1].Attempt)
+
+		r := requests[1]
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, `{"title":"a"}`, string(bodies[1]))
+		assert.Equal(t, delivery.ID, r.Header.Get(mason.WebhookIDHeader))
+		assert.Equal(t, requests[0].Header.Get(mason.WebhookIDHeader), r.Header.Get(mason.WebhookIDHeader))
+		assert.Equal(t, "item.created", r.Header.Get(mason.WebhookEventHeader))
+		signature := sender.Sign(delivery.ID, r.Header.Get(mason.WebhookTimestampHeader), bodies[1])
+		assert.Equal(t, "v1,"+signature, r.Header.Get(mason.WebhookSignatureHeader))
+
+		assert.Equal(t, 2, len(log.Attempts()))
+		assert.Equal(t, delivery.ID, log.Attempts()[1].DeliveryID)
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		statuses, requests = []int{http.StatusBadRequest}, nil
+		delivery, err := sender.Send(context.Background(), srv.URL, "item.created", &Item{Title: "a"})
+		assert.ErrorContains(t, err, "status 400")
+		assert.Equal(t, 1, len(delivery.Attempts))
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		statuses, requests = []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusInternalServerError}, nil
+		delivery, err := sender.Send(context.Background(), srv.URL, "item.created", &Item{Title: "a"})
+		assert.ErrorContains(t, err, "status 500")
+		assert.Equal(t, 3, len(delivery.Attempts))
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		_, err := sender.Send(context.Background(), srv.URL, "item.deleted", &Item{Title: "a"})
+		assert.ErrorContains(t, err, "not registered")
+	})
+
+	t.Run("wrong payload", func(t *testing.T) {
+		_, err := sender.Send(context.Background(), srv.URL, "item.created", &QuotaError{})
+		assert.ErrorContains(t, err, "has a payload of type Item, not QuotaError")
+	})
+}
+
+func TestRegisterWebhook(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.RegisterWebhook("item.created", &Item{})
+
+	event, ok := api.GetWebhook("item.created")
+	assert.Assert(t, ok)
+	assert.Equal(t, "Item", event.Payload.Name())
+	assert.Equal(t, 0, len(api.UnusedModels()))
+	assert.Assert(t, cmpPanics(func() { api.RegisterWebhook("item.created", &Item{}) }))
+}
+
+func TestWebhookSender_Limits(t *testing.T) {
+	api := mason.NewAPI(mason.NewHTTPRuntime())
+	api.RegisterWebhook("item.created", &Item{})
+
+	t.Run("retry after capped", func(t *testing.T) {
+		attempts := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts++; attempts == 1 {
+				w.Header().Set("Retry-After", "3600")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		sender := mason.NewWebhookSender(api, []byte("secret"), mason.WithWebhookMaxBackoff(time.Millisecond))
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		delivery, err := sender.Send(ctx, srv.URL, "item.created", &Item{Title: "a"})
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(delivery.Attempts))
+	})
+
+	t.Run("redirect refused", func(t *testing.T) {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("the redirect was followed")
+		}))
+		defer target.Close()
+		srv := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		defer srv.Close()
+
+		sender := mason.NewWebhookSender(api, []byte("secret"))
+		delivery, err := sender.Send(context.Background(), srv.URL, "item.created", &Item{Title: "a"})
+		assert.ErrorContains(t, err, "status 307")
+		assert.Equal(t, 1, len(delivery.Attempts))
+	})
+}
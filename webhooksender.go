@@ -0,0 +1,278 @@
+package mason
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tailbits/mason/model"
+)
+
+// The headers of the webhook requests. The signature is the HMAC-SHA256 of the ID, the timestamp and the body,
+// separated by dots, encoded in base64 and prefixed with the version of the scheme, like v1,<signature>.
+const (
+	WebhookIDHeader        = "Webhook-Id"
+	WebhookEventHeader     = "Webhook-Event"
+	WebhookTimestampHeader = "Webhook-Timestamp"
+	WebhookSignatureHeader = "Webhook-Signature"
+)
+
+// DeliveryAttempt is an attempt to deliver an event to a webhook.
+type DeliveryAttempt struct {
+	// DeliveryID identifies the delivery, and is sent in the Webhook-Id header of all its attempts, so the receivers
+	// can ignore the events they already got.
+	DeliveryID string
+	Event      string
+	URL        string
+	// Attempt is the number of the attempt, from 1.
+	Attempt  int
+	Time     time.Time
+	Duration time.Duration
+	// Status is the status of the response, 0 when there was none.
+	Status int
+	// Err is the failure of the attempt, nil when the webhook responded with a 2xx.
+	Err error
+}
+
+// Delivery is the delivery of an event to a webhook, with its attempts.
+type Delivery struct {
+	ID       string
+	Event    string
+	URL      string
+	Attempts []DeliveryAttempt
+}
+
+// DeliveryLog records the attempts of the deliveries, e.g. in a table the clients can browse to debug their
+// webhooks.
+type DeliveryLog interface {
+	Record(ctx context.Context, attempt DeliveryAttempt)
+}
+
+var _ DeliveryLog = (*MemoryDeliveryLog)(nil)
+
+// MemoryDeliveryLog is a DeliveryLog that keeps the attempts in memory, for tests and single instance deployments.
+type MemoryDeliveryLog struct {
+	mu       sync.Mutex
+	attempts []DeliveryAttempt
+}
+
+func (l *MemoryDeliveryLog) Record(_ context.Context, attempt DeliveryAttempt) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.attempts = append(l.attempts, attempt)
+}
+
+// Attempts returns the attempts recorded, in order.
+func (l *MemoryDeliveryLog) Attempts() []DeliveryAttempt {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]DeliveryAttempt(nil), l.attempts...)
+}
+
+// WebhookSenderOption configures a WebhookSender.
+type WebhookSenderOption func(s *WebhookSender)
+
+// WithWebhookClient sets the client sending the webhook requests, by default a client with a timeout of 10 seconds,
+// which does not follow redirects.
+func WithWebhookClient(client *http.Client) WebhookSenderOption {
+	return func(s *WebhookSender) {
+		s.client = client
+	}
+}
+
+// WithWebhookRetries sets the number of attempts of a delivery, 5 by default, and the delay before the first retry,
+// 1 second by default, which doubles after each retry.
+func WithWebhookRetries(attempts int, backoff time.Duration) WebhookSenderOption {
+	return func(s *WebhookSender) {
+		s.attempts = max(attempts, 1)
+		s.backoff = backoff
+	}
+}
+
+// WithWebhookMaxBackoff caps the delay before a retry, 5 minutes by default, including the delays asked by the
+// Retry-After headers of the webhooks.
+func WithWebhookMaxBackoff(d time.Duration) WebhookSenderOption {
+	return func(s *WebhookSender) {
+		s.maxBackoff = d
+	}
+}
+
+// WithDeliveryLog records the attempts of the deliveries in the log.
+func WithDeliveryLog(log DeliveryLog) WebhookSenderOption {
+	return func(s *WebhookSender) {
+		s.log = log
+	}
+}
+
+// WebhookSender delivers the events registered with API.RegisterWebhook to the webhooks of the clients, as signed
+// POST requests with the JSON payload of the event.
+type WebhookSender struct {
+	api        *API
+	key        []byte
+	client     *http.Client
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	log        DeliveryLog
+	now        func() time.Time
+}
+
+// NewWebhookSender returns a sender of the webhook events of the API, signing them with the secret key.
+func NewWebhookSender(api *API, key []byte, opts ...WebhookSenderOption) *WebhookSender {
+	s := &WebhookSender{
+		api: api,
+		key: key,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			// a redirect would send the signed event to another URL than the one of the webhook
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		attempts:   5,
+		backoff:    time.Second,
+		maxBackoff: 5 * time.Minute,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Send delivers the payload of the event to the webhook URL. The payload must be the entity of the event, and is
+// validated against its schema. The attempts failing with a network error, a 408, a 429 or a 5xx are retried with an
+// exponential backoff with jitter, or after the delay of the Retry-After header of the response, both capped by the
+// WithWebhookMaxBackoff. Send returns the delivery with its attempts, and the error of the last attempt when none
+// succeeded.
+func (s *WebhookSender) Send(ctx context.Context, url string, event string, payload model.Entity) (Delivery, error) {
+	def, ok := s.api.GetWebhook(event)
+	if !ok {
+		return Delivery{}, fmt.Errorf("webhook event %s is not registered", event)
+	}
+	if payload.Name() != def.Payload.Name() {
+		return Delivery{}, fmt.Errorf("webhook event %s has a payload of type %s, not %s", event, def.Payload.Name(), payload.Name())
+	}
+
+	body, err := payload.Marshal()
+	if err != nil {
+		return Delivery{}, fmt.Errorf("failed to marshal the payload of webhook event %s: %w", event, err)
+	}
+	if err := s.api.validateEntity(def.Payload, body); err != nil {
+		return Delivery{}, fmt.Errorf("invalid payload of webhook event %s: %w", event, err)
+	}
+
+	delivery := Delivery{ID: newDeliveryID(), Event: event, URL: url}
+	delay := s.backoff
+	for i := 1; ; i++ {
+		attempt, retryAfter := s.attempt(ctx, delivery, i, body)
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		if s.log != nil {
+			s.log.Record(ctx, attempt)
+		}
+		if attempt.Err == nil {
+			return delivery, nil
+		}
+		if i >= s.attempts || retryAfter < 0 {
+			return delivery, attempt.Err
+		}
+
+		// the jitter spreads the retries of the deliveries failing together, e.g. while a webhook is down
+		wait := delay/2 + mathrand.N(delay/2+1)
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return delivery, ctx.Err()
+		case <-time.After(min(wait, s.maxBackoff)):
+		}
+		delay = min(delay*2, s.maxBackoff)
+	}
+}
+
+// attempt sends the request of an attempt. It returns the delay of the Retry-After header of the response, if any,
+// or a negative delay when the attempt must not be retried.
+func (s *WebhookSender) attempt(ctx context.Context, delivery Delivery, n int, body []byte) (DeliveryAttempt, time.Duration) {
+	start := s.now()
+	attempt := DeliveryAttempt{
+		DeliveryID: delivery.ID,
+		Event:      delivery.Event,
+		URL:        delivery.URL,
+		Attempt:    n,
+		Time:       start,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Err = fmt.Errorf("failed to create the webhook request: %w", err)
+		return attempt, -1
+	}
+	timestamp := strconv.FormatInt(start.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, delivery.ID)
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "v1,"+s.Sign(delivery.ID, timestamp, body))
+
+	rsp, err := s.client.Do(req)
+	attempt.Duration = s.now().Sub(start)
+	if err != nil {
+		attempt.Err = fmt.Errorf("failed to deliver webhook event %s: %w", delivery.Event, err)
+		if ctx.Err() != nil {
+			return attempt, -1
+		}
+		return attempt, 0
+	}
+	defer rsp.Body.Close()
+	// the body is drained, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(rsp.Body, 64<<10))
+
+	attempt.Status = rsp.StatusCode
+	if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
+		return attempt, 0
+	}
+	attempt.Err = fmt.Errorf("webhook event %s was refused with status %d", delivery.Event, rsp.StatusCode)
+
+	switch {
+	case rsp.StatusCode == http.StatusRequestTimeout, rsp.StatusCode == http.StatusTooManyRequests, rsp.StatusCode >= 500:
+		if seconds, err := strconv.Atoi(rsp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			return attempt, time.Duration(seconds) * time.Second
+		}
+		return attempt, 0
+	default:
+		return attempt, -1
+	}
+}
+
+// Sign returns the signature of the body of a webhook request, without the version prefix. Receivers sharing the
+// key compute it to check the Webhook-Signature header, with the Webhook-Id and the Webhook-Timestamp headers.
+func (s *WebhookSender) Sign(id string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(id + "." + timestamp + "."))
+	mac.Write(body)
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newDeliveryID returns a random ID for a delivery.
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return "msg_" + hex.EncodeToString(b)
+}